@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"wox/ui"
+)
+
+type cliCommand struct {
+	Usage       string
+	Description string
+	Method      string
+	Path        string
+	MinArgs     int
+	Body        func(args []string) map[string]string
+}
+
+// "wox <command>" talks to the running instance through automation api, see docs/automation.md
+var cliCommands = map[string]cliCommand{
+	"query": {
+		Usage:       "query <text>",
+		Description: "run a query and print results as json",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/query",
+		MinArgs:     1,
+		Body: func(args []string) map[string]string {
+			return map[string]string{"query": strings.Join(args, " ")}
+		},
+	},
+	"action": {
+		Usage:       "action <result id> <action id>",
+		Description: "execute an action of a result returned by last query",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/action",
+		MinArgs:     2,
+		Body: func(args []string) map[string]string {
+			return map[string]string{"resultId": args[0], "actionId": args[1]}
+		},
+	},
+	"change-query": {
+		Usage:       "change-query <text>",
+		Description: "show wox with the given query",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/query/change",
+		MinArgs:     1,
+		Body: func(args []string) map[string]string {
+			return map[string]string{"query": strings.Join(args, " ")}
+		},
+	},
+	"show":    {Usage: "show", Description: "show wox", Method: http.MethodPost, Path: "/api/v1/show"},
+	"hide":    {Usage: "hide", Description: "hide wox", Method: http.MethodPost, Path: "/api/v1/hide"},
+	"toggle":  {Usage: "toggle", Description: "show or hide wox", Method: http.MethodPost, Path: "/api/v1/toggle"},
+	"plugins": {Usage: "plugins", Description: "list installed plugins as json", Method: http.MethodGet, Path: "/api/v1/plugins"},
+}
+
+func isCliCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	_, exist := cliCommands[args[0]]
+	return exist || args[0] == "help" || args[0] == "--help"
+}
+
+// runCli runs a cli command and returns the process exit code
+func runCli(ctx context.Context, args []string) int {
+	command, exist := cliCommands[args[0]]
+	if !exist {
+		printCliUsage(os.Stdout)
+		return 0
+	}
+	if len(args)-1 < command.MinArgs {
+		fmt.Fprintf(os.Stderr, "usage: wox %s\n", command.Usage)
+		return 2
+	}
+
+	port := getExistingInstancePort(ctx)
+	if port == 0 {
+		fmt.Fprintln(os.Stderr, "wox is not running")
+		return 1
+	}
+	token, tokenErr := ui.GetAutomationToken(ctx)
+	if tokenErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to read automation token: %s\n", tokenErr.Error())
+		return 1
+	}
+
+	var body io.Reader
+	if command.Body != nil {
+		bodyJson, marshalErr := json.Marshal(command.Body(args[1:]))
+		if marshalErr != nil {
+			fmt.Fprintln(os.Stderr, marshalErr.Error())
+			return 1
+		}
+		body = bytes.NewReader(bodyJson)
+	}
+
+	request, requestErr := http.NewRequestWithContext(ctx, command.Method, fmt.Sprintf("http://localhost:%d%s", port, command.Path), body)
+	if requestErr != nil {
+		fmt.Fprintln(os.Stderr, requestErr.Error())
+		return 1
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: time.Minute * 2}
+	response, responseErr := client.Do(request)
+	if responseErr != nil {
+		fmt.Fprintln(os.Stderr, responseErr.Error())
+		return 1
+	}
+	defer response.Body.Close()
+
+	var restResponse ui.RestResponse
+	if decodeErr := json.NewDecoder(response.Body).Decode(&restResponse); decodeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to decode response: %s\n", decodeErr.Error())
+		return 1
+	}
+	if !restResponse.Success {
+		fmt.Fprintln(os.Stderr, restResponse.Message)
+		return 1
+	}
+
+	if data, ok := restResponse.Data.(string); ok && data == "" {
+		return 0
+	}
+	output, _ := json.MarshalIndent(restResponse.Data, "", "  ")
+	fmt.Println(string(output))
+	return 0
+}
+
+func printCliUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: wox <command> [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "commands:")
+	for _, name := range []string{"query", "action", "change-query", "show", "hide", "toggle", "plugins"} {
+		command := cliCommands[name]
+		fmt.Fprintf(w, "  %-32s %s\n", command.Usage, command.Description)
+	}
+}
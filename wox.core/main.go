@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -45,6 +46,13 @@ func main() {
 	})
 
 	ctx := util.NewTraceContext()
+	if isCliCommand(os.Args[1:]) {
+		os.Exit(runCli(ctx, os.Args[1:]))
+	}
+
+	// headless mode runs settings, plugins and http api only, without ui, tray, hotkeys and selection, see docs/automation.md
+	isHeadless := slices.Contains(os.Args[1:], "--headless")
+
 	util.GetLogger().Info(ctx, "------------------------------")
 	util.GetLogger().Info(ctx, "Wox starting")
 	util.GetLogger().Info(ctx, fmt.Sprintf("golang version: %s", strings.ReplaceAll(runtime.Version(), "go", "")))
@@ -66,6 +74,10 @@ func main() {
 	// check if there is existing instance running
 	if existingPort := getExistingInstancePort(ctx); existingPort > 0 {
 		util.GetLogger().Error(ctx, fmt.Sprintf("there is existing instance running, port: %d", existingPort))
+		if isHeadless {
+			fmt.Fprintf(os.Stderr, "there is existing instance running, port: %d\n", existingPort)
+			os.Exit(1)
+		}
 		_, postShowErr := util.HttpPost(ctx, fmt.Sprintf("http://localhost:%d/show", existingPort), "")
 		if postShowErr != nil {
 			util.GetLogger().Error(ctx, fmt.Sprintf("failed to show existing instance: %s", postShowErr.Error()))
@@ -81,7 +93,7 @@ func main() {
 		}
 	}
 
-	extractErr := resource.Extract(ctx)
+	extractErr := resource.Extract(ctx, !isHeadless)
 	if extractErr != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to extract embed file: %s", extractErr.Error()))
 		return
@@ -100,19 +112,29 @@ func main() {
 		return
 	}
 
+	if isHeadless {
+		ui.GetUIManager().EnableHeadless()
+	}
 	themeErr := ui.GetUIManager().Start(ctx)
 	if themeErr != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("failed to initialize themes: %s", themeErr.Error()))
 		return
 	}
 
-	if woxSetting.ShowTray {
+	if woxSetting.ShowTray && !isHeadless {
 		ui.GetUIManager().ShowTray()
 	}
 
 	shareUI := ui.GetUIManager().GetUI(ctx)
 	plugin.GetPluginManager().Start(ctx, shareUI)
 
+	if isHeadless {
+		util.GetLogger().Info(ctx, "running in headless mode")
+		fmt.Printf("wox is running in headless mode, port: %d, token file: %s\n", serverPort, util.GetLocation().GetAutomationTokenPath())
+		ui.GetUIManager().StartWebsocketAndWait(ctx)
+		return
+	}
+
 	util.InitSelection()
 
 	// hotkey must be registered in main thread
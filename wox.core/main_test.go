@@ -339,7 +339,7 @@ func init() {
 	}
 
 	// Extract resources
-	err = resource.Extract(ctx)
+	err = resource.Extract(ctx, true)
 	if err != nil {
 		panic(err)
 	}
@@ -11,6 +11,7 @@ import (
 	"wox/setting"
 	"wox/share"
 	"wox/util"
+	"wox/util/secret"
 
 	"github.com/disintegration/imaging"
 	"github.com/samber/lo"
@@ -33,13 +34,15 @@ type API interface {
 	Log(ctx context.Context, level LogLevel, msg string)
 	GetTranslation(ctx context.Context, key string) string
 	GetSetting(ctx context.Context, key string) string
-	SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool)
+	SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) error
 	OnSettingChanged(ctx context.Context, callback func(key string, value string))
 	OnGetDynamicSetting(ctx context.Context, callback func(key string) string)
 	OnDeepLink(ctx context.Context, callback func(arguments map[string]string))
 	OnUnload(ctx context.Context, callback func())
 	RegisterQueryCommands(ctx context.Context, commands []MetadataCommand)
 	AIChatStream(ctx context.Context, model ai.Model, conversations []ai.Conversation, callback ai.ChatStreamFunc) error
+	PushResults(ctx context.Context, queryId string, results []QueryResult) error
+	RegisterQueryVariable(ctx context.Context, name string, description string, resolver QueryVariableResolver) error
 }
 
 type APIImpl struct {
@@ -107,6 +110,20 @@ func (a *APIImpl) GetTranslation(ctx context.Context, key string) string {
 func (a *APIImpl) GetSetting(ctx context.Context, key string) string {
 	// try to get platform specific setting first
 	platformSpecificKey := key + "@" + util.GetCurrentPlatform()
+	if a.isSecretSetting(key) {
+		for _, k := range []string{platformSpecificKey, key} {
+			v, getErr := secret.GetSecretManager().Get(ctx, setting.GetPluginSecretKey(a.pluginInstance.Metadata.Id, k))
+			if getErr == nil {
+				return v
+			}
+		}
+	}
+
+	// active setting profile may override this setting, secrets are never overridden
+	if v, overridden := setting.GetSettingManager().GetProfilePluginSetting(a.pluginInstance.Metadata.Id, key); overridden && !a.isSecretSetting(key) {
+		return v
+	}
+
 	v, exist := a.pluginInstance.Setting.GetSetting(platformSpecificKey)
 	if exist {
 		return v
@@ -119,7 +136,24 @@ func (a *APIImpl) GetSetting(ctx context.Context, key string) string {
 	return ""
 }
 
-func (a *APIImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
+func (a *APIImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) error {
+	if !a.isSecretSetting(key) {
+		existValue, _ := setting.GetSettingManager().GetProfilePluginSetting(a.pluginInstance.Metadata.Id, key)
+		overridden, updateErr := setting.GetSettingManager().UpdateProfilePluginSetting(ctx, a.pluginInstance.Metadata.Id, key, value)
+		if overridden {
+			if updateErr != nil {
+				a.logger.Error(ctx, fmt.Sprintf("failed to save setting to profile: %s", updateErr.Error()))
+				return updateErr
+			}
+			if existValue != value {
+				for _, callback := range a.pluginInstance.SettingChangeCallbacks {
+					callback(key, value)
+				}
+			}
+			return nil
+		}
+	}
+
 	finalKey := key
 	if isPlatformSpecific {
 		finalKey = key + "@" + util.GetCurrentPlatform()
@@ -128,12 +162,16 @@ func (a *APIImpl) SaveSetting(ctx context.Context, key string, value string, isP
 		a.pluginInstance.Setting.Settings.Delete(key + "@" + util.GetCurrentPlatform())
 	}
 
+	if a.isSecretSetting(key) {
+		return a.saveSecretSetting(ctx, key, finalKey, value, isPlatformSpecific)
+	}
+
 	existValue, exist := a.pluginInstance.Setting.Settings.Load(finalKey)
 	a.pluginInstance.Setting.Settings.Store(finalKey, value)
 	saveErr := a.pluginInstance.SaveSetting(ctx)
 	if saveErr != nil {
 		a.logger.Error(ctx, fmt.Sprintf("failed to save setting: %s", saveErr.Error()))
-		return
+		return saveErr
 	}
 
 	if !exist || (existValue != value) {
@@ -141,6 +179,47 @@ func (a *APIImpl) SaveSetting(ctx context.Context, key string, value string, isP
 			callback(key, value)
 		}
 	}
+	return nil
+}
+
+func (a *APIImpl) isSecretSetting(key string) bool {
+	settingDefinition, found := a.pluginInstance.Metadata.SettingDefinitions.GetDefinition(key)
+	return found && settingDefinition.IsSecretSetting()
+}
+
+// saveSecretSetting stores value in secret store and removes any plaintext copy left in setting file
+func (a *APIImpl) saveSecretSetting(ctx context.Context, key string, finalKey string, value string, isPlatformSpecific bool) error {
+	existValue := a.GetSetting(ctx, key)
+	pluginId := a.pluginInstance.Metadata.Id
+
+	var saveErr error
+	if value == "" {
+		saveErr = secret.GetSecretManager().Delete(ctx, setting.GetPluginSecretKey(pluginId, finalKey))
+	} else {
+		saveErr = secret.GetSecretManager().Set(ctx, setting.GetPluginSecretKey(pluginId, finalKey), value)
+	}
+	if saveErr != nil {
+		a.logger.Error(ctx, fmt.Sprintf("failed to save secret setting %s: %s", key, saveErr.Error()))
+		return fmt.Errorf("failed to save secret setting %s: %w", key, saveErr)
+	}
+	if !isPlatformSpecific {
+		secret.GetSecretManager().Delete(ctx, setting.GetPluginSecretKey(pluginId, key+"@"+util.GetCurrentPlatform()))
+	}
+
+	_, hasPlaintext := a.pluginInstance.Setting.Settings.Load(finalKey)
+	if hasPlaintext {
+		a.pluginInstance.Setting.Settings.Delete(finalKey)
+		if saveSettingErr := a.pluginInstance.SaveSetting(ctx); saveSettingErr != nil {
+			a.logger.Error(ctx, fmt.Sprintf("failed to save setting: %s", saveSettingErr.Error()))
+		}
+	}
+
+	if existValue != value {
+		for _, callback := range a.pluginInstance.SettingChangeCallbacks {
+			callback(key, value)
+		}
+	}
+	return nil
 }
 
 func (a *APIImpl) OnSettingChanged(ctx context.Context, callback func(key string, value string)) {
@@ -174,6 +253,16 @@ func (a *APIImpl) RegisterQueryCommands(ctx context.Context, commands []Metadata
 	a.pluginInstance.SaveSetting(ctx)
 }
 
+// PushResults shows results of a running query before plugin's Query returns, it fails if the query is already finished
+func (a *APIImpl) PushResults(ctx context.Context, queryId string, results []QueryResult) error {
+	return GetPluginManager().PushResults(ctx, a.pluginInstance.Metadata.Id, queryId, results)
+}
+
+// RegisterQueryVariable adds a {wox:<name>} variable usable in query hotkeys, query shortcuts and other places accepting query variables
+func (a *APIImpl) RegisterQueryVariable(ctx context.Context, name string, description string, resolver QueryVariableResolver) error {
+	return GetPluginManager().RegisterQueryVariable(ctx, a.pluginInstance.Metadata.Id, name, description, resolver)
+}
+
 func (a *APIImpl) AIChatStream(ctx context.Context, model ai.Model, conversations []ai.Conversation, callback ai.ChatStreamFunc) error {
 	//check if plugin has the feature permission
 	if !a.pluginInstance.Metadata.IsSupportFeature(MetadataFeatureAI) {
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"sort"
 	"wox/i18n"
+	"wox/setting"
 	"wox/updater"
 	"wox/util"
+	"wox/util/interpreter"
 	"wox/util/permission"
+
+	"github.com/samber/lo"
 )
 
 type DoctorCheckResult struct {
@@ -28,6 +32,8 @@ func RunDoctorChecks(ctx context.Context) []DoctorCheckResult {
 		results = append(results, checkAccessibilityPermission(ctx))
 	}
 
+	results = append(results, checkInterpreters(ctx)...)
+
 	//sort by status, false first
 	sort.Slice(results, func(i, j int) bool {
 		return !results[i].Status && results[j].Status
@@ -94,3 +100,57 @@ func checkAccessibilityPermission(ctx context.Context) DoctorCheckResult {
 		},
 	}
 }
+
+// checkInterpreters reports interpreter discovery results for runtimes used by installed plugins
+func checkInterpreters(ctx context.Context) []DoctorCheckResult {
+	metadataList, metadataErr := GetPluginManager().getUserPluginMetadataList(ctx)
+	if metadataErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to get plugin metadata for interpreter check: %s", metadataErr.Error()))
+		return nil
+	}
+
+	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
+	checks := []struct {
+		runtime         Runtime
+		interpreterType interpreter.Type
+		name            string
+		customPath      string
+	}{
+		{PLUGIN_RUNTIME_PYTHON, interpreter.TypePython, "i18n:plugin_doctor_python", woxSetting.CustomPythonPath.Get()},
+		{PLUGIN_RUNTIME_NODEJS, interpreter.TypeNodejs, "i18n:plugin_doctor_nodejs", woxSetting.CustomNodejsPath.Get()},
+	}
+
+	var results []DoctorCheckResult
+	for _, check := range checks {
+		isUsed := lo.ContainsBy(metadataList, func(item MetadataWithDirectory) bool {
+			return ConvertToRuntime(item.Metadata.Runtime) == check.runtime
+		})
+		if !isUsed {
+			continue
+		}
+
+		discovery := interpreter.Discover(ctx, check.interpreterType, check.customPath)
+		if !discovery.IsFound() {
+			results = append(results, DoctorCheckResult{
+				Name:        check.name,
+				Status:      false,
+				Description: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_doctor_interpreter_not_found"), discovery.MinVersion, len(discovery.Candidates)),
+				ActionName:  "",
+				Action: func(ctx context.Context) {
+				},
+			})
+			continue
+		}
+
+		results = append(results, DoctorCheckResult{
+			Name:        check.name,
+			Status:      true,
+			Description: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_doctor_interpreter_found"), discovery.Path, discovery.Version, discovery.Source),
+			ActionName:  "",
+			Action: func(ctx context.Context) {
+			},
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,116 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"wox/plugin"
+	"wox/util"
+)
+
+func init() {
+	plugin.AllHosts = append(plugin.AllHosts, &GoHost{})
+}
+
+// GoHost runs third-party go plugins, see wox.plugin.go.
+// Each plugin is a standalone executable which serves the websocket host protocol itself,
+// so every plugin owns a dedicated host process and there is no shared host to start.
+// System plugins are also written in go, but they are loaded in process and don't go through this host.
+type GoHost struct {
+}
+
+func (g *GoHost) GetRuntime(ctx context.Context) plugin.Runtime {
+	return plugin.PLUGIN_RUNTIME_GO
+}
+
+func (g *GoHost) Start(ctx context.Context) error {
+	return nil
+}
+
+func (g *GoHost) Stop(ctx context.Context) {
+	stopIsolatedHosts(ctx, g)
+}
+
+func (g *GoHost) IsStarted(ctx context.Context) bool {
+	return true
+}
+
+func (g *GoHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (plugin.Plugin, error) {
+	entryPath, entryErr := g.getEntryPath(metadata, pluginDirectory)
+	if entryErr != nil {
+		return nil, entryErr
+	}
+
+	// resource limits and idle timeout only apply when plugin declares isolatedHost feature
+	var params plugin.MetadataFeatureParamsIsolatedHost
+	if metadata.IsSupportFeature(plugin.MetadataFeatureIsolatedHost) {
+		featureParams, paramsErr := metadata.GetFeatureParamsForIsolatedHost()
+		if paramsErr != nil {
+			return nil, paramsErr
+		}
+		params = featureParams
+	}
+
+	// plugin may be reloaded, stop the previous process first
+	unloadIsolatedPlugin(ctx, metadata)
+
+	pluginHost := &WebsocketHost{
+		host:       g,
+		requestMap: util.NewHashMap[string, *hostResponseQueue](),
+		isolatedPlugin: &isolatedPlugin{
+			metadata:            metadata,
+			pluginDirectory:     pluginDirectory,
+			params:              params,
+			lastActiveTimestamp: util.GetSystemTimestamp(),
+		},
+	}
+	startErr := pluginHost.StartHost(ctx, entryPath, "", nil)
+	if startErr != nil {
+		return nil, fmt.Errorf("failed to start go plugin: %w", startErr)
+	}
+
+	pluginInstance, loadErr := pluginHost.LoadPlugin(ctx, metadata, pluginDirectory, nil)
+	if loadErr != nil {
+		pluginHost.StopHost(ctx)
+		return nil, loadErr
+	}
+	isolatedHosts.Store(metadata.Id, pluginHost)
+
+	if params.IdleTimeoutMinutes > 0 {
+		util.Go(ctx, fmt.Sprintf("[%s] watch go plugin idle", metadata.Name), func() {
+			pluginHost.watchIsolatedHostIdle(util.NewTraceContext())
+		})
+	}
+
+	return pluginInstance, nil
+}
+
+func (g *GoHost) UnloadPlugin(ctx context.Context, metadata plugin.Metadata) {
+	unloadIsolatedPlugin(ctx, metadata)
+}
+
+func (g *GoHost) getEntryPath(metadata plugin.Metadata, pluginDirectory string) (string, error) {
+	entryPath := metadata.Entry
+	if !filepath.IsAbs(entryPath) {
+		entryPath = filepath.Join(pluginDirectory, entryPath)
+	}
+	if util.IsWindows() && !strings.HasSuffix(strings.ToLower(entryPath), ".exe") && !util.IsFileExists(entryPath) {
+		entryPath += ".exe"
+	}
+
+	entryStat, statErr := os.Stat(entryPath)
+	if statErr != nil {
+		return "", fmt.Errorf("failed to find go plugin executable: %w", statErr)
+	}
+
+	// executable bit may be lost after unzipping the plugin package
+	if !util.IsWindows() && entryStat.Mode()&0111 == 0 {
+		if chmodErr := os.Chmod(entryPath, entryStat.Mode()|0755); chmodErr != nil {
+			return "", fmt.Errorf("failed to make go plugin executable: %w", chmodErr)
+		}
+	}
+
+	return entryPath, nil
+}
@@ -0,0 +1,181 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"wox/plugin"
+	"wox/setting"
+	"wox/util"
+)
+
+// dedicated hosts for plugins which run in their own host process, key is plugin id
+var isolatedHosts = util.NewHashMap[string, *WebsocketHost]()
+
+// isolatedPlugin holds the state of a host dedicated to a single plugin
+type isolatedPlugin struct {
+	metadata            plugin.Metadata
+	pluginDirectory     string
+	params              plugin.MetadataFeatureParamsIsolatedHost
+	loadParams          map[string]string // extra params passed to host when loading plugin
+	lock                sync.Mutex
+	isIdle              bool // host is stopped because of idle, it will be restarted on next request
+	isRemoved           bool // plugin is unloaded, host won't be restarted anymore
+	lastActiveTimestamp int64
+}
+
+func isIsolatedPlugin(ctx context.Context, metadata plugin.Metadata) bool {
+	if metadata.IsSupportFeature(plugin.MetadataFeatureIsolatedHost) {
+		return true
+	}
+
+	pluginSetting, settingErr := setting.GetSettingManager().LoadPluginSetting(ctx, metadata.Id, metadata.Name, metadata.SettingDefinitions)
+	return settingErr == nil && pluginSetting.IsolatedHost
+}
+
+// loadIsolatedPlugin starts a dedicated host with the same entry as the shared host, then loads the plugin into it.
+// The host is run by executablePath if it's not empty (e.g. python of plugin virtualenv), otherwise by the executable of shared host
+func loadIsolatedPlugin(ctx context.Context, sharedHost *WebsocketHost, executablePath string, metadata plugin.Metadata, pluginDirectory string, extraParams map[string]string) (plugin.Plugin, error) {
+	params, paramsErr := metadata.GetFeatureParamsForIsolatedHost()
+	if paramsErr != nil {
+		return nil, paramsErr
+	}
+	if sharedHost.executablePath == "" {
+		return nil, fmt.Errorf("%s host is not started", sharedHost.host.GetRuntime(ctx))
+	}
+	if executablePath == "" {
+		executablePath = sharedHost.executablePath
+	}
+
+	// plugin may be reloaded, stop the previous dedicated host first
+	unloadIsolatedPlugin(ctx, metadata)
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("start dedicated host for plugin %s", metadata.Name))
+	isolatedHost := &WebsocketHost{
+		host:       sharedHost.host,
+		requestMap: util.NewHashMap[string, *hostResponseQueue](),
+		isolatedPlugin: &isolatedPlugin{
+			metadata:            metadata,
+			pluginDirectory:     pluginDirectory,
+			params:              params,
+			loadParams:          extraParams,
+			lastActiveTimestamp: util.GetSystemTimestamp(),
+		},
+	}
+	startErr := isolatedHost.StartHost(ctx, executablePath, sharedHost.entry, sharedHost.envs, sharedHost.executableArgs...)
+	if startErr != nil {
+		return nil, fmt.Errorf("failed to start dedicated host: %w", startErr)
+	}
+
+	pluginInstance, loadErr := isolatedHost.LoadPlugin(ctx, metadata, pluginDirectory, extraParams)
+	if loadErr != nil {
+		isolatedHost.StopHost(ctx)
+		return nil, loadErr
+	}
+	isolatedHosts.Store(metadata.Id, isolatedHost)
+
+	if params.IdleTimeoutMinutes > 0 {
+		util.Go(ctx, fmt.Sprintf("[%s] watch dedicated host idle", metadata.Name), func() {
+			isolatedHost.watchIsolatedHostIdle(util.NewTraceContext())
+		})
+	}
+
+	return pluginInstance, nil
+}
+
+// unloadIsolatedPlugin returns false if the plugin is not running in a dedicated host
+func unloadIsolatedPlugin(ctx context.Context, metadata plugin.Metadata) bool {
+	isolatedHost, exist := isolatedHosts.Load(metadata.Id)
+	if !exist {
+		return false
+	}
+
+	isolatedHost.isolatedPlugin.lock.Lock()
+	isolatedHost.isolatedPlugin.isRemoved = true
+	if !isolatedHost.isolatedPlugin.isIdle {
+		isolatedHost.UnloadPlugin(ctx, metadata)
+	}
+	isolatedHost.StopHost(ctx)
+	isolatedHost.isolatedPlugin.lock.Unlock()
+
+	isolatedHosts.Delete(metadata.Id)
+	return true
+}
+
+func stopIsolatedHosts(ctx context.Context, runtimeHost plugin.Host) {
+	for _, isolatedHost := range isolatedHosts.FilterList(func(_ string, h *WebsocketHost) bool { return h.host == runtimeHost }) {
+		unloadIsolatedPlugin(ctx, isolatedHost.isolatedPlugin.metadata)
+	}
+}
+
+// isServedBySharedHost returns true if the plugin instance is not running in a dedicated host
+func isServedBySharedHost(instance *plugin.Instance) bool {
+	return !isolatedHosts.Exist(instance.Metadata.Id)
+}
+
+func (w *WebsocketHost) watchIsolatedHostIdle(ctx context.Context) {
+	idleTimeout := time.Duration(w.isolatedPlugin.params.IdleTimeoutMinutes) * time.Minute
+	checkInterval := min(idleTimeout, time.Minute)
+	for {
+		time.Sleep(checkInterval)
+
+		w.isolatedPlugin.lock.Lock()
+		if w.isolatedPlugin.isRemoved {
+			w.isolatedPlugin.lock.Unlock()
+			return
+		}
+		if !w.isolatedPlugin.isIdle && util.GetSystemTimestamp()-w.isolatedPlugin.lastActiveTimestamp > idleTimeout.Milliseconds() {
+			util.GetLogger().Info(ctx, fmt.Sprintf("[%s] dedicated host is idle for %s, stop it", w.isolatedPlugin.metadata.Name, idleTimeout))
+			w.isolatedPlugin.isIdle = true
+			w.StopHost(ctx)
+		}
+		w.isolatedPlugin.lock.Unlock()
+	}
+}
+
+// wakeIsolatedHost restarts the dedicated host if it's stopped because of idle
+func (w *WebsocketHost) wakeIsolatedHost(ctx context.Context) error {
+	w.isolatedPlugin.lock.Lock()
+	defer w.isolatedPlugin.lock.Unlock()
+
+	w.isolatedPlugin.lastActiveTimestamp = util.GetSystemTimestamp()
+	if !w.isolatedPlugin.isIdle || w.isolatedPlugin.isRemoved {
+		return nil
+	}
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("[%s] wake up idle dedicated host", w.isolatedPlugin.metadata.Name))
+	startErr := w.StartHost(ctx, w.executablePath, w.entry, w.envs, w.executableArgs...)
+	if startErr != nil {
+		return fmt.Errorf("failed to wake up dedicated host: %w", startErr)
+	}
+	w.isolatedPlugin.isIdle = false
+
+	return w.reloadIsolatedPlugin(ctx)
+}
+
+// reloadIsolatedPlugin loads and inits the plugin again after dedicated host process is restarted
+func (w *WebsocketHost) reloadIsolatedPlugin(ctx context.Context) error {
+	metadata := w.isolatedPlugin.metadata
+	if _, loadErr := w.LoadPlugin(ctx, metadata, w.isolatedPlugin.pluginDirectory, w.isolatedPlugin.loadParams); loadErr != nil {
+		return loadErr
+	}
+
+	for _, instance := range plugin.GetPluginManager().GetPluginInstances() {
+		if instance.Metadata.Id != metadata.Id {
+			continue
+		}
+		if instance.IsDisabled() {
+			return nil
+		}
+
+		// callbacks registered by previous host process are gone, plugin will register them again in init
+		instance.SettingChangeCallbacks = nil
+		instance.DynamicSettingCallbacks = nil
+		instance.DeepLinkCallbacks = nil
+		instance.UnloadCallbacks = nil
+		instance.Plugin.Init(ctx, plugin.InitParams{API: instance.API, PluginDirectory: w.isolatedPlugin.pluginDirectory})
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"wox/plugin"
+	"wox/util"
+)
+
+const cgroupCpuPeriod = 100000
+
+// startLimitedHostProcess starts the host process with memory and cpu limits applied before it executes any code.
+// The process is spawned directly into a cgroup v2 group of the plugin. If cgroup is not writable (e.g. not delegated to current user),
+// memory is limited by rlimit which is set by a shell wrapper before exec, cpu limit can't be enforced in that case
+func startLimitedHostProcess(ctx context.Context, pluginId string, params plugin.MetadataFeatureParamsIsolatedHost, name string, envs []string, args ...string) (*exec.Cmd, error) {
+	if params.MaxMemoryMb == 0 && params.MaxCpuPercent == 0 {
+		return util.ShellRunWithEnv(name, envs, args...)
+	}
+
+	cgroupDirectory, cgroupErr := prepareCgroup(pluginId, params)
+	if cgroupErr == nil {
+		cmd, startErr := startInCgroup(cgroupDirectory, name, envs, args...)
+		if startErr == nil {
+			util.GetLogger().Info(ctx, fmt.Sprintf("started host process(%d) in cgroup %s, memory: %dMB, cpu: %d%%", cmd.Process.Pid, cgroupDirectory, params.MaxMemoryMb, params.MaxCpuPercent))
+			return cmd, nil
+		}
+		cgroupErr = startErr
+	}
+	util.GetLogger().Warn(ctx, fmt.Sprintf("failed to use cgroup for host process, fallback to rlimit: %s", cgroupErr))
+
+	if params.MaxCpuPercent > 0 {
+		util.GetLogger().Error(ctx, fmt.Sprintf("cpu limit of %s is not enforced, it requires a writable cgroup v2 hierarchy with cpu controller", pluginId))
+	}
+	if params.MaxMemoryMb == 0 {
+		return util.ShellRunWithEnv(name, envs, args...)
+	}
+
+	// ulimit -d takes kilobytes, exec keeps the pid so the wrapper is transparent for supervisor
+	script := fmt.Sprintf(`ulimit -d %d && exec "$0" "$@"`, params.MaxMemoryMb*1024)
+	return util.ShellRunWithEnv("/bin/sh", envs, append([]string{"-c", script, name}, args...)...)
+}
+
+// prepareCgroup creates the cgroup of plugin with controllers enabled and limits written, and returns its directory
+func prepareCgroup(pluginId string, params plugin.MetadataFeatureParamsIsolatedHost) (string, error) {
+	content, readErr := os.ReadFile("/proc/self/cgroup")
+	if readErr != nil {
+		return "", readErr
+	}
+
+	// cgroup v2 only has one line: 0::/path/of/cgroup
+	selfCgroup := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			selfCgroup = strings.TrimSpace(strings.TrimPrefix(line, "0::"))
+		}
+	}
+	if selfCgroup == "" {
+		return "", errors.New("cgroup v2 is not available")
+	}
+
+	// processes can only live in leaf cgroups, so create the plugin cgroup as a sibling of wox's own cgroup.
+	// limits of a cgroup only take effect if the controllers are enabled in subtree_control of its parent
+	parentDirectory := filepath.Join("/sys/fs/cgroup", filepath.Dir(selfCgroup))
+	var controllers []string
+	if params.MaxMemoryMb > 0 {
+		controllers = append(controllers, "memory")
+	}
+	if params.MaxCpuPercent > 0 {
+		controllers = append(controllers, "cpu")
+	}
+	if enableErr := enableCgroupControllers(parentDirectory, controllers); enableErr != nil {
+		return "", enableErr
+	}
+
+	cgroupName := "wox-plugin-" + regexp.MustCompile(`[^a-zA-Z0-9_.-]`).ReplaceAllString(pluginId, "-")
+	cgroupDirectory := filepath.Join(parentDirectory, cgroupName)
+	if mkdirErr := os.MkdirAll(cgroupDirectory, 0755); mkdirErr != nil {
+		return "", mkdirErr
+	}
+
+	if params.MaxMemoryMb > 0 {
+		memoryMax := fmt.Sprintf("%d", params.MaxMemoryMb*1024*1024)
+		if writeErr := os.WriteFile(filepath.Join(cgroupDirectory, "memory.max"), []byte(memoryMax), 0644); writeErr != nil {
+			return "", writeErr
+		}
+	}
+	if params.MaxCpuPercent > 0 {
+		cpuMax := fmt.Sprintf("%d %d", params.MaxCpuPercent*cgroupCpuPeriod/100, cgroupCpuPeriod)
+		if writeErr := os.WriteFile(filepath.Join(cgroupDirectory, "cpu.max"), []byte(cpuMax), 0644); writeErr != nil {
+			return "", writeErr
+		}
+	}
+
+	return cgroupDirectory, nil
+}
+
+func enableCgroupControllers(parentDirectory string, controllers []string) error {
+	available, readErr := os.ReadFile(filepath.Join(parentDirectory, "cgroup.controllers"))
+	if readErr != nil {
+		return readErr
+	}
+	enabled, readErr := os.ReadFile(filepath.Join(parentDirectory, "cgroup.subtree_control"))
+	if readErr != nil {
+		return readErr
+	}
+
+	for _, controller := range controllers {
+		if slices.Contains(strings.Fields(string(enabled)), controller) {
+			continue
+		}
+		if !slices.Contains(strings.Fields(string(available)), controller) {
+			return fmt.Errorf("%s controller is not available in %s", controller, parentDirectory)
+		}
+		if writeErr := os.WriteFile(filepath.Join(parentDirectory, "cgroup.subtree_control"), []byte("+"+controller), 0644); writeErr != nil {
+			return fmt.Errorf("failed to enable %s controller in %s: %w", controller, parentDirectory, writeErr)
+		}
+	}
+
+	return nil
+}
+
+func startInCgroup(cgroupDirectory string, name string, envs []string, args ...string) (*exec.Cmd, error) {
+	cgroupFile, openErr := os.Open(cgroupDirectory)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer cgroupFile.Close()
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = util.GetLogger().GetWriter()
+	cmd.Stderr = util.GetLogger().GetWriter()
+	cmd.Env = append(os.Environ(), envs...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{UseCgroupFD: true, CgroupFD: int(cgroupFile.Fd())}
+	if startErr := cmd.Start(); startErr != nil {
+		return nil, startErr
+	}
+
+	return cmd, nil
+}
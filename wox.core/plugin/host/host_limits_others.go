@@ -0,0 +1,17 @@
+//go:build !linux
+
+package host
+
+import (
+	"context"
+	"os/exec"
+	"wox/plugin"
+	"wox/util"
+)
+
+func startLimitedHostProcess(ctx context.Context, pluginId string, params plugin.MetadataFeatureParamsIsolatedHost, name string, envs []string, args ...string) (*exec.Cmd, error) {
+	if params.MaxMemoryMb > 0 || params.MaxCpuPercent > 0 {
+		util.GetLogger().Warn(ctx, "host resource limits are only supported on linux, ignored")
+	}
+	return util.ShellRunWithEnv(name, envs, args...)
+}
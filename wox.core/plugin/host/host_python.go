@@ -2,27 +2,31 @@ package host
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
 	"wox/plugin"
+	"wox/setting"
 	"wox/util"
-
-	"github.com/Masterminds/semver/v3"
-	"github.com/mitchellh/go-homedir"
+	"wox/util/interpreter"
 )
 
 func init() {
 	host := &PythonHost{}
 	host.websocketHost = &WebsocketHost{
 		host:       host,
-		requestMap: util.NewHashMap[string, chan JsonRpcResponse](),
+		requestMap: util.NewHashMap[string, *hostResponseQueue](),
 	}
 	plugin.AllHosts = append(plugin.AllHosts, host)
 }
 
 type PythonHost struct {
 	websocketHost *WebsocketHost
+	interpreter   interpreter.DiscoveryResult
 }
 
 func (n *PythonHost) GetRuntime(ctx context.Context) plugin.Runtime {
@@ -30,61 +34,74 @@ func (n *PythonHost) GetRuntime(ctx context.Context) plugin.Runtime {
 }
 
 func (n *PythonHost) Start(ctx context.Context) error {
-	return n.websocketHost.StartHost(ctx, n.findPythonPath(ctx), path.Join(util.GetLocation().GetHostDirectory(), "python-host.pyz"), []string{"SHIV_ROOT=" + util.GetLocation().GetCacheDirectory()})
+	n.interpreter = interpreter.Discover(ctx, interpreter.TypePython, setting.GetSettingManager().GetWoxSetting(ctx).CustomPythonPath.Get())
+	if !n.interpreter.IsFound() {
+		return fmt.Errorf("no python %s or newer found, install one or set python path in settings", n.interpreter.MinVersion)
+	}
+
+	return n.websocketHost.StartHost(ctx, n.interpreter.Path, path.Join(util.GetLocation().GetHostDirectory(), "python-host.pyz"), []string{"SHIV_ROOT=" + util.GetLocation().GetCacheDirectory()})
 }
 
-func (n *PythonHost) findPythonPath(ctx context.Context) string {
-	util.GetLogger().Debug(ctx, "start finding python path")
+// prepareVirtualEnv creates a virtualenv for python plugins which ship a requirements.txt, and installs requirements when they are changed.
+// Returns the python of the virtualenv, or empty if plugin has no requirements.txt
+func (n *PythonHost) prepareVirtualEnv(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (string, error) {
+	requirementsPath := filepath.Join(pluginDirectory, "requirements.txt")
+	if !util.IsFileExists(requirementsPath) {
+		return "", nil
+	}
+	requirements, readErr := os.ReadFile(requirementsPath)
+	if readErr != nil {
+		return "", readErr
+	}
 
-	var possiblePythonPaths = []string{
-		"/opt/homebrew/bin/python3",
-		"/usr/local/bin/python3",
-		"/usr/bin/python3",
-		"/usr/local/python3",
+	venvDirectory := filepath.Join(util.GetLocation().GetCacheDirectory(), "venvs", metadata.Id)
+	venvPython := filepath.Join(venvDirectory, "bin", "python3")
+	if util.IsWindows() {
+		venvPython = filepath.Join(venvDirectory, "Scripts", "python.exe")
 	}
 
-	pyenvPaths, _ := homedir.Expand("~/.pyenv/versions")
-	if util.IsDirExists(pyenvPaths) {
-		versions, _ := util.ListDir(pyenvPaths)
-		for _, v := range versions {
-			possiblePythonPaths = append(possiblePythonPaths, path.Join(pyenvPaths, v, "bin", "python3"))
-		}
+	requirementsHashPath := filepath.Join(venvDirectory, "wox-requirements.md5")
+	requirementsHash := util.Md5(requirements)
+	existRequirementsHash, _ := os.ReadFile(requirementsHashPath)
+	interpreterHashPath := filepath.Join(venvDirectory, "wox-interpreter.md5")
+	interpreterHash := util.Md5([]byte(n.interpreter.Path + n.interpreter.Version))
+	existInterpreterHash, _ := os.ReadFile(interpreterHashPath)
+	if string(existRequirementsHash) == requirementsHash && string(existInterpreterHash) == interpreterHash && util.IsFileExists(venvPython) {
+		return venvPython, nil
 	}
 
-	foundVersion, _ := semver.NewVersion("v0.0.1")
-	foundPath := ""
-	for _, p := range possiblePythonPaths {
-		if util.IsFileExists(p) {
-			versionOriginal, versionErr := util.ShellRunOutput(p, "--version")
-			if versionErr != nil {
-				util.GetLogger().Error(ctx, fmt.Sprintf("failed to get python version: %s, path=%s", versionErr, p))
-				continue
-			}
-			// Python version output format is like "Python 3.9.0"
-			version := strings.TrimSpace(string(versionOriginal))
-			version = strings.TrimPrefix(version, "Python ")
-			version = "v" + version
-			installedVersion, err := semver.NewVersion(version)
-			if err != nil {
-				util.GetLogger().Error(ctx, fmt.Sprintf("failed to parse python version: %s, path=%s", err, p))
-				continue
-			}
-			util.GetLogger().Debug(ctx, fmt.Sprintf("found python path: %s, version: %s", p, installedVersion.String()))
-
-			if installedVersion.GreaterThan(foundVersion) {
-				foundPath = p
-				foundVersion = installedVersion
-			}
+	util.GetLogger().Info(ctx, fmt.Sprintf("[%s] preparing virtualenv: %s", metadata.Name, venvDirectory))
+	// recreate virtualenv if interpreter is changed, installed packages may not be compatible
+	if string(existInterpreterHash) != interpreterHash || !util.IsFileExists(venvPython) {
+		os.RemoveAll(venvDirectory)
+		if _, venvErr := runPython(n.interpreter.Path, "-m", "venv", venvDirectory); venvErr != nil {
+			return "", fmt.Errorf("failed to create virtualenv: %w", venvErr)
+		}
+		if writeErr := os.WriteFile(interpreterHashPath, []byte(interpreterHash), 0644); writeErr != nil {
+			return "", writeErr
 		}
 	}
-
-	if foundPath != "" {
-		util.GetLogger().Info(ctx, fmt.Sprintf("finally use python path: %s, version: %s", foundPath, foundVersion.String()))
-		return foundPath
+	if _, pipErr := runPython(venvPython, "-m", "pip", "install", "--disable-pip-version-check", "-r", requirementsPath); pipErr != nil {
+		return "", fmt.Errorf("failed to install requirements: %w", pipErr)
+	}
+	if writeErr := os.WriteFile(requirementsHashPath, []byte(requirementsHash), 0644); writeErr != nil {
+		return "", writeErr
 	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("[%s] virtualenv is ready", metadata.Name))
 
-	util.GetLogger().Info(ctx, "finally use default python3 from env path")
-	return "python3"
+	return venvPython, nil
+}
+
+func runPython(pythonPath string, args ...string) (string, error) {
+	output, runErr := util.ShellRunOutput(pythonPath, args...)
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", runErr, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", runErr
+	}
+	return string(output), nil
 }
 
 func (n *PythonHost) IsStarted(ctx context.Context) bool {
@@ -92,13 +109,26 @@ func (n *PythonHost) IsStarted(ctx context.Context) bool {
 }
 
 func (n *PythonHost) Stop(ctx context.Context) {
+	stopIsolatedHosts(ctx, n)
 	n.websocketHost.StopHost(ctx)
 }
 
 func (n *PythonHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (plugin.Plugin, error) {
-	return n.websocketHost.LoadPlugin(ctx, metadata, pluginDirectory)
+	venvPython, venvErr := n.prepareVirtualEnv(ctx, metadata, pluginDirectory)
+	if venvErr != nil {
+		return nil, venvErr
+	}
+
+	// dependencies of different plugins can't coexist in one interpreter, so plugins with a virtualenv always run in a dedicated host with python of the virtualenv
+	if venvPython != "" || isIsolatedPlugin(ctx, metadata) {
+		return loadIsolatedPlugin(ctx, n.websocketHost, venvPython, metadata, pluginDirectory, nil)
+	}
+	return n.websocketHost.LoadPlugin(ctx, metadata, pluginDirectory, nil)
 }
 
 func (n *PythonHost) UnloadPlugin(ctx context.Context, metadata plugin.Metadata) {
+	if unloadIsolatedPlugin(ctx, metadata) {
+		return
+	}
 	n.websocketHost.UnloadPlugin(ctx, metadata)
 }
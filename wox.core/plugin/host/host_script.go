@@ -0,0 +1,82 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"wox/plugin"
+	"wox/util"
+)
+
+func init() {
+	host := &ScriptHost{
+		plugins: util.NewHashMap[string, *ScriptPlugin](),
+	}
+	plugin.AllHosts = append(plugin.AllHosts, host)
+}
+
+// ScriptHost runs plugins as plain executables (shell, ruby, compiled binaries, etc.)
+// which exchange line-delimited json with Wox over stdin/stdout.
+// Unlike python and nodejs hosts, there is no shared host process, every plugin owns its own process.
+type ScriptHost struct {
+	plugins *util.HashMap[string, *ScriptPlugin]
+}
+
+func (s *ScriptHost) GetRuntime(ctx context.Context) plugin.Runtime {
+	return plugin.PLUGIN_RUNTIME_SCRIPT
+}
+
+func (s *ScriptHost) Start(ctx context.Context) error {
+	return nil
+}
+
+func (s *ScriptHost) Stop(ctx context.Context) {
+	s.plugins.Range(func(pluginId string, scriptPlugin *ScriptPlugin) bool {
+		scriptPlugin.stopProcess(ctx)
+		return true
+	})
+}
+
+func (s *ScriptHost) IsStarted(ctx context.Context) bool {
+	return true
+}
+
+func (s *ScriptHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (plugin.Plugin, error) {
+	util.GetLogger().Info(ctx, fmt.Sprintf("start loading %s plugin, directory: %s", metadata.Name, pluginDirectory))
+
+	params, paramsErr := metadata.GetFeatureParamsForScript()
+	if paramsErr != nil {
+		return nil, paramsErr
+	}
+
+	entryPath := metadata.Entry
+	if !filepath.IsAbs(entryPath) {
+		entryPath = filepath.Join(pluginDirectory, entryPath)
+	}
+	entryStat, statErr := os.Stat(entryPath)
+	if statErr != nil {
+		return nil, fmt.Errorf("failed to find entry executable: %w", statErr)
+	}
+
+	// executable bit may be lost after unzipping the plugin package
+	if !util.IsWindows() && entryStat.Mode()&0111 == 0 {
+		if chmodErr := os.Chmod(entryPath, entryStat.Mode()|0755); chmodErr != nil {
+			return nil, fmt.Errorf("failed to make entry executable: %w", chmodErr)
+		}
+	}
+
+	scriptPlugin := NewScriptPlugin(metadata, pluginDirectory, entryPath, params)
+	if existPlugin, exist := s.plugins.Load(metadata.Id); exist {
+		existPlugin.stopProcess(ctx)
+	}
+	s.plugins.Store(metadata.Id, scriptPlugin)
+	return scriptPlugin, nil
+}
+
+func (s *ScriptHost) UnloadPlugin(ctx context.Context, metadata plugin.Metadata) {
+	if scriptPlugin, exist := s.plugins.Load(metadata.Id); exist {
+		scriptPlugin.stopProcess(ctx)
+		s.plugins.Delete(metadata.Id)
+	}
+}
@@ -0,0 +1,397 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"wox/plugin"
+	"wox/share"
+	"wox/util"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// max size of a single json line written by script plugins
+const scriptMaxLineSize = 10 * 1024 * 1024
+
+// ScriptPlugin talks to an executable with line-delimited json:
+// Wox writes one JsonRpcRequest per line to stdin, the script writes one JsonRpcResponse per line to stdout.
+// Script may also write JsonRpcRequest lines (Log, Notify, ChangeQuery, HideApp, ShowApp) to call Wox apis,
+// those requests are one-way and Wox won't send responses back.
+// Everything written to stderr is routed to the plugin log.
+type ScriptPlugin struct {
+	metadata        plugin.Metadata
+	pluginDirectory string
+	entryPath       string
+	params          plugin.MetadataFeatureParamsScript
+	api             plugin.API
+
+	// only used in resident mode
+	processLock sync.Mutex
+	process     *exec.Cmd
+	stdin       io.WriteCloser
+	requestMap  *util.HashMap[string, chan JsonRpcResponse]
+}
+
+func NewScriptPlugin(metadata plugin.Metadata, pluginDirectory string, entryPath string, params plugin.MetadataFeatureParamsScript) *ScriptPlugin {
+	return &ScriptPlugin{
+		metadata:        metadata,
+		pluginDirectory: pluginDirectory,
+		entryPath:       entryPath,
+		params:          params,
+		requestMap:      util.NewHashMap[string, chan JsonRpcResponse](),
+	}
+}
+
+func (s *ScriptPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
+	s.api = initParams.API
+
+	// oneshot scripts are spawned on demand, there is nothing to init
+	if s.params.Mode != plugin.ScriptModeResident {
+		return
+	}
+
+	_, initErr := s.invokeMethod(ctx, "init", map[string]string{
+		"PluginDirectory": initParams.PluginDirectory,
+	})
+	if initErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] init failed: %s", s.metadata.Name, initErr.Error()))
+	}
+}
+
+func (s *ScriptPlugin) Query(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	selectionJson, marshalErr := json.Marshal(query.Selection)
+	if marshalErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to marshal plugin query selection: %s", s.metadata.Name, marshalErr.Error()))
+		return []plugin.QueryResult{}
+	}
+
+	envJson, marshalEnvErr := json.Marshal(query.Env)
+	if marshalEnvErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to marshal plugin query env: %s", s.metadata.Name, marshalEnvErr.Error()))
+		return []plugin.QueryResult{}
+	}
+
+	rawResults, queryErr := s.invokeMethod(ctx, "query", map[string]string{
+		"Type":           query.Type,
+		"RawQuery":       query.RawQuery,
+		"TriggerKeyword": query.TriggerKeyword,
+		"Command":        query.Command,
+		"Search":         query.Search,
+		"Selection":      string(selectionJson),
+		"Env":            string(envJson),
+	})
+	if queryErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] query failed: %s", s.metadata.Name, queryErr.Error()))
+		return []plugin.QueryResult{
+			plugin.GetPluginManager().GetResultForFailedQuery(ctx, s.metadata, query, queryErr),
+		}
+	}
+
+	var results []plugin.QueryResult
+	marshalData, marshalErr := json.Marshal(rawResults)
+	if marshalErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to marshal plugin query results: %s", s.metadata.Name, marshalErr.Error()))
+		return nil
+	}
+	unmarshalErr := json.Unmarshal(marshalData, &results)
+	if unmarshalErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal query results: %s", s.metadata.Name, unmarshalErr.Error()))
+		return []plugin.QueryResult{}
+	}
+
+	for i := range results {
+		for j := range results[i].Actions {
+			actionId := results[i].Actions[j].Id
+			results[i].Actions[j].Action = func(ctx context.Context, actionContext plugin.ActionContext) {
+				_, actionErr := s.invokeMethod(ctx, "action", map[string]string{
+					"ActionId":    actionId,
+					"ContextData": actionContext.ContextData,
+				})
+				if actionErr != nil {
+					util.GetLogger().Error(ctx, fmt.Sprintf("[%s] action failed: %s", s.metadata.Name, actionErr.Error()))
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+func (s *ScriptPlugin) invokeMethod(ctx context.Context, method string, params map[string]string) (any, error) {
+	request := JsonRpcRequest{
+		TraceId:    util.GetContextTraceId(ctx),
+		Id:         uuid.NewString(),
+		PluginId:   s.metadata.Id,
+		PluginName: s.metadata.Name,
+		Method:     method,
+		Type:       JsonRpcTypeRequest,
+		Params:     params,
+	}
+	util.GetLogger().Debug(ctx, fmt.Sprintf("<Wox -> Script> inovke plugin <%s> method: %s, request id: %s", s.metadata.Name, method, request.Id))
+
+	requestJson, marshalErr := json.Marshal(request)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	startTimestamp := util.GetSystemTimestamp()
+	var response JsonRpcResponse
+	var invokeErr error
+	if s.params.Mode == plugin.ScriptModeResident {
+		response, invokeErr = s.invokeResident(ctx, request.Id, requestJson)
+	} else {
+		response, invokeErr = s.invokeOneShot(ctx, request.Id, requestJson)
+	}
+	if invokeErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("invoke %s failed, response time: %dms, err: %s", s.metadata.Name, util.GetSystemTimestamp()-startTimestamp, invokeErr))
+		return nil, invokeErr
+	}
+
+	util.GetLogger().Debug(ctx, fmt.Sprintf("inovke plugin <%s> method: %s finished, response time: %dms", s.metadata.Name, method, util.GetSystemTimestamp()-startTimestamp))
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response.Result, nil
+}
+
+// invokeOneShot spawns the entry executable, writes the request and waits the response before the process exits
+func (s *ScriptPlugin) invokeOneShot(ctx context.Context, requestId string, requestJson []byte) (JsonRpcResponse, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(s.params.TimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	cmd := exec.CommandContext(timeoutCtx, s.entryPath)
+	cmd.Dir = s.pluginDirectory
+	cmd.Stdin = bytes.NewReader(append(requestJson, '\n'))
+	cmd.Stderr = &scriptStderrWriter{ctx: ctx, plugin: s}
+	cmd.WaitDelay = time.Second // don't wait forever if the script leaves children holding stdout
+	stdout, stdoutErr := cmd.StdoutPipe()
+	if stdoutErr != nil {
+		return JsonRpcResponse{}, stdoutErr
+	}
+	if startErr := cmd.Start(); startErr != nil {
+		return JsonRpcResponse{}, fmt.Errorf("failed to start script: %w", startErr)
+	}
+
+	var response *JsonRpcResponse
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), scriptMaxLineSize)
+	for scanner.Scan() {
+		if lineResponse, isResponse := s.handleLine(ctx, scanner.Text()); isResponse && lineResponse.Id == requestId {
+			response = &lineResponse
+		}
+	}
+
+	waitErr := cmd.Wait()
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		return JsonRpcResponse{}, fmt.Errorf("script timeout after %dms", s.params.TimeoutMs)
+	}
+	if response == nil {
+		if waitErr != nil {
+			return JsonRpcResponse{}, fmt.Errorf("script exited without response: %w", waitErr)
+		}
+		return JsonRpcResponse{}, errors.New("script exited without response")
+	}
+
+	return *response, nil
+}
+
+// invokeResident writes the request to the running process (start it if necessary) and waits the response
+func (s *ScriptPlugin) invokeResident(ctx context.Context, requestId string, requestJson []byte) (JsonRpcResponse, error) {
+	resultChan := make(chan JsonRpcResponse, 1)
+	s.requestMap.Store(requestId, resultChan)
+	defer s.requestMap.Delete(requestId)
+
+	s.processLock.Lock()
+	if s.process == nil {
+		if startErr := s.startProcess(ctx); startErr != nil {
+			s.processLock.Unlock()
+			return JsonRpcResponse{}, startErr
+		}
+	}
+	_, writeErr := s.stdin.Write(append(requestJson, '\n'))
+	s.processLock.Unlock()
+	if writeErr != nil {
+		return JsonRpcResponse{}, fmt.Errorf("failed to write request to script: %w", writeErr)
+	}
+
+	select {
+	case <-time.NewTimer(time.Duration(s.params.TimeoutMs) * time.Millisecond).C:
+		return JsonRpcResponse{}, fmt.Errorf("request timeout after %dms, request id: %s", s.params.TimeoutMs, requestId)
+	case response := <-resultChan:
+		return response, nil
+	}
+}
+
+// startProcess must be called with processLock held
+func (s *ScriptPlugin) startProcess(ctx context.Context) error {
+	cmd := exec.Command(s.entryPath)
+	cmd.Dir = s.pluginDirectory
+	cmd.Stderr = &scriptStderrWriter{ctx: util.NewTraceContext(), plugin: s}
+	stdin, stdinErr := cmd.StdinPipe()
+	if stdinErr != nil {
+		return stdinErr
+	}
+	stdout, stdoutErr := cmd.StdoutPipe()
+	if stdoutErr != nil {
+		return stdoutErr
+	}
+	if startErr := cmd.Start(); startErr != nil {
+		return fmt.Errorf("failed to start script: %w", startErr)
+	}
+	util.GetLogger().Info(ctx, fmt.Sprintf("[%s] script process started, pid: %d", s.metadata.Name, cmd.Process.Pid))
+
+	s.process = cmd
+	s.stdin = stdin
+
+	util.Go(ctx, fmt.Sprintf("[%s] read script output", s.metadata.Name), func() {
+		newCtx := util.NewTraceContext()
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), scriptMaxLineSize)
+		for scanner.Scan() {
+			if response, isResponse := s.handleLine(newCtx, scanner.Text()); isResponse {
+				if resultChan, exist := s.requestMap.Load(response.Id); exist {
+					resultChan <- response
+				}
+			}
+		}
+
+		waitErr := cmd.Wait()
+		util.GetLogger().Info(newCtx, fmt.Sprintf("[%s] script process exited: %v", s.metadata.Name, waitErr))
+
+		// next request will start a new process
+		s.processLock.Lock()
+		if s.process == cmd {
+			s.process = nil
+			s.stdin = nil
+		}
+		s.processLock.Unlock()
+	})
+
+	return nil
+}
+
+func (s *ScriptPlugin) stopProcess(ctx context.Context) {
+	s.processLock.Lock()
+	defer s.processLock.Unlock()
+
+	if s.process == nil {
+		return
+	}
+
+	pid := s.process.Process.Pid
+	s.stdin.Close()
+	killErr := s.process.Process.Kill()
+	if killErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to kill script process(%d): %s", s.metadata.Name, pid, killErr))
+	} else {
+		util.GetLogger().Info(ctx, fmt.Sprintf("[%s] killed script process(%d)", s.metadata.Name, pid))
+	}
+	s.process = nil
+	s.stdin = nil
+}
+
+// handleLine handles a single line from script stdout, returns the response if the line is a response
+func (s *ScriptPlugin) handleLine(ctx context.Context, line string) (JsonRpcResponse, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return JsonRpcResponse{}, false
+	}
+
+	if !gjson.Valid(line) {
+		s.log(ctx, plugin.LogLevelInfo, line)
+		return JsonRpcResponse{}, false
+	}
+
+	switch gjson.Get(line, "Type").String() {
+	case string(JsonRpcTypeResponse):
+		var response JsonRpcResponse
+		unmarshalErr := json.Unmarshal([]byte(line), &response)
+		if unmarshalErr != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal script response: %s", s.metadata.Name, unmarshalErr))
+			return JsonRpcResponse{}, false
+		}
+		return response, true
+	case string(JsonRpcTypeRequest):
+		var request JsonRpcRequest
+		unmarshalErr := json.Unmarshal([]byte(line), &request)
+		if unmarshalErr != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal script request: %s", s.metadata.Name, unmarshalErr))
+			return JsonRpcResponse{}, false
+		}
+		s.handleRequestFromScript(ctx, request)
+	default:
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] unknown script message type: %s", s.metadata.Name, line))
+	}
+
+	return JsonRpcResponse{}, false
+}
+
+func (s *ScriptPlugin) handleRequestFromScript(ctx context.Context, request JsonRpcRequest) {
+	if s.api == nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] plugin is not initialized, ignore request: %s", s.metadata.Name, request.Method))
+		return
+	}
+
+	switch request.Method {
+	case "HideApp":
+		s.api.HideApp(ctx)
+	case "ShowApp":
+		s.api.ShowApp(ctx)
+	case "ChangeQuery":
+		s.api.ChangeQuery(ctx, share.PlainQuery{
+			QueryType: plugin.QueryTypeInput,
+			QueryText: request.Params["queryText"],
+		})
+	case "Notify":
+		s.api.Notify(ctx, request.Params["message"])
+	case "Log":
+		level, exist := request.Params["level"]
+		if !exist {
+			level = plugin.LogLevelInfo
+		}
+		s.api.Log(ctx, level, request.Params["msg"])
+	default:
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] unsupported script request method: %s", s.metadata.Name, request.Method))
+	}
+}
+
+func (s *ScriptPlugin) log(ctx context.Context, level plugin.LogLevel, msg string) {
+	if s.api != nil {
+		s.api.Log(ctx, level, msg)
+	} else {
+		util.GetLogger().Info(util.NewComponentContext(ctx, s.metadata.Name), msg)
+	}
+}
+
+// scriptStderrWriter routes script stderr to plugin log line by line
+type scriptStderrWriter struct {
+	ctx    context.Context
+	plugin *ScriptPlugin
+	buffer []byte
+}
+
+func (w *scriptStderrWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.buffer[:index]), "\r")
+		w.buffer = w.buffer[index+1:]
+		if line != "" {
+			w.plugin.log(w.ctx, plugin.LogLevelInfo, line)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,56 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/plugin"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ScriptPluginOneShot(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("shell script is not available on windows")
+	}
+
+	ctx := util.NewTraceContext()
+	pluginDirectory := t.TempDir()
+	script := `#!/bin/sh
+read request
+id=$(echo "$request" | sed 's/.*"Id":"\([^"]*\)".*/\1/')
+echo "some debug output" >&2
+echo '{"Type":"WOX_JSONRPC_REQUEST","Method":"Log","Params":{"level":"Info","msg":"hi"}}'
+echo "{\"Id\":\"$id\",\"Type\":\"WOX_JSONRPC_RESPONSE\",\"Result\":[{\"Title\":\"hello\",\"Actions\":[{\"Id\":\"a\",\"Name\":\"Open\"}]}]}"
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "main.sh"), []byte(script), 0644))
+
+	host := &ScriptHost{plugins: util.NewHashMap[string, *ScriptPlugin]()}
+	instance, loadErr := host.LoadPlugin(ctx, plugin.Metadata{Id: "script", Name: "script", Entry: "main.sh"}, pluginDirectory)
+	assert.Nil(t, loadErr)
+
+	results := instance.Query(ctx, plugin.Query{RawQuery: "s hello", Search: "hello"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, "hello", results[0].Title)
+	assert.Len(t, results[0].Actions, 1)
+	assert.NotNil(t, results[0].Actions[0].Action)
+}
+
+func Test_ScriptPluginTimeout(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("shell script is not available on windows")
+	}
+
+	ctx := util.NewTraceContext()
+	pluginDirectory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "main.sh"), []byte("#!/bin/sh\nsleep 5\n"), 0755))
+
+	scriptPlugin := NewScriptPlugin(plugin.Metadata{Id: "script", Name: "script"}, pluginDirectory, filepath.Join(pluginDirectory, "main.sh"), plugin.MetadataFeatureParamsScript{
+		Mode:      plugin.ScriptModeOneShot,
+		TimeoutMs: 200,
+	})
+	_, invokeErr := scriptPlugin.invokeMethod(ctx, "query", map[string]string{})
+	assert.NotNil(t, invokeErr)
+	assert.Contains(t, invokeErr.Error(), "timeout")
+}
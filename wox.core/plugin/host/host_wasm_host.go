@@ -0,0 +1,74 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"wox/plugin"
+	"wox/util"
+)
+
+func init() {
+	host := &WasmHost{
+		plugins: util.NewHashMap[string, *WasmPlugin](),
+	}
+	plugin.AllHosts = append(plugin.AllHosts, host)
+}
+
+// WasmHost runs webassembly plugins in process with an embedded wasm engine, so no interpreter is required.
+// Every plugin gets its own sandboxed runtime, it can only reach Wox through host functions granted by the wasm feature.
+type WasmHost struct {
+	plugins *util.HashMap[string, *WasmPlugin]
+}
+
+func (w *WasmHost) GetRuntime(ctx context.Context) plugin.Runtime {
+	return plugin.PLUGIN_RUNTIME_WASM
+}
+
+func (w *WasmHost) Start(ctx context.Context) error {
+	return nil
+}
+
+func (w *WasmHost) Stop(ctx context.Context) {
+	w.plugins.Range(func(pluginId string, wasmPlugin *WasmPlugin) bool {
+		wasmPlugin.close(ctx)
+		return true
+	})
+	w.plugins.Clear()
+}
+
+func (w *WasmHost) IsStarted(ctx context.Context) bool {
+	return true
+}
+
+func (w *WasmHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (plugin.Plugin, error) {
+	util.GetLogger().Info(ctx, fmt.Sprintf("start loading %s plugin, directory: %s", metadata.Name, pluginDirectory))
+
+	params, paramsErr := metadata.GetFeatureParamsForWasm()
+	if paramsErr != nil {
+		return nil, paramsErr
+	}
+
+	entryPath := metadata.Entry
+	if !filepath.IsAbs(entryPath) {
+		entryPath = filepath.Join(pluginDirectory, entryPath)
+	}
+
+	if existPlugin, exist := w.plugins.Load(metadata.Id); exist {
+		existPlugin.close(ctx)
+	}
+
+	wasmPlugin, newErr := NewWasmPlugin(ctx, metadata, pluginDirectory, entryPath, params)
+	if newErr != nil {
+		return nil, newErr
+	}
+	w.plugins.Store(metadata.Id, wasmPlugin)
+	return wasmPlugin, nil
+}
+
+func (w *WasmHost) UnloadPlugin(ctx context.Context, metadata plugin.Metadata) {
+	if wasmPlugin, exist := w.plugins.Load(metadata.Id); exist {
+		wasmPlugin.close(ctx)
+		w.plugins.Delete(metadata.Id)
+	}
+}
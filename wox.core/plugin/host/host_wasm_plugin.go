@@ -0,0 +1,386 @@
+package host
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"wox/plugin"
+	"wox/share"
+	"wox/util"
+
+	"github.com/samber/lo"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// ABI between Wox and wasm plugins, all data is utf-8 json written to guest memory:
+//
+//   - guest exports "memory", "wox_alloc(size i32) i32" and "query(ptr i32, len i32) i64"
+//   - guest may export "init(ptr i32, len i32)", "action(ptr i32, len i32)" and "wox_free(ptr i32, len i32)"
+//   - returned i64 packs the output buffer as ptr<<32|len, 0 means empty. Wox calls wox_free on it after reading if exported
+//   - host functions are imported from "wox" module, see instantiateHostModule
+const (
+	wasmHostModuleName = "wox"
+	wasmPageSize       = 64 * 1024
+)
+
+// max time of a single call into guest, guest is closed if it doesn't return in time (e.g. stuck in an infinite loop)
+// and instantiated again on next call
+var wasmCallTimeout = time.Second * 10
+
+type WasmPlugin struct {
+	metadata        plugin.Metadata
+	pluginDirectory string
+	params          plugin.MetadataFeatureParamsWasm
+	runtime         wazero.Runtime
+	compiled        wazero.CompiledModule
+	module          api.Module
+	api             plugin.API
+	initInput       map[string]string // passed to guest init again after module is instantiated again
+	isClosed        bool              // plugin is unloaded, module must not be instantiated again
+	lock            sync.Mutex        // wasm instance is single threaded, calls into guest must be serialized
+}
+
+func NewWasmPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string, entryPath string, params plugin.MetadataFeatureParamsWasm) (*WasmPlugin, error) {
+	wasmBytes, readErr := os.ReadFile(entryPath)
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read wasm module: %w", readErr)
+	}
+
+	// guest code only stops at context cancellation if this is enabled
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if params.MaxMemoryMb > 0 {
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(uint32(params.MaxMemoryMb * 1024 * 1024 / wasmPageSize))
+	}
+
+	w := &WasmPlugin{
+		metadata:        metadata,
+		pluginDirectory: pluginDirectory,
+		params:          params,
+		runtime:         wazero.NewRuntimeWithConfig(ctx, runtimeConfig),
+	}
+
+	// wasi is needed by most toolchains, it has no access to file system, network or env unless configured
+	if _, wasiErr := wasi_snapshot_preview1.Instantiate(ctx, w.runtime); wasiErr != nil {
+		w.close(ctx)
+		return nil, fmt.Errorf("failed to instantiate wasi: %w", wasiErr)
+	}
+	if hostErr := w.instantiateHostModule(ctx); hostErr != nil {
+		w.close(ctx)
+		return nil, fmt.Errorf("failed to instantiate host functions: %w", hostErr)
+	}
+
+	// compiled once, so a module closed by call timeout can be instantiated again cheaply
+	compiled, compileErr := w.runtime.CompileModule(ctx, wasmBytes)
+	if compileErr != nil {
+		w.close(ctx)
+		return nil, fmt.Errorf("failed to compile wasm module: %w", compileErr)
+	}
+	w.compiled = compiled
+
+	module, instantiateErr := w.instantiateModule(ctx)
+	if instantiateErr != nil {
+		w.close(ctx)
+		return nil, instantiateErr
+	}
+	w.module = module
+
+	for _, export := range []string{"wox_alloc", "query"} {
+		if module.ExportedFunction(export) == nil {
+			w.close(ctx)
+			return nil, fmt.Errorf("wasm module doesn't export %s function", export)
+		}
+	}
+	if module.Memory() == nil {
+		w.close(ctx)
+		return nil, fmt.Errorf("wasm module doesn't export memory")
+	}
+
+	return w, nil
+}
+
+func (w *WasmPlugin) instantiateModule(ctx context.Context) (api.Module, error) {
+	// reactor modules export _initialize instead of _start, _start would run main and exit the module
+	moduleConfig := wazero.NewModuleConfig().
+		WithName(w.metadata.Id).
+		WithStartFunctions("_initialize").
+		WithStdout(&wasmLogWriter{pluginName: w.metadata.Name}).
+		WithStderr(&wasmLogWriter{pluginName: w.metadata.Name, isError: true})
+	instantiateCtx, cancel := context.WithTimeout(ctx, wasmCallTimeout)
+	defer cancel()
+	module, instantiateErr := w.runtime.InstantiateModule(instantiateCtx, w.compiled, moduleConfig)
+	if instantiateErr != nil {
+		return nil, fmt.Errorf("failed to instantiate wasm module: %w", instantiateErr)
+	}
+	return module, nil
+}
+
+func (w *WasmPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.api = initParams.API
+	if w.module.ExportedFunction("init") == nil {
+		return
+	}
+
+	w.initInput = map[string]string{
+		"PluginDirectory": initParams.PluginDirectory,
+	}
+	_, callErr := w.callLocked(ctx, "init", w.initInput)
+	if callErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] init failed: %s", w.metadata.Name, callErr))
+	}
+}
+
+func (w *WasmPlugin) Query(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	output, callErr := w.call(ctx, "query", query)
+	if callErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] query failed: %s", w.metadata.Name, callErr))
+		return []plugin.QueryResult{
+			plugin.GetPluginManager().GetResultForFailedQuery(ctx, w.metadata, query, callErr),
+		}
+	}
+	if len(output) == 0 {
+		return []plugin.QueryResult{}
+	}
+
+	var results []plugin.QueryResult
+	unmarshalErr := json.Unmarshal(output, &results)
+	if unmarshalErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal query results: %s", w.metadata.Name, unmarshalErr))
+		return []plugin.QueryResult{}
+	}
+
+	for i := range results {
+		for j := range results[i].Actions {
+			actionId := results[i].Actions[j].Id
+			results[i].Actions[j].Action = func(ctx context.Context, actionContext plugin.ActionContext) {
+				_, actionErr := w.call(ctx, "action", map[string]string{
+					"ActionId":    actionId,
+					"ContextData": actionContext.ContextData,
+				})
+				if actionErr != nil {
+					util.GetLogger().Error(ctx, fmt.Sprintf("[%s] action failed: %s", w.metadata.Name, actionErr))
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// call marshals input to guest memory, invokes the exported function and returns its output.
+// If the module was closed by a timed out call, it's instantiated and initialized again first, guest memory of the old instance is lost
+func (w *WasmPlugin) call(ctx context.Context, function string, input any) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.isClosed || w.module == nil {
+		return nil, fmt.Errorf("wasm module is closed")
+	}
+	if w.module.IsClosed() {
+		util.GetLogger().Info(ctx, fmt.Sprintf("[%s] wasm module was closed, instantiate it again", w.metadata.Name))
+		module, instantiateErr := w.instantiateModule(ctx)
+		if instantiateErr != nil {
+			return nil, instantiateErr
+		}
+		w.module = module
+
+		if w.initInput != nil {
+			if _, initErr := w.callLocked(ctx, "init", w.initInput); initErr != nil {
+				return nil, fmt.Errorf("failed to init wasm module again: %w", initErr)
+			}
+		}
+	}
+
+	return w.callLocked(ctx, function, input)
+}
+
+// callLocked must be called with lock held
+func (w *WasmPlugin) callLocked(ctx context.Context, function string, input any) ([]byte, error) {
+	exportedFunction := w.module.ExportedFunction(function)
+	if exportedFunction == nil {
+		return nil, fmt.Errorf("wasm module doesn't export %s function", function)
+	}
+
+	inputJson, marshalErr := json.Marshal(input)
+	if marshalErr != nil {
+		return nil, fmt.Errorf("failed to marshal %s input: %w", function, marshalErr)
+	}
+
+	// module is closed when the deadline is exceeded, next call instantiates it again
+	callCtx, cancel := context.WithTimeout(ctx, wasmCallTimeout)
+	defer cancel()
+	inputPtr, writeErr := w.writeToGuest(callCtx, w.module, inputJson)
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	results, callErr := exportedFunction.Call(callCtx, uint64(inputPtr), uint64(len(inputJson)))
+	if callErr != nil {
+		if callCtx.Err() != nil {
+			return nil, fmt.Errorf("%s didn't return in %s, wasm module is closed and will be instantiated again: %w", function, wasmCallTimeout, callErr)
+		}
+		return nil, callErr
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return w.readFromGuest(callCtx, w.module, results[0])
+}
+
+// writeToGuest copies data into memory allocated by guest, guest owns the memory afterwards
+func (w *WasmPlugin) writeToGuest(ctx context.Context, module api.Module, data []byte) (uint32, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	allocResults, allocErr := module.ExportedFunction("wox_alloc").Call(ctx, uint64(len(data)))
+	if allocErr != nil {
+		return 0, fmt.Errorf("failed to allocate guest memory: %w", allocErr)
+	}
+	ptr := uint32(allocResults[0])
+	if !module.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("guest memory out of range, ptr: %d, size: %d", ptr, len(data))
+	}
+
+	return ptr, nil
+}
+
+// readFromGuest copies the buffer packed as ptr<<32|len out of guest memory
+func (w *WasmPlugin) readFromGuest(ctx context.Context, module api.Module, packed uint64) ([]byte, error) {
+	ptr, size := uint32(packed>>32), uint32(packed)
+	if size == 0 {
+		return nil, nil
+	}
+
+	data, ok := module.Memory().Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("guest memory out of range, ptr: %d, size: %d", ptr, size)
+	}
+	output := bytes.Clone(data)
+
+	if free := module.ExportedFunction("wox_free"); free != nil {
+		if _, freeErr := free.Call(ctx, uint64(ptr), uint64(size)); freeErr != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to free guest memory: %s", w.metadata.Name, freeErr))
+		}
+	}
+
+	return output, nil
+}
+
+func (w *WasmPlugin) readString(module api.Module, ptr uint32, size uint32) (string, bool) {
+	data, ok := module.Memory().Read(ptr, size)
+	if !ok {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (w *WasmPlugin) hasCapability(ctx context.Context, capability plugin.WasmCapability) bool {
+	if lo.Contains(w.params.Capabilities, capability) {
+		return true
+	}
+
+	util.GetLogger().Error(ctx, fmt.Sprintf("[%s] %s capability is not granted, declare it in wasm feature", w.metadata.Name, capability))
+	return false
+}
+
+// instantiateHostModule exposes a subset of plugin.API to guest, gated by capabilities declared in wasm feature
+func (w *WasmPlugin) instantiateHostModule(ctx context.Context) error {
+	_, instantiateErr := w.runtime.NewHostModuleBuilder(wasmHostModuleName).
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, module api.Module, levelPtr, levelLen, msgPtr, msgLen uint32) {
+			level, levelOk := w.readString(module, levelPtr, levelLen)
+			msg, msgOk := w.readString(module, msgPtr, msgLen)
+			if !levelOk || !msgOk || w.api == nil {
+				return
+			}
+			w.api.Log(ctx, level, msg)
+		}).
+		Export("log").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, module api.Module, ptr, size uint32) {
+			if !w.hasCapability(ctx, plugin.WasmCapabilityNotify) {
+				return
+			}
+			message, ok := w.readString(module, ptr, size)
+			if !ok || w.api == nil {
+				return
+			}
+			w.api.Notify(ctx, message)
+		}).
+		Export("notify").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, module api.Module, ptr, size uint32) {
+			if !w.hasCapability(ctx, plugin.WasmCapabilityChangeQuery) {
+				return
+			}
+			queryJson, ok := w.readString(module, ptr, size)
+			if !ok || w.api == nil {
+				return
+			}
+			var query share.PlainQuery
+			if unmarshalErr := json.Unmarshal([]byte(queryJson), &query); unmarshalErr != nil {
+				util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal change query: %s", w.metadata.Name, unmarshalErr))
+				return
+			}
+			w.api.ChangeQuery(ctx, query)
+		}).
+		Export("change_query").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, module api.Module, keyPtr, keyLen uint32) uint64 {
+			if !w.hasCapability(ctx, plugin.WasmCapabilityGetSetting) {
+				return 0
+			}
+			key, ok := w.readString(module, keyPtr, keyLen)
+			if !ok || w.api == nil {
+				return 0
+			}
+			value := w.api.GetSetting(ctx, key)
+			ptr, writeErr := w.writeToGuest(ctx, module, []byte(value))
+			if writeErr != nil {
+				util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to write setting to guest: %s", w.metadata.Name, writeErr))
+				return 0
+			}
+			return uint64(ptr)<<32 | uint64(len(value))
+		}).
+		Export("get_setting").
+		Instantiate(ctx)
+	return instantiateErr
+}
+
+func (w *WasmPlugin) close(ctx context.Context) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.isClosed = true
+	if closeErr := w.runtime.Close(ctx); closeErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to close wasm runtime: %s", w.metadata.Name, closeErr))
+	}
+}
+
+// wasmLogWriter writes stdout and stderr of wasm plugin to Wox log
+type wasmLogWriter struct {
+	pluginName string
+	isError    bool
+}
+
+func (l *wasmLogWriter) Write(p []byte) (int, error) {
+	ctx := util.NewTraceContext()
+	msg := fmt.Sprintf("[%s] %s", l.pluginName, strings.TrimRight(string(p), "\n"))
+	if l.isError {
+		util.GetLogger().Error(ctx, msg)
+	} else {
+		util.GetLogger().Info(ctx, msg)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,158 @@
+package host
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wox/plugin"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildTestWasmModule assembles a minimal guest by hand, so tests don't depend on a wasm toolchain:
+// query returns the given results json, init stores 1 at address 4, action stores its input length at address 0, or loops forever if actionLoops
+func buildTestWasmModule(results string, actionLoops bool) []byte {
+	section := func(id byte, items ...[]byte) []byte {
+		var payload []byte
+		payload = binary.AppendUvarint(payload, uint64(len(items)))
+		for _, item := range items {
+			payload = append(payload, item...)
+		}
+		return append(binary.AppendUvarint([]byte{id}, uint64(len(payload))), payload...)
+	}
+	name := func(s string) []byte {
+		return append(binary.AppendUvarint(nil, uint64(len(s))), s...)
+	}
+	code := func(body ...byte) []byte {
+		body = append([]byte{0x00}, body...) // no locals
+		return append(binary.AppendUvarint(nil, uint64(len(body))), body...)
+	}
+
+	// i64.const takes signed leb128, which is not the zigzag encoding of binary.AppendVarint
+	sleb128 := func(v int64) []byte {
+		var out []byte
+		for {
+			b := byte(v & 0x7f)
+			v >>= 7
+			if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+				return append(out, b)
+			}
+			out = append(out, b|0x80)
+		}
+	}
+
+	const resultsOffset = 16
+	packed := sleb128(int64(resultsOffset)<<32 | int64(len(results)))
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(1,
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},       // (i32) -> i32
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e}, // (i32, i32) -> i64
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x00},       // (i32, i32) -> ()
+	)...)
+	module = append(module, section(3, []byte{0x00}, []byte{0x01}, []byte{0x02}, []byte{0x02})...)
+	module = append(module, section(5, []byte{0x00, 0x01})...)                         // 1 page memory
+	module = append(module, section(6, []byte{0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b})...) // mutable heap pointer starts at 1024
+	module = append(module, section(7,
+		append(name("memory"), 0x02, 0x00),
+		append(name("wox_alloc"), 0x00, 0x00),
+		append(name("query"), 0x00, 0x01),
+		append(name("action"), 0x00, 0x02),
+		append(name("init"), 0x00, 0x03),
+	)...)
+	actionCode := code(0x41, 0x00, 0x20, 0x01, 0x36, 0x02, 0x00, 0x0b) // store input length at 0
+	if actionLoops {
+		actionCode = code(0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b) // loop br 0 end
+	}
+	module = append(module, section(10,
+		code(0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b), // return heap, heap += size
+		code(append(append([]byte{0x42}, packed...), 0x0b)...),           // return packed results
+		actionCode,
+		code(0x41, 0x04, 0x41, 0x01, 0x36, 0x02, 0x00, 0x0b), // store 1 at 4
+	)...)
+	module = append(module, section(11, append([]byte{0x00, 0x41, resultsOffset, 0x0b}, name(results)...))...)
+	return module
+}
+
+func Test_WasmPlugin(t *testing.T) {
+	ctx := util.NewTraceContext()
+	pluginDirectory := t.TempDir()
+	wasmModule := buildTestWasmModule(`[{"Title":"hello","Actions":[{"Id":"open","Name":"Open"}]}]`, false)
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "plugin.wasm"), wasmModule, 0644))
+
+	host := &WasmHost{plugins: util.NewHashMap[string, *WasmPlugin]()}
+	instance, loadErr := host.LoadPlugin(ctx, plugin.Metadata{Id: "wasm", Name: "wasm", Entry: "plugin.wasm"}, pluginDirectory)
+	assert.Nil(t, loadErr)
+
+	results := instance.Query(ctx, plugin.Query{RawQuery: "w hello", Search: "hello"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, "hello", results[0].Title)
+	assert.Len(t, results[0].Actions, 1)
+
+	results[0].Actions[0].Action(ctx, plugin.ActionContext{ContextData: "ctx"})
+	inputLength, ok := instance.(*WasmPlugin).module.Memory().ReadUint32Le(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(len(`{"ActionId":"open","ContextData":"ctx"}`)), inputLength)
+
+	assert.False(t, instance.(*WasmPlugin).hasCapability(ctx, plugin.WasmCapabilityNotify))
+
+	host.UnloadPlugin(ctx, plugin.Metadata{Id: "wasm"})
+	assert.True(t, instance.(*WasmPlugin).module.IsClosed())
+}
+
+func Test_WasmPluginMissingExport(t *testing.T) {
+	ctx := util.NewTraceContext()
+	pluginDirectory := t.TempDir()
+	// empty module, no memory and no exports
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "plugin.wasm"), []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, 0644))
+
+	host := &WasmHost{plugins: util.NewHashMap[string, *WasmPlugin]()}
+	_, loadErr := host.LoadPlugin(ctx, plugin.Metadata{Id: "wasm", Name: "wasm", Entry: "plugin.wasm"}, pluginDirectory)
+	assert.NotNil(t, loadErr)
+}
+
+func Test_WasmPluginCallTimeout(t *testing.T) {
+	ctx := util.NewTraceContext()
+	pluginDirectory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "plugin.wasm"), buildTestWasmModule(`[]`, true), 0644))
+
+	previousTimeout := wasmCallTimeout
+	wasmCallTimeout = time.Millisecond * 200
+	t.Cleanup(func() { wasmCallTimeout = previousTimeout })
+
+	host := &WasmHost{plugins: util.NewHashMap[string, *WasmPlugin]()}
+	instance, loadErr := host.LoadPlugin(ctx, plugin.Metadata{Id: "wasm-loop", Name: "wasm-loop", Entry: "plugin.wasm"}, pluginDirectory)
+	assert.Nil(t, loadErr)
+
+	instance.Init(ctx, plugin.InitParams{PluginDirectory: pluginDirectory})
+	_, callErr := instance.(*WasmPlugin).call(ctx, "action", map[string]string{})
+	assert.NotNil(t, callErr)
+	assert.True(t, instance.(*WasmPlugin).module.IsClosed())
+
+	// module is instantiated and initialized again, plugin keeps working after a stuck call
+	results := instance.Query(ctx, plugin.Query{RawQuery: "w hello", Search: "hello"})
+	assert.Empty(t, results)
+	assert.False(t, instance.(*WasmPlugin).module.IsClosed())
+	initialized, ok := instance.(*WasmPlugin).module.Memory().ReadUint32Le(4)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), initialized)
+
+	// unloaded plugin is never instantiated again
+	host.UnloadPlugin(ctx, plugin.Metadata{Id: "wasm-loop"})
+	_, callErr = instance.(*WasmPlugin).call(ctx, "query", plugin.Query{})
+	assert.NotNil(t, callErr)
+}
+
+func Test_WasmPluginMaxMemory(t *testing.T) {
+	metadata := plugin.Metadata{Features: []plugin.MetadataFeature{{Name: plugin.MetadataFeatureWasm, Params: map[string]string{"maxMemoryMb": "4097"}}}}
+	_, paramsErr := metadata.GetFeatureParamsForWasm()
+	assert.NotNil(t, paramsErr)
+
+	metadata.Features[0].Params["maxMemoryMb"] = "4096"
+	params, paramsErr := metadata.GetFeatureParamsForWasm()
+	assert.Nil(t, paramsErr)
+	assert.Equal(t, 4096, params.MaxMemoryMb)
+}
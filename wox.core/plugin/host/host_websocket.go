@@ -6,35 +6,138 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
+	"sync"
 	"time"
 	"wox/ai"
+	"wox/i18n"
 	"wox/plugin"
 	"wox/setting/definition"
 	"wox/share"
 	"wox/util"
 
 	"github.com/google/uuid"
-	"github.com/tidwall/gjson"
+	"github.com/samber/lo"
 )
 
+const (
+	hostReadyTimeout          = time.Second * 10 // max time to wait for host to accept connection and answer the handshake
+	hostHealthCheckInterval   = time.Second * 5
+	hostMaxDisconnectedChecks = 3 // kill the host if websocket keeps disconnected for this many checks
+	hostMaxRestartBackoff     = time.Minute
+	hostStableDuration        = time.Minute // reset restart backoff if host has been running longer than this
+)
+
+// hostResponseQueue keeps responses and stream frames of one request until invoker takes them.
+// It's unbounded, so the receiving goroutine never waits for a slow invoker and no response is ever dropped
+type hostResponseQueue struct {
+	lock      sync.Mutex
+	responses []JsonRpcResponse
+	arrived   chan struct{} // signaled after responses are added
+}
+
+func newHostResponseQueue() *hostResponseQueue {
+	return &hostResponseQueue{arrived: make(chan struct{}, 1)}
+}
+
+func (q *hostResponseQueue) push(response JsonRpcResponse) {
+	q.lock.Lock()
+	q.responses = append(q.responses, response)
+	q.lock.Unlock()
+
+	select {
+	case q.arrived <- struct{}{}:
+	default:
+		// invoker is already signaled and will take this response together with the pending ones
+	}
+}
+
+// take returns all queued responses in arrival order
+func (q *hostResponseQueue) take() []JsonRpcResponse {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	responses := q.responses
+	q.responses = nil
+	return responses
+}
+
 type WebsocketHost struct {
+	host       plugin.Host
+	requestMap *util.HashMap[string, *hostResponseQueue]
+
+	isolatedPlugin *isolatedPlugin // only available when this host is dedicated to a single plugin
+
+	// StartHost, StopHost and supervisors run in different goroutines, fields below are guarded by lock
+	lock        sync.Mutex
 	ws          *util.WebsocketClient
-	host        plugin.Host
-	requestMap  *util.HashMap[string, chan JsonRpcResponse]
 	hostProcess *os.Process
+	protocol    HostProtocol // negotiated in handshake, legacy until handshake is finished
+
+	// used by supervisor to restart the host after crash
+	executablePath   string
+	entry            string
+	envs             []string
+	executableArgs   []string
+	restartCount     int
+	startedTimestamp int64
+	stopChan         chan struct{} // created by every StartHost and closed by StopHost, supervisors of a closed one exit
+}
+
+// isHostStopped reports whether the start which created stopChan has been stopped
+func isHostStopped(stopChan chan struct{}) bool {
+	select {
+	case <-stopChan:
+		return true
+	default:
+		return false
+	}
 }
 
 func (w *WebsocketHost) getHostName(ctx context.Context) string {
+	if w.isolatedPlugin != nil {
+		return fmt.Sprintf("%s Host Impl (%s)", w.host.GetRuntime(ctx), w.isolatedPlugin.metadata.Name)
+	}
 	return fmt.Sprintf("%s Host Impl", w.host.GetRuntime(ctx))
 }
 
 func (w *WebsocketHost) StartHost(ctx context.Context, executablePath string, entry string, envs []string, executableArgs ...string) error {
+	stopChan := make(chan struct{})
+	w.lock.Lock()
+	// supervisor of the previous start must not restart its host anymore
+	if w.stopChan != nil && !isHostStopped(w.stopChan) {
+		close(w.stopChan)
+	}
+	w.stopChan = stopChan
+	w.executablePath = executablePath
+	w.entry = entry
+	w.envs = envs
+	w.executableArgs = executableArgs
+	w.lock.Unlock()
+
+	cmd, exitChan, startErr := w.startHostProcess(ctx, stopChan)
+	if startErr != nil {
+		return startErr
+	}
+
+	util.Go(ctx, fmt.Sprintf("<%s> supervise host", w.getHostName(ctx)), func() {
+		w.superviseHost(util.NewTraceContext(), stopChan, cmd, exitChan)
+	})
+	return nil
+}
+
+// startHostProcess starts the host process and waits until it's ready to serve requests.
+// The process is killed if host is stopped meanwhile, so a stopped host never leaves a process behind
+func (w *WebsocketHost) startHostProcess(ctx context.Context, stopChan chan struct{}) (*exec.Cmd, chan error, error) {
 	port, portErr := util.GetAvailableTcpPort(ctx)
 	if portErr != nil {
-		return fmt.Errorf("failed to get available port: %w", portErr)
+		return nil, nil, fmt.Errorf("failed to get available port: %w", portErr)
 	}
 
+	w.lock.Lock()
+	executablePath, entry, envs, executableArgs := w.executablePath, w.entry, w.envs, w.executableArgs
+	w.lock.Unlock()
+
 	util.GetLogger().Info(ctx, fmt.Sprintf("<%s> starting host on port %d", w.getHostName(ctx), port))
 	util.GetLogger().Info(ctx, fmt.Sprintf("<%s> host path: %s", w.getHostName(ctx), executablePath))
 	util.GetLogger().Info(ctx, fmt.Sprintf("<%s> host entry: %s", w.getHostName(ctx), entry))
@@ -43,26 +146,263 @@ func (w *WebsocketHost) StartHost(ctx context.Context, executablePath string, en
 
 	var args []string
 	args = append(args, executableArgs...)
-	args = append(args, entry, fmt.Sprintf("%d", port), util.GetLocation().GetLogHostsDirectory(), fmt.Sprintf("%d", os.Getpid()))
+	// go plugins are executables themselves, there is no entry to pass
+	if entry != "" {
+		args = append(args, entry)
+	}
+	args = append(args, fmt.Sprintf("%d", port), util.GetLocation().GetLogHostsDirectory(), fmt.Sprintf("%d", os.Getpid()))
 
-	cmd, err := util.ShellRunWithEnv(executablePath, envs, args...)
+	var cmd *exec.Cmd
+	var err error
+	if w.isolatedPlugin != nil {
+		// limits must be in place before host runs any plugin code
+		cmd, err = startLimitedHostProcess(ctx, w.isolatedPlugin.metadata.Id, w.isolatedPlugin.params, executablePath, envs, args...)
+	} else {
+		cmd, err = util.ShellRunWithEnv(executablePath, envs, args...)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to start host: %w", err)
+		return nil, nil, fmt.Errorf("failed to start host: %w", err)
 	}
 	util.GetLogger().Info(ctx, fmt.Sprintf("<%s> host pid: %d", w.getHostName(ctx), cmd.Process.Pid))
 
-	time.Sleep(time.Second) // wait for host to start
-	w.startWebsocketServer(ctx, port)
+	exitChan := make(chan error, 1)
+	util.Go(ctx, fmt.Sprintf("<%s> wait host process", w.getHostName(ctx)), func() {
+		exitChan <- cmd.Wait()
+	})
+
+	ws, readyErr := w.waitHostReady(ctx, stopChan, port, exitChan)
+	if readyErr != nil {
+		cmd.Process.Kill()
+		return nil, nil, fmt.Errorf("host is not ready: %w", readyErr)
+	}
 
+	w.lock.Lock()
+	if isHostStopped(stopChan) {
+		w.lock.Unlock()
+		ws.Close(ctx)
+		cmd.Process.Kill()
+		return nil, nil, fmt.Errorf("host is stopped during startup")
+	}
 	w.hostProcess = cmd.Process
-	return nil
+	w.startedTimestamp = util.GetSystemTimestamp()
+	protocol := w.protocol
+	w.lock.Unlock()
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("<%s> host is ready, protocol version: %d, encoding: %s, capabilities: %s", w.getHostName(ctx), protocol.ProtocolVersion, protocol.Encoding, strings.Join(protocol.Capabilities, ",")))
+	return cmd, exitChan, nil
+}
+
+// waitHostReady connects to host websocket server until it accepts connection, then negotiates protocol with host
+func (w *WebsocketHost) waitHostReady(ctx context.Context, stopChan chan struct{}, port int, exitChan chan error) (*util.WebsocketClient, error) {
+	deadline := time.Now().Add(hostReadyTimeout)
+	var ws *util.WebsocketClient
+	for {
+		select {
+		case exitErr := <-exitChan:
+			exitChan <- exitErr // put it back for supervisor
+			return nil, fmt.Errorf("host process exited during startup: %v", exitErr)
+		default:
+		}
+
+		client, connectErr := w.startWebsocketServer(ctx, port)
+		if connectErr == nil {
+			ws = client
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to connect to host in %s: %w", hostReadyTimeout, connectErr)
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	// handshake is sent through w.ws, don't replace the connection of a newer start
+	w.lock.Lock()
+	if isHostStopped(stopChan) {
+		w.lock.Unlock()
+		ws.Close(ctx)
+		return nil, fmt.Errorf("host is stopped during startup")
+	}
+	w.ws = ws
+	w.protocol = legacyHostProtocol
+	w.lock.Unlock()
+
+	protocol, handshakeErr := w.handshake(ctx, time.Until(deadline))
+	if handshakeErr != nil {
+		ws.Close(ctx)
+		return nil, fmt.Errorf("handshake failed: %w", handshakeErr)
+	}
+	w.lock.Lock()
+	w.protocol = protocol
+	w.lock.Unlock()
+
+	return ws, nil
+}
+
+// handshake tells host the protocol version, encodings and capabilities Wox supports, host answers with what it picked.
+// Hosts which don't know handshake answer with an unknown method error, they are served with legacy protocol.
+func (w *WebsocketHost) handshake(ctx context.Context, timeout time.Duration) (HostProtocol, error) {
+	result, handshakeErr := w.invokeMethodWithTimeout(ctx, plugin.Metadata{Name: w.getHostName(ctx)}, "handshake", map[string]string{
+		"ProtocolVersion": fmt.Sprintf("%d", HostProtocolVersionCurrent),
+		"Encodings":       strings.Join([]HostEncoding{HostEncodingMsgpack, HostEncodingJson}, ","),
+		"Capabilities":    strings.Join([]HostCapability{HostCapabilityStreamResults}, ","),
+	}, timeout)
+	if handshakeErr != nil {
+		var responseErr *JsonRpcResponseError
+		if errors.As(handshakeErr, &responseErr) {
+			util.GetLogger().Info(ctx, fmt.Sprintf("<%s> host doesn't support handshake (%s), use legacy protocol", w.getHostName(ctx), responseErr.Message))
+			return legacyHostProtocol, nil
+		}
+		return HostProtocol{}, handshakeErr
+	}
+
+	var protocol HostProtocol
+	resultJson, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return HostProtocol{}, fmt.Errorf("failed to marshal handshake result: %w", marshalErr)
+	}
+	unmarshalErr := json.Unmarshal(resultJson, &protocol)
+	if unmarshalErr != nil {
+		return HostProtocol{}, fmt.Errorf("failed to unmarshal handshake result: %w", unmarshalErr)
+	}
+	if protocol.ProtocolVersion < HostProtocolVersionLegacy || protocol.ProtocolVersion > HostProtocolVersionCurrent {
+		return HostProtocol{}, fmt.Errorf("unsupported protocol version: %d", protocol.ProtocolVersion)
+	}
+	if protocol.Encoding != HostEncodingJson && protocol.Encoding != HostEncodingMsgpack {
+		return HostProtocol{}, fmt.Errorf("unsupported encoding: %s", protocol.Encoding)
+	}
+
+	return protocol, nil
+}
+
+// superviseHost watches the host process and websocket connection, restarts the host if it crashed
+func (w *WebsocketHost) superviseHost(ctx context.Context, stopChan chan struct{}, cmd *exec.Cmd, exitChan chan error) {
+	ticker := time.NewTicker(hostHealthCheckInterval)
+	defer ticker.Stop()
+
+	disconnectedChecks := 0
+	for {
+		select {
+		case <-stopChan:
+			return
+		case exitErr := <-exitChan:
+			// StopHost closes stopChan before killing the process
+			if isHostStopped(stopChan) {
+				return
+			}
+			w.restartHost(ctx, stopChan, fmt.Sprintf("host process(%d) exited: %v", cmd.Process.Pid, exitErr))
+			return
+		case <-ticker.C:
+			if w.IsHostStarted(ctx) {
+				disconnectedChecks = 0
+				continue
+			}
+
+			disconnectedChecks++
+			util.GetLogger().Warn(ctx, fmt.Sprintf("<%s> host is disconnected, check count: %d", w.getHostName(ctx), disconnectedChecks))
+			if disconnectedChecks >= hostMaxDisconnectedChecks {
+				// process will exit and be restarted in next loop
+				util.GetLogger().Error(ctx, fmt.Sprintf("<%s> host keeps disconnected, kill it", w.getHostName(ctx)))
+				cmd.Process.Kill()
+				disconnectedChecks = 0
+			}
+		}
+	}
+}
+
+func (w *WebsocketHost) restartHost(ctx context.Context, stopChan chan struct{}, reason string) {
+	w.lock.Lock()
+	if isHostStopped(stopChan) {
+		w.lock.Unlock()
+		return
+	}
+	ws := w.ws
+	w.ws = nil
+	if util.GetSystemTimestamp()-w.startedTimestamp > hostStableDuration.Milliseconds() {
+		w.restartCount = 0
+	}
+	w.lock.Unlock()
+
+	util.GetLogger().Error(ctx, fmt.Sprintf("<%s> %s, restarting host", w.getHostName(ctx), reason))
+	if ws != nil {
+		ws.Close(ctx)
+	}
+
+	notified := false
+	for !isHostStopped(stopChan) {
+		w.lock.Lock()
+		backoff := time.Second * time.Duration(1<<min(w.restartCount, 6))
+		if backoff > hostMaxRestartBackoff {
+			backoff = hostMaxRestartBackoff
+		}
+		w.restartCount++
+		attempt := w.restartCount
+		w.lock.Unlock()
+
+		if !notified {
+			w.notify(ctx, fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_manager_host_crashed"), w.host.GetRuntime(ctx), int(backoff.Seconds())))
+			notified = true
+		}
+		util.GetLogger().Info(ctx, fmt.Sprintf("<%s> restart host in %s, attempt: %d", w.getHostName(ctx), backoff, attempt))
+		select {
+		case <-time.After(backoff):
+		case <-stopChan:
+			return
+		}
+
+		cmd, exitChan, startErr := w.startHostProcess(ctx, stopChan)
+		if startErr != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to restart host: %s", w.getHostName(ctx), startErr))
+			continue
+		}
+
+		reloadedCount := 1
+		if w.isolatedPlugin != nil {
+			if reloadErr := w.reloadIsolatedPlugin(ctx); reloadErr != nil {
+				util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to reload plugin: %s", w.getHostName(ctx), reloadErr))
+				reloadedCount = 0
+			}
+		} else {
+			reloadedCount = plugin.GetPluginManager().ReloadHostPlugins(ctx, w.host, isServedBySharedHost)
+		}
+		util.GetLogger().Info(ctx, fmt.Sprintf("<%s> host restarted, reloaded %d plugins", w.getHostName(ctx), reloadedCount))
+		w.notify(ctx, fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_manager_host_restarted"), w.host.GetRuntime(ctx), reloadedCount))
+
+		util.Go(ctx, fmt.Sprintf("<%s> supervise host", w.getHostName(ctx)), func() {
+			w.superviseHost(util.NewTraceContext(), stopChan, cmd, exitChan)
+		})
+		return
+	}
+}
+
+func (w *WebsocketHost) notify(ctx context.Context, message string) {
+	ui := plugin.GetPluginManager().GetUI()
+	if ui == nil {
+		return
+	}
+
+	ui.Notify(ctx, share.NotifyMsg{
+		Text:           message,
+		DisplaySeconds: 5,
+	})
 }
 
 func (w *WebsocketHost) StopHost(ctx context.Context) {
 	util.GetLogger().Info(ctx, fmt.Sprintf("<%s> stopping host", w.getHostName(ctx)))
-	if w.hostProcess != nil {
-		var pid = w.hostProcess.Pid
-		killErr := w.hostProcess.Kill()
+	w.lock.Lock()
+	if w.stopChan != nil && !isHostStopped(w.stopChan) {
+		close(w.stopChan)
+	}
+	ws, hostProcess := w.ws, w.hostProcess
+	w.ws = nil
+	w.hostProcess = nil
+	w.lock.Unlock()
+
+	if ws != nil {
+		ws.Close(ctx)
+	}
+	if hostProcess != nil {
+		var pid = hostProcess.Pid
+		killErr := hostProcess.Kill()
 		if killErr != nil {
 			util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to kill host process(%d): %s", w.getHostName(ctx), pid, killErr))
 		} else {
@@ -72,16 +412,24 @@ func (w *WebsocketHost) StopHost(ctx context.Context) {
 }
 
 func (w *WebsocketHost) IsHostStarted(ctx context.Context) bool {
-	return w.ws != nil && w.ws.IsConnected()
+	w.lock.Lock()
+	ws := w.ws
+	w.lock.Unlock()
+	return ws != nil && ws.IsConnected()
 }
 
-func (w *WebsocketHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (plugin.Plugin, error) {
+// LoadPlugin loads plugin into host, extraParams are runtime specific params passed to host, e.g. SitePackagesDirectory for python
+func (w *WebsocketHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string, extraParams map[string]string) (plugin.Plugin, error) {
 	util.GetLogger().Info(ctx, fmt.Sprintf("start loading %s plugin, directory: %s", metadata.Name, pluginDirectory))
-	_, loadPluginErr := w.invokeMethod(ctx, metadata, "loadPlugin", map[string]string{
+	params := map[string]string{
 		"PluginId":        metadata.Id,
 		"PluginDirectory": pluginDirectory,
 		"Entry":           metadata.Entry,
-	})
+	}
+	for key, value := range extraParams {
+		params[key] = value
+	}
+	_, loadPluginErr := w.invokeMethod(ctx, metadata, "loadPlugin", params)
 	if loadPluginErr != nil {
 		return nil, loadPluginErr
 	}
@@ -99,7 +447,24 @@ func (w *WebsocketHost) UnloadPlugin(ctx context.Context, metadata plugin.Metada
 }
 
 func (w *WebsocketHost) invokeMethod(ctx context.Context, metadata plugin.Metadata, method string, params map[string]string) (result any, err error) {
-	if w.ws == nil || !w.ws.IsConnected() {
+	return w.invokeMethodWithTimeout(ctx, metadata, method, params, time.Second*30)
+}
+
+func (w *WebsocketHost) invokeMethodWithTimeout(ctx context.Context, metadata plugin.Metadata, method string, params map[string]string, timeout time.Duration) (result any, err error) {
+	return w.invokeMethodWithStream(ctx, metadata, method, params, timeout, nil)
+}
+
+// invokeMethodWithStream invokes method and calls onStream with every partial result host streams before the final response.
+// Timeout is counted from the last message received, so a plugin which keeps streaming won't time out.
+func (w *WebsocketHost) invokeMethodWithStream(ctx context.Context, metadata plugin.Metadata, method string, params map[string]string, timeout time.Duration, onStream func(result any)) (result any, err error) {
+	// lifecycle methods are invoked while (re)starting the dedicated host, don't wake it up again
+	if w.isolatedPlugin != nil && !lo.Contains([]string{"handshake", "ping", "loadPlugin", "init", "unloadPlugin"}, method) {
+		if wakeErr := w.wakeIsolatedHost(ctx); wakeErr != nil {
+			return "", wakeErr
+		}
+	}
+
+	if !w.IsHostStarted(ctx) {
 		return "", fmt.Errorf("host is not connected")
 	}
 
@@ -114,87 +479,113 @@ func (w *WebsocketHost) invokeMethod(ctx context.Context, metadata plugin.Metada
 	}
 	util.GetLogger().Debug(ctx, fmt.Sprintf("<Wox -> %s> inovke plugin <%s> method: %s, request id: %s", w.getHostName(ctx), metadata.Name, method, request.Id))
 
-	jsonData, marshalErr := json.Marshal(request)
-	if marshalErr != nil {
-		return "", marshalErr
-	}
-
-	resultChan := make(chan JsonRpcResponse)
-	w.requestMap.Store(request.Id, resultChan)
+	// queued so receiving goroutine never waits for a slow invoker, which would block responses of all other plugins on this host
+	responseQueue := newHostResponseQueue()
+	w.requestMap.Store(request.Id, responseQueue)
 	defer w.requestMap.Delete(request.Id)
 
 	startTimestamp := util.GetSystemTimestamp()
-	sendErr := w.ws.Send(ctx, jsonData)
+	sendErr := w.sendMessage(ctx, request)
 	if sendErr != nil {
 		return "", sendErr
 	}
 
-	select {
-	case <-time.NewTimer(time.Second * 30).C:
-		util.GetLogger().Error(ctx, fmt.Sprintf("invoke %s response timeout, response time: %dms", metadata.Name, util.GetSystemTimestamp()-startTimestamp))
-		return "", fmt.Errorf("request timeout, request id: %s", request.Id)
-	case response := <-resultChan:
-		util.GetLogger().Debug(ctx, fmt.Sprintf("inovke plugin <%s> method: %s finished, response time: %dms", metadata.Name, method, util.GetSystemTimestamp()-startTimestamp))
-		if response.Error != "" {
-			return "", errors.New(response.Error)
-		} else {
-			return response.Result, nil
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			util.GetLogger().Error(ctx, fmt.Sprintf("invoke %s response timeout, response time: %dms", metadata.Name, util.GetSystemTimestamp()-startTimestamp))
+			return "", fmt.Errorf("request timeout, request id: %s", request.Id)
+		case <-responseQueue.arrived:
+			for _, response := range responseQueue.take() {
+				if response.Type == JsonRpcTypeStream {
+					if onStream != nil {
+						onStream(response.Result)
+					}
+					timer.Reset(timeout)
+					continue
+				}
+
+				util.GetLogger().Debug(ctx, fmt.Sprintf("inovke plugin <%s> method: %s finished, response time: %dms", metadata.Name, method, util.GetSystemTimestamp()-startTimestamp))
+				if response.Error != "" {
+					return "", &JsonRpcResponseError{Message: response.Error}
+				} else {
+					return response.Result, nil
+				}
+			}
 		}
 	}
 }
 
-func (w *WebsocketHost) startWebsocketServer(ctx context.Context, port int) {
-	w.ws = util.NewWebsocketClient(fmt.Sprintf("ws://localhost:%d", port))
-	w.ws.OnMessage(ctx, func(data []byte) {
-		util.Go(ctx, fmt.Sprintf("<%s> onMessage", w.getHostName(ctx)), func() {
-			w.onMessage(string(data))
-		})
+// sendMessage encodes message with negotiated encoding, msgpack is sent as binary frame
+func (w *WebsocketHost) sendMessage(ctx context.Context, message any) error {
+	w.lock.Lock()
+	ws, protocol := w.ws, w.protocol
+	w.lock.Unlock()
+	if ws == nil {
+		return fmt.Errorf("host is not connected")
+	}
+
+	data, encodeErr := encodeJsonRpcMessage(protocol.Encoding, message)
+	if encodeErr != nil {
+		return fmt.Errorf("failed to encode message: %w", encodeErr)
+	}
+
+	if protocol.Encoding == HostEncodingMsgpack {
+		return ws.SendBinary(ctx, data)
+	}
+	return ws.Send(ctx, data)
+}
+
+func (w *WebsocketHost) startWebsocketServer(ctx context.Context, port int) (*util.WebsocketClient, error) {
+	ws := util.NewWebsocketClient(fmt.Sprintf("ws://localhost:%d", port))
+	// host may switch encoding right after handshake, so decode by frame type instead of negotiated encoding
+	ws.OnMessage(ctx, func(data []byte) {
+		w.onMessage(HostEncodingJson, data)
+	})
+	ws.OnBinaryMessage(ctx, func(data []byte) {
+		w.onMessage(HostEncodingMsgpack, data)
 	})
-	connErr := w.ws.Connect(ctx)
+	connErr := ws.Connect(ctx)
 	if connErr != nil {
-		util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to connect to host: %s", w.getHostName(ctx), connErr))
-		return
+		ws.Close(ctx)
+		return nil, connErr
 	}
+
+	return ws, nil
 }
 
-func (w *WebsocketHost) onMessage(data string) {
+// onMessage is called in websocket receiving goroutine, responses are delivered in order so streamed results arrive before final response
+func (w *WebsocketHost) onMessage(encoding HostEncoding, data []byte) {
 	ctx := util.NewTraceContext()
 
-	if strings.Contains(data, string(JsonRpcTypeSystemLog)) {
-		traceId := gjson.Get(data, "TraceId").String()
-		level := gjson.Get(data, "Level").String()
-		msg := gjson.Get(data, "Message").String()
+	message, decodeErr := decodeJsonRpcMessage(encoding, data)
+	if decodeErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to decode %s message: %s", w.getHostName(ctx), encoding, decodeErr))
+		return
+	}
 
-		logCtx := util.NewComponentContext(util.NewTraceContextWith(traceId), fmt.Sprintf("%s HOST", w.host.GetRuntime(ctx)))
-		if level == "error" {
-			util.GetLogger().Error(logCtx, msg)
+	switch message.Type {
+	case JsonRpcTypeSystemLog:
+		logCtx := util.NewComponentContext(util.NewTraceContextWith(message.TraceId), fmt.Sprintf("%s HOST", w.host.GetRuntime(ctx)))
+		if message.Level == "error" {
+			util.GetLogger().Error(logCtx, message.Message)
 		}
-		if level == "info" {
-			util.GetLogger().Info(logCtx, msg)
-		}
-		if level == "debug" {
-			util.GetLogger().Debug(logCtx, msg)
-		}
-	} else if strings.Contains(data, string(JsonRpcTypeRequest)) {
-		var request JsonRpcRequest
-		unmarshalErr := json.Unmarshal([]byte(data), &request)
-		if unmarshalErr != nil {
-			util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to unmarshal request: %s", w.getHostName(ctx), unmarshalErr))
-			return
+		if message.Level == "info" {
+			util.GetLogger().Info(logCtx, message.Message)
 		}
-
-		w.handleRequestFromPlugin(util.NewTraceContextWith(request.TraceId), request)
-	} else if strings.Contains(data, string(JsonRpcTypeResponse)) {
-		var response JsonRpcResponse
-		unmarshalErr := json.Unmarshal([]byte(data), &response)
-		if unmarshalErr != nil {
-			util.GetLogger().Error(ctx, fmt.Sprintf("<%s> failed to unmarshal response: %s", w.getHostName(ctx), unmarshalErr))
-			return
+		if message.Level == "debug" {
+			util.GetLogger().Debug(logCtx, message.Message)
 		}
-
-		w.handleResponseFromPlugin(util.NewTraceContextWith(response.TraceId), response)
-	} else {
-		util.GetLogger().Error(ctx, fmt.Sprintf("<%s> unknown message type: %s", w.getHostName(ctx), data))
+	case JsonRpcTypeRequest:
+		util.Go(ctx, fmt.Sprintf("<%s> handle request", w.getHostName(ctx)), func() {
+			w.handleRequestFromPlugin(util.NewTraceContextWith(message.TraceId), message.ToRequest())
+		})
+	case JsonRpcTypeResponse, JsonRpcTypeStream:
+		w.handleResponseFromPlugin(util.NewTraceContextWith(message.TraceId), message.ToResponse())
+	default:
+		util.GetLogger().Error(ctx, fmt.Sprintf("<%s> unknown message type: %s", w.getHostName(ctx), message.Type))
 	}
 }
 
@@ -318,7 +709,11 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 		}
 		isPlatformSpecific := strings.ToLower(isPlatformSpecificStr) == "true"
 
-		pluginInstance.API.SaveSetting(ctx, key, value, isPlatformSpecific)
+		saveErr := pluginInstance.API.SaveSetting(ctx, key, value, isPlatformSpecific)
+		if saveErr != nil {
+			w.sendErrorResponseToHost(ctx, request, saveErr)
+			return
+		}
 		w.sendResponseToHost(ctx, request, "")
 	case "OnPluginSettingChanged":
 		callbackId, exist := request.Params["callbackId"]
@@ -429,6 +824,71 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 
 		pluginInstance.API.RegisterQueryCommands(ctx, commands)
 		w.sendResponseToHost(ctx, request, "")
+	case "PushResults":
+		queryId, exist := request.Params["queryId"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] PushResults method must have a queryId parameter", request.PluginName))
+			return
+		}
+		resultsStr, exist := request.Params["results"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] PushResults method must have a results parameter", request.PluginName))
+			return
+		}
+		websocketPlugin, ok := pluginInstance.Plugin.(*WebsocketPlugin)
+		if !ok {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] PushResults is only available for websocket plugins", request.PluginName))
+			return
+		}
+
+		var rawResults []any
+		unmarshalErr := json.Unmarshal([]byte(resultsStr), &rawResults)
+		if unmarshalErr != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to unmarshal pushed results: %s", request.PluginName, unmarshalErr))
+			w.sendErrorResponseToHost(ctx, request, unmarshalErr)
+			return
+		}
+
+		pushErr := pluginInstance.API.PushResults(ctx, queryId, websocketPlugin.parseQueryResults(ctx, rawResults))
+		if pushErr != nil {
+			util.GetLogger().Warn(ctx, fmt.Sprintf("[%s] failed to push results: %s", request.PluginName, pushErr))
+			w.sendErrorResponseToHost(ctx, request, pushErr)
+			return
+		}
+		w.sendResponseToHost(ctx, request, "")
+	case "RegisterQueryVariable":
+		callbackId, exist := request.Params["callbackId"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] RegisterQueryVariable method must have a callbackId parameter", request.PluginName))
+			return
+		}
+		name, exist := request.Params["name"]
+		if !exist {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] RegisterQueryVariable method must have a name parameter", request.PluginName))
+			return
+		}
+
+		metadata := pluginInstance.Metadata
+		registerErr := pluginInstance.API.RegisterQueryVariable(ctx, name, request.Params["description"], func(ctx context.Context, arg string) (string, error) {
+			result, err := w.invokeMethod(ctx, metadata, "onQueryVariable", map[string]string{
+				"CallbackId": callbackId,
+				"Arg":        arg,
+			})
+			if err != nil {
+				return "", err
+			}
+			value, ok := result.(string)
+			if !ok {
+				return "", fmt.Errorf("query variable value is not a string: %v", result)
+			}
+			return value, nil
+		})
+		if registerErr != nil {
+			util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to register query variable: %s", request.PluginName, registerErr))
+			w.sendErrorResponseToHost(ctx, request, registerErr)
+			return
+		}
+		w.sendResponseToHost(ctx, request, "")
 	case "AIChatStream":
 		callbackId, exist := request.Params["callbackId"]
 		if !exist {
@@ -476,13 +936,13 @@ func (w *WebsocketHost) handleRequestFromPlugin(ctx context.Context, request Jso
 }
 
 func (w *WebsocketHost) handleResponseFromPlugin(ctx context.Context, response JsonRpcResponse) {
-	resultChan, exist := w.requestMap.Load(response.Id)
+	responseQueue, exist := w.requestMap.Load(response.Id)
 	if !exist {
 		util.GetLogger().Error(ctx, fmt.Sprintf("%s failed to find request id: %s", w.getHostName(ctx), response.Id))
 		return
 	}
 
-	resultChan <- response
+	responseQueue.push(response)
 }
 
 func (w *WebsocketHost) sendResponseToHost(ctx context.Context, request JsonRpcRequest, result string) {
@@ -492,15 +952,22 @@ func (w *WebsocketHost) sendResponseToHost(ctx context.Context, request JsonRpcR
 		Type:   JsonRpcTypeResponse,
 		Result: result,
 	}
-	responseJson, marshalErr := json.Marshal(response)
-	if marshalErr != nil {
-		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to marshal response: %s", request.PluginName, marshalErr))
+	sendErr := w.sendMessage(ctx, response)
+	if sendErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to send response: %s", request.PluginName, sendErr))
 		return
 	}
+}
 
-	sendErr := w.ws.Send(ctx, responseJson)
+func (w *WebsocketHost) sendErrorResponseToHost(ctx context.Context, request JsonRpcRequest, err error) {
+	response := JsonRpcResponse{
+		Id:     request.Id,
+		Method: request.Method,
+		Type:   JsonRpcTypeResponse,
+		Error:  err.Error(),
+	}
+	sendErr := w.sendMessage(ctx, response)
 	if sendErr != nil {
-		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to send response: %s", request.PluginName, sendErr))
-		return
+		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] failed to send error response: %s", request.PluginName, sendErr))
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 	"wox/plugin"
 	"wox/util"
 
@@ -41,7 +42,11 @@ func (w *WebsocketPlugin) Query(ctx context.Context, query plugin.Query) []plugi
 		return []plugin.QueryResult{}
 	}
 
-	rawResults, queryErr := w.websocketHost.invokeMethod(ctx, w.metadata, "query", map[string]string{
+	// host which supports streaming sends results in batches, each batch is pushed to the running query right away.
+	// Batches which can't be pushed (e.g. query is not started by plugin manager) are returned with the final results
+	var results []plugin.QueryResult
+	finalResults, queryErr := w.websocketHost.invokeMethodWithStream(ctx, w.metadata, "query", map[string]string{
+		"Id":             query.Id,
 		"Type":           query.Type,
 		"RawQuery":       query.RawQuery,
 		"TriggerKeyword": query.TriggerKeyword,
@@ -49,6 +54,11 @@ func (w *WebsocketPlugin) Query(ctx context.Context, query plugin.Query) []plugi
 		"Search":         query.Search,
 		"Selection":      string(selectionJson),
 		"Env":            string(envJson),
+	}, time.Second*30, func(partialResults any) {
+		batch := w.parseQueryResults(ctx, partialResults)
+		if pushErr := plugin.GetPluginManager().PushResults(ctx, w.metadata.Id, query.Id, batch); pushErr != nil {
+			results = append(results, batch...)
+		}
 	})
 	if queryErr != nil {
 		util.GetLogger().Error(ctx, fmt.Sprintf("[%s] query failed: %s", w.metadata.Name, queryErr.Error()))
@@ -57,6 +67,11 @@ func (w *WebsocketPlugin) Query(ctx context.Context, query plugin.Query) []plugi
 		}
 	}
 
+	return append(results, w.parseQueryResults(ctx, finalResults)...)
+}
+
+// parseQueryResults converts results returned by host, actions and refreshes are proxied to host
+func (w *WebsocketPlugin) parseQueryResults(ctx context.Context, rawResults any) []plugin.QueryResult {
 	var results []plugin.QueryResult
 	marshalData, marshalErr := json.Marshal(rawResults)
 	if marshalErr != nil {
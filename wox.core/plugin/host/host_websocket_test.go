@@ -0,0 +1,169 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+	"wox/plugin"
+	"wox/util"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHostEnvName makes the test binary serve as a websocket host, value is the directory where it records its pid
+const fakeHostEnvName = "WOX_TEST_FAKE_HOST"
+
+// TestMain points user data directory to a temporary home, so hosts started by tests never touch real data of current user
+func TestMain(m *testing.M) {
+	if pidDirectory := os.Getenv(fakeHostEnvName); pidDirectory != "" {
+		runFakeHost(pidDirectory)
+		return
+	}
+
+	home, tempErr := os.MkdirTemp("", "wox-host-test")
+	if tempErr != nil {
+		panic(tempErr)
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+	if initErr := util.GetLocation().Init(); initErr != nil {
+		panic(initErr)
+	}
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// runFakeHost answers every request with an error, so Wox talks to it with legacy protocol.
+// Host arguments are port, log directory and wox pid
+func runFakeHost(pidDirectory string) {
+	port := os.Args[len(os.Args)-3]
+	os.WriteFile(filepath.Join(pidDirectory, fmt.Sprintf("%d", os.Getpid())), nil, 0644)
+
+	upgrader := websocket.Upgrader{}
+	http.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		conn, upgradeErr := upgrader.Upgrade(writer, request, nil)
+		if upgradeErr != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, readErr := conn.ReadMessage()
+			if readErr != nil {
+				return
+			}
+			message, decodeErr := decodeJsonRpcMessage(HostEncodingJson, data)
+			if decodeErr != nil {
+				continue
+			}
+			response, _ := encodeJsonRpcMessage(HostEncodingJson, JsonRpcResponse{Id: message.Id, Type: JsonRpcTypeResponse, Error: "unknown method"})
+			conn.WriteMessage(websocket.TextMessage, response)
+		}
+	})
+	http.ListenAndServe("localhost:"+port, nil)
+}
+
+// fakeRuntimeHost runs the test binary as its host
+type fakeRuntimeHost struct {
+	websocketHost *WebsocketHost
+	pidDirectory  string
+}
+
+func (f *fakeRuntimeHost) GetRuntime(ctx context.Context) plugin.Runtime {
+	return "FAKE"
+}
+
+func (f *fakeRuntimeHost) Start(ctx context.Context) error {
+	executablePath, executableErr := os.Executable()
+	if executableErr != nil {
+		return executableErr
+	}
+	return f.websocketHost.StartHost(ctx, executablePath, "", []string{fakeHostEnvName + "=" + f.pidDirectory})
+}
+
+func (f *fakeRuntimeHost) Stop(ctx context.Context) {
+	f.websocketHost.StopHost(ctx)
+}
+
+func (f *fakeRuntimeHost) IsStarted(ctx context.Context) bool {
+	return f.websocketHost.IsHostStarted(ctx)
+}
+
+func (f *fakeRuntimeHost) LoadPlugin(ctx context.Context, metadata plugin.Metadata, pluginDirectory string) (plugin.Plugin, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (f *fakeRuntimeHost) UnloadPlugin(ctx context.Context, metadata plugin.Metadata) {
+}
+
+// alivePids returns pids of fake host processes which are still running
+func (f *fakeRuntimeHost) alivePids(t *testing.T) []string {
+	entries, readErr := os.ReadDir(f.pidDirectory)
+	assert.Nil(t, readErr)
+
+	var pids []string
+	for _, entry := range entries {
+		var pid int
+		fmt.Sscanf(entry.Name(), "%d", &pid)
+		process, findErr := os.FindProcess(pid)
+		if findErr == nil && process.Signal(syscall.Signal(0)) == nil {
+			pids = append(pids, entry.Name())
+		}
+	}
+	return pids
+}
+
+func Test_WebsocketHostNeverDropsResponses(t *testing.T) {
+	ctx := util.NewTraceContext()
+	w := &WebsocketHost{requestMap: util.NewHashMap[string, *hostResponseQueue]()}
+	responseQueue := newHostResponseQueue()
+	w.requestMap.Store("request", responseQueue)
+
+	// invoker is busy and takes nothing while host streams a lot of frames, the final response must still arrive
+	for i := 0; i < 1000; i++ {
+		w.handleResponseFromPlugin(ctx, JsonRpcResponse{Id: "request", Type: JsonRpcTypeStream, Result: fmt.Sprintf("frame %d", i)})
+	}
+	w.handleResponseFromPlugin(ctx, JsonRpcResponse{Id: "request", Type: JsonRpcTypeResponse, Result: "final"})
+
+	<-responseQueue.arrived
+	responses := responseQueue.take()
+	assert.Len(t, responses, 1001)
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("frame %d", i), responses[i].Result)
+	}
+	assert.Equal(t, JsonRpcTypeResponse, responses[1000].Type)
+	assert.Equal(t, "final", responses[1000].Result)
+	assert.Empty(t, responseQueue.take())
+}
+
+func Test_WebsocketHostRestartLeavesOneProcess(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("process liveness is checked with signal 0")
+	}
+
+	ctx := util.NewTraceContext()
+	fakeHost := &fakeRuntimeHost{pidDirectory: t.TempDir()}
+	fakeHost.websocketHost = &WebsocketHost{host: fakeHost, requestMap: util.NewHashMap[string, *hostResponseQueue]()}
+	plugin.AllHosts = append(plugin.AllHosts, fakeHost)
+	t.Cleanup(func() {
+		plugin.AllHosts = plugin.AllHosts[:len(plugin.AllHosts)-1]
+		fakeHost.Stop(ctx)
+	})
+
+	assert.Nil(t, fakeHost.Start(ctx))
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, plugin.GetPluginManager().RestartHost(ctx, fakeHost.GetRuntime(ctx)))
+	}
+
+	// supervisors of stopped hosts see their process exit, they must not start it again after restart backoff
+	time.Sleep(time.Second * 3)
+	assert.True(t, fakeHost.IsStarted(ctx))
+	assert.Len(t, fakeHost.alivePids(t), 1)
+}
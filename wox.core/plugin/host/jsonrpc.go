@@ -1,13 +1,57 @@
 package host
 
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
 type JsonRpcType string
 
 const (
 	JsonRpcTypeRequest   JsonRpcType = "WOX_JSONRPC_REQUEST"
 	JsonRpcTypeResponse  JsonRpcType = "WOX_JSONRPC_RESPONSE"
 	JsonRpcTypeSystemLog JsonRpcType = "WOX_JSONRPC_SYSTEM_LOG"
+	JsonRpcTypeStream    JsonRpcType = "WOX_JSONRPC_STREAM" // partial result of a request, the final response still follows
+)
+
+// host protocol version negotiated in handshake
+// hosts which don't understand handshake are treated as legacy, which only speak json and don't stream results
+const (
+	HostProtocolVersionLegacy  = 1
+	HostProtocolVersionCurrent = 2
 )
 
+type HostEncoding = string
+
+const (
+	HostEncodingJson    HostEncoding = "json"
+	HostEncodingMsgpack HostEncoding = "msgpack" // sent as websocket binary frames
+)
+
+type HostCapability = string
+
+const (
+	HostCapabilityStreamResults HostCapability = "streamResults"
+)
+
+var legacyHostProtocol = HostProtocol{
+	ProtocolVersion: HostProtocolVersionLegacy,
+	Encoding:        HostEncodingJson,
+}
+
+type HostProtocol struct {
+	ProtocolVersion int
+	Encoding        HostEncoding
+	Capabilities    []HostCapability
+}
+
+func (h HostProtocol) HasCapability(capability HostCapability) bool {
+	return lo.Contains(h.Capabilities, capability)
+}
+
 type JsonRpcRequest struct {
 	TraceId    string
 	Id         string
@@ -26,3 +70,82 @@ type JsonRpcResponse struct {
 	Result  any
 	Error   string
 }
+
+// JsonRpcMessage is the envelope of all messages sent by host, fields are filled according to Type
+type JsonRpcMessage struct {
+	TraceId    string
+	Id         string
+	PluginId   string
+	PluginName string
+	Method     string
+	Type       JsonRpcType
+	Params     map[string]string // request only
+	Result     any               // response and stream only
+	Error      string            // response only
+	Level      string            // system log only
+	Message    string            // system log only
+}
+
+func (m JsonRpcMessage) ToRequest() JsonRpcRequest {
+	return JsonRpcRequest{
+		TraceId:    m.TraceId,
+		Id:         m.Id,
+		PluginId:   m.PluginId,
+		PluginName: m.PluginName,
+		Method:     m.Method,
+		Type:       m.Type,
+		Params:     m.Params,
+	}
+}
+
+func (m JsonRpcMessage) ToResponse() JsonRpcResponse {
+	return JsonRpcResponse{
+		TraceId: m.TraceId,
+		Id:      m.Id,
+		Method:  m.Method,
+		Type:    m.Type,
+		Result:  m.Result,
+		Error:   m.Error,
+	}
+}
+
+// JsonRpcResponseError is returned when host answers a request with an error
+type JsonRpcResponseError struct {
+	Message string
+}
+
+func (e *JsonRpcResponseError) Error() string {
+	return e.Message
+}
+
+func encodeJsonRpcMessage(encoding HostEncoding, message any) ([]byte, error) {
+	switch encoding {
+	case HostEncodingJson:
+		return json.Marshal(message)
+	case HostEncodingMsgpack:
+		return msgpack.Marshal(message)
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+func decodeJsonRpcMessage(encoding HostEncoding, data []byte) (JsonRpcMessage, error) {
+	var message JsonRpcMessage
+	var decodeErr error
+	switch encoding {
+	case HostEncodingJson:
+		decodeErr = json.Unmarshal(data, &message)
+	case HostEncodingMsgpack:
+		decodeErr = msgpack.Unmarshal(data, &message)
+	default:
+		decodeErr = fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	if decodeErr != nil {
+		return JsonRpcMessage{}, decodeErr
+	}
+	if message.Type == "" {
+		return JsonRpcMessage{}, fmt.Errorf("message type is empty")
+	}
+
+	return message, nil
+}
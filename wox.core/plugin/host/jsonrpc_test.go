@@ -0,0 +1,33 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JsonRpcMessageEncoding(t *testing.T) {
+	for _, encoding := range []HostEncoding{HostEncodingJson, HostEncodingMsgpack} {
+		data, encodeErr := encodeJsonRpcMessage(encoding, JsonRpcResponse{
+			Id:     "1",
+			Type:   JsonRpcTypeStream,
+			Result: []any{map[string]any{"Title": "hello", "Score": 10}},
+		})
+		assert.Nil(t, encodeErr)
+
+		message, decodeErr := decodeJsonRpcMessage(encoding, data)
+		assert.Nil(t, decodeErr)
+		assert.Equal(t, JsonRpcTypeStream, message.Type)
+		assert.Equal(t, "1", message.Id)
+
+		results, ok := message.Result.([]any)
+		assert.True(t, ok, encoding)
+		assert.Len(t, results, 1)
+		result, ok := results[0].(map[string]any)
+		assert.True(t, ok, encoding)
+		assert.Equal(t, "hello", result["Title"])
+	}
+
+	_, decodeErr := decodeJsonRpcMessage(HostEncodingJson, []byte(`{"Id":"1"}`))
+	assert.NotNil(t, decodeErr)
+}
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"strconv"
 	"wox/setting"
 )
 
@@ -55,3 +56,22 @@ func (i *Instance) String() string {
 func (i *Instance) SaveSetting(ctx context.Context) error {
 	return setting.GetSettingManager().SavePluginSetting(ctx, i.Metadata.Id, i.Setting)
 }
+
+// IsDisabled returns whether plugin is disabled, active setting profile may override user setting
+func (i *Instance) IsDisabled() bool {
+	if value, overridden := setting.GetSettingManager().GetProfilePluginSetting(i.Metadata.Id, setting.ProfilePluginDisabledKey); overridden {
+		return value == "true"
+	}
+	return i.Setting != nil && i.Setting.Disabled
+}
+
+// SetDisabled updates the override of active profile if it overrides disabled state, otherwise updates user setting
+func (i *Instance) SetDisabled(ctx context.Context, disabled bool) error {
+	overridden, updateErr := setting.GetSettingManager().UpdateProfilePluginSetting(ctx, i.Metadata.Id, setting.ProfilePluginDisabledKey, strconv.FormatBool(disabled))
+	if overridden {
+		return updateErr
+	}
+
+	i.Setting.Disabled = disabled
+	return i.SaveSetting(ctx)
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/jinzhu/copier"
 	"github.com/samber/lo"
-	"github.com/wissance/stringFormatter"
 )
 
 var managerInstance *Manager
@@ -37,12 +36,41 @@ type debounceTimer struct {
 	onStop func()
 }
 
+// pushableQuery is a query which is still running in a plugin, the plugin can push results to it until its Query returns.
+// Pushed batches are queued and forwarded to results in order, all of them are delivered before the final results of plugin
+type pushableQuery struct {
+	pluginInstance *Instance
+	query          Query
+	results        chan []QueryResultUI
+	pending        [][]QueryResultUI // batches waiting to be forwarded to results
+	isForwarding   bool              // a goroutine is forwarding pending batches
+	forwarding     sync.WaitGroup
+	lock           sync.Mutex
+	isFinished     bool
+}
+
+// internalQueryResultsExpiry is how long results of an internal query can be actioned, E.g. by automation action after automation query
+const internalQueryResultsExpiry = 10 * time.Minute
+
+// internalQueryResults are results of one internal query, internal queries may run concurrently so each keeps its own results until expired
+type internalQueryResults struct {
+	results        *util.HashMap[string, *QueryResultCache]
+	startTimestamp int64
+}
+
 type Manager struct {
-	instances          []*Instance
-	ui                 share.UI
-	resultCache        *util.HashMap[string, *QueryResultCache]
-	debounceQueryTimer *util.HashMap[string, *debounceTimer]
-	aiProviders        *util.HashMap[ai.ProviderName, ai.Provider]
+	instances           []*Instance
+	instancesLock       sync.RWMutex // system plugins are added from goroutines, plugintest adds instances while queries run
+	ui                  share.UI
+	uiLock              sync.RWMutex // plugintest replaces ui while goroutines of previous tests may still use it
+	resultCache         *util.HashMap[string, *QueryResultCache]
+	internalResultCache *util.HashMap[string, *internalQueryResults] // key is query id, results of internal queries are kept apart so they won't clear results shown in ui
+	debounceQueryTimer  *util.HashMap[string, *debounceTimer]
+	aiProviders         *util.HashMap[ai.ProviderName, ai.Provider]
+	pushableQueries     *util.HashMap[string, *pushableQuery] // key is query id + plugin id
+	queryVariables      *util.HashMap[string, *queryVariable] // key is variable name
+
+	queryVariableSnapshot atomic.Pointer[map[string]string] // placeholder => value, taken before wox is shown, used by query shortcuts
 
 	activeBrowserUrl string //active browser url before wox is activated
 }
@@ -50,17 +78,21 @@ type Manager struct {
 func GetPluginManager() *Manager {
 	managerOnce.Do(func() {
 		managerInstance = &Manager{
-			resultCache:        util.NewHashMap[string, *QueryResultCache](),
-			debounceQueryTimer: util.NewHashMap[string, *debounceTimer](),
-			aiProviders:        util.NewHashMap[ai.ProviderName, ai.Provider](),
+			resultCache:         util.NewHashMap[string, *QueryResultCache](),
+			internalResultCache: util.NewHashMap[string, *internalQueryResults](),
+			debounceQueryTimer:  util.NewHashMap[string, *debounceTimer](),
+			aiProviders:         util.NewHashMap[ai.ProviderName, ai.Provider](),
+			pushableQueries:     util.NewHashMap[string, *pushableQuery](),
+			queryVariables:      util.NewHashMap[string, *queryVariable](),
 		}
+		managerInstance.registerBuiltinQueryVariables()
 		logger = util.GetLogger()
 	})
 	return managerInstance
 }
 
 func (m *Manager) Start(ctx context.Context, ui share.UI) error {
-	m.ui = ui
+	m.SetUI(ui)
 
 	loadErr := m.loadPlugins(ctx)
 	if loadErr != nil {
@@ -71,9 +103,86 @@ func (m *Manager) Start(ctx context.Context, ui share.UI) error {
 		GetStoreManager().Start(util.NewTraceContext())
 	})
 
+	setting.GetSettingManager().OnProfileChanged(m.onProfileChanged)
+	setting.GetSettingManager().OnSettingsImported(m.onSettingsImported)
+
 	return nil
 }
 
+// onProfileChanged notifies plugins about settings changed by setting profile, and inits plugins enabled by profile
+func (m *Manager) onProfileChanged(ctx context.Context, change setting.ProfileChange) {
+	for pluginId, keys := range change.PluginSettings {
+		instance, found := lo.Find(m.GetPluginInstances(), func(item *Instance) bool {
+			return item.Metadata.Id == pluginId
+		})
+		if !found {
+			continue
+		}
+
+		for _, key := range keys {
+			if key == setting.ProfilePluginDisabledKey {
+				if !instance.IsDisabled() && instance.InitStartTimestamp == 0 {
+					logger.Info(ctx, fmt.Sprintf("plugin %s is enabled by profile, init it", instance.Metadata.Name))
+					util.Go(ctx, fmt.Sprintf("[%s] init plugin", instance.Metadata.Name), func() {
+						m.initPlugin(util.NewTraceContext(), instance)
+					})
+				}
+				continue
+			}
+
+			value := instance.API.GetSetting(ctx, key)
+			for _, callback := range instance.SettingChangeCallbacks {
+				callback(key, value)
+			}
+		}
+	}
+}
+
+// onSettingsImported updates loaded plugins with plugin settings imported or synced from other machines,
+// otherwise in memory settings would overwrite them on next save
+func (m *Manager) onSettingsImported(ctx context.Context, event setting.SettingsImportedEvent) {
+	for pluginId, settings := range event.PluginSettings {
+		instance, found := lo.Find(m.GetPluginInstances(), func(item *Instance) bool {
+			return item.Metadata.Id == pluginId
+		})
+		if !found || instance.Setting == nil {
+			continue
+		}
+
+		for key, value := range settings {
+			instance.Setting.Settings.Store(key, value)
+		}
+		for key := range settings {
+			// platform specific settings are stored with platform suffix, callbacks expect the setting key
+			settingKey := strings.Split(key, "@")[0]
+			settingValue := instance.API.GetSetting(ctx, settingKey)
+			for _, callback := range instance.SettingChangeCallbacks {
+				callback(settingKey, settingValue)
+			}
+		}
+	}
+
+	for pluginId, keys := range event.DeletedPluginSettings {
+		instance, found := lo.Find(m.GetPluginInstances(), func(item *Instance) bool {
+			return item.Metadata.Id == pluginId
+		})
+		if !found || instance.Setting == nil {
+			continue
+		}
+
+		for _, key := range keys {
+			instance.Setting.Settings.Delete(key)
+		}
+		for _, key := range keys {
+			settingKey := strings.Split(key, "@")[0]
+			settingValue := instance.API.GetSetting(ctx, settingKey)
+			for _, callback := range instance.SettingChangeCallbacks {
+				callback(settingKey, settingValue)
+			}
+		}
+	}
+}
+
 func (m *Manager) Stop(ctx context.Context) {
 	for _, host := range AllHosts {
 		host.Stop(ctx)
@@ -90,11 +199,45 @@ func (m *Manager) loadPlugins(ctx context.Context) error {
 	// load system plugin first
 	m.loadSystemPlugins(ctx)
 
+	metaDataList, metadataErr := m.getUserPluginMetadataList(ctx)
+	if metadataErr != nil {
+		return metadataErr
+	}
+	logger.Info(ctx, fmt.Sprintf("start loading user plugins, found %d user plugins", len(metaDataList)))
+
+	for _, host := range AllHosts {
+		util.Go(ctx, fmt.Sprintf("[%s] start host", host.GetRuntime(ctx)), func() {
+			newCtx := util.NewTraceContext()
+			hostErr := host.Start(newCtx)
+			if hostErr != nil {
+				logger.Error(newCtx, fmt.Errorf("[%s HOST] %w", host.GetRuntime(newCtx), hostErr).Error())
+				return
+			}
+
+			for _, metadata := range metaDataList {
+				if strings.ToUpper(metadata.Metadata.Runtime) != strings.ToUpper(string(host.GetRuntime(newCtx))) {
+					continue
+				}
+
+				loadErr := m.loadHostPlugin(newCtx, host, metadata)
+				if loadErr != nil {
+					logger.Error(newCtx, fmt.Errorf("[%s HOST] %w", host.GetRuntime(newCtx), loadErr).Error())
+					continue
+				}
+			}
+		})
+	}
+
+	return nil
+}
+
+// getUserPluginMetadataList parses metadata of all installed user plugins, only the newest version is kept if a plugin is installed multiple times
+func (m *Manager) getUserPluginMetadataList(ctx context.Context) ([]MetadataWithDirectory, error) {
 	logger.Debug(ctx, "start loading user plugin metadata")
 	basePluginDirectory := util.GetLocation().GetPluginDirectory()
 	pluginDirectories, readErr := os.ReadDir(basePluginDirectory)
 	if readErr != nil {
-		return fmt.Errorf("failed to read plugin directory: %w", readErr)
+		return nil, fmt.Errorf("failed to read plugin directory: %w", readErr)
 	}
 
 	var metaDataList []MetadataWithDirectory
@@ -139,32 +282,8 @@ func (m *Manager) loadPlugins(ctx context.Context) error {
 		}
 		metaDataList = append(metaDataList, MetadataWithDirectory{Metadata: metadata, Directory: pluginDirectory})
 	}
-	logger.Info(ctx, fmt.Sprintf("start loading user plugins, found %d user plugins", len(metaDataList)))
-
-	for _, host := range AllHosts {
-		util.Go(ctx, fmt.Sprintf("[%s] start host", host.GetRuntime(ctx)), func() {
-			newCtx := util.NewTraceContext()
-			hostErr := host.Start(newCtx)
-			if hostErr != nil {
-				logger.Error(newCtx, fmt.Errorf("[%s HOST] %w", host.GetRuntime(newCtx), hostErr).Error())
-				return
-			}
-
-			for _, metadata := range metaDataList {
-				if strings.ToUpper(metadata.Metadata.Runtime) != strings.ToUpper(string(host.GetRuntime(newCtx))) {
-					continue
-				}
-
-				loadErr := m.loadHostPlugin(newCtx, host, metadata)
-				if loadErr != nil {
-					logger.Error(newCtx, fmt.Errorf("[%s HOST] %w", host.GetRuntime(newCtx), loadErr).Error())
-					continue
-				}
-			}
-		})
-	}
 
-	return nil
+	return metaDataList, nil
 }
 
 func (m *Manager) ReloadPlugin(ctx context.Context, metadata MetadataWithDirectory) error {
@@ -177,7 +296,7 @@ func (m *Manager) ReloadPlugin(ctx context.Context, metadata MetadataWithDirecto
 		return fmt.Errorf("unsupported runtime: %s", metadata.Metadata.Runtime)
 	}
 
-	pluginInstance, pluginInstanceExist := lo.Find(m.instances, func(item *Instance) bool {
+	pluginInstance, pluginInstanceExist := lo.Find(m.GetPluginInstances(), func(item *Instance) bool {
 		return item.Metadata.Id == metadata.Metadata.Id
 	})
 	if pluginInstanceExist {
@@ -195,6 +314,74 @@ func (m *Manager) ReloadPlugin(ctx context.Context, metadata MetadataWithDirecto
 	return nil
 }
 
+// ReloadHostPlugins reloads plugins running on given host which match the filter, e.g. after the host process is restarted
+func (m *Manager) ReloadHostPlugins(ctx context.Context, host Host, filter func(instance *Instance) bool) int {
+	var metadataList []MetadataWithDirectory
+	for _, instance := range m.GetPluginInstances() {
+		if instance.Host != host || !filter(instance) {
+			continue
+		}
+		metadataList = append(metadataList, MetadataWithDirectory{
+			Metadata:           instance.Metadata,
+			Directory:          instance.PluginDirectory,
+			IsDev:              instance.IsDevPlugin,
+			DevPluginDirectory: instance.DevPluginDirectory,
+		})
+	}
+
+	reloadedCount := 0
+	for _, metadata := range metadataList {
+		reloadErr := m.ReloadPlugin(ctx, metadata)
+		if reloadErr != nil {
+			logger.Error(ctx, fmt.Errorf("[%s HOST] failed to reload plugin %s: %w", host.GetRuntime(ctx), metadata.Metadata.Name, reloadErr).Error())
+			continue
+		}
+		reloadedCount++
+	}
+
+	return reloadedCount
+}
+
+// RestartHost restarts the host of given runtime and reloads all its plugins, e.g. after the interpreter is changed
+func (m *Manager) RestartHost(ctx context.Context, runtime Runtime) error {
+	pluginHost, exist := lo.Find(AllHosts, func(item Host) bool {
+		return item.GetRuntime(ctx) == runtime
+	})
+	if !exist {
+		return fmt.Errorf("unsupported runtime: %s", runtime)
+	}
+
+	logger.Info(ctx, fmt.Sprintf("[%s HOST] restarting host", runtime))
+	pluginHost.Stop(ctx)
+	startErr := pluginHost.Start(ctx)
+	if startErr != nil {
+		return startErr
+	}
+
+	reloadedCount := m.ReloadHostPlugins(ctx, pluginHost, func(instance *Instance) bool { return true })
+	logger.Info(ctx, fmt.Sprintf("[%s HOST] host restarted, reloaded %d plugins", runtime, reloadedCount))
+
+	// plugins are not loaded if the host failed to start before, e.g. interpreter was not found
+	metadataList, metadataErr := m.getUserPluginMetadataList(ctx)
+	if metadataErr != nil {
+		return metadataErr
+	}
+	for _, metadata := range metadataList {
+		if ConvertToRuntime(metadata.Metadata.Runtime) != runtime {
+			continue
+		}
+		if lo.ContainsBy(m.GetPluginInstances(), func(item *Instance) bool { return item.Metadata.Id == metadata.Metadata.Id }) {
+			continue
+		}
+		loadErr := m.loadHostPlugin(ctx, pluginHost, metadata)
+		if loadErr != nil {
+			logger.Error(ctx, fmt.Errorf("[%s HOST] %w", runtime, loadErr).Error())
+		}
+	}
+
+	return nil
+}
+
 func (m *Manager) loadHostPlugin(ctx context.Context, host Host, metadata MetadataWithDirectory) error {
 	loadStartTimestamp := util.GetSystemTimestamp()
 	plugin, loadErr := host.LoadPlugin(ctx, metadata.Metadata, metadata.Directory)
@@ -222,9 +409,9 @@ func (m *Manager) loadHostPlugin(ctx context.Context, host Host, metadata Metada
 	}
 	instance.Setting = pluginSetting
 
-	m.instances = append(m.instances, instance)
+	m.addInstance(instance)
 
-	if pluginSetting.Disabled {
+	if instance.IsDisabled() {
 		logger.Info(ctx, fmt.Errorf("[%s HOST] plugin is disabled by user, skip init: %s", host.GetRuntime(ctx), metadata.Metadata.Name).Error())
 		instance.API.Log(ctx, LogLevelWarning, fmt.Sprintf("[SYS] plugin is disabled by user, skip init: %s", metadata.Metadata.Name))
 		return nil
@@ -262,8 +449,11 @@ func (m *Manager) UnloadPlugin(ctx context.Context, pluginInstance *Instance) {
 	for _, callback := range pluginInstance.UnloadCallbacks {
 		callback()
 	}
+	m.unregisterQueryVariables(pluginInstance.Metadata.Id)
 	pluginInstance.Host.UnloadPlugin(ctx, pluginInstance.Metadata)
 
+	m.instancesLock.Lock()
+	defer m.instancesLock.Unlock()
 	var newInstances []*Instance
 	for _, instance := range m.instances {
 		if instance.Metadata.Id != pluginInstance.Metadata.Id {
@@ -305,7 +495,7 @@ func (m *Manager) loadSystemPlugins(ctx context.Context) {
 				logger.Warn(ctx, fmt.Sprintf("load system plugin[%s] setting too slow, cost %d ms", metadata.Name, util.GetSystemTimestamp()-startTimestamp))
 			}
 
-			m.instances = append(m.instances, instance)
+			m.addInstance(instance)
 
 			m.initPlugin(util.NewTraceContext(), instance)
 		})
@@ -355,12 +545,27 @@ func (m *Manager) ParseMetadata(ctx context.Context, pluginDirectory string) (Me
 	return metadata, nil
 }
 
+// GetPluginInstances returns a copy of loaded plugin instances, it's safe to iterate while plugins are loaded or unloaded
 func (m *Manager) GetPluginInstances() []*Instance {
-	return m.instances
+	m.instancesLock.RLock()
+	defer m.instancesLock.RUnlock()
+	return slices.Clone(m.instances)
+}
+
+// AddInstance registers an already loaded plugin instance, hosts route plugin api calls to registered instances.
+// It's used by plugintest to drive plugins with a fake api, normal plugins are loaded by Start
+func (m *Manager) AddInstance(instance *Instance) {
+	m.addInstance(instance)
+}
+
+func (m *Manager) addInstance(instance *Instance) {
+	m.instancesLock.Lock()
+	defer m.instancesLock.Unlock()
+	m.instances = append(m.instances, instance)
 }
 
 func (m *Manager) canOperateQuery(ctx context.Context, pluginInstance *Instance, query Query) bool {
-	if pluginInstance.Setting.Disabled {
+	if pluginInstance.IsDisabled() {
 		return false
 	}
 
@@ -414,6 +619,10 @@ func (m *Manager) queryForPlugin(ctx context.Context, pluginInstance *Instance,
 	results = pluginInstance.Plugin.Query(ctx, query)
 	logger.Debug(ctx, fmt.Sprintf("<%s> finish query, result count: %d, cost: %dms", pluginInstance.Metadata.Name, len(results), util.GetSystemTimestamp()-start))
 
+	return m.polishQueryResults(ctx, pluginInstance, query, results)
+}
+
+func (m *Manager) polishQueryResults(ctx context.Context, pluginInstance *Instance, query Query, results []QueryResult) []QueryResult {
 	for i := range results {
 		results[i] = m.addDefaultActions(ctx, pluginInstance, query, results[i])
 		results[i] = m.PolishResult(ctx, pluginInstance, query, results[i])
@@ -615,7 +824,13 @@ func (m *Manager) PolishResult(ctx context.Context, pluginInstance *Instance, qu
 		}
 	}
 
-	m.resultCache.Store(result.Id, resultCache)
+	if query.isInternal {
+		if queryResults, exist := m.internalResultCache.Load(query.Id); exist {
+			queryResults.results.Store(result.Id, resultCache)
+		}
+	} else {
+		m.resultCache.Store(result.Id, resultCache)
+	}
 
 	return result
 }
@@ -775,13 +990,30 @@ func (m *Manager) Query(ctx context.Context, query Query) (results chan []QueryR
 	results = make(chan []QueryResultUI, 10)
 	done = make(chan bool)
 
+	if query.Id == "" {
+		query.Id = uuid.NewString()
+	}
+
 	// clear old result cache
-	m.resultCache.Clear()
+	if query.isInternal {
+		m.evictInternalQueryResults()
+		m.internalResultCache.Store(query.Id, &internalQueryResults{
+			results:        util.NewHashMap[string, *QueryResultCache](),
+			startTimestamp: util.GetSystemTimestamp(),
+		})
+	} else {
+		m.resultCache.Clear()
+	}
+
+	if query.shortcutExpansion != nil {
+		m.previewQueryShortcut(ctx, query, results)
+	}
 
+	instances := m.GetPluginInstances()
 	counter := &atomic.Int32{}
-	counter.Store(int32(len(m.instances)))
+	counter.Store(int32(len(instances)))
 
-	for _, pluginInstance := range m.instances {
+	for _, pluginInstance := range instances {
 		if !m.canOperateQuery(ctx, pluginInstance, query) {
 			counter.Add(-1)
 			if counter.Load() == 0 {
@@ -826,34 +1058,65 @@ func (m *Manager) Query(ctx context.Context, query Query) (results chan []QueryR
 	return
 }
 
+// previewQueryShortcut sends results showing how query shortcut is expanded, before results of plugins
+func (m *Manager) previewQueryShortcut(ctx context.Context, query Query, results chan []QueryResultUI) {
+	for _, instance := range m.GetPluginInstances() {
+		previewer, ok := instance.Plugin.(QueryShortcutPreviewer)
+		if !ok || instance.IsDisabled() {
+			continue
+		}
+
+		previewResults := previewer.PreviewQueryShortcut(ctx, query, *query.shortcutExpansion)
+		results <- lo.Map(previewResults, func(item QueryResult, index int) QueryResultUI {
+			polished := m.PolishResult(ctx, instance, query, item)
+			return polished.ToUI()
+		})
+	}
+}
+
 func (m *Manager) QuerySilent(ctx context.Context, query Query) bool {
+	results, queryErr := m.QueryAndWait(ctx, query)
+	if queryErr != nil {
+		logger.Error(ctx, "silent query timeout")
+		return false
+	}
+
+	// execute default action if only one result
+	if len(results) == 1 {
+		result := results[0]
+		for _, action := range result.Actions {
+			if action.IsDefault {
+				m.ExecuteAction(ctx, result.Id, action.Id)
+				return true
+			}
+		}
+	} else {
+		notifier.Notify(fmt.Sprintf("Silent query failed, there shouldbe only one result, but got %d", len(results)))
+	}
+
+	return false
+}
+
+// QueryAndWait collects all results of a query, it fails if the query is not done in one minute
+func (m *Manager) QueryAndWait(ctx context.Context, query Query) ([]QueryResultUI, error) {
 	var startTimestamp = util.GetSystemTimestamp()
 	var results []QueryResultUI
+	query.isInternal = true
 	resultChan, doneChan := m.Query(ctx, query)
+	timeout := time.After(time.Minute)
 	for {
 		select {
 		case r := <-resultChan:
 			results = append(results, r...)
 		case <-doneChan:
-			logger.Info(ctx, fmt.Sprintf("silent query done, total results: %d, cost %d ms", len(results), util.GetSystemTimestamp()-startTimestamp))
-
-			// execute default action if only one result
-			if len(results) == 1 {
-				result := results[0]
-				for _, action := range result.Actions {
-					if action.IsDefault {
-						m.ExecuteAction(ctx, result.Id, action.Id)
-						return true
-					}
-				}
-			} else {
-				notifier.Notify(fmt.Sprintf("Silent query failed, there shouldbe only one result, but got %d", len(results)))
+			// results of the last plugin are sent right before done, they may still be buffered
+			for len(resultChan) > 0 {
+				results = append(results, <-resultChan...)
 			}
-
-			return false
-		case <-time.After(time.Minute):
-			logger.Error(ctx, "silent query timeout")
-			return false
+			logger.Info(ctx, fmt.Sprintf("query done, total results: %d, cost %d ms", len(results), util.GetSystemTimestamp()-startTimestamp))
+			return results, nil
+		case <-timeout:
+			return results, fmt.Errorf("query timeout: %s", query.RawQuery)
 		}
 	}
 }
@@ -861,7 +1124,7 @@ func (m *Manager) QuerySilent(ctx context.Context, query Query) bool {
 func (m *Manager) QueryFallback(ctx context.Context, query Query, queryPlugin *Instance) (results []QueryResultUI) {
 	var queryResults []QueryResult
 	if query.IsGlobalQuery() {
-		for _, instance := range m.instances {
+		for _, instance := range m.GetPluginInstances() {
 			pluginInstance := instance
 			if v, ok := pluginInstance.Plugin.(FallbackSearcher); ok {
 				queryResults = v.QueryFallback(ctx, query)
@@ -889,7 +1152,7 @@ func (m *Manager) QueryFallback(ctx context.Context, query Query, queryPlugin *I
 						Name:                   "Execute",
 						PreventHideAfterAction: true,
 						Action: func(ctx context.Context, actionContext ActionContext) {
-							m.ui.ChangeQuery(ctx, share.PlainQuery{
+							m.GetUI().ChangeQuery(ctx, share.PlainQuery{
 								QueryType: QueryTypeInput,
 								QueryText: fmt.Sprintf("%s %s ", query.TriggerKeyword, item.Command),
 							})
@@ -911,8 +1174,17 @@ func (m *Manager) QueryFallback(ctx context.Context, query Query, queryPlugin *I
 }
 
 func (m *Manager) queryParallel(ctx context.Context, pluginInstance *Instance, query Query, results chan []QueryResultUI, done chan bool, counter *atomic.Int32) {
+	pushable := &pushableQuery{
+		pluginInstance: pluginInstance,
+		query:          query,
+		results:        results,
+	}
+	pushableKey := query.Id + pluginInstance.Metadata.Id
+	m.pushableQueries.Store(pushableKey, pushable)
+
 	util.Go(ctx, fmt.Sprintf("[%s] parallel query", pluginInstance.Metadata.Name), func() {
 		queryResults := m.queryForPlugin(ctx, pluginInstance, query)
+		m.finishPushableQuery(pushableKey, pushable)
 		results <- lo.Map(queryResults, func(item QueryResult, index int) QueryResultUI {
 			return item.ToUI()
 		})
@@ -921,6 +1193,7 @@ func (m *Manager) queryParallel(ctx context.Context, pluginInstance *Instance, q
 			done <- true
 		}
 	}, func() {
+		m.finishPushableQuery(pushableKey, pushable)
 		counter.Add(-1)
 		if counter.Load() == 0 {
 			done <- true
@@ -928,6 +1201,66 @@ func (m *Manager) queryParallel(ctx context.Context, pluginInstance *Instance, q
 	})
 }
 
+// finishPushableQuery rejects later pushes and waits until all pushed batches are forwarded to results,
+// so they always arrive before the final results and done
+func (m *Manager) finishPushableQuery(key string, pushable *pushableQuery) {
+	pushable.lock.Lock()
+	pushable.isFinished = true
+	pushable.lock.Unlock()
+
+	pushable.forwarding.Wait()
+	m.pushableQueries.Delete(key)
+}
+
+// forwardPushedResults sends pending batches to results one by one, it exits once the queue is empty
+func (m *Manager) forwardPushedResults(pushable *pushableQuery) {
+	defer pushable.forwarding.Done()
+	for {
+		pushable.lock.Lock()
+		if len(pushable.pending) == 0 {
+			pushable.isForwarding = false
+			pushable.lock.Unlock()
+			return
+		}
+		batch := pushable.pending[0]
+		pushable.pending = pushable.pending[1:]
+		pushable.lock.Unlock()
+
+		pushable.results <- batch
+	}
+}
+
+// PushResults sends a batch of results to a query while the plugin is still querying,
+// so plugins backed by slow sources can show their first results before Query returns
+func (m *Manager) PushResults(ctx context.Context, pluginId string, queryId string, results []QueryResult) error {
+	pushable, exist := m.pushableQueries.Load(queryId + pluginId)
+	if !exist {
+		return fmt.Errorf("query %s is finished or doesn't exist", queryId)
+	}
+
+	results = m.polishQueryResults(ctx, pushable.pluginInstance, pushable.query, results)
+	logger.Debug(ctx, fmt.Sprintf("<%s> push %d results to query %s", pushable.pluginInstance.Metadata.Name, len(results), queryId))
+	resultsUI := lo.Map(results, func(item QueryResult, index int) QueryResultUI {
+		return item.ToUI()
+	})
+
+	// caller may be a host receiving goroutine, so never wait for the consumer here, batches are queued and forwarded in order
+	pushable.lock.Lock()
+	defer pushable.lock.Unlock()
+	if pushable.isFinished {
+		return fmt.Errorf("query %s is finished or doesn't exist", queryId)
+	}
+	pushable.pending = append(pushable.pending, resultsUI)
+	if !pushable.isForwarding {
+		pushable.isForwarding = true
+		pushable.forwarding.Add(1)
+		util.Go(ctx, fmt.Sprintf("[%s] push results", pushable.pluginInstance.Metadata.Name), func() {
+			m.forwardPushedResults(pushable)
+		})
+	}
+	return nil
+}
+
 func (m *Manager) translatePlugin(ctx context.Context, pluginInstance *Instance, key string) string {
 	if !strings.HasPrefix(key, "i18n:") {
 		return key
@@ -941,22 +1274,32 @@ func (m *Manager) translatePlugin(ctx context.Context, pluginInstance *Instance,
 }
 
 func (m *Manager) GetUI() share.UI {
+	m.uiLock.RLock()
+	defer m.uiLock.RUnlock()
 	return m.ui
 }
 
+// SetUI replaces the ui used by plugin apis, it's used by plugintest to run plugins without Start
+func (m *Manager) SetUI(ui share.UI) {
+	m.uiLock.Lock()
+	defer m.uiLock.Unlock()
+	m.ui = ui
+}
+
 func (m *Manager) NewQuery(ctx context.Context, plainQuery share.PlainQuery) (Query, *Instance, error) {
 	if plainQuery.QueryType == QueryTypeInput {
 		newQuery := plainQuery.QueryText
-		woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-		if len(woxSetting.QueryShortcuts) > 0 {
-			originQuery := plainQuery.QueryText
-			expandedQuery := m.expandQueryShortcut(ctx, plainQuery.QueryText, woxSetting.QueryShortcuts)
-			if originQuery != expandedQuery {
-				logger.Info(ctx, fmt.Sprintf("expand query shortcut: %s -> %s", originQuery, expandedQuery))
-				newQuery = expandedQuery
+		queryShortcuts := m.getQueryShortcuts(ctx)
+		var shortcutExpansion *QueryShortcutExpansion
+		if len(queryShortcuts) > 0 {
+			shortcutExpansion = m.findQueryShortcutExpansion(ctx, plainQuery.QueryText, queryShortcuts)
+			if shortcutExpansion != nil && shortcutExpansion.ExpandedQuery != plainQuery.QueryText {
+				logger.Info(ctx, fmt.Sprintf("expand query shortcut: %s -> %s", plainQuery.QueryText, shortcutExpansion.ExpandedQuery))
+				newQuery = shortcutExpansion.ExpandedQuery
 			}
 		}
 		query, instance := newQueryInputWithPlugins(newQuery, GetPluginManager().GetPluginInstances())
+		query.shortcutExpansion = shortcutExpansion
 		query.Env.ActiveWindowTitle = m.GetUI().GetActiveWindowName()
 		query.Env.ActiveWindowPid = m.GetUI().GetActiveWindowPid()
 		query.Env.ActiveBrowserUrl = m.getActiveBrowserUrl(ctx)
@@ -989,47 +1332,97 @@ func (m *Manager) getActiveBrowserUrl(ctx context.Context) string {
 	return m.activeBrowserUrl
 }
 
+// getQueryShortcuts returns user defined query shortcuts and shortcuts contributed by enabled plugins
+func (m *Manager) getQueryShortcuts(ctx context.Context) []setting.QueryShortcut {
+	queryShortcuts := slices.Clone(setting.GetSettingManager().GetWoxSetting(ctx).QueryShortcuts)
+	for _, instance := range m.GetPluginInstances() {
+		if instance.IsDisabled() {
+			continue
+		}
+
+		for _, shortcut := range instance.Metadata.QueryShortcuts {
+			isDuplicated := lo.ContainsBy(queryShortcuts, func(item setting.QueryShortcut) bool {
+				return item.Shortcut == shortcut.Shortcut
+			})
+			if shortcut.Shortcut == "" || isDuplicated {
+				continue
+			}
+			queryShortcuts = append(queryShortcuts, shortcut)
+		}
+	}
+
+	return queryShortcuts
+}
+
 func (m *Manager) expandQueryShortcut(ctx context.Context, query string, queryShorts []setting.QueryShortcut) (newQuery string) {
-	newQuery = query
+	expansion := m.findQueryShortcutExpansion(ctx, query, queryShorts)
+	if expansion == nil {
+		return query
+	}
+	return expansion.ExpandedQuery
+}
 
+// findQueryShortcutExpansion expands query with the longest matched shortcut, returns nil if no shortcut matches
+func (m *Manager) findQueryShortcutExpansion(ctx context.Context, query string, queryShorts []setting.QueryShortcut) *QueryShortcutExpansion {
 	//sort query shorts by shortcut length, we will expand the longest shortcut first
 	slices.SortFunc(queryShorts, func(i, j setting.QueryShortcut) int {
 		return len(j.Shortcut) - len(i.Shortcut)
 	})
 
 	for _, shortcut := range queryShorts {
-		if strings.HasPrefix(query, shortcut.Shortcut) {
-			if !shortcut.HasPlaceholder() {
-				newQuery = strings.Replace(query, shortcut.Shortcut, shortcut.Query, 1)
-				break
-			} else {
-				queryWithoutShortcut := strings.Replace(query, shortcut.Shortcut, "", 1)
-				queryWithoutShortcut = strings.TrimLeft(queryWithoutShortcut, " ")
-				parameters := strings.Split(queryWithoutShortcut, " ")
-				placeholderCount := shortcut.PlaceholderCount()
-				var paramsCount = 0
-
-				var params []any
-				var nonPrams string
-				for _, param := range parameters {
-					if paramsCount < placeholderCount {
-						paramsCount++
-						params = append(params, param)
-					} else {
-						nonPrams += " " + param
-					}
-				}
-				newQuery = stringFormatter.Format(shortcut.Query, params...) + nonPrams
-				break
-			}
+		if !strings.HasPrefix(query, shortcut.Shortcut) {
+			continue
+		}
+
+		expansion := &QueryShortcutExpansion{
+			Shortcut:    shortcut,
+			OriginQuery: query,
+		}
+		// query variables come from the snapshot taken before wox is shown, and only variables of shortcut are replaced, never the user input
+		if !shortcut.HasPlaceholder() {
+			template := queryVariableRegex.ReplaceAllStringFunc(shortcut.Query, m.getQueryVariableSnapshot)
+			expansion.ExpandedQuery = strings.Replace(query, shortcut.Shortcut, template, 1)
+		} else {
+			arguments := strings.TrimLeft(strings.Replace(query, shortcut.Shortcut, "", 1), " ")
+			expansion.ExpandedQuery, expansion.Parameters = shortcut.ExpandWithVariables(arguments, m.getQueryVariableSnapshot)
 		}
+		return expansion
 	}
 
-	return newQuery
+	return nil
+}
+
+// loadResultCache finds result in results shown in ui first, then in results of internal queries
+func (m *Manager) loadResultCache(resultId string) (*QueryResultCache, bool) {
+	if resultCache, found := m.resultCache.Load(resultId); found {
+		return resultCache, true
+	}
+	var resultCache *QueryResultCache
+	var found bool
+	m.internalResultCache.Range(func(queryId string, queryResults *internalQueryResults) bool {
+		resultCache, found = queryResults.results.Load(resultId)
+		return !found
+	})
+	return resultCache, found
+}
+
+// evictInternalQueryResults removes results of internal queries started longer than internalQueryResultsExpiry ago
+func (m *Manager) evictInternalQueryResults() {
+	expireTimestamp := util.GetSystemTimestamp() - internalQueryResultsExpiry.Milliseconds()
+	var expiredQueryIds []string
+	m.internalResultCache.Range(func(queryId string, queryResults *internalQueryResults) bool {
+		if queryResults.startTimestamp < expireTimestamp {
+			expiredQueryIds = append(expiredQueryIds, queryId)
+		}
+		return true
+	})
+	for _, queryId := range expiredQueryIds {
+		m.internalResultCache.Delete(queryId)
+	}
 }
 
 func (m *Manager) ExecuteAction(ctx context.Context, resultId string, actionId string) error {
-	resultCache, found := m.resultCache.Load(resultId)
+	resultCache, found := m.loadResultCache(resultId)
 	if !found {
 		return fmt.Errorf("result cache not found for result id (execute action): %s", resultId)
 	}
@@ -1056,7 +1449,7 @@ func (m *Manager) ExecuteRefresh(ctx context.Context, refreshableResultWithId Re
 		return RefreshableResultWithResultId{}, fmt.Errorf("failed to copy refreshable result: %w", copyErr)
 	}
 
-	resultCache, found := m.resultCache.Load(refreshableResultWithId.ResultId)
+	resultCache, found := m.loadResultCache(refreshableResultWithId.ResultId)
 	if !found {
 		return refreshableResultWithId, fmt.Errorf("result cache not found for result id (execute refresh): %s", refreshableResultWithId.ResultId)
 	}
@@ -1105,7 +1498,7 @@ func (m *Manager) ExecuteRefresh(ctx context.Context, refreshableResultWithId Re
 }
 
 func (m *Manager) GetResultPreview(ctx context.Context, resultId string) (WoxPreview, error) {
-	resultCache, found := m.resultCache.Load(resultId)
+	resultCache, found := m.loadResultCache(resultId)
 	if !found {
 		return WoxPreview{}, fmt.Errorf("result cache not found for result id (get preview): %s", resultId)
 	}
@@ -1141,28 +1534,6 @@ func (m *Manager) polishPreview(ctx context.Context, preview WoxPreview) WoxPrev
 	return preview
 }
 
-func (m *Manager) ReplaceQueryVariable(ctx context.Context, query string) string {
-	if strings.Contains(query, QueryVariableSelectedText) {
-		selection, selectedErr := util.GetSelected()
-		if selectedErr != nil {
-			logger.Error(ctx, fmt.Sprintf("failed to get selected text: %s", selectedErr.Error()))
-		} else {
-			if selection.Type == util.SelectionTypeText {
-				query = strings.ReplaceAll(query, QueryVariableSelectedText, selection.Text)
-			} else {
-				logger.Error(ctx, fmt.Sprintf("selected data is not text, type: %s", selection.Type))
-			}
-		}
-	}
-
-	if strings.Contains(query, QueryVariableActiveBrowserUrl) {
-		activeBrowserUrl := m.activeBrowserUrl
-		query = strings.ReplaceAll(query, QueryVariableActiveBrowserUrl, activeBrowserUrl)
-	}
-
-	return query
-}
-
 func (m *Manager) IsHostStarted(ctx context.Context, runtime Runtime) bool {
 	if runtime == PLUGIN_RUNTIME_GO {
 		return true
@@ -1200,7 +1571,7 @@ func (m *Manager) GetAIProvider(ctx context.Context, provider ai.ProviderName) (
 }
 
 func (m *Manager) ExecutePluginDeeplink(ctx context.Context, pluginId string, arguments map[string]string) {
-	pluginInstance, exist := lo.Find(m.instances, func(item *Instance) bool {
+	pluginInstance, exist := lo.Find(m.GetPluginInstances(), func(item *Instance) bool {
 		return item.Metadata.Id == pluginId
 	})
 	if !exist {
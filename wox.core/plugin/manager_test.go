@@ -1,8 +1,14 @@
 package plugin
 
 import (
+	"context"
+	"fmt"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 	"wox/setting"
 	"wox/util"
 )
@@ -34,3 +40,250 @@ func Test_QueryShortcut(t *testing.T) {
 	query = GetPluginManager().expandQueryShortcut(util.NewTraceContext(), "wix 1", shortcuts)
 	assert.Equal(t, "wpm install 1 x {1}", query)
 }
+
+func Test_QueryShortcutVariables(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	shortcuts := []setting.QueryShortcut{
+		{Shortcut: "tr", Query: "translate {wox:clipboard}"},
+		{Shortcut: "rp", Query: "replace {from} {wox:clipboard}"},
+	}
+
+	// variables are only taken from the snapshot made before wox is shown, typed text is never expanded
+	m.ClearQueryVariableSnapshot()
+	assert.Equal(t, "translate {wox:clipboard} {wox:env:HOME}", m.expandQueryShortcut(ctx, "tr {wox:env:HOME}", shortcuts))
+
+	m.queryVariableSnapshot.Store(&map[string]string{"{wox:clipboard}": "{from}"})
+	defer m.ClearQueryVariableSnapshot()
+	assert.Equal(t, "translate {from} {wox:env:HOME}", m.expandQueryShortcut(ctx, "tr {wox:env:HOME}", shortcuts))
+	assert.Equal(t, "replace {wox:env:HOME} {from}", m.expandQueryShortcut(ctx, "rp {wox:env:HOME}", shortcuts))
+
+	m.SnapshotQueryVariables(ctx, "")
+	assert.NotNil(t, m.queryVariableSnapshot.Load())
+
+	// selection variables simulate copy, they are only resolved if wox is shown with a query using them
+	resolveCount := 0
+	m.queryVariables.Store("selected_text", &queryVariable{QueryVariableInfo{Name: "selected_text"}, func(ctx context.Context, arg string) (string, error) {
+		resolveCount++
+		return "selected", nil
+	}})
+	defer m.registerBuiltinQueryVariables()
+	shortcuts = append(shortcuts, setting.QueryShortcut{Shortcut: "ts", Query: "translate {wox:selected_text}"})
+
+	m.ClearQueryVariableSnapshot()
+	m.snapshotQueryVariables(ctx, "", shortcuts)
+	m.snapshotQueryVariables(ctx, "tr", shortcuts)
+	assert.Equal(t, 0, resolveCount)
+	assert.Equal(t, "translate {wox:selected_text}", m.expandQueryShortcut(ctx, "ts", shortcuts))
+
+	m.snapshotQueryVariables(ctx, "ts", shortcuts)
+	assert.Equal(t, 1, resolveCount)
+	// snapshot taken when wox is shown keeps selection taken by query hotkey
+	m.snapshotQueryVariables(ctx, "", shortcuts)
+	assert.Equal(t, 1, resolveCount)
+	assert.Equal(t, "translate selected", m.expandQueryShortcut(ctx, "ts", shortcuts))
+}
+
+func Test_PushResults(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	instance := &Instance{Metadata: Metadata{Id: "push", Name: "push", Features: []MetadataFeature{{Name: MetadataFeatureIgnoreAutoScore}}}}
+	query := Query{Id: "q1", Type: QueryTypeSelection, Selection: util.Selection{Type: util.SelectionTypeText, Text: "hello"}}
+
+	results := make(chan []QueryResultUI, 10)
+	pushable := &pushableQuery{pluginInstance: instance, query: query, results: results}
+	m.pushableQueries.Store(query.Id+instance.Metadata.Id, pushable)
+
+	assert.Nil(t, m.PushResults(ctx, instance.Metadata.Id, query.Id, []QueryResult{{Title: "first"}}))
+	pushed := <-results
+	assert.Len(t, pushed, 1)
+	assert.Equal(t, "first", pushed[0].Title)
+	assert.NotEmpty(t, pushed[0].Id)
+
+	assert.NotNil(t, m.PushResults(ctx, "other", query.Id, []QueryResult{{Title: "second"}}))
+
+	m.finishPushableQuery(query.Id+instance.Metadata.Id, pushable)
+	assert.NotNil(t, m.PushResults(ctx, instance.Metadata.Id, query.Id, []QueryResult{{Title: "third"}}))
+
+	// nobody reads results yet, push must not block and batches are delivered in order once results are read
+	unread := &pushableQuery{pluginInstance: instance, query: query, results: make(chan []QueryResultUI)}
+	m.pushableQueries.Store(query.Id+instance.Metadata.Id, unread)
+	assert.Nil(t, m.PushResults(ctx, instance.Metadata.Id, query.Id, []QueryResult{{Title: "fourth"}}))
+	assert.Nil(t, m.PushResults(ctx, instance.Metadata.Id, query.Id, []QueryResult{{Title: "fifth"}}))
+	assert.Equal(t, "fourth", (<-unread.results)[0].Title)
+	assert.Equal(t, "fifth", (<-unread.results)[0].Title)
+	m.finishPushableQuery(query.Id+instance.Metadata.Id, unread)
+}
+
+type pushingPlugin struct {
+	batches int
+}
+
+func (p *pushingPlugin) Init(ctx context.Context, initParams InitParams) {
+}
+
+// Query pushes all batches right before it returns, like a host streaming results of a fast query
+func (p *pushingPlugin) Query(ctx context.Context, query Query) []QueryResult {
+	for i := 0; i < p.batches; i++ {
+		if pushErr := GetPluginManager().PushResults(ctx, "pushing", query.Id, []QueryResult{{Title: fmt.Sprintf("pushed %d", i)}}); pushErr != nil {
+			panic(pushErr)
+		}
+	}
+	return []QueryResult{{Title: "final"}}
+}
+
+func Test_PushResultsBeforeQueryReturns(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	instance := &Instance{
+		Plugin:   &pushingPlugin{batches: 50},
+		Metadata: Metadata{Id: "pushing", Name: "pushing", Features: []MetadataFeature{{Name: MetadataFeatureIgnoreAutoScore}}},
+	}
+
+	for round := 0; round < 20; round++ {
+		results := make(chan []QueryResultUI, 10)
+		done := make(chan bool)
+		counter := &atomic.Int32{}
+		counter.Store(1)
+		m.queryParallel(ctx, instance, Query{Id: fmt.Sprintf("push-%d", round), Type: QueryTypeSelection, Selection: util.Selection{Type: util.SelectionTypeText, Text: "hello"}}, results, done, counter)
+
+		var titles []string
+	collect:
+		for {
+			select {
+			case batch := <-results:
+				for _, result := range batch {
+					titles = append(titles, result.Title)
+				}
+			case <-done:
+				// final results may still be buffered when done is received
+				for len(results) > 0 {
+					for _, result := range <-results {
+						titles = append(titles, result.Title)
+					}
+				}
+				break collect
+			}
+		}
+
+		// every pushed batch arrives in order, before the final results
+		var expected []string
+		for i := 0; i < 50; i++ {
+			expected = append(expected, fmt.Sprintf("pushed %d", i))
+		}
+		assert.Equal(t, append(expected, "final"), titles)
+	}
+}
+
+func Test_ReplaceQueryVariable(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	t.Setenv("WOX_QUERY_VARIABLE_TEST", "wox")
+
+	assert.Equal(t, "hello wox", m.ReplaceQueryVariable(ctx, "hello {wox:env:WOX_QUERY_VARIABLE_TEST}"))
+	assert.Equal(t, time.Now().Format("2006"), m.ReplaceQueryVariable(ctx, "{wox:date:2006}"))
+	assert.Equal(t, "{wox:not_exist} {wox:new_ai_conversation}", m.ReplaceQueryVariable(ctx, "{wox:not_exist} {wox:new_ai_conversation}"))
+
+	assert.Nil(t, m.RegisterQueryVariable(ctx, "test_plugin", "greeting", "", func(ctx context.Context, arg string) (string, error) {
+		return "hi " + arg, nil
+	}))
+	assert.NotNil(t, m.RegisterQueryVariable(ctx, "other_plugin", "greeting", "", nil))
+	assert.NotNil(t, m.RegisterQueryVariable(ctx, "test_plugin", "Invalid-Name", "", nil))
+	assert.Equal(t, "hi wox, hi ", m.ReplaceQueryVariable(ctx, "{wox:greeting:wox}, {wox:greeting}"))
+
+	// values are escaped so template can still be used as a format string
+	escapeFormat := func(value string) string { return strings.ReplaceAll(value, "%", "%%") }
+	template := m.ReplaceQueryVariableEscaped(ctx, "%s {wox:greeting:100%s}", escapeFormat)
+	assert.Equal(t, "%s hi 100%%s", template)
+	assert.Equal(t, "{wox:env:HOME} hi 100%s", fmt.Sprintf(template, "{wox:env:HOME}"))
+
+	m.unregisterQueryVariables("test_plugin")
+	assert.Equal(t, "{wox:greeting}", m.ReplaceQueryVariable(ctx, "{wox:greeting}"))
+}
+
+func Test_InternalQueryResultCache(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	instance := &Instance{Metadata: Metadata{Id: "cache", Name: "cache", Features: []MetadataFeature{{Name: MetadataFeatureIgnoreAutoScore}}}}
+
+	uiQuery := Query{Id: "ui", RawQuery: "ui"}
+	m.Query(ctx, uiQuery)
+	uiResult := m.PolishResult(ctx, instance, uiQuery, QueryResult{Title: "ui"})
+
+	// internal query must not clear results shown in ui, E.g. automation query while ui is open
+	internalQuery := Query{Id: "internal", RawQuery: "internal", isInternal: true}
+	m.Query(ctx, internalQuery)
+	internalResult := m.PolishResult(ctx, instance, internalQuery, QueryResult{Title: "internal"})
+	_, found := m.loadResultCache(uiResult.Id)
+	assert.True(t, found)
+	_, found = m.loadResultCache(internalResult.Id)
+	assert.True(t, found)
+
+	// and next ui query doesn't clear results of internal query
+	m.Query(ctx, uiQuery)
+	_, found = m.loadResultCache(uiResult.Id)
+	assert.False(t, found)
+	_, found = m.loadResultCache(internalResult.Id)
+	assert.True(t, found)
+
+	// another internal query, E.g. a workflow step between automation query and action, keeps results of other internal queries
+	otherQuery := Query{Id: "other", RawQuery: "other", isInternal: true}
+	m.Query(ctx, otherQuery)
+	otherResult := m.PolishResult(ctx, instance, otherQuery, QueryResult{Title: "other"})
+	_, found = m.loadResultCache(internalResult.Id)
+	assert.True(t, found)
+	_, found = m.loadResultCache(otherResult.Id)
+	assert.True(t, found)
+
+	// results are evicted per query once expired
+	internalResults, _ := m.internalResultCache.Load(internalQuery.Id)
+	internalResults.startTimestamp -= internalQueryResultsExpiry.Milliseconds() + 1
+	m.Query(ctx, Query{Id: "next", RawQuery: "next", isInternal: true})
+	_, found = m.loadResultCache(internalResult.Id)
+	assert.False(t, found)
+	_, found = m.loadResultCache(otherResult.Id)
+	assert.True(t, found)
+}
+
+type titlePlugin struct {
+	title string
+}
+
+func (p *titlePlugin) Init(ctx context.Context, initParams InitParams) {
+}
+
+func (p *titlePlugin) Query(ctx context.Context, query Query) []QueryResult {
+	return []QueryResult{{Title: p.title}}
+}
+
+func Test_QueryAndWaitCollectsLastResults(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	newInstance := func(id string) *Instance {
+		return &Instance{
+			Plugin:   &titlePlugin{title: id},
+			Metadata: Metadata{Id: id, Name: id, TriggerKeywords: []string{"*"}, Features: []MetadataFeature{{Name: MetadataFeatureIgnoreAutoScore}, {Name: MetadataFeatureQuerySelection}}},
+			Setting:  &setting.PluginSetting{},
+		}
+	}
+	var expected []string
+	for i := 0; i < 30; i++ {
+		id := fmt.Sprintf("plugin-%d", i)
+		m.AddInstance(newInstance(id))
+		expected = append(expected, id)
+	}
+	t.Cleanup(func() {
+		m.instancesLock.Lock()
+		defer m.instancesLock.Unlock()
+		m.instances = nil
+	})
+
+	// plugins answer at the same time, results of the one answering last are sent right before done and must not be lost
+	for round := 0; round < 500; round++ {
+		results, queryErr := m.QueryAndWait(ctx, Query{Id: fmt.Sprintf("wait-%d", round), Type: QueryTypeSelection, Selection: util.Selection{Type: util.SelectionTypeText, Text: "wait"}})
+		assert.Nil(t, queryErr)
+		assert.ElementsMatch(t, expected, lo.Map(results, func(result QueryResultUI, _ int) string {
+			return result.Title
+		}))
+	}
+}
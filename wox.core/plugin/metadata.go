@@ -6,6 +6,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"wox/setting"
 	"wox/setting/definition"
 )
 
@@ -31,6 +32,36 @@ const (
 
 	// enable this feature to execute custom deep link in plugin
 	MetadataFeatureDeepLink MetadataFeatureName = "deepLink"
+
+	// enable this feature to control how SCRIPT runtime plugins are executed
+	// params see MetadataFeatureParamsScript
+	MetadataFeatureScript MetadataFeatureName = "script"
+
+	// enable this feature to run python/nodejs plugin in its own dedicated host process
+	// params see MetadataFeatureParamsIsolatedHost
+	MetadataFeatureIsolatedHost MetadataFeatureName = "isolatedHost"
+
+	// enable this feature to grant WASM runtime plugins access to host functions
+	// params see MetadataFeatureParamsWasm
+	MetadataFeatureWasm MetadataFeatureName = "wasm"
+)
+
+type WasmCapability = string
+
+// host functions which WASM plugins must declare in wasm feature before calling, log is always allowed
+const (
+	WasmCapabilityNotify      WasmCapability = "notify"
+	WasmCapabilityChangeQuery WasmCapability = "changeQuery"
+	WasmCapabilityGetSetting  WasmCapability = "getSetting"
+)
+
+const WasmMaxMemoryMb = 4096 // address space of wasm32
+
+type ScriptMode = string
+
+const (
+	ScriptModeOneShot  ScriptMode = "oneshot"  // spawn the entry executable for every request
+	ScriptModeResident ScriptMode = "resident" // keep the entry executable running and send requests line by line
 )
 
 // Metadata parsed from plugin.json, see `Plugin.json.md` for more detail
@@ -51,6 +82,7 @@ type Metadata struct {
 	SupportedOS        []string
 	Features           []MetadataFeature
 	SettingDefinitions definition.PluginSettingDefinitions
+	QueryShortcuts     []setting.QueryShortcut // query shortcuts contributed by plugin, user defined shortcuts take precedence
 }
 
 func (m *Metadata) GetIconOrDefault(pluginDirectory string, defaultImage WoxImage) WoxImage {
@@ -126,6 +158,101 @@ func (m *Metadata) GetFeatureParamsForQueryEnv() (MetadataFeatureParamsQueryEnv,
 	return MetadataFeatureParamsQueryEnv{}, errors.New("plugin does not support queryEnv feature")
 }
 
+// GetFeatureParamsForScript returns default params if plugin doesn't declare script feature
+func (m *Metadata) GetFeatureParamsForScript() (MetadataFeatureParamsScript, error) {
+	params := MetadataFeatureParamsScript{
+		Mode:      ScriptModeOneShot,
+		TimeoutMs: 10000,
+	}
+
+	for _, feature := range m.Features {
+		if strings.ToLower(feature.Name) == strings.ToLower(MetadataFeatureScript) {
+			if v, ok := feature.Params["mode"]; ok {
+				mode := strings.ToLower(v)
+				if mode != ScriptModeOneShot && mode != ScriptModeResident {
+					return MetadataFeatureParamsScript{}, fmt.Errorf("script feature mode param is not valid: %s", v)
+				}
+				params.Mode = mode
+			}
+
+			if v, ok := feature.Params["timeoutMs"]; ok {
+				timeoutMs, convertErr := strconv.Atoi(v)
+				if convertErr != nil {
+					return MetadataFeatureParamsScript{}, fmt.Errorf("script feature timeoutMs param is not a valid number: %s", convertErr.Error())
+				}
+				if timeoutMs > 0 {
+					params.TimeoutMs = timeoutMs
+				}
+			}
+		}
+	}
+
+	return params, nil
+}
+
+// GetFeatureParamsForIsolatedHost returns default params if plugin doesn't declare isolatedHost feature
+func (m *Metadata) GetFeatureParamsForIsolatedHost() (MetadataFeatureParamsIsolatedHost, error) {
+	params := MetadataFeatureParamsIsolatedHost{
+		IdleTimeoutMinutes: 10,
+	}
+
+	for _, feature := range m.Features {
+		if strings.ToLower(feature.Name) == strings.ToLower(MetadataFeatureIsolatedHost) {
+			for key, target := range map[string]*int{
+				"maxMemoryMb":        &params.MaxMemoryMb,
+				"maxCpuPercent":      &params.MaxCpuPercent,
+				"idleTimeoutMinutes": &params.IdleTimeoutMinutes,
+			} {
+				if v, ok := feature.Params[key]; ok {
+					value, convertErr := strconv.Atoi(v)
+					if convertErr != nil || value < 0 {
+						return MetadataFeatureParamsIsolatedHost{}, fmt.Errorf("isolatedHost feature %s param is not a valid number: %s", key, v)
+					}
+					*target = value
+				}
+			}
+		}
+	}
+
+	return params, nil
+}
+
+// GetFeatureParamsForWasm returns no capabilities and no memory limit if plugin doesn't declare wasm feature
+func (m *Metadata) GetFeatureParamsForWasm() (MetadataFeatureParamsWasm, error) {
+	params := MetadataFeatureParamsWasm{}
+
+	for _, feature := range m.Features {
+		if strings.ToLower(feature.Name) == strings.ToLower(MetadataFeatureWasm) {
+			if v, ok := feature.Params["capabilities"]; ok {
+				for _, capability := range strings.Split(v, ",") {
+					capability = strings.TrimSpace(capability)
+					if capability == "" {
+						continue
+					}
+					if capability != WasmCapabilityNotify && capability != WasmCapabilityChangeQuery && capability != WasmCapabilityGetSetting {
+						return MetadataFeatureParamsWasm{}, fmt.Errorf("wasm feature capability is not valid: %s", capability)
+					}
+					params.Capabilities = append(params.Capabilities, capability)
+				}
+			}
+
+			if v, ok := feature.Params["maxMemoryMb"]; ok {
+				maxMemoryMb, convertErr := strconv.Atoi(v)
+				if convertErr != nil || maxMemoryMb < 0 {
+					return MetadataFeatureParamsWasm{}, fmt.Errorf("wasm feature maxMemoryMb param is not a valid number: %s", v)
+				}
+				// wasm32 can't address more than 65536 pages (4GB)
+				if maxMemoryMb > WasmMaxMemoryMb {
+					return MetadataFeatureParamsWasm{}, fmt.Errorf("wasm feature maxMemoryMb param can't be larger than %d: %s", WasmMaxMemoryMb, v)
+				}
+				params.MaxMemoryMb = maxMemoryMb
+			}
+		}
+	}
+
+	return params, nil
+}
+
 type MetadataFeature struct {
 	Name   MetadataFeatureName
 	Params map[string]string
@@ -154,3 +281,19 @@ type MetadataFeatureParamsQueryEnv struct {
 	RequireActiveWindowPid  bool
 	RequireActiveBrowserUrl bool
 }
+
+type MetadataFeatureParamsScript struct {
+	Mode      ScriptMode
+	TimeoutMs int
+}
+
+type MetadataFeatureParamsIsolatedHost struct {
+	MaxMemoryMb        int // 0 means no limit
+	MaxCpuPercent      int // percent of a single cpu core, 0 means no limit
+	IdleTimeoutMinutes int // stop the host after idle for this many minutes and restart it on next query, 0 means never
+}
+
+type MetadataFeatureParamsWasm struct {
+	Capabilities []WasmCapability
+	MaxMemoryMb  int // 0 means no limit besides the 4GB address space of wasm32, can't be larger than WasmMaxMemoryMb
+}
@@ -19,6 +19,11 @@ type FallbackSearcher interface {
 	QueryFallback(ctx context.Context, query Query) []QueryResult
 }
 
+// QueryShortcutPreviewer shows how a query shortcut is expanded, Wox calls PreviewQueryShortcut when user input matches a query shortcut
+type QueryShortcutPreviewer interface {
+	PreviewQueryShortcut(ctx context.Context, query Query, expansion QueryShortcutExpansion) []QueryResult
+}
+
 type InitParams struct {
 	API             API
 	PluginDirectory string
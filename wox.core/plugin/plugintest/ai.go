@@ -0,0 +1,87 @@
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"wox/ai"
+)
+
+// FakeAIProvider implements ai.Provider, every ChatStream call consumes the next scripted stream
+type FakeAIProvider struct {
+	ModelList []ai.Model // returned by Models
+
+	scripts       []fakeChatScript
+	conversations [][]ai.Conversation
+	lock          sync.Mutex
+}
+
+type fakeChatScript struct {
+	chunks []string
+	err    error // returned by Receive after all chunks are received
+}
+
+func NewFakeAIProvider() *FakeAIProvider {
+	return &FakeAIProvider{}
+}
+
+// Script adds a stream which returns chunks in order and then io.EOF
+func (p *FakeAIProvider) Script(chunks ...string) *FakeAIProvider {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.scripts = append(p.scripts, fakeChatScript{chunks: chunks, err: io.EOF})
+	return p
+}
+
+// ScriptError adds a stream which returns chunks in order and then err
+func (p *FakeAIProvider) ScriptError(err error, chunks ...string) *FakeAIProvider {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.scripts = append(p.scripts, fakeChatScript{chunks: chunks, err: err})
+	return p
+}
+
+// Conversations returns conversations of every ChatStream call in order
+func (p *FakeAIProvider) Conversations() [][]ai.Conversation {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([][]ai.Conversation{}, p.conversations...)
+}
+
+func (p *FakeAIProvider) Close(ctx context.Context) error {
+	return nil
+}
+
+func (p *FakeAIProvider) ChatStream(ctx context.Context, model ai.Model, conversations []ai.Conversation) (ai.ChatStream, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.conversations = append(p.conversations, conversations)
+	if len(p.scripts) == 0 {
+		return nil, fmt.Errorf("no scripted ai stream left for model %s", model.Name)
+	}
+
+	script := p.scripts[0]
+	p.scripts = p.scripts[1:]
+	return &fakeChatStream{script: script}, nil
+}
+
+func (p *FakeAIProvider) Models(ctx context.Context) ([]ai.Model, error) {
+	return p.ModelList, nil
+}
+
+type fakeChatStream struct {
+	script fakeChatScript
+	index  int
+}
+
+func (s *fakeChatStream) Receive(ctx context.Context) (string, error) {
+	if s.index >= len(s.script.chunks) {
+		return "", s.script.err
+	}
+
+	chunk := s.script.chunks[s.index]
+	s.index++
+	return chunk, nil
+}
@@ -0,0 +1,229 @@
+package plugintest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"wox/ai"
+	"wox/plugin"
+	"wox/share"
+
+	"github.com/samber/lo"
+)
+
+// Call is a recorded api or ui call
+type Call struct {
+	Method string
+	Args   []any
+}
+
+type callRecorder struct {
+	calls []Call
+	lock  sync.Mutex
+}
+
+func (r *callRecorder) record(method string, args ...any) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns recorded calls in order
+func (r *callRecorder) Calls() []Call {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Call{}, r.calls...)
+}
+
+// CallsOf returns recorded calls of the given method
+func (r *callRecorder) CallsOf(method string) []Call {
+	return lo.Filter(r.Calls(), func(call Call, _ int) bool {
+		return call.Method == method
+	})
+}
+
+// Reset clears recorded calls
+func (r *callRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = nil
+}
+
+// FakeAPI implements plugin.API with recorded calls and in-memory settings.
+// UI related apis are forwarded to UI, AI chat is served by AI synchronously so tests don't need to wait
+type FakeAPI struct {
+	callRecorder
+
+	UI           *FakeUI
+	AI           *FakeAIProvider
+	Translations map[string]string // translations returned by GetTranslation, key itself is returned if not found
+
+	settings               map[string]string
+	settingLock            sync.Mutex
+	settingChangeCallbacks []func(key string, value string)
+	dynamicSettingCallback []func(key string) string
+	deepLinkCallbacks      []func(arguments map[string]string)
+	unloadCallbacks        []func()
+	queryCommands          []plugin.MetadataCommand
+	queryVariables         map[string]plugin.QueryVariableResolver
+}
+
+func NewFakeAPI() *FakeAPI {
+	return &FakeAPI{
+		UI:             NewFakeUI(),
+		AI:             NewFakeAIProvider(),
+		Translations:   map[string]string{},
+		settings:       map[string]string{},
+		queryVariables: map[string]plugin.QueryVariableResolver{},
+	}
+}
+
+func (a *FakeAPI) ChangeQuery(ctx context.Context, query share.PlainQuery) {
+	a.record("ChangeQuery", query)
+	a.UI.ChangeQuery(ctx, query)
+}
+
+func (a *FakeAPI) HideApp(ctx context.Context) {
+	a.record("HideApp")
+	a.UI.HideApp(ctx)
+}
+
+func (a *FakeAPI) ShowApp(ctx context.Context) {
+	a.record("ShowApp")
+	a.UI.ShowApp(ctx, share.ShowContext{SelectAll: true})
+}
+
+func (a *FakeAPI) Notify(ctx context.Context, message string) {
+	a.record("Notify", message)
+	a.UI.Notify(ctx, share.NotifyMsg{Text: a.GetTranslation(ctx, message), DisplaySeconds: 3})
+}
+
+func (a *FakeAPI) Log(ctx context.Context, level plugin.LogLevel, msg string) {
+	a.record("Log", level, msg)
+}
+
+func (a *FakeAPI) GetTranslation(ctx context.Context, key string) string {
+	if translation, exist := a.Translations[key]; exist {
+		return translation
+	}
+	return key
+}
+
+func (a *FakeAPI) GetSetting(ctx context.Context, key string) string {
+	a.settingLock.Lock()
+	defer a.settingLock.Unlock()
+	return a.settings[key]
+}
+
+// SetSetting sets a setting without notifying plugin, use it to prepare settings before Init
+func (a *FakeAPI) SetSetting(key string, value string) {
+	a.settingLock.Lock()
+	defer a.settingLock.Unlock()
+	a.settings[key] = value
+}
+
+func (a *FakeAPI) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) error {
+	a.record("SaveSetting", key, value, isPlatformSpecific)
+	a.SetSetting(key, value)
+	for _, callback := range a.settingChangeCallbacks {
+		callback(key, value)
+	}
+	return nil
+}
+
+func (a *FakeAPI) OnSettingChanged(ctx context.Context, callback func(key string, value string)) {
+	a.settingChangeCallbacks = append(a.settingChangeCallbacks, callback)
+}
+
+func (a *FakeAPI) OnGetDynamicSetting(ctx context.Context, callback func(key string) string) {
+	a.dynamicSettingCallback = append(a.dynamicSettingCallback, callback)
+}
+
+// GetDynamicSetting returns value of the first dynamic setting callback which has a value for key
+func (a *FakeAPI) GetDynamicSetting(key string) string {
+	for _, callback := range a.dynamicSettingCallback {
+		if value := callback(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (a *FakeAPI) OnDeepLink(ctx context.Context, callback func(arguments map[string]string)) {
+	a.deepLinkCallbacks = append(a.deepLinkCallbacks, callback)
+}
+
+// TriggerDeepLink invokes deep link callbacks registered by plugin
+func (a *FakeAPI) TriggerDeepLink(arguments map[string]string) {
+	for _, callback := range a.deepLinkCallbacks {
+		callback(arguments)
+	}
+}
+
+func (a *FakeAPI) OnUnload(ctx context.Context, callback func()) {
+	a.unloadCallbacks = append(a.unloadCallbacks, callback)
+}
+
+// Unload invokes unload callbacks registered by plugin
+func (a *FakeAPI) Unload() {
+	for _, callback := range a.unloadCallbacks {
+		callback()
+	}
+}
+
+func (a *FakeAPI) RegisterQueryCommands(ctx context.Context, commands []plugin.MetadataCommand) {
+	a.record("RegisterQueryCommands", commands)
+	a.queryCommands = commands
+}
+
+// QueryCommands returns commands registered by plugin
+func (a *FakeAPI) QueryCommands() []plugin.MetadataCommand {
+	return a.queryCommands
+}
+
+func (a *FakeAPI) AIChatStream(ctx context.Context, model ai.Model, conversations []ai.Conversation, callback ai.ChatStreamFunc) error {
+	a.record("AIChatStream", model, conversations)
+
+	stream, streamErr := a.AI.ChatStream(ctx, model, conversations)
+	if streamErr != nil {
+		return streamErr
+	}
+	if callback == nil {
+		return nil
+	}
+
+	for {
+		response, receiveErr := stream.Receive(ctx)
+		if errors.Is(receiveErr, io.EOF) {
+			callback(ai.ChatStreamTypeFinished, "")
+			return nil
+		}
+		if receiveErr != nil {
+			callback(ai.ChatStreamTypeError, receiveErr.Error())
+			return nil
+		}
+		callback(ai.ChatStreamTypeStreaming, response)
+	}
+}
+
+func (a *FakeAPI) PushResults(ctx context.Context, queryId string, results []plugin.QueryResult) error {
+	a.record("PushResults", queryId, results)
+	return nil
+}
+
+func (a *FakeAPI) RegisterQueryVariable(ctx context.Context, name string, description string, resolver plugin.QueryVariableResolver) error {
+	a.record("RegisterQueryVariable", name, description)
+	a.queryVariables[name] = resolver
+	return nil
+}
+
+// ResolveQueryVariable resolves a query variable registered by plugin
+func (a *FakeAPI) ResolveQueryVariable(ctx context.Context, name string, arg string) (string, error) {
+	resolver, exist := a.queryVariables[name]
+	if !exist {
+		return "", fmt.Errorf("query variable %s is not registered", name)
+	}
+	return resolver(ctx, arg)
+}
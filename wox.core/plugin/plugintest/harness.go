@@ -0,0 +1,241 @@
+// Package plugintest runs plugins against a fake api, ui and ai provider so plugin behaviours can be tested deterministically.
+//
+//	h := plugintest.NewSystemPlugin(t, &MyPlugin{})
+//	results := h.Query("hello")
+//	results.AssertTitles("Hello world")
+//	results.ExecuteAction("Hello world", "Copy")
+//	assert.Len(t, h.API.CallsOf("Notify"), 1)
+//
+// Results are polished by plugin.Manager.PolishResult, so they look exactly like what ui receives.
+// Harness replaces ui of the global plugin manager until the test ends, so tests using it must not run in parallel
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"wox/plugin"
+	_ "wox/plugin/host" // register hosts for NewExternal
+	"wox/setting"
+	"wox/util"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Harness struct {
+	Ctx      context.Context
+	API      *FakeAPI
+	UI       *FakeUI
+	AI       *FakeAIProvider
+	Instance *plugin.Instance
+
+	t testing.TB
+}
+
+type Option func(h *Harness)
+
+// WithSettings prepares plugin settings before Init
+func WithSettings(settings map[string]string) Option {
+	return func(h *Harness) {
+		for key, value := range settings {
+			h.API.SetSetting(key, value)
+		}
+	}
+}
+
+// WithTranslations sets translations returned by api GetTranslation, result titles are still translated by i18n manager
+func WithTranslations(translations map[string]string) Option {
+	return func(h *Harness) {
+		h.API.Translations = translations
+	}
+}
+
+// New creates a harness for an in-process plugin and initializes it
+func New(t testing.TB, p plugin.Plugin, metadata plugin.Metadata, options ...Option) *Harness {
+	h := newHarness(t, &plugin.Instance{
+		Plugin:          p,
+		Metadata:        metadata,
+		PluginDirectory: t.TempDir(),
+	}, options)
+	h.init()
+	return h
+}
+
+// NewSystemPlugin creates a harness for a system plugin and initializes it
+func NewSystemPlugin(t testing.TB, p plugin.SystemPlugin, options ...Option) *Harness {
+	h := newHarness(t, &plugin.Instance{
+		Plugin:         p,
+		Metadata:       p.GetMetadata(),
+		IsSystemPlugin: true,
+	}, options)
+	h.init()
+	return h
+}
+
+// NewExternal loads the plugin in pluginDirectory through its real host and initializes it with the fake api.
+// Host started by harness is stopped when the test ends.
+// Hosts of nodejs and python plugins need wox data directory and extracted host resources, which are not prepared here
+func NewExternal(t testing.TB, pluginDirectory string, options ...Option) *Harness {
+	ctx := util.NewTraceContext()
+	metadata, parseErr := plugin.GetPluginManager().ParseMetadata(ctx, pluginDirectory)
+	require.NoError(t, parseErr)
+
+	pluginHost, exist := lo.Find(plugin.AllHosts, func(item plugin.Host) bool {
+		return strings.EqualFold(string(item.GetRuntime(ctx)), metadata.Runtime)
+	})
+	require.True(t, exist, fmt.Sprintf("unsupported runtime: %s", metadata.Runtime))
+	if !pluginHost.IsStarted(ctx) {
+		require.NoError(t, pluginHost.Start(ctx))
+		// cleanups run in reverse order, so host is stopped after plugin is unloaded
+		t.Cleanup(func() {
+			pluginHost.Stop(ctx)
+		})
+	}
+
+	p, loadErr := pluginHost.LoadPlugin(ctx, metadata, pluginDirectory)
+	require.NoError(t, loadErr)
+
+	h := newHarness(t, &plugin.Instance{
+		Plugin:          p,
+		Metadata:        metadata,
+		PluginDirectory: pluginDirectory,
+		Host:            pluginHost,
+	}, options)
+
+	// hosts find the instance by plugin id when plugin calls api
+	plugin.GetPluginManager().AddInstance(h.Instance)
+	t.Cleanup(func() {
+		plugin.GetPluginManager().UnloadPlugin(ctx, h.Instance)
+	})
+
+	h.init()
+	return h
+}
+
+func newHarness(t testing.TB, instance *plugin.Instance, options []Option) *Harness {
+	api := NewFakeAPI()
+	instance.API = api
+	instance.Setting = &setting.PluginSetting{Name: instance.Metadata.Name}
+
+	h := &Harness{
+		Ctx:      util.NewTraceContext(),
+		API:      api,
+		UI:       api.UI,
+		AI:       api.AI,
+		Instance: instance,
+		t:        t,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+func (h *Harness) init() {
+	// plugin manager is shared by all tests of a package, give the ui back so later tests don't see this fake ui
+	previousUI := plugin.GetPluginManager().GetUI()
+	plugin.GetPluginManager().SetUI(h.UI)
+	h.t.Cleanup(func() {
+		plugin.GetPluginManager().SetUI(previousUI)
+	})
+	h.Instance.Plugin.Init(h.Ctx, plugin.InitParams{
+		API:             h.API,
+		PluginDirectory: h.Instance.PluginDirectory,
+	})
+}
+
+// Query runs an input query with the first trigger keyword of plugin, search is the text after trigger keyword
+func (h *Harness) Query(search string) *Results {
+	query := plugin.Query{
+		Type:     plugin.QueryTypeInput,
+		RawQuery: search,
+		Search:   search,
+	}
+	triggerKeywords := h.Instance.GetTriggerKeywords()
+	if len(triggerKeywords) > 0 && triggerKeywords[0] != "*" {
+		query.TriggerKeyword = triggerKeywords[0]
+		query.RawQuery = fmt.Sprintf("%s %s", triggerKeywords[0], search)
+	}
+	return h.QueryWith(query)
+}
+
+// QueryWith runs the given query, a query id is assigned if empty
+func (h *Harness) QueryWith(query plugin.Query) *Results {
+	if query.Id == "" {
+		query.Id = uuid.NewString()
+	}
+
+	raw := h.Instance.Plugin.Query(h.Ctx, query)
+	polished := lo.Map(raw, func(result plugin.QueryResult, _ int) plugin.QueryResult {
+		// polishing sorts actions in place, keep raw results untouched
+		result.Actions = append([]plugin.QueryResultAction{}, result.Actions...)
+		return plugin.GetPluginManager().PolishResult(h.Ctx, h.Instance, query, result)
+	})
+	return &Results{Query: query, Raw: raw, Polished: polished, h: h}
+}
+
+// Results of a query, Raw is returned by plugin and Polished is what ui would receive
+type Results struct {
+	Query    plugin.Query
+	Raw      []plugin.QueryResult
+	Polished []plugin.QueryResult
+
+	h *Harness
+}
+
+func (r *Results) Titles() []string {
+	return lo.Map(r.Polished, func(result plugin.QueryResult, _ int) string {
+		return result.Title
+	})
+}
+
+// Find returns the first polished result with the given title
+func (r *Results) Find(title string) (plugin.QueryResult, bool) {
+	return lo.Find(r.Polished, func(result plugin.QueryResult) bool {
+		return result.Title == title
+	})
+}
+
+// MustFind is like Find but fails the test if no result has the given title
+func (r *Results) MustFind(title string) plugin.QueryResult {
+	result, found := r.Find(title)
+	require.True(r.h.t, found, fmt.Sprintf("no result titled %q, got %v", title, r.Titles()))
+	return result
+}
+
+func (r *Results) AssertCount(count int) bool {
+	return assert.Len(r.h.t, r.Polished, count, fmt.Sprintf("results: %v", r.Titles()))
+}
+
+// AssertTitles asserts titles of results in order
+func (r *Results) AssertTitles(titles ...string) bool {
+	return assert.Equal(r.h.t, titles, r.Titles())
+}
+
+// AssertActions asserts action names of the result with the given title in order, default action comes first after polishing
+func (r *Results) AssertActions(title string, actionNames ...string) bool {
+	result := r.MustFind(title)
+	return assert.Equal(r.h.t, actionNames, lo.Map(result.Actions, func(action plugin.QueryResultAction, _ int) string {
+		return action.Name
+	}))
+}
+
+// ExecuteAction runs the action named actionName of the result with the given title, empty actionName means the default action.
+// Unlike plugin.Manager.ExecuteAction, the result is not recorded as actioned so scores of later queries are not affected
+func (r *Results) ExecuteAction(title string, actionName string) {
+	result := r.MustFind(title)
+	action, found := lo.Find(result.Actions, func(action plugin.QueryResultAction) bool {
+		if actionName == "" {
+			return action.IsDefault
+		}
+		return strings.EqualFold(action.Name, actionName)
+	})
+	require.True(r.h.t, found, fmt.Sprintf("result %q has no action named %q", title, actionName))
+	require.NotNil(r.h.t, action.Action, fmt.Sprintf("action %q of result %q has no callback", action.Name, title))
+
+	action.Action(r.h.Ctx, plugin.ActionContext{ContextData: result.ContextData})
+}
@@ -0,0 +1,171 @@
+package plugintest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/ai"
+	"wox/plugin"
+	"wox/share"
+	"wox/util"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+type echoPlugin struct {
+	api    plugin.API
+	prefix string
+}
+
+func (e *echoPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
+	e.api = initParams.API
+	e.prefix = e.api.GetSetting(ctx, "prefix")
+	e.api.OnSettingChanged(ctx, func(key string, value string) {
+		if key == "prefix" {
+			e.prefix = value
+		}
+	})
+}
+
+func (e *echoPlugin) Query(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	return []plugin.QueryResult{
+		{
+			Title: e.prefix + query.Search,
+			Actions: []plugin.QueryResultAction{
+				{
+					Name: "Notify",
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						e.api.Notify(ctx, "notify_"+actionContext.ContextData)
+					},
+				},
+				{
+					Name:      "Ask",
+					IsDefault: true,
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						var answer string
+						e.api.AIChatStream(ctx, ai.Model{Name: "fake"}, []ai.Conversation{{Role: ai.ConversationRoleUser, Text: query.Search}}, func(t ai.ChatStreamDataType, data string) {
+							if t == ai.ChatStreamTypeStreaming {
+								answer += data
+							}
+							if t == ai.ChatStreamTypeError {
+								answer = "error: " + data
+							}
+						})
+						e.api.SaveSetting(ctx, "answer", answer, false)
+					},
+				},
+			},
+			ContextData: "ctx-" + query.Search,
+		},
+	}
+}
+
+func Test_HarnessInProcessPlugin(t *testing.T) {
+	h := New(t, &echoPlugin{}, plugin.Metadata{Id: "echo", Name: "echo", TriggerKeywords: []string{"echo"}},
+		WithSettings(map[string]string{"prefix": "> "}),
+		WithTranslations(map[string]string{"notify_ctx-hello": "Hello from context"}))
+
+	results := h.Query("hello")
+	assert.Equal(t, "echo hello", results.Query.RawQuery)
+	results.AssertCount(1)
+	results.AssertTitles("> hello")
+	results.AssertActions("> hello", "Ask", "Notify")
+	assert.Equal(t, "Notify", results.Raw[0].Actions[0].Name)
+
+	results.ExecuteAction("> hello", "notify")
+	assert.Len(t, h.API.CallsOf("Notify"), 1)
+	assert.Equal(t, []any{"notify_ctx-hello"}, h.API.CallsOf("Notify")[0].Args)
+	assert.Equal(t, "Hello from context", h.UI.CallsOf("Notify")[0].Args[0].(share.NotifyMsg).Text)
+
+	h.AI.Script("hi ", "there").ScriptError(errors.New("quota exceeded"), "partial")
+	results.ExecuteAction("> hello", "")
+	assert.Equal(t, "hi there", h.API.GetSetting(h.Ctx, "answer"))
+	results.ExecuteAction("> hello", "")
+	assert.Equal(t, "error: quota exceeded", h.API.GetSetting(h.Ctx, "answer"))
+	assert.Len(t, h.AI.Conversations(), 2)
+	assert.Equal(t, "hello", h.AI.Conversations()[0][0].Text)
+
+	h.API.SaveSetting(h.Ctx, "prefix", "# ", false)
+	h.Query("world").AssertTitles("# world")
+}
+
+func Test_HarnessRestoresUI(t *testing.T) {
+	previousUI := plugin.GetPluginManager().GetUI()
+	t.Run("harness", func(t *testing.T) {
+		h := New(t, &echoPlugin{}, plugin.Metadata{Id: "echo", Name: "echo", TriggerKeywords: []string{"echo"}})
+		assert.Equal(t, h.UI, plugin.GetPluginManager().GetUI())
+	})
+	assert.Equal(t, previousUI, plugin.GetPluginManager().GetUI())
+}
+
+func Test_HarnessExternalPlugin(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("shell script is not available on windows")
+	}
+
+	pluginDirectory := t.TempDir()
+	metadata := `{"Id":"script-echo","Name":"script-echo","Version":"1.0.0","Runtime":"SCRIPT","Entry":"main.sh","TriggerKeywords":["se"],"SupportedOS":["Windows","Linux","Macos"]}`
+	script := `#!/bin/sh
+read request
+id=$(echo "$request" | sed 's/.*"Id":"\([^"]*\)".*/\1/')
+echo '{"Type":"WOX_JSONRPC_REQUEST","Method":"Log","Params":{"level":"Info","msg":"queried"}}'
+echo "{\"Id\":\"$id\",\"Type\":\"WOX_JSONRPC_RESPONSE\",\"Result\":[{\"Title\":\"from script\",\"Actions\":[{\"Id\":\"a\",\"Name\":\"Open\"}]}]}"
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "plugin.json"), []byte(metadata), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "main.sh"), []byte(script), 0755))
+
+	h := NewExternal(t, pluginDirectory)
+	results := h.Query("anything")
+	results.AssertTitles("from script")
+	results.AssertActions("from script", "Open")
+	assert.Equal(t, []any{plugin.LogLevelInfo, "queried"}, h.API.CallsOf("Log")[0].Args)
+}
+
+// stoppedHost reports it's not started, so harness has to start it
+type stoppedHost struct {
+	plugin.Host
+	isStarted bool
+}
+
+func (s *stoppedHost) Start(ctx context.Context) error {
+	s.isStarted = true
+	return s.Host.Start(ctx)
+}
+
+func (s *stoppedHost) Stop(ctx context.Context) {
+	s.isStarted = false
+	s.Host.Stop(ctx)
+}
+
+func (s *stoppedHost) IsStarted(ctx context.Context) bool {
+	return s.isStarted
+}
+
+func Test_HarnessStopsStartedHost(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("shell script is not available on windows")
+	}
+
+	ctx := util.NewTraceContext()
+	scriptHost, _ := lo.Find(plugin.AllHosts, func(item plugin.Host) bool {
+		return item.GetRuntime(ctx) == plugin.PLUGIN_RUNTIME_SCRIPT
+	})
+	host := &stoppedHost{Host: scriptHost}
+	previousHosts := plugin.AllHosts
+	plugin.AllHosts = append([]plugin.Host{host}, previousHosts...)
+	t.Cleanup(func() { plugin.AllHosts = previousHosts })
+
+	pluginDirectory := t.TempDir()
+	metadata := `{"Id":"script-stop","Name":"script-stop","Version":"1.0.0","Runtime":"SCRIPT","Entry":"main.sh","TriggerKeywords":["ss"],"SupportedOS":["Windows","Linux","Macos"]}`
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "plugin.json"), []byte(metadata), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(pluginDirectory, "main.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	t.Run("harness", func(t *testing.T) {
+		NewExternal(t, pluginDirectory)
+		assert.True(t, host.IsStarted(ctx))
+	})
+	assert.False(t, host.IsStarted(ctx))
+}
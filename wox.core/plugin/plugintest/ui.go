@@ -0,0 +1,81 @@
+package plugintest
+
+import (
+	"context"
+	"wox/share"
+)
+
+// FakeUI implements share.UI with recorded calls
+type FakeUI struct {
+	callRecorder
+
+	PickFilesResult  []string // returned by PickFiles
+	ActiveWindowName string   // returned by GetActiveWindowName
+	ActiveWindowPid  int      // returned by GetActiveWindowPid
+	Themes           []share.Theme
+}
+
+func NewFakeUI() *FakeUI {
+	return &FakeUI{}
+}
+
+func (u *FakeUI) ChangeQuery(ctx context.Context, query share.PlainQuery) {
+	u.record("ChangeQuery", query)
+}
+
+func (u *FakeUI) HideApp(ctx context.Context) {
+	u.record("HideApp")
+}
+
+func (u *FakeUI) ShowApp(ctx context.Context, showContext share.ShowContext) {
+	u.record("ShowApp", showContext)
+}
+
+func (u *FakeUI) ToggleApp(ctx context.Context) {
+	u.record("ToggleApp")
+}
+
+func (u *FakeUI) OpenSettingWindow(ctx context.Context, windowContext share.SettingWindowContext) {
+	u.record("OpenSettingWindow", windowContext)
+}
+
+func (u *FakeUI) PickFiles(ctx context.Context, params share.PickFilesParams) []string {
+	u.record("PickFiles", params)
+	return u.PickFilesResult
+}
+
+func (u *FakeUI) GetActiveWindowName() string {
+	return u.ActiveWindowName
+}
+
+func (u *FakeUI) GetActiveWindowPid() int {
+	return u.ActiveWindowPid
+}
+
+func (u *FakeUI) GetServerPort(ctx context.Context) int {
+	return 0
+}
+
+func (u *FakeUI) GetAllThemes(ctx context.Context) []share.Theme {
+	return u.Themes
+}
+
+func (u *FakeUI) ChangeTheme(ctx context.Context, theme share.Theme) {
+	u.record("ChangeTheme", theme)
+}
+
+func (u *FakeUI) InstallTheme(ctx context.Context, theme share.Theme) {
+	u.record("InstallTheme", theme)
+}
+
+func (u *FakeUI) UninstallTheme(ctx context.Context, theme share.Theme) {
+	u.record("UninstallTheme", theme)
+}
+
+func (u *FakeUI) RestoreTheme(ctx context.Context) {
+	u.record("RestoreTheme")
+}
+
+func (u *FakeUI) Notify(ctx context.Context, msg share.NotifyMsg) {
+	u.record("Notify", msg)
+}
@@ -3,6 +3,7 @@ package plugin
 import (
 	"context"
 	"strings"
+	"wox/setting"
 	"wox/util"
 
 	"github.com/samber/lo"
@@ -17,9 +18,17 @@ const (
 	QueryTypeSelection QueryType = "selection" // user selection query
 )
 
+// built-in query variables, see RegisterQueryVariable for all variables
 const (
-	QueryVariableSelectedText     QueryVariable = "{wox:selected_text}"
-	QueryVariableActiveBrowserUrl QueryVariable = "{wox:active_browser_url}"
+	QueryVariableSelectedText      QueryVariable = "{wox:selected_text}"
+	QueryVariableActiveBrowserUrl  QueryVariable = "{wox:active_browser_url}"
+	QueryVariableClipboard         QueryVariable = "{wox:clipboard}"
+	QueryVariableDate              QueryVariable = "{wox:date}" // current date, custom go layout can be given, E.g. {wox:date:2006-01-02 15:04}
+	QueryVariableTime              QueryVariable = "{wox:time}" // current time, custom go layout can be given like QueryVariableDate
+	QueryVariableEnv               QueryVariable = "{wox:env}"  // environment variable, E.g. {wox:env:HOME}
+	QueryVariableActiveWindowTitle QueryVariable = "{wox:active_window_title}"
+	QueryVariableUUID              QueryVariable = "{wox:uuid}"
+	QueryVariableFileSelection     QueryVariable = "{wox:file_selection}" // selected file paths, separated by space and quoted if path contains space
 )
 
 const (
@@ -29,6 +38,9 @@ const (
 
 // Query from Wox. See "Doc/Query.md" for details.
 type Query struct {
+	// Unique id of this query, plugins can push results to a running query with this id, see API.PushResults
+	Id string
+
 	// By default, Wox will only pass QueryTypeInput query to plugin.
 	// plugin author need to enable MetadataFeatureQuerySelection feature to handle QueryTypeSelection query
 	Type QueryType
@@ -61,6 +73,17 @@ type Query struct {
 	// additional query environment data
 	// expose more context env data to plugin, E.g. plugin A only show result when active window title is "Chrome"
 	Env QueryEnv
+
+	shortcutExpansion *QueryShortcutExpansion // not nil if user input was expanded from a query shortcut
+	isInternal        bool                    // query is not shown in ui, E.g. from automation or workflow, see QueryAndWait
+}
+
+// QueryShortcutExpansion describes how user input is expanded by a query shortcut
+type QueryShortcutExpansion struct {
+	Shortcut      setting.QueryShortcut
+	OriginQuery   string            // user input
+	ExpandedQuery string            // query after parameters are replaced, query variables of shortcut are replaced with values taken before wox is shown
+	Parameters    map[string]string // values of filled parameters
 }
 
 func (q *Query) IsGlobalQuery() bool {
@@ -0,0 +1,265 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"wox/setting"
+	"wox/util"
+	"wox/util/clipboard"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// QueryVariableResolver returns the value of a query variable.
+// arg is the text after variable name, E.g. "HOME" of {wox:env:HOME}, empty if not given
+type QueryVariableResolver func(ctx context.Context, arg string) (string, error)
+
+type QueryVariableInfo struct {
+	Name        string // used as {wox:<name>} or {wox:<name>:<arg>}
+	Description string
+	PluginId    string // empty for built-in variables
+}
+
+type queryVariable struct {
+	QueryVariableInfo
+	resolver QueryVariableResolver
+}
+
+var queryVariableRegex = regexp.MustCompile(`\{wox:([a-z][a-z0-9_]*)(?::([^{}]*))?\}`)
+var queryVariableNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// selection variables simulate copy in active window, which is slow and overwrites clipboard
+var selectionQueryVariableNames = []string{"selected_text", "file_selection"}
+
+func (m *Manager) registerBuiltinQueryVariables() {
+	builtins := []queryVariable{
+		{QueryVariableInfo{Name: "selected_text", Description: "Currently selected text"}, m.resolveSelectedText},
+		{QueryVariableInfo{Name: "file_selection", Description: "Currently selected files, separated by space"}, m.resolveFileSelection},
+		{QueryVariableInfo{Name: "active_browser_url", Description: "Url of active browser tab"}, func(ctx context.Context, arg string) (string, error) {
+			return m.activeBrowserUrl, nil
+		}},
+		{QueryVariableInfo{Name: "active_window_title", Description: "Title of active window before Wox is shown"}, func(ctx context.Context, arg string) (string, error) {
+			return m.GetUI().GetActiveWindowName(), nil
+		}},
+		{QueryVariableInfo{Name: "clipboard", Description: "Text in clipboard"}, resolveClipboard},
+		{QueryVariableInfo{Name: "date", Description: "Current date, layout can be given in go format, E.g. {wox:date:2006-01-02 15:04}"}, func(ctx context.Context, arg string) (string, error) {
+			if arg == "" {
+				arg = "2006-01-02"
+			}
+			return time.Now().Format(arg), nil
+		}},
+		{QueryVariableInfo{Name: "time", Description: "Current time, layout can be given in go format, E.g. {wox:time:15:04}"}, func(ctx context.Context, arg string) (string, error) {
+			if arg == "" {
+				arg = "15:04:05"
+			}
+			return time.Now().Format(arg), nil
+		}},
+		{QueryVariableInfo{Name: "env", Description: "Environment variable, E.g. {wox:env:HOME}"}, func(ctx context.Context, arg string) (string, error) {
+			if arg == "" {
+				return "", fmt.Errorf("environment variable name is empty")
+			}
+			return os.Getenv(arg), nil
+		}},
+		{QueryVariableInfo{Name: "uuid", Description: "A random uuid"}, func(ctx context.Context, arg string) (string, error) {
+			return uuid.NewString(), nil
+		}},
+	}
+	for _, builtin := range builtins {
+		variable := builtin
+		m.queryVariables.Store(variable.Name, &variable)
+	}
+}
+
+// RegisterQueryVariable adds a variable which can be used as {wox:<name>} in queries, pluginId is empty for built-in variables
+func (m *Manager) RegisterQueryVariable(ctx context.Context, pluginId string, name string, description string, resolver QueryVariableResolver) error {
+	if !queryVariableNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid query variable name: %s, only lower case letters, digits and underscore are allowed", name)
+	}
+	if existing, exist := m.queryVariables.Load(name); exist && existing.PluginId != pluginId {
+		return fmt.Errorf("query variable %s is already registered", name)
+	}
+
+	m.queryVariables.Store(name, &queryVariable{
+		QueryVariableInfo: QueryVariableInfo{Name: name, Description: description, PluginId: pluginId},
+		resolver:          resolver,
+	})
+	logger.Info(ctx, fmt.Sprintf("query variable registered: %s", name))
+	return nil
+}
+
+func (m *Manager) unregisterQueryVariables(pluginId string) {
+	for _, variable := range m.queryVariables.FilterList(func(name string, variable *queryVariable) bool {
+		return variable.PluginId == pluginId
+	}) {
+		m.queryVariables.Delete(variable.Name)
+	}
+}
+
+// GetQueryVariables returns all registered variables sorted by name
+func (m *Manager) GetQueryVariables() []QueryVariableInfo {
+	variables := lo.Map(m.queryVariables.FilterList(func(name string, variable *queryVariable) bool {
+		return true
+	}), func(variable *queryVariable, _ int) QueryVariableInfo {
+		return variable.QueryVariableInfo
+	})
+	slices.SortFunc(variables, func(a, b QueryVariableInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return variables
+}
+
+// ReplaceQueryVariable replaces {wox:<name>} and {wox:<name>:<arg>} with values of registered variables.
+// Unknown variables and variables failed to resolve are kept as is
+func (m *Manager) ReplaceQueryVariable(ctx context.Context, query string) string {
+	return m.ReplaceQueryVariableEscaped(ctx, query, nil)
+}
+
+// ReplaceQueryVariableEscaped is same as ReplaceQueryVariable, but resolved values are passed through escape before replacing,
+// E.g. escape "%" when query is used as a format string afterwards. escape can be nil
+func (m *Manager) ReplaceQueryVariableEscaped(ctx context.Context, query string, escape func(value string) string) string {
+	if !strings.Contains(query, "{wox:") {
+		return query
+	}
+
+	resolved := map[string]string{}
+	return queryVariableRegex.ReplaceAllStringFunc(query, func(placeholder string) string {
+		if value, exist := resolved[placeholder]; exist {
+			return value
+		}
+
+		value, isResolved := m.resolveQueryVariable(ctx, placeholder)
+		if !isResolved {
+			return placeholder
+		}
+
+		if escape != nil {
+			value = escape(value)
+		}
+		// same variable in one query always has the same value, E.g. {wox:uuid}
+		resolved[placeholder] = value
+		return value
+	})
+}
+
+func (m *Manager) resolveQueryVariable(ctx context.Context, placeholder string) (string, bool) {
+	match := queryVariableRegex.FindStringSubmatch(placeholder)
+	if match == nil {
+		return "", false
+	}
+	variable, exist := m.queryVariables.Load(match[1])
+	if !exist {
+		return "", false
+	}
+	value, resolveErr := variable.resolver(ctx, match[2])
+	if resolveErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to resolve query variable %s: %s", placeholder, resolveErr.Error()))
+		return "", false
+	}
+	return value, true
+}
+
+// SnapshotQueryVariables resolves variables used by query shortcuts, it should be called right before wox is shown.
+// Query shortcuts are expanded on every keystroke, variables such as {wox:active_window_title} can't be resolved then because wox has focus.
+// Selection variables are only resolved if query, which wox is about to show, expands to a shortcut using them. query is empty if wox is shown without one
+func (m *Manager) SnapshotQueryVariables(ctx context.Context, query string) {
+	m.snapshotQueryVariables(ctx, query, m.getQueryShortcuts(ctx))
+}
+
+func (m *Manager) snapshotQueryVariables(ctx context.Context, query string, queryShortcuts []setting.QueryShortcut) {
+	var queryShortcut setting.QueryShortcut
+	if query != "" {
+		if expansion := m.findQueryShortcutExpansion(ctx, query, queryShortcuts); expansion != nil {
+			queryShortcut = expansion.Shortcut
+		}
+	}
+
+	snapshot := map[string]string{}
+	for _, shortcut := range queryShortcuts {
+		for _, match := range queryVariableRegex.FindAllStringSubmatch(shortcut.Query, -1) {
+			placeholder := match[0]
+			if _, exist := snapshot[placeholder]; exist {
+				continue
+			}
+			if lo.Contains(selectionQueryVariableNames, match[1]) && shortcut.Shortcut != queryShortcut.Shortcut {
+				continue
+			}
+			if value, isResolved := m.resolveQueryVariable(ctx, placeholder); isResolved {
+				snapshot[placeholder] = value
+			}
+		}
+	}
+
+	// query hotkey takes selection variables before it shows wox, showing wox takes a snapshot again and must keep them until wox is hidden
+	if previous := m.queryVariableSnapshot.Load(); previous != nil {
+		for placeholder, value := range *previous {
+			if _, exist := snapshot[placeholder]; exist {
+				continue
+			}
+			if match := queryVariableRegex.FindStringSubmatch(placeholder); match != nil && lo.Contains(selectionQueryVariableNames, match[1]) {
+				snapshot[placeholder] = value
+			}
+		}
+	}
+	m.queryVariableSnapshot.Store(&snapshot)
+}
+
+// ClearQueryVariableSnapshot drops the snapshot after wox is hidden, query shortcuts keep variables unresolved until next snapshot
+func (m *Manager) ClearQueryVariableSnapshot() {
+	m.queryVariableSnapshot.Store(nil)
+}
+
+// getQueryVariableSnapshot returns value of placeholder in snapshot, placeholder is kept if it's not in snapshot
+func (m *Manager) getQueryVariableSnapshot(placeholder string) string {
+	snapshot := m.queryVariableSnapshot.Load()
+	if snapshot == nil {
+		return placeholder
+	}
+	if value, exist := (*snapshot)[placeholder]; exist {
+		return value
+	}
+	return placeholder
+}
+
+func (m *Manager) resolveSelectedText(ctx context.Context, arg string) (string, error) {
+	selection, selectedErr := util.GetSelected()
+	if selectedErr != nil {
+		return "", selectedErr
+	}
+	if selection.Type != util.SelectionTypeText {
+		return "", fmt.Errorf("selected data is not text, type: %s", selection.Type)
+	}
+	return selection.Text, nil
+}
+
+func (m *Manager) resolveFileSelection(ctx context.Context, arg string) (string, error) {
+	selection, selectedErr := util.GetSelected()
+	if selectedErr != nil {
+		return "", selectedErr
+	}
+	if selection.Type != util.SelectionTypeFile {
+		return "", fmt.Errorf("selected data is not file, type: %s", selection.Type)
+	}
+	return strings.Join(lo.Map(selection.FilePaths, func(filePath string, _ int) string {
+		if strings.Contains(filePath, " ") {
+			return fmt.Sprintf(`"%s"`, filePath)
+		}
+		return filePath
+	}), " "), nil
+}
+
+func resolveClipboard(ctx context.Context, arg string) (string, error) {
+	clipboardData, readErr := clipboard.ReadFilesAndText()
+	if readErr != nil {
+		return "", readErr
+	}
+	if clipboardData.GetType() != clipboard.ClipboardTypeText {
+		return "", fmt.Errorf("clipboard data is not text, type: %s", clipboardData.GetType())
+	}
+	return clipboardData.String(), nil
+}
@@ -8,11 +8,13 @@ const (
 	PLUGIN_RUNTIME_GO     Runtime = "GO"
 	PLUGIN_RUNTIME_PYTHON Runtime = "PYTHON"
 	PLUGIN_RUNTIME_NODEJS Runtime = "NODEJS"
+	PLUGIN_RUNTIME_SCRIPT Runtime = "SCRIPT" // any executable speaking line-delimited json over stdin/stdout
+	PLUGIN_RUNTIME_WASM   Runtime = "WASM"   // sandboxed webassembly module running in process
 )
 
 func IsSupportedRuntime(runtime string) bool {
 	runtimeUpper := strings.ToUpper(runtime)
-	return runtimeUpper == string(PLUGIN_RUNTIME_PYTHON) || runtimeUpper == string(PLUGIN_RUNTIME_NODEJS) || runtimeUpper == string(PLUGIN_RUNTIME_GO)
+	return runtimeUpper == string(PLUGIN_RUNTIME_PYTHON) || runtimeUpper == string(PLUGIN_RUNTIME_NODEJS) || runtimeUpper == string(PLUGIN_RUNTIME_GO) || runtimeUpper == string(PLUGIN_RUNTIME_SCRIPT) || runtimeUpper == string(PLUGIN_RUNTIME_WASM)
 }
 
 func ConvertToRuntime(runtime string) Runtime {
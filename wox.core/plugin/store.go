@@ -8,17 +8,33 @@ import (
 	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
+	"wox/setting"
 	"wox/util"
 
 	"github.com/Masterminds/semver/v3"
+	cp "github.com/otiai10/copy"
 	"github.com/samber/lo"
 )
 
+const officialStoreUrl = "https://raw.githubusercontent.com/Wox-launcher/Wox/v2/store-plugin.json"
+
+// local store sources can be a manifest json file, or a directory which contains this file
+const localStoreManifestFileName = "store-plugin.json"
+
 type storeManifest struct {
-	Name string
-	Url  string
+	Name     string
+	Url      string
+	Priority int
+}
+
+// IsLocal returns true if the store manifest is located in local file system instead of http server
+func (s storeManifest) IsLocal() bool {
+	return isLocalStoreUrl(s.Url)
 }
 
 type StorePluginManifest struct {
@@ -32,9 +48,30 @@ type StorePluginManifest struct {
 	IconUrl        string
 	Website        string
 	DownloadUrl    string
+	DownloadUrls   map[string]string // per platform artifacts (e.g. go plugins), key is "<os>-<arch>" like "darwin-arm64", falls back to DownloadUrl
 	ScreenshotUrls []string
+	Tags           []string // used as categories when browsing store
+	SupportedOS    []string // empty means all os are supported
 	DateCreated    string
 	DateUpdated    string
+
+	// which store source this plugin comes from, filled when loading manifests
+	SourceName     string
+	SourceUrl      string
+	SourcePriority int
+}
+
+// GetDownloadUrl returns the artifact for current os and arch, or DownloadUrl if there is no platform specific one
+func (m StorePluginManifest) GetDownloadUrl() (string, error) {
+	platform := fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)
+	if downloadUrl, ok := m.DownloadUrls[platform]; ok && downloadUrl != "" {
+		return downloadUrl, nil
+	}
+	if m.DownloadUrl == "" {
+		return "", fmt.Errorf("plugin %s(%s) doesn't provide a package for %s", m.Name, m.Version, platform)
+	}
+
+	return m.DownloadUrl, nil
 }
 
 var storeInstance *Store
@@ -52,12 +89,38 @@ func GetStoreManager() *Store {
 }
 
 func (s *Store) getStoreManifests(ctx context.Context) []storeManifest {
-	return []storeManifest{
+	var stores = []storeManifest{
 		{
 			Name: "Wox Official Plugin Store",
-			Url:  "https://raw.githubusercontent.com/Wox-launcher/Wox/v2/store-plugin.json",
+			Url:  officialStoreUrl,
 		},
 	}
+
+	for _, source := range setting.GetSettingManager().GetWoxSetting(ctx).PluginStoreSources {
+		if source.Url == "" {
+			continue
+		}
+
+		// user may override or disable the official store by adding a source with same url
+		stores = lo.Filter(stores, func(store storeManifest, _ int) bool {
+			return store.Url != source.Url
+		})
+		if source.Disabled {
+			continue
+		}
+
+		name := source.Name
+		if name == "" {
+			name = source.Url
+		}
+		stores = append(stores, storeManifest{
+			Name:     name,
+			Url:      source.Url,
+			Priority: source.Priority,
+		})
+	}
+
+	return stores
 }
 
 // get plugin manifests from plugin stores, and update in the background every 10 minutes
@@ -65,65 +128,100 @@ func (s *Store) Start(ctx context.Context) {
 	s.pluginManifests = s.GetStorePluginManifests(ctx)
 
 	util.Go(ctx, "load store plugins immediately", func() {
-		pluginManifests := s.GetStorePluginManifests(util.NewTraceContext())
-		if len(pluginManifests) > 0 {
-			s.pluginManifests = pluginManifests
-		}
+		s.Refresh(util.NewTraceContext())
 	})
 
 	util.Go(ctx, "load store plugins", func() {
 		for range time.NewTicker(time.Minute * 10).C {
-			pluginManifests := s.GetStorePluginManifests(util.NewTraceContext())
-			if len(pluginManifests) > 0 {
-				s.pluginManifests = pluginManifests
-			}
+			s.Refresh(util.NewTraceContext())
 		}
 	})
 }
 
+// Refresh reloads plugin manifests from all store sources, e.g. after store sources changed
+func (s *Store) Refresh(ctx context.Context) {
+	pluginManifests := s.GetStorePluginManifests(ctx)
+	if len(pluginManifests) > 0 {
+		s.pluginManifests = pluginManifests
+	}
+}
+
 func (s *Store) GetStorePluginManifests(ctx context.Context) []StorePluginManifest {
 	var storePluginManifests []StorePluginManifest
 
 	for _, store := range s.getStoreManifests(ctx) {
-		pluginManifest, manifestErr := s.GetStorePluginManifest(ctx, store)
+		pluginManifests, manifestErr := s.GetStorePluginManifest(ctx, store)
 		if manifestErr != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to get plugin manifest from %s store: %s", store.Name, manifestErr.Error()))
 			continue
 		}
 
-		for _, manifest := range pluginManifest {
-			existingManifest, found := lo.Find(storePluginManifests, func(manifest StorePluginManifest) bool {
-				return manifest.Id == manifest.Id
-			})
-			if found {
-				existingVersion, existingErr := semver.NewVersion(existingManifest.Version)
-				currentVersion, currentErr := semver.NewVersion(manifest.Version)
-				if existingErr != nil && currentErr != nil {
-					if existingVersion.GreaterThan(currentVersion) {
-						logger.Info(ctx, fmt.Sprintf("skip %s(%s) from %s store, because it's already installed(%s)", manifest.Name, manifest.Version, store.Name, existingManifest.Version))
-						continue
-					}
-				}
-			}
-
-			storePluginManifests = append(storePluginManifests, manifest)
-		}
+		storePluginManifests = mergeStorePluginManifests(ctx, storePluginManifests, pluginManifests)
 	}
 
 	logger.Info(ctx, fmt.Sprintf("found %d plugins from stores", len(storePluginManifests)))
 	return storePluginManifests
 }
 
+// mergeStorePluginManifests merges manifests from a new store into existing ones.
+// If the same plugin id exists in multiple stores, the one from higher priority store wins,
+// if the priorities are the same, the one with newer version wins
+func mergeStorePluginManifests(ctx context.Context, existingManifests []StorePluginManifest, newManifests []StorePluginManifest) []StorePluginManifest {
+	for _, manifest := range newManifests {
+		existingManifest, existingIndex, found := lo.FindIndexOf(existingManifests, func(item StorePluginManifest) bool {
+			return item.Id == manifest.Id
+		})
+		if !found {
+			existingManifests = append(existingManifests, manifest)
+			continue
+		}
+
+		if existingManifest.SourcePriority > manifest.SourcePriority {
+			logger.Info(ctx, fmt.Sprintf("skip %s(%s) from %s store, because %s store has higher priority", manifest.Name, manifest.Version, manifest.SourceName, existingManifest.SourceName))
+			continue
+		}
+		if existingManifest.SourcePriority == manifest.SourcePriority {
+			existingVersion, existingErr := semver.NewVersion(existingManifest.Version)
+			currentVersion, currentErr := semver.NewVersion(manifest.Version)
+			if existingErr != nil || currentErr != nil || !currentVersion.GreaterThan(existingVersion) {
+				logger.Info(ctx, fmt.Sprintf("skip %s(%s) from %s store, because %s store has same or newer version(%s)", manifest.Name, manifest.Version, manifest.SourceName, existingManifest.SourceName, existingManifest.Version))
+				continue
+			}
+		}
+
+		existingManifests[existingIndex] = manifest
+	}
+
+	return existingManifests
+}
+
 func (s *Store) GetStorePluginManifest(ctx context.Context, store storeManifest) ([]StorePluginManifest, error) {
 	logger.Info(ctx, fmt.Sprintf("start to get plugin manifest from %s(%s)", store.Name, store.Url))
 
-	response, getErr := util.HttpGet(ctx, store.Url)
-	if getErr != nil {
-		return nil, getErr
+	var manifestContent []byte
+	var localStoreDirectory string
+	if store.IsLocal() {
+		manifestPath := getLocalStorePath(store.Url)
+		if util.IsDirExists(manifestPath) {
+			manifestPath = filepath.Join(manifestPath, localStoreManifestFileName)
+		}
+		localStoreDirectory = filepath.Dir(manifestPath)
+
+		content, readErr := os.ReadFile(manifestPath)
+		if readErr != nil {
+			return nil, readErr
+		}
+		manifestContent = content
+	} else {
+		response, getErr := util.HttpGet(ctx, store.Url)
+		if getErr != nil {
+			return nil, getErr
+		}
+		manifestContent = response
 	}
 
 	var storePluginManifests []StorePluginManifest
-	unmarshalErr := json.Unmarshal(response, &storePluginManifests)
+	unmarshalErr := json.Unmarshal(manifestContent, &storePluginManifests)
 	if unmarshalErr != nil {
 		return nil, unmarshalErr
 	}
@@ -132,11 +230,38 @@ func (s *Store) GetStorePluginManifest(ctx context.Context, store storeManifest)
 		if IsSupportedRuntime(string(storePluginManifests[i].Runtime)) {
 			storePluginManifests[i].Runtime = ConvertToRuntime(string(storePluginManifests[i].Runtime))
 		}
+
+		// relative download urls in local stores are relative to the store directory
+		if localStoreDirectory != "" && !isLocalStoreUrl(storePluginManifests[i].DownloadUrl) && !isHttpStoreUrl(storePluginManifests[i].DownloadUrl) {
+			storePluginManifests[i].DownloadUrl = filepath.Join(localStoreDirectory, storePluginManifests[i].DownloadUrl)
+		}
+
+		storePluginManifests[i].SourceName = store.Name
+		storePluginManifests[i].SourceUrl = store.Url
+		storePluginManifests[i].SourcePriority = store.Priority
 	}
 
 	return storePluginManifests, nil
 }
 
+func isHttpStoreUrl(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+func isLocalStoreUrl(url string) bool {
+	return strings.HasPrefix(url, "file://") || filepath.IsAbs(url)
+}
+
+// getLocalStorePath converts file:// url to local file system path
+func getLocalStorePath(url string) string {
+	localPath := strings.TrimPrefix(url, "file://")
+	// file:///C:/plugins on windows
+	if util.IsWindows() && strings.HasPrefix(localPath, "/") && filepath.VolumeName(localPath[1:]) != "" {
+		localPath = localPath[1:]
+	}
+	return filepath.FromSlash(localPath)
+}
+
 func (s *Store) GetStorePluginManifestById(ctx context.Context, id string) (StorePluginManifest, error) {
 	manifest, found := lo.Find(s.pluginManifests, func(manifest StorePluginManifest) bool {
 		return manifest.Id == id
@@ -148,16 +273,6 @@ func (s *Store) GetStorePluginManifestById(ctx context.Context, id string) (Stor
 	return StorePluginManifest{}, fmt.Errorf("plugin %s not found", id)
 }
 
-func (s *Store) Search(ctx context.Context, keyword string) []StorePluginManifest {
-	return lo.Filter(s.pluginManifests, func(manifest StorePluginManifest, _ int) bool {
-		if keyword == "" {
-			return true
-		}
-
-		return util.IsStringMatch(manifest.Name, keyword, false)
-	})
-}
-
 func (s *Store) Install(ctx context.Context, manifest StorePluginManifest) error {
 	logger.Info(ctx, fmt.Sprintf("start to install plugin %s(%s)", manifest.Name, manifest.Version))
 
@@ -189,8 +304,14 @@ func (s *Store) Install(ctx context.Context, manifest StorePluginManifest) error
 		}
 	}
 
+	downloadUrl, downloadUrlErr := manifest.GetDownloadUrl()
+	if downloadUrlErr != nil {
+		logger.Error(ctx, downloadUrlErr.Error())
+		return downloadUrlErr
+	}
+
 	// download plugin
-	logger.Info(ctx, fmt.Sprintf("start to download plugin: %s", manifest.DownloadUrl))
+	logger.Info(ctx, fmt.Sprintf("start to download plugin: %s", downloadUrl))
 	pluginDirectory := path.Join(util.GetLocation().GetPluginDirectory(), fmt.Sprintf("%s_%s@%s", manifest.Id, manifest.Name, manifest.Version))
 	directoryErr := util.GetLocation().EnsureDirectoryExist(pluginDirectory)
 	if directoryErr != nil {
@@ -198,7 +319,12 @@ func (s *Store) Install(ctx context.Context, manifest StorePluginManifest) error
 		return fmt.Errorf("failed to create plugin directory %s: %s", pluginDirectory, directoryErr.Error())
 	}
 	pluginZipPath := path.Join(pluginDirectory, "plugin.zip")
-	downloadErr := util.HttpDownload(ctx, manifest.DownloadUrl, pluginZipPath)
+	var downloadErr error
+	if isLocalStoreUrl(downloadUrl) {
+		downloadErr = cp.Copy(getLocalStorePath(downloadUrl), pluginZipPath)
+	} else {
+		downloadErr = util.HttpDownload(ctx, downloadUrl, pluginZipPath)
+	}
 	if downloadErr != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to download plugin %s(%s): %s", manifest.Name, manifest.Version, downloadErr.Error()))
 		removeErr := os.Remove(pluginZipPath)
@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"wox/util"
+
+	"github.com/samber/lo"
+)
+
+type StoreSearchOptions struct {
+	Keyword string  // match against name, description and tags
+	Tag     string  // only return plugins with this tag, case-insensitive
+	OS      string  // only return plugins supporting this os, e.g. windows, macos, linux
+	Runtime Runtime // only return plugins with this runtime
+	Offset  int
+	Limit   int // 0 means no limit
+}
+
+type StoreSearchResult struct {
+	Manifests []StorePluginManifest
+	Total     int // total count of matched plugins before pagination
+}
+
+const (
+	storeSearchScoreNameExact        = 1000
+	storeSearchScoreNamePrefix       = 500
+	storeSearchScoreNameMatch        = 300
+	storeSearchScoreTagMatch         = 200
+	storeSearchScoreDescriptionMatch = 100
+)
+
+func (s *Store) Search(ctx context.Context, options StoreSearchOptions) StoreSearchResult {
+	type scoredManifest struct {
+		manifest StorePluginManifest
+		score    int64
+	}
+
+	var matched []scoredManifest
+	for _, manifest := range s.pluginManifests {
+		if !isStoreManifestMatchFilters(manifest, options) {
+			continue
+		}
+
+		score, isMatch := getStoreManifestSearchScore(manifest, options.Keyword)
+		if !isMatch {
+			continue
+		}
+
+		matched = append(matched, scoredManifest{manifest: manifest, score: score})
+	}
+
+	// keep store order for plugins with same score
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].score > matched[j].score
+	})
+
+	result := StoreSearchResult{Total: len(matched)}
+	if options.Offset > 0 {
+		if options.Offset >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[options.Offset:]
+		}
+	}
+	if options.Limit > 0 && len(matched) > options.Limit {
+		matched = matched[:options.Limit]
+	}
+
+	result.Manifests = lo.Map(matched, func(item scoredManifest, _ int) StorePluginManifest {
+		return item.manifest
+	})
+	return result
+}
+
+// GetTags returns all tags used by store plugins, which can be used as categories
+func (s *Store) GetTags(ctx context.Context) []string {
+	var tags []string
+	for _, manifest := range s.pluginManifests {
+		for _, tag := range manifest.Tags {
+			if !lo.ContainsBy(tags, func(item string) bool { return strings.EqualFold(item, tag) }) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	sort.Strings(tags)
+	return tags
+}
+
+func isStoreManifestMatchFilters(manifest StorePluginManifest, options StoreSearchOptions) bool {
+	if options.Tag != "" && !lo.ContainsBy(manifest.Tags, func(tag string) bool { return strings.EqualFold(tag, options.Tag) }) {
+		return false
+	}
+
+	if options.Runtime != "" && ConvertToRuntime(string(manifest.Runtime)) != ConvertToRuntime(string(options.Runtime)) {
+		return false
+	}
+
+	// plugins without supported os declared are treated as supporting all os
+	if options.OS != "" && len(manifest.SupportedOS) > 0 {
+		if !lo.ContainsBy(manifest.SupportedOS, func(os string) bool { return normalizeStoreOS(os) == normalizeStoreOS(options.OS) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getStoreManifestSearchScore(manifest StorePluginManifest, keyword string) (int64, bool) {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if keyword == "" {
+		return 0, true
+	}
+
+	var score int64
+	name := strings.ToLower(manifest.Name)
+	if name == keyword {
+		score += storeSearchScoreNameExact
+	} else if strings.HasPrefix(name, keyword) {
+		score += storeSearchScoreNamePrefix
+	} else if isMatch, matchScore := util.IsStringMatchScore(manifest.Name, keyword, false); isMatch {
+		score += storeSearchScoreNameMatch + matchScore
+	}
+
+	if lo.ContainsBy(manifest.Tags, func(tag string) bool { return strings.Contains(strings.ToLower(tag), keyword) }) {
+		score += storeSearchScoreTagMatch
+	}
+
+	if strings.Contains(strings.ToLower(manifest.Description), keyword) {
+		score += storeSearchScoreDescriptionMatch
+	}
+
+	return score, score > 0
+}
+
+func normalizeStoreOS(os string) string {
+	os = strings.ToUpper(os)
+	if os == "MACOS" || os == "MAC" || os == "OSX" {
+		return string(PLUGIN_OS_DARWIN)
+	}
+	return os
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"wox/util"
+)
+
+func Test_MergeStorePluginManifests(t *testing.T) {
+	ctx := util.NewTraceContext()
+	GetPluginManager()
+
+	official := []StorePluginManifest{
+		{Id: "a", Version: "1.0.0", SourceName: "official"},
+		{Id: "b", Version: "1.0.0", SourceName: "official"},
+	}
+	company := []StorePluginManifest{
+		{Id: "a", Version: "0.9.0", SourceName: "company", SourcePriority: 10},
+		{Id: "c", Version: "1.0.0", SourceName: "company", SourcePriority: 10},
+	}
+	mirror := []StorePluginManifest{
+		{Id: "b", Version: "1.1.0", SourceName: "mirror"},
+		{Id: "c", Version: "2.0.0", SourceName: "mirror"},
+	}
+
+	merged := mergeStorePluginManifests(ctx, nil, official)
+	merged = mergeStorePluginManifests(ctx, merged, company)
+	merged = mergeStorePluginManifests(ctx, merged, mirror)
+
+	assert.Len(t, merged, 3)
+	sources := map[string]string{}
+	for _, manifest := range merged {
+		sources[manifest.Id] = manifest.SourceName
+	}
+	assert.Equal(t, "company", sources["a"]) // higher priority wins even with older version
+	assert.Equal(t, "mirror", sources["b"])  // same priority, newer version wins
+	assert.Equal(t, "company", sources["c"]) // lower priority is skipped
+}
+
+func Test_LocalStorePluginManifest(t *testing.T) {
+	ctx := util.NewTraceContext()
+	GetPluginManager()
+
+	storeDirectory := t.TempDir()
+	manifestJson := `[{"Id":"a","Name":"Internal","Version":"1.0.0","Runtime":"python","DownloadUrl":"internal.wox"}]`
+	assert.Nil(t, os.WriteFile(filepath.Join(storeDirectory, localStoreManifestFileName), []byte(manifestJson), 0644))
+
+	manifests, err := GetStoreManager().GetStorePluginManifest(ctx, storeManifest{Name: "company", Url: storeDirectory, Priority: 5})
+	assert.Nil(t, err)
+	assert.Len(t, manifests, 1)
+	assert.Equal(t, PLUGIN_RUNTIME_PYTHON, manifests[0].Runtime)
+	assert.Equal(t, filepath.Join(storeDirectory, "internal.wox"), manifests[0].DownloadUrl)
+	assert.Equal(t, "company", manifests[0].SourceName)
+	assert.Equal(t, 5, manifests[0].SourcePriority)
+}
+
+func Test_StoreSearch(t *testing.T) {
+	ctx := util.NewTraceContext()
+	store := &Store{pluginManifests: []StorePluginManifest{
+		{Id: "1", Name: "Clipboard Tools", Description: "manage your git snippets", Runtime: PLUGIN_RUNTIME_NODEJS},
+		{Id: "2", Name: "Github", Description: "search repositories", Tags: []string{"Dev"}, Runtime: PLUGIN_RUNTIME_PYTHON, SupportedOS: []string{"Macos"}},
+		{Id: "3", Name: "Git", Tags: []string{"dev"}, Runtime: PLUGIN_RUNTIME_PYTHON},
+		{Id: "4", Name: "Gitlab", Tags: []string{"dev"}, Runtime: PLUGIN_RUNTIME_NODEJS, SupportedOS: []string{"Linux"}},
+	}}
+
+	result := store.Search(ctx, StoreSearchOptions{Keyword: "git"})
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, []string{"3", "2", "4", "1"}, lo.Map(result.Manifests, func(m StorePluginManifest, _ int) string { return m.Id }))
+
+	result = store.Search(ctx, StoreSearchOptions{Tag: "DEV", Runtime: "python"})
+	assert.Equal(t, []string{"2", "3"}, lo.Map(result.Manifests, func(m StorePluginManifest, _ int) string { return m.Id }))
+
+	result = store.Search(ctx, StoreSearchOptions{OS: "darwin"})
+	assert.Equal(t, []string{"1", "2", "3"}, lo.Map(result.Manifests, func(m StorePluginManifest, _ int) string { return m.Id }))
+
+	result = store.Search(ctx, StoreSearchOptions{Offset: 1, Limit: 2})
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, []string{"2", "3"}, lo.Map(result.Manifests, func(m StorePluginManifest, _ int) string { return m.Id }))
+}
+
+func Test_StorePluginManifestDownloadUrl(t *testing.T) {
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	manifest := StorePluginManifest{
+		Name:         "go plugin",
+		DownloadUrl:  "https://example.com/plugin.zip",
+		DownloadUrls: map[string]string{platform: "https://example.com/plugin-" + platform + ".zip"},
+	}
+	downloadUrl, downloadUrlErr := manifest.GetDownloadUrl()
+	assert.Nil(t, downloadUrlErr)
+	assert.Equal(t, "https://example.com/plugin-"+platform+".zip", downloadUrl)
+
+	manifest.DownloadUrls = map[string]string{"plan9-mips": "https://example.com/plugin-plan9.zip"}
+	downloadUrl, downloadUrlErr = manifest.GetDownloadUrl()
+	assert.Nil(t, downloadUrlErr)
+	assert.Equal(t, "https://example.com/plugin.zip", downloadUrl)
+
+	manifest.DownloadUrl = ""
+	_, downloadUrlErr = manifest.GetDownloadUrl()
+	assert.NotNil(t, downloadUrlErr)
+}
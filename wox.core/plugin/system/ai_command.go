@@ -379,21 +379,29 @@ func (c *Plugin) queryCommand(ctx context.Context, query plugin.Query) []plugin.
 		}
 	}
 
-	var prompts = strings.Split(aiCommandSetting.Prompt, "{wox:new_ai_conversation}")
-	var conversations []ai.Conversation
-	for index, message := range prompts {
-		msg := fmt.Sprintf(message, query.Search)
-		if index%2 == 0 {
-			conversations = append(conversations, ai.Conversation{
-				Role: ai.ConversationRoleUser,
-				Text: msg,
-			})
-		} else {
-			conversations = append(conversations, ai.Conversation{
-				Role: ai.ConversationRoleAI,
-				Text: msg,
+	// conversations are built when the command starts answering, so query variables are not resolved on every keystroke
+	chatStream := func(ctx context.Context, model ai.Model, _ []ai.Conversation, callback ai.ChatStreamFunc) error {
+		var prompts = strings.Split(aiCommandSetting.Prompt, "{wox:new_ai_conversation}")
+		var conversations []ai.Conversation
+		for index, message := range prompts {
+			// only variables in the prompt are resolved, never the ones typed in query. "%" in values is escaped for the format
+			message = plugin.GetPluginManager().ReplaceQueryVariableEscaped(ctx, message, func(value string) string {
+				return strings.ReplaceAll(value, "%", "%%")
 			})
+			msg := fmt.Sprintf(message, query.Search)
+			if index%2 == 0 {
+				conversations = append(conversations, ai.Conversation{
+					Role: ai.ConversationRoleUser,
+					Text: msg,
+				})
+			} else {
+				conversations = append(conversations, ai.Conversation{
+					Role: ai.ConversationRoleAI,
+					Text: msg,
+				})
+			}
 		}
+		return c.api.AIChatStream(ctx, model, conversations, callback)
 	}
 
 	onAnswering := func(current plugin.RefreshableResult, deltaAnswer string, isFinished bool) plugin.RefreshableResult {
@@ -418,7 +426,7 @@ func (c *Plugin) queryCommand(ctx context.Context, query plugin.Query) []plugin.
 		Preview:         plugin.WoxPreview{PreviewType: plugin.WoxPreviewTypeMarkdown, PreviewData: ""},
 		Icon:            aiCommandIcon,
 		RefreshInterval: 100,
-		OnRefresh: createLLMOnRefreshHandler(ctx, c.api.AIChatStream, aiCommandSetting.AIModel(), conversations, func() bool {
+		OnRefresh: createLLMOnRefreshHandler(ctx, chatStream, aiCommandSetting.AIModel(), nil, func() bool {
 			return true
 		}, nil, onAnswering, onAnswerErr),
 		Actions: []plugin.QueryResultAction{
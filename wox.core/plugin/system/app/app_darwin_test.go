@@ -40,7 +40,8 @@ func (e emptyAPIImpl) GetSetting(ctx context.Context, key string) string {
 	return ""
 }
 
-func (e emptyAPIImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
+func (e emptyAPIImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) error {
+	return nil
 }
 
 func (e emptyAPIImpl) OnSettingChanged(ctx context.Context, callback func(key string, value string)) {
@@ -59,6 +60,14 @@ func (e emptyAPIImpl) AIChatStream(ctx context.Context, model ai.Model, conversa
 	return nil
 }
 
+func (e emptyAPIImpl) PushResults(ctx context.Context, queryId string, results []plugin.QueryResult) error {
+	return nil
+}
+
+func (e emptyAPIImpl) RegisterQueryVariable(ctx context.Context, name string, description string, resolver plugin.QueryVariableResolver) error {
+	return nil
+}
+
 func TestMacRetriever_ParseAppInfo(t *testing.T) {
 	if util.IsMacOS() {
 		util.GetLocation().Init()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 	"wox/i18n"
 	"wox/plugin"
 	"wox/setting"
@@ -103,6 +104,7 @@ func (c *BackupPlugin) restore(ctx context.Context, query plugin.Query) []plugin
 			Title:    fmt.Sprintf("#%d", index+1),
 			SubTitle: fmt.Sprintf("%s - %s", backup.Type, util.FormatTimestamp(backup.Timestamp)),
 			Icon:     backupIcon,
+			Preview:  c.getRestorePreview(ctx, backup),
 			Actions: []plugin.QueryResultAction{
 				{
 					Name: "i18n:plugin_backup_restore",
@@ -115,9 +117,61 @@ func (c *BackupPlugin) restore(ctx context.Context, query plugin.Query) []plugin
 						}
 					},
 				},
+				{
+					Name: "i18n:plugin_backup_verify",
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						verifyErr := setting.GetSettingManager().VerifyBackup(ctx, backup.Id)
+						if verifyErr != nil {
+							c.api.Notify(ctx, verifyErr.Error())
+						} else {
+							c.api.Notify(ctx, i18n.GetI18nManager().TranslateWox(ctx, "plugin_backup_verify_success"))
+						}
+					},
+				},
 			},
 		})
 	}
 
 	return results
 }
+
+// getRestorePreview shows files which would be changed by restoring the backup
+func (c *BackupPlugin) getRestorePreview(ctx context.Context, backup setting.Backup) plugin.WoxPreview {
+	changes, err := setting.GetSettingManager().PreviewRestore(ctx, backup.Id)
+	if err != nil {
+		return plugin.WoxPreview{
+			PreviewType: plugin.WoxPreviewTypeText,
+			PreviewData: err.Error(),
+		}
+	}
+
+	var sb strings.Builder
+	if len(changes) == 0 {
+		sb.WriteString(i18n.GetI18nManager().TranslateWox(ctx, "plugin_backup_restore_no_change"))
+	} else {
+		sb.WriteString(fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_backup_restore_changes"), len(changes)))
+		sb.WriteString("\n\n")
+		for _, change := range changes {
+			sb.WriteString(fmt.Sprintf("- `%s` %s\n", change.Type, change.Path))
+		}
+	}
+
+	return plugin.WoxPreview{
+		PreviewType: plugin.WoxPreviewTypeMarkdown,
+		PreviewData: sb.String(),
+		PreviewProperties: map[string]string{
+			"i18n:plugin_backup_files": fmt.Sprintf("%d", backup.FileCount),
+			"i18n:plugin_backup_size":  formatBackupSize(backup.Size),
+		},
+	}
+}
+
+func formatBackupSize(size int64) string {
+	if size > 1024*1024 {
+		return fmt.Sprintf("%.1f MB", float64(size)/1024/1024)
+	}
+	if size > 1024 {
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%d B", size)
+}
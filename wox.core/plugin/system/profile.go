@@ -0,0 +1,96 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"wox/i18n"
+	"wox/plugin"
+	"wox/setting"
+)
+
+var profileIcon = plugin.PluginSysIcon
+
+func init() {
+	plugin.AllSystemPlugin = append(plugin.AllSystemPlugin, &ProfilePlugin{})
+}
+
+type ProfilePlugin struct {
+	api plugin.API
+}
+
+func (c *ProfilePlugin) GetMetadata() plugin.Metadata {
+	return plugin.Metadata{
+		Id:            "5d8a0d3e-2b1f-4c6e-9a47-3f0e8c2b71d4",
+		Name:          "Setting profiles",
+		Author:        "Wox Launcher",
+		Website:       "https://github.com/Wox-launcher/Wox",
+		Version:       "1.0.0",
+		MinWoxVersion: "2.0.0",
+		Runtime:       "Go",
+		Description:   "Switch between setting profiles",
+		Icon:          profileIcon.String(),
+		Entry:         "",
+		TriggerKeywords: []string{
+			"profile",
+		},
+		Commands: []plugin.MetadataCommand{},
+		SupportedOS: []string{
+			"Windows",
+			"Macos",
+			"Linux",
+		},
+	}
+}
+
+func (c *ProfilePlugin) Init(ctx context.Context, initParams plugin.InitParams) {
+	c.api = initParams.API
+}
+
+func (c *ProfilePlugin) Query(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	// empty profile id means default settings without any profile
+	profiles := append([]setting.Profile{{Id: "", Name: i18n.GetI18nManager().TranslateWox(ctx, "plugin_profile_default")}}, setting.GetSettingManager().GetProfiles(ctx)...)
+	activeProfileId := setting.GetSettingManager().GetActiveProfileId(ctx)
+
+	var results []plugin.QueryResult
+	for _, profile := range profiles {
+		if match, _ := IsStringMatchScore(ctx, profile.Name, query.Search); !match {
+			continue
+		}
+
+		result := plugin.QueryResult{
+			Title:    profile.Name,
+			SubTitle: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_profile_subtitle"), len(profile.WoxSettings), len(profile.PluginSettings)),
+			Icon:     profileIcon,
+			Actions: []plugin.QueryResultAction{
+				{
+					Name: "i18n:plugin_profile_switch",
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						switchErr := setting.GetSettingManager().SwitchProfile(ctx, profile.Id)
+						if switchErr != nil {
+							c.api.Notify(ctx, switchErr.Error())
+						} else {
+							c.api.Notify(ctx, fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_profile_switch_success"), profile.Name))
+						}
+					},
+				},
+			},
+		}
+		if profile.Id == activeProfileId {
+			result.Group = i18n.GetI18nManager().TranslateWox(ctx, "plugin_profile_group_active")
+			result.GroupScore = 100
+		} else {
+			result.Group = i18n.GetI18nManager().TranslateWox(ctx, "plugin_profile_group_available")
+			result.GroupScore = 50
+		}
+		if len(profile.Rules) > 0 {
+			result.Tails = append(result.Tails, plugin.QueryResultTail{
+				Type: plugin.QueryResultTailTypeText,
+				Text: i18n.GetI18nManager().TranslateWox(ctx, "plugin_profile_auto"),
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
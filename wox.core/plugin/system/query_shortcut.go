@@ -0,0 +1,143 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"wox/i18n"
+	"wox/plugin"
+	"wox/setting"
+	"wox/share"
+)
+
+var queryShortcutIcon = plugin.PluginSysIcon
+
+func init() {
+	plugin.AllSystemPlugin = append(plugin.AllSystemPlugin, &QueryShortcutPlugin{})
+}
+
+type QueryShortcutPlugin struct {
+	api plugin.API
+}
+
+func (c *QueryShortcutPlugin) GetMetadata() plugin.Metadata {
+	return plugin.Metadata{
+		Id:            "8f0c4b52-6d3e-4a1f-b7e9-2c5d9a1e3f60",
+		Name:          "Query shortcuts",
+		Author:        "Wox Launcher",
+		Website:       "https://github.com/Wox-launcher/Wox",
+		Version:       "1.0.0",
+		MinWoxVersion: "2.0.0",
+		Runtime:       "Go",
+		Description:   "List query shortcuts and preview how they expand",
+		Icon:          queryShortcutIcon.String(),
+		Entry:         "",
+		TriggerKeywords: []string{
+			"shortcut",
+		},
+		Commands: []plugin.MetadataCommand{},
+		SupportedOS: []string{
+			"Windows",
+			"Macos",
+			"Linux",
+		},
+	}
+}
+
+func (c *QueryShortcutPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
+	c.api = initParams.API
+}
+
+func (c *QueryShortcutPlugin) Query(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	shortcuts := slices.Clone(setting.GetSettingManager().GetWoxSetting(ctx).QueryShortcuts)
+	slices.SortFunc(shortcuts, func(a, b setting.QueryShortcut) int {
+		return strings.Compare(a.Shortcut, b.Shortcut)
+	})
+
+	var results []plugin.QueryResult
+	for _, shortcut := range shortcuts {
+		if match, _ := IsStringMatchScore(ctx, shortcut.Shortcut, query.Search); !match {
+			if match, _ = IsStringMatchScore(ctx, shortcut.Query, query.Search); !match {
+				continue
+			}
+		}
+
+		results = append(results, plugin.QueryResult{
+			Title:    shortcut.Shortcut,
+			SubTitle: shortcut.Query,
+			Icon:     queryShortcutIcon,
+			Preview: plugin.WoxPreview{
+				PreviewType: plugin.WoxPreviewTypeMarkdown,
+				PreviewData: c.formatParameters(ctx, shortcut.Parameters(), nil),
+			},
+			Actions: []plugin.QueryResultAction{
+				{
+					Name:                   "i18n:plugin_query_shortcut_use",
+					PreventHideAfterAction: true,
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						c.api.ChangeQuery(ctx, share.PlainQuery{
+							QueryType: plugin.QueryTypeInput,
+							QueryText: shortcut.Shortcut + " ",
+						})
+					},
+				},
+			},
+		})
+	}
+
+	return results
+}
+
+// PreviewQueryShortcut shows the expanded query on top of results, so user knows what will be queried
+func (c *QueryShortcutPlugin) PreviewQueryShortcut(ctx context.Context, query plugin.Query, expansion plugin.QueryShortcutExpansion) []plugin.QueryResult {
+	return []plugin.QueryResult{
+		{
+			Title:    expansion.ExpandedQuery,
+			SubTitle: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_query_shortcut_expanded_from"), expansion.Shortcut.Shortcut, expansion.Shortcut.Query),
+			Icon:     queryShortcutIcon,
+			Score:    100000,
+			Preview: plugin.WoxPreview{
+				PreviewType: plugin.WoxPreviewTypeMarkdown,
+				PreviewData: c.formatParameters(ctx, expansion.Shortcut.Parameters(), expansion.Parameters),
+			},
+			Actions: []plugin.QueryResultAction{
+				{
+					Name:                   "i18n:plugin_query_shortcut_edit",
+					PreventHideAfterAction: true,
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						c.api.ChangeQuery(ctx, share.PlainQuery{
+							QueryType: plugin.QueryTypeInput,
+							QueryText: expansion.ExpandedQuery,
+						})
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *QueryShortcutPlugin) formatParameters(ctx context.Context, parameters []setting.QueryShortcutParameter, values map[string]string) string {
+	if len(parameters) == 0 {
+		return i18n.GetI18nManager().TranslateWox(ctx, "plugin_query_shortcut_no_parameter")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n|---|---|---|\n",
+		i18n.GetI18nManager().TranslateWox(ctx, "plugin_query_shortcut_parameter"),
+		i18n.GetI18nManager().TranslateWox(ctx, "plugin_query_shortcut_default"),
+		i18n.GetI18nManager().TranslateWox(ctx, "plugin_query_shortcut_value"),
+	))
+	for _, parameter := range parameters {
+		name := parameter.Name
+		if parameter.IsRest {
+			name += "..."
+		}
+		value, found := values[parameter.Name]
+		if !found {
+			value = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", name, parameter.Default, value))
+	}
+	return sb.String()
+}
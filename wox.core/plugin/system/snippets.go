@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 	"wox/plugin"
+	"wox/setting"
 	"wox/share"
 	"wox/util"
 	"wox/util/clipboard"
@@ -21,6 +22,7 @@ const snippetsSettings = "snippets"
 
 func init() {
 	plugin.AllSystemPlugin = append(plugin.AllSystemPlugin, &snippetsPlugin{})
+	setting.RegisterPluginSettingSection("ee767db8-e2ca-48d2-94fc-f520879de380", "snippets")
 }
 
 type snippet struct {
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 	"wox/plugin"
+	"wox/setting"
 	"wox/setting/definition"
 	"wox/setting/validator"
 	"wox/util"
@@ -21,6 +22,7 @@ var defaultWebSearchAddedKey = "defaultWebSearchAdded"
 
 func init() {
 	plugin.AllSystemPlugin = append(plugin.AllSystemPlugin, &WebSearchPlugin{})
+	setting.RegisterPluginSettingSection("c1e350a7-c521-4dc3-b4ff-509f720fde86", "websearches")
 }
 
 type webSearch struct {
@@ -266,7 +268,7 @@ func (r *WebSearchPlugin) Query(ctx context.Context, query plugin.Query) (result
 						Action: func(ctx context.Context, actionContext plugin.ActionContext) {
 							util.Go(ctx, "open urls", func() {
 								for _, url := range search.Urls {
-									util.OpenHttp(r.replaceVariables(ctx, url, otherQuery))
+									util.OpenHttp(r.replaceUrlVariables(ctx, url, otherQuery))
 									time.Sleep(time.Millisecond * 100)
 								}
 							})
@@ -299,7 +301,7 @@ func (r *WebSearchPlugin) QueryFallback(ctx context.Context, query plugin.Query)
 					Icon: plugin.SearchIcon,
 					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
 						for _, url := range search.Urls {
-							util.OpenHttp(r.replaceVariables(ctx, url, query.RawQuery))
+							util.OpenHttp(r.replaceUrlVariables(ctx, url, query.RawQuery))
 							time.Sleep(time.Millisecond * 100)
 						}
 					},
@@ -331,7 +333,7 @@ func (r *WebSearchPlugin) querySelection(ctx context.Context, query plugin.Query
 					Icon: plugin.SearchIcon,
 					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
 						for _, url := range search.Urls {
-							util.OpenHttp(r.replaceVariables(ctx, url, query.Selection.Text))
+							util.OpenHttp(r.replaceUrlVariables(ctx, url, query.Selection.Text))
 							time.Sleep(time.Millisecond * 100)
 						}
 					},
@@ -349,3 +351,9 @@ func (r *WebSearchPlugin) replaceVariables(ctx context.Context, text string, que
 	result = strings.ReplaceAll(result, "{upper_query}", strings.ToUpper(query))
 	return result
 }
+
+// replaceUrlVariables also resolves wox query variables, E.g. {wox:clipboard}.
+// They are resolved only when url is opened, and before {query} is filled so query text is never treated as a variable
+func (r *WebSearchPlugin) replaceUrlVariables(ctx context.Context, url string, query string) string {
+	return r.replaceVariables(ctx, plugin.GetPluginManager().ReplaceQueryVariable(ctx, url), query)
+}
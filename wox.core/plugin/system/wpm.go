@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 	"wox/i18n"
 	"wox/plugin"
+	"wox/resource"
 	"wox/setting/definition"
 	"wox/share"
 	"wox/util"
@@ -24,9 +28,29 @@ var wpmIcon = plugin.PluginWPMIcon
 var localPluginDirectoriesKey = "local_plugin_directories"
 var pluginTemplates = []pluginTemplate{
 	{
-		Runtime: plugin.PLUGIN_RUNTIME_NODEJS,
-		Name:    "Wox.Plugin.Template.Nodejs",
-		Url:     "https://codeload.github.com/Wox-launcher/Wox.Plugin.Template.Nodejs/zip/refs/heads/main",
+		Id:       "python-minimal",
+		Name:     "i18n:plugin_wpm_template_python_minimal",
+		Runtimes: []plugin.Runtime{plugin.PLUGIN_RUNTIME_PYTHON},
+	},
+	{
+		Id:       "nodejs-typescript",
+		Name:     "i18n:plugin_wpm_template_nodejs_typescript",
+		Runtimes: []plugin.Runtime{plugin.PLUGIN_RUNTIME_NODEJS},
+	},
+	{
+		Id:       "query-shortcut-pack",
+		Name:     "i18n:plugin_wpm_template_query_shortcut_pack",
+		Runtimes: []plugin.Runtime{plugin.PLUGIN_RUNTIME_PYTHON, plugin.PLUGIN_RUNTIME_NODEJS},
+		Entries: map[plugin.Runtime]string{
+			plugin.PLUGIN_RUNTIME_PYTHON: "main.py",
+			plugin.PLUGIN_RUNTIME_NODEJS: "index.js",
+		},
+	},
+	{
+		Id:       "Wox.Plugin.Template.Nodejs",
+		Name:     "i18n:plugin_wpm_template_nodejs_full",
+		Runtimes: []plugin.Runtime{plugin.PLUGIN_RUNTIME_NODEJS},
+		Url:      "https://codeload.github.com/Wox-launcher/Wox.Plugin.Template.Nodejs/zip/refs/heads/main",
 	},
 }
 
@@ -34,24 +58,39 @@ type LocalPlugin struct {
 	Path string
 }
 
+// directories which are ignored when watching dev plugin source changes
+var devPluginIgnoredDirectories = []string{"node_modules", ".git", ".venv", "venv", "__pycache__", ".idea", ".vscode"}
+
 func init() {
 	plugin.AllSystemPlugin = append(plugin.AllSystemPlugin, &WPMPlugin{
 		reloadPluginTimers: util.NewHashMap[string, *time.Timer](),
+		reloadPluginErrors: util.NewHashMap[string, error](),
 	})
 }
 
 type WPMPlugin struct {
 	api                    plugin.API
 	creatingProcess        string
+	creation               *pluginCreation // plugin being filled by successive create queries, nil if not started
 	localPluginDirectories []string
 	localPlugins           []localPlugin
 	reloadPluginTimers     *util.HashMap[string, *time.Timer]
+	reloadPluginErrors     *util.HashMap[string, error] // last reload error of dev plugins, key is plugin id
 }
 
 type pluginTemplate struct {
-	Runtime plugin.Runtime
-	Name    string
-	Url     string
+	Id       string                    // directory name in resource/templates, or repository name for remote templates
+	Name     string                    // display name, support i18n
+	Runtimes []plugin.Runtime          // user will be asked to choose one if there are multiple runtimes
+	Entries  map[plugin.Runtime]string // entry file of each runtime, entries of other runtimes will be removed after creation
+	Url      string                    // remote template zip url, empty for templates bundled in resource
+}
+
+type pluginCreation struct {
+	template       pluginTemplate
+	name           string
+	triggerKeyword string
+	runtime        plugin.Runtime
 }
 
 type localPlugin struct {
@@ -145,7 +184,7 @@ func (w *WPMPlugin) Init(ctx context.Context, initParams plugin.InitParams) {
 
 		newCtx := util.NewTraceContext()
 		for _, lp := range w.localPlugins {
-			w.reloadLocalDistPlugin(newCtx, lp.metadata, "reload after startup")
+			w.reloadDevPlugin(newCtx, lp.metadata, "reload after startup")
 		}
 	})
 }
@@ -209,31 +248,67 @@ func (w *WPMPlugin) loadDevPlugin(ctx context.Context, pluginDirectory string) {
 		})
 	}
 
-	// watch dist directory changes and auto reload plugin
-	distDirectory := path.Join(pluginDirectory, "dist")
-	if _, statErr := os.Stat(distDirectory); statErr == nil {
-		watcher, watchErr := util.WatchDirectoryChanges(ctx, distDirectory, func(e fsnotify.Event) {
-			if e.Op != fsnotify.Chmod {
-				// debounce reload plugin to avoid reload multiple times in a short time
-				if t, ok := w.reloadPluginTimers.Load(metadata.Metadata.Id); ok {
-					t.Stop()
+	// watch source and dist directory changes and auto reload plugin
+	// watcher is assigned after WatchDirectoriesChanges returns, but events may arrive before that
+	var watcherPointer atomic.Pointer[fsnotify.Watcher]
+	watcher, watchErr := util.WatchDirectoriesChanges(ctx, w.getDevPluginWatchDirectories(ctx, pluginDirectory), func(e fsnotify.Event) {
+		if e.Op == fsnotify.Chmod {
+			return
+		}
+
+		// sub directories are not watched automatically, e.g. dist directory created by first build
+		currentWatcher := watcherPointer.Load()
+		if e.Has(fsnotify.Create) && currentWatcher != nil && util.IsDirExists(e.Name) && !lo.Contains(devPluginIgnoredDirectories, filepath.Base(e.Name)) {
+			for _, directory := range w.getDevPluginWatchDirectories(ctx, e.Name) {
+				if addErr := currentWatcher.Add(directory); addErr != nil {
+					w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to watch new directory: %s", addErr.Error()))
 				}
-				w.reloadPluginTimers.Store(metadata.Metadata.Id, time.AfterFunc(time.Second*2, func() {
-					w.reloadLocalDistPlugin(ctx, metadata, "dist directory changed")
-				}))
 			}
-		})
-		if watchErr != nil {
-			w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to watch dist directory: %s", watchErr.Error()))
-		} else {
-			w.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("Watching dist directory: %s", distDirectory))
-			lp.watcher = watcher
 		}
+
+		// debounce reload plugin to avoid reload multiple times in a short time
+		if t, ok := w.reloadPluginTimers.Load(metadata.Metadata.Id); ok {
+			t.Stop()
+		}
+		w.reloadPluginTimers.Store(metadata.Metadata.Id, time.AfterFunc(time.Second*2, func() {
+			w.reloadDevPlugin(util.NewTraceContext(), metadata, fmt.Sprintf("%s changed", path.Base(e.Name)))
+		}))
+	})
+	if watchErr != nil {
+		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to watch plugin directory: %s", watchErr.Error()))
+	} else {
+		w.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("Watching plugin directory: %s", pluginDirectory))
+		watcherPointer.Store(watcher)
+		lp.watcher = watcher
 	}
 
 	w.localPlugins = append(w.localPlugins, lp)
 }
 
+// getDevPluginWatchDirectories returns plugin directory and all its sub directories (including dist), except ignored ones
+func (w *WPMPlugin) getDevPluginWatchDirectories(ctx context.Context, pluginDirectory string) []string {
+	var directories []string
+	walkErr := filepath.WalkDir(pluginDirectory, func(currentPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if currentPath != pluginDirectory && lo.Contains(devPluginIgnoredDirectories, d.Name()) {
+			return filepath.SkipDir
+		}
+
+		directories = append(directories, currentPath)
+		return nil
+	})
+	if walkErr != nil {
+		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to walk plugin directory: %s", walkErr.Error()))
+	}
+
+	return directories
+}
+
 func (w *WPMPlugin) parseMetadata(ctx context.Context, directory string) (plugin.MetadataWithDirectory, error) {
 	// parse plugin.json in directory
 	metadata, metadataErr := plugin.GetPluginManager().ParseMetadata(ctx, directory)
@@ -295,26 +370,57 @@ func (w *WPMPlugin) createCommand(ctx context.Context, query plugin.Query) []plu
 		}
 	}
 
+	if w.creation == nil {
+		return w.createTemplateResults(ctx, query)
+	}
+
+	results := w.createStepResults(ctx, query)
+	results = append(results, plugin.QueryResult{
+		Id:       uuid.NewString(),
+		Title:    "i18n:plugin_wpm_create_cancel",
+		SubTitle: i18n.GetI18nManager().TranslateWox(ctx, w.creation.template.Name),
+		Icon:     wpmIcon,
+		Score:    -1,
+		Actions: []plugin.QueryResultAction{
+			{
+				Name:                   "i18n:plugin_wpm_create_cancel",
+				PreventHideAfterAction: true,
+				Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+					w.creation = nil
+					w.changeCreateQuery(ctx, query)
+				},
+			},
+		},
+	})
+	return results
+}
+
+func (w *WPMPlugin) createTemplateResults(ctx context.Context, query plugin.Query) []plugin.QueryResult {
 	var results []plugin.QueryResult
 	for _, template := range pluginTemplates {
+		templateName := i18n.GetI18nManager().TranslateWox(ctx, template.Name)
+		if query.Search != "" && !IsStringMatchNoPinYin(ctx, templateName, query.Search) {
+			continue
+		}
+
+		runtimes := lo.Map(template.Runtimes, func(runtime plugin.Runtime, _ int) string {
+			return string(runtime)
+		})
 		results = append(results, plugin.QueryResult{
 			Id:       uuid.NewString(),
-			Title:    fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_create_plugin"), string(template.Runtime)),
-			SubTitle: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_plugin_name"), query.Search),
+			Title:    templateName,
+			SubTitle: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_create_plugin"), strings.Join(runtimes, "/")),
 			Icon:     wpmIcon,
 			Actions: []plugin.QueryResultAction{
 				{
 					Name:                   "i18n:plugin_wpm_create",
 					PreventHideAfterAction: true,
 					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
-						pluginName := query.Search
-						util.Go(ctx, "create plugin", func() {
-							w.createPlugin(ctx, template, pluginName, query)
-						})
-						w.api.ChangeQuery(ctx, share.PlainQuery{
-							QueryType: plugin.QueryTypeInput,
-							QueryText: fmt.Sprintf("%s create ", query.TriggerKeyword),
-						})
+						w.creation = &pluginCreation{template: template}
+						if len(template.Runtimes) == 1 {
+							w.creation.runtime = template.Runtimes[0]
+						}
+						w.changeCreateQuery(ctx, query)
 					},
 				},
 			}})
@@ -323,6 +429,100 @@ func (w *WPMPlugin) createCommand(ctx context.Context, query plugin.Query) []plu
 	return results
 }
 
+// createStepResults asks user to fill plugin name, trigger keyword and runtime one by one
+func (w *WPMPlugin) createStepResults(ctx context.Context, query plugin.Query) []plugin.QueryResult {
+	creation := w.creation
+
+	if creation.name == "" || creation.triggerKeyword == "" {
+		titleKey := "plugin_wpm_create_input_name"
+		subTitle := "i18n:plugin_wpm_create_input_name_tip"
+		if creation.name != "" {
+			titleKey = "plugin_wpm_create_input_trigger_keyword"
+			subTitle = "i18n:plugin_wpm_create_input_trigger_keyword_tip"
+		}
+
+		return []plugin.QueryResult{
+			{
+				Id:       uuid.NewString(),
+				Title:    fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, titleKey), query.Search),
+				SubTitle: subTitle,
+				Icon:     wpmIcon,
+				Actions: []plugin.QueryResultAction{
+					{
+						Name:                   "i18n:plugin_wpm_next",
+						IsDefault:              true,
+						PreventHideAfterAction: true,
+						Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+							value := strings.TrimSpace(query.Search)
+							if value == "" {
+								w.api.Notify(ctx, "i18n:plugin_wpm_create_value_empty")
+								return
+							}
+
+							if creation.name == "" {
+								creation.name = value
+							} else {
+								// trigger keyword can not contain spaces
+								creation.triggerKeyword = strings.Fields(value)[0]
+							}
+							w.changeCreateQuery(ctx, query)
+						},
+					},
+				},
+			},
+		}
+	}
+
+	if creation.runtime == "" {
+		return lo.Map(creation.template.Runtimes, func(runtime plugin.Runtime, _ int) plugin.QueryResult {
+			return plugin.QueryResult{
+				Id:    uuid.NewString(),
+				Title: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_create_choose_runtime"), runtime),
+				Icon:  wpmIcon,
+				Actions: []plugin.QueryResultAction{
+					{
+						Name:                   "i18n:plugin_wpm_next",
+						PreventHideAfterAction: true,
+						Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+							creation.runtime = runtime
+							w.changeCreateQuery(ctx, query)
+						},
+					},
+				},
+			}
+		})
+	}
+
+	return []plugin.QueryResult{
+		{
+			Id:       uuid.NewString(),
+			Title:    fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_create_confirm"), creation.runtime, creation.name),
+			SubTitle: fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_create_confirm_detail"), i18n.GetI18nManager().TranslateWox(ctx, creation.template.Name), creation.triggerKeyword),
+			Icon:     wpmIcon,
+			Actions: []plugin.QueryResultAction{
+				{
+					Name:                   "i18n:plugin_wpm_create",
+					PreventHideAfterAction: true,
+					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
+						w.creation = nil
+						util.Go(ctx, "create plugin", func() {
+							w.createPlugin(ctx, *creation, query)
+						})
+						w.changeCreateQuery(ctx, query)
+					},
+				},
+			},
+		},
+	}
+}
+
+func (w *WPMPlugin) changeCreateQuery(ctx context.Context, query plugin.Query) {
+	w.api.ChangeQuery(ctx, share.PlainQuery{
+		QueryType: plugin.QueryTypeInput,
+		QueryText: fmt.Sprintf("%s create ", query.TriggerKeyword),
+	})
+}
+
 func (w *WPMPlugin) uninstallCommand(ctx context.Context, query plugin.Query) []plugin.QueryResult {
 	var results []plugin.QueryResult
 	plugins := plugin.GetPluginManager().GetPluginInstances()
@@ -362,8 +562,8 @@ func (w *WPMPlugin) uninstallCommand(ctx context.Context, query plugin.Query) []
 
 func (w *WPMPlugin) installCommand(ctx context.Context, query plugin.Query) []plugin.QueryResult {
 	var results []plugin.QueryResult
-	pluginManifests := plugin.GetStoreManager().Search(ctx, query.Search)
-	for _, pluginManifest := range pluginManifests {
+	searchResult := plugin.GetStoreManager().Search(ctx, w.parseStoreSearchOptions(query.Search))
+	for _, pluginManifest := range searchResult.Manifests {
 		screenShotsMarkdown := lo.Map(pluginManifest.ScreenshotUrls, func(screenshot string, _ int) string {
 			return fmt.Sprintf("![screenshot](%s)", screenshot)
 		})
@@ -392,6 +592,9 @@ func (w *WPMPlugin) installCommand(ctx context.Context, query plugin.Query) []pl
 					"Author":  pluginManifest.Author,
 					"Version": pluginManifest.Version,
 					"Website": pluginManifest.Website,
+					"Runtime": string(pluginManifest.Runtime),
+					"Tags":    strings.Join(pluginManifest.Tags, ", "),
+					"Source":  pluginManifest.SourceName,
 				},
 			},
 			Actions: []plugin.QueryResultAction{
@@ -409,20 +612,56 @@ func (w *WPMPlugin) installCommand(ctx context.Context, query plugin.Query) []pl
 	return results
 }
 
+// parseStoreSearchOptions parses filters from install search, e.g. "wpm install tag:dev os:linux runtime:python git"
+func (w *WPMPlugin) parseStoreSearchOptions(search string) plugin.StoreSearchOptions {
+	var options plugin.StoreSearchOptions
+	var keywords []string
+	for _, term := range strings.Fields(search) {
+		key, value, found := strings.Cut(term, ":")
+		if found && value != "" {
+			switch strings.ToLower(key) {
+			case "tag":
+				options.Tag = value
+				continue
+			case "os":
+				options.OS = value
+				continue
+			case "runtime":
+				options.Runtime = plugin.ConvertToRuntime(value)
+				continue
+			}
+		}
+
+		keywords = append(keywords, term)
+	}
+
+	options.Keyword = strings.Join(keywords, " ")
+	return options
+}
+
 func (w *WPMPlugin) listDevCommand(ctx context.Context) []plugin.QueryResult {
 	//list all local plugins
 	return lo.Map(w.localPlugins, func(lp localPlugin, _ int) plugin.QueryResult {
 		iconImage := plugin.ParseWoxImageOrDefault(lp.metadata.Metadata.Icon, wpmIcon)
 		iconImage = plugin.ConvertIcon(ctx, iconImage, lp.metadata.Directory)
 
+		// surface last load error inline, so developers don't need to dig into logs
+		subTitle := lp.metadata.Metadata.Description
+		loadError := "-"
+		if reloadErr, ok := w.reloadPluginErrors.Load(lp.metadata.Metadata.Id); ok {
+			subTitle = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_dev_load_error"), reloadErr.Error())
+			loadError = reloadErr.Error()
+		}
+
 		return plugin.QueryResult{
 			Id:       uuid.NewString(),
 			Title:    lp.metadata.Metadata.Name,
-			SubTitle: lp.metadata.Metadata.Description,
+			SubTitle: subTitle,
 			Icon:     iconImage,
 			Preview: plugin.WoxPreview{
 				PreviewType: plugin.WoxPreviewTypeMarkdown,
 				PreviewData: fmt.Sprintf(`
+- **LoadError**: %s
 - **Directory**: %s
 - **Name**: %s  
 - **Description**: %s
@@ -436,7 +675,7 @@ func (w *WPMPlugin) listDevCommand(ctx context.Context) []plugin.QueryResult {
 - **Commands**: %s
 - **SupportedOS**: %s
 - **Features**: %s
-`, lp.metadata.Directory, lp.metadata.Metadata.Name, lp.metadata.Metadata.Description, lp.metadata.Metadata.Author,
+`, loadError, lp.metadata.Directory, lp.metadata.Metadata.Name, lp.metadata.Metadata.Description, lp.metadata.Metadata.Author,
 					lp.metadata.Metadata.Website, lp.metadata.Metadata.Version, lp.metadata.Metadata.MinWoxVersion,
 					lp.metadata.Metadata.Runtime, lp.metadata.Metadata.Entry, lp.metadata.Metadata.TriggerKeywords,
 					lp.metadata.Metadata.Commands, lp.metadata.Metadata.SupportedOS, lp.metadata.Metadata.Features),
@@ -446,7 +685,7 @@ func (w *WPMPlugin) listDevCommand(ctx context.Context) []plugin.QueryResult {
 					Name:      "i18n:plugin_wpm_reload",
 					IsDefault: true,
 					Action: func(ctx context.Context, actionContext plugin.ActionContext) {
-						w.reloadLocalDistPlugin(ctx, lp.metadata, "reload by user")
+						w.reloadDevPlugin(ctx, lp.metadata, "reload by user")
 					},
 				},
 				{
@@ -500,7 +739,7 @@ func (w *WPMPlugin) reloadDevCommand(ctx context.Context) []plugin.QueryResult {
 						util.Go(ctx, "reload dev plugins in dist", func() {
 							newCtx := util.NewTraceContext()
 							for _, lp := range w.localPlugins {
-								w.reloadLocalDistPlugin(newCtx, lp.metadata, "reload after user action")
+								w.reloadDevPlugin(newCtx, lp.metadata, "reload after user action")
 							}
 						})
 					},
@@ -551,7 +790,8 @@ func (w *WPMPlugin) removeDevCommand(ctx context.Context, query plugin.Query) []
 	return []plugin.QueryResult{}
 }
 
-func (w *WPMPlugin) createPlugin(ctx context.Context, template pluginTemplate, pluginName string, query plugin.Query) {
+func (w *WPMPlugin) createPlugin(ctx context.Context, creation pluginCreation, query plugin.Query) {
+	template := creation.template
 	w.creatingProcess = "i18n:plugin_wpm_downloading_template"
 
 	tempPluginDirectory := path.Join(os.TempDir(), uuid.NewString())
@@ -561,39 +801,65 @@ func (w *WPMPlugin) createPlugin(ctx context.Context, template pluginTemplate, p
 		return
 	}
 
-	w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_downloading_template_to"), template.Runtime, tempPluginDirectory)
-	tempZipPath := path.Join(tempPluginDirectory, "template.zip")
-	err := util.HttpDownload(ctx, template.Url, tempZipPath)
-	if err != nil {
-		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to download template: %s", err.Error()))
-		w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_download_template_failed"), err.Error())
-		return
-	}
+	var templateDirectory string
+	if template.Url == "" {
+		// bundled templates work offline
+		w.creatingProcess = "i18n:plugin_wpm_extracting_template"
+		extractErr := resource.ExtractPluginTemplate(ctx, template.Id, tempPluginDirectory)
+		if extractErr != nil {
+			w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to extract template: %s", extractErr.Error()))
+			w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_extract_template_failed"), extractErr.Error())
+			return
+		}
+		templateDirectory = path.Join(tempPluginDirectory, template.Id)
+	} else {
+		w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_downloading_template_to"), creation.runtime, tempPluginDirectory)
+		tempZipPath := path.Join(tempPluginDirectory, "template.zip")
+		err := util.HttpDownload(ctx, template.Url, tempZipPath)
+		if err != nil {
+			w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to download template: %s", err.Error()))
+			w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_download_template_failed"), err.Error())
+			return
+		}
 
-	w.creatingProcess = "i18n:plugin_wpm_extracting_template"
-	err = util.Unzip(tempZipPath, tempPluginDirectory)
-	if err != nil {
-		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to extract template: %s", err.Error()))
-		w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_extract_template_failed"), err.Error())
-		return
+		w.creatingProcess = "i18n:plugin_wpm_extracting_template"
+		err = util.Unzip(tempZipPath, tempPluginDirectory)
+		if err != nil {
+			w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to extract template: %s", err.Error()))
+			w.creatingProcess = fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_extract_template_failed"), err.Error())
+			return
+		}
+		templateDirectory = path.Join(tempPluginDirectory, template.Id+"-main")
 	}
 
 	w.creatingProcess = "i18n:plugin_wpm_choose_directory_prompt"
 	pluginDirectories := plugin.GetPluginManager().GetUI().PickFiles(ctx, share.PickFilesParams{IsDirectory: true})
 	if len(pluginDirectories) == 0 {
 		w.api.Notify(ctx, "You need to choose a directory to create the plugin")
+		w.creatingProcess = ""
 		return
 	}
-	pluginDirectory := path.Join(pluginDirectories[0], pluginName)
+	pluginDirectory := path.Join(pluginDirectories[0], creation.name)
 	w.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("Creating plugin in directory: %s", pluginDirectory))
 
-	cpErr := cp.Copy(path.Join(tempPluginDirectory, template.Name+"-main"), pluginDirectory)
+	cpErr := cp.Copy(templateDirectory, pluginDirectory)
 	if cpErr != nil {
 		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to copy template: %s", cpErr.Error()))
 		w.creatingProcess = fmt.Sprintf("Failed to copy template: %s", cpErr.Error())
 		return
 	}
 
+	// remove entries of other runtimes, e.g. query shortcut pack template contains entries for all runtimes
+	for runtime, entry := range template.Entries {
+		if runtime == creation.runtime {
+			continue
+		}
+		removeErr := os.Remove(path.Join(pluginDirectory, entry))
+		if removeErr != nil {
+			w.api.Log(ctx, plugin.LogLevelWarning, fmt.Sprintf("Failed to remove unused entry %s: %s", entry, removeErr.Error()))
+		}
+	}
+
 	// replace variables in plugin.json
 	pluginJsonPath := path.Join(pluginDirectory, "plugin.json")
 	pluginJson, readErr := os.ReadFile(pluginJsonPath)
@@ -605,9 +871,10 @@ func (w *WPMPlugin) createPlugin(ctx context.Context, template pluginTemplate, p
 
 	pluginJsonString := string(pluginJson)
 	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Id]", uuid.NewString())
-	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Name]", pluginName)
-	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Runtime]", strings.ToLower(string(template.Runtime)))
-	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Trigger Keyword]", "np")
+	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Name]", creation.name)
+	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Runtime]", strings.ToLower(string(creation.runtime)))
+	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Trigger Keyword]", creation.triggerKeyword)
+	pluginJsonString = strings.ReplaceAll(pluginJsonString, "[Entry]", template.Entries[creation.runtime])
 
 	writeErr := os.WriteFile(pluginJsonPath, []byte(pluginJsonString), 0644)
 	if writeErr != nil {
@@ -617,8 +884,8 @@ func (w *WPMPlugin) createPlugin(ctx context.Context, template pluginTemplate, p
 	}
 
 	// replace variables in package.json
-	if template.Runtime == plugin.PLUGIN_RUNTIME_NODEJS {
-		packageJsonPath := path.Join(pluginDirectory, "package.json")
+	packageJsonPath := path.Join(pluginDirectory, "package.json")
+	if util.IsFileExists(packageJsonPath) {
 		packageJson, readPackageErr := os.ReadFile(packageJsonPath)
 		if readPackageErr != nil {
 			w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to read package.json: %s", readPackageErr.Error()))
@@ -627,7 +894,8 @@ func (w *WPMPlugin) createPlugin(ctx context.Context, template pluginTemplate, p
 		}
 
 		packageJsonString := string(packageJson)
-		packageName := strings.ReplaceAll(strings.ToLower(pluginName), ".", "_")
+		packageName := strings.ReplaceAll(strings.ToLower(creation.name), ".", "_")
+		packageName = strings.ReplaceAll(packageName, " ", "_")
 		packageJsonString = strings.ReplaceAll(packageJsonString, "replace_me_with_name", packageName)
 
 		writePackageErr := os.WriteFile(packageJsonPath, []byte(packageJsonString), 0644)
@@ -662,33 +930,37 @@ func (w *WPMPlugin) saveLocalPluginDirectories(ctx context.Context) {
 	w.api.SaveSetting(ctx, localPluginDirectoriesKey, string(data), false)
 }
 
-func (w *WPMPlugin) reloadLocalDistPlugin(ctx context.Context, localPlugin plugin.MetadataWithDirectory, reason string) error {
+// reloadDevPlugin reloads plugin from dist directory if exists, otherwise from the plugin directory itself
+func (w *WPMPlugin) reloadDevPlugin(ctx context.Context, localPlugin plugin.MetadataWithDirectory, reason string) error {
 	w.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("Reloading plugin: %s, reason: %s", localPlugin.Metadata.Name, reason))
 
-	// find dist directory, if not exist, prompt user to build it
-	distDirectory := path.Join(localPlugin.Directory, "dist")
-	_, statErr := os.Stat(distDirectory)
-	if statErr != nil {
-		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to stat dist directory: %s", statErr.Error()))
-		return statErr
-	}
-
-	distPluginMetadata, err := w.parseMetadata(ctx, distDirectory)
-	if err != nil {
-		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to load local plugin: %s", err.Error()))
-		return err
-	}
-	distPluginMetadata.IsDev = true
-	distPluginMetadata.DevPluginDirectory = localPlugin.Directory
-
-	reloadErr := plugin.GetPluginManager().ReloadPlugin(ctx, distPluginMetadata)
+	reloadErr := w.loadDevPluginFromDisk(ctx, localPlugin)
 	if reloadErr != nil {
 		w.api.Log(ctx, plugin.LogLevelError, fmt.Sprintf("Failed to reload plugin: %s", reloadErr.Error()))
+		w.reloadPluginErrors.Store(localPlugin.Metadata.Id, reloadErr)
+		w.api.Notify(ctx, fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_reload_failed"), localPlugin.Metadata.Name, reloadErr.Error()))
 		return reloadErr
-	} else {
-		w.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("Reloaded plugin: %s", localPlugin.Metadata.Name))
 	}
 
+	w.api.Log(ctx, plugin.LogLevelInfo, fmt.Sprintf("Reloaded plugin: %s", localPlugin.Metadata.Name))
+	w.reloadPluginErrors.Delete(localPlugin.Metadata.Id)
 	w.api.Notify(ctx, fmt.Sprintf(i18n.GetI18nManager().TranslateWox(ctx, "plugin_wpm_reload_success"), localPlugin.Metadata.Name, reason))
 	return nil
 }
+
+func (w *WPMPlugin) loadDevPluginFromDisk(ctx context.Context, localPlugin plugin.MetadataWithDirectory) error {
+	loadDirectory := localPlugin.Directory
+	distDirectory := path.Join(localPlugin.Directory, "dist")
+	if util.IsDirExists(distDirectory) {
+		loadDirectory = distDirectory
+	}
+
+	pluginMetadata, err := w.parseMetadata(ctx, loadDirectory)
+	if err != nil {
+		return err
+	}
+	pluginMetadata.IsDev = true
+	pluginMetadata.DevPluginDirectory = localPlugin.Directory
+
+	return plugin.GetPluginManager().ReloadPlugin(ctx, pluginMetadata)
+}
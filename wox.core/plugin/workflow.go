@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"wox/setting"
+	"wox/share"
+	"wox/util/clipboard"
+	"wox/util/keyboard"
+	"wox/util/notifier"
+
+	"github.com/samber/lo"
+)
+
+var workflowVariableRegex = regexp.MustCompile(`\{\$([A-Za-z0-9_]+)\}`)
+
+// workflowState is passed between steps of a running workflow
+type workflowState struct {
+	variables    map[string]string // outputs of steps, "last" is the output of previous step
+	results      []QueryResultUI   // results of last query step
+	pickedResult *QueryResultUI
+}
+
+// RunWorkflow executes steps of a query hotkey workflow in order and stops at the first failed step
+func (m *Manager) RunWorkflow(ctx context.Context, steps []setting.WorkflowStep) error {
+	state := &workflowState{variables: map[string]string{}}
+	for i, step := range steps {
+		if validateErr := step.Validate(); validateErr != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Type, validateErr)
+		}
+
+		value := m.expandWorkflowValue(ctx, step, state)
+		logger.Info(ctx, fmt.Sprintf("run workflow step %d (%s): %s", i+1, step.Type, value))
+		output, stepErr := m.runWorkflowStep(ctx, step, value, state)
+		if stepErr != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Type, stepErr)
+		}
+
+		state.variables["last"] = output
+		if step.Output != "" {
+			state.variables[step.Output] = output
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) runWorkflowStep(ctx context.Context, step setting.WorkflowStep, value string, state *workflowState) (string, error) {
+	switch step.Type {
+	case setting.WorkflowStepTypeQuery:
+		query, _, queryErr := m.NewQuery(ctx, share.PlainQuery{QueryType: QueryTypeInput, QueryText: value})
+		if queryErr != nil {
+			return "", queryErr
+		}
+		results, waitErr := m.QueryAndWait(ctx, query)
+		if waitErr != nil {
+			return "", waitErr
+		}
+		if len(results) == 0 {
+			return "", fmt.Errorf("no result for query: %s", value)
+		}
+
+		state.results = results
+		state.pickedResult = nil
+		return results[0].Title, nil
+	case setting.WorkflowStepTypePickResult:
+		if len(state.results) == 0 {
+			return "", fmt.Errorf("no query results to pick, add a query step before")
+		}
+
+		if value != "" {
+			result, found := lo.Find(state.results, func(result QueryResultUI) bool {
+				return strings.Contains(strings.ToLower(result.Title), strings.ToLower(value))
+			})
+			if !found {
+				return "", fmt.Errorf("no result title contains: %s", value)
+			}
+			state.pickedResult = &result
+		} else {
+			if step.Index >= len(state.results) {
+				return "", fmt.Errorf("result index %d out of range, got %d results", step.Index, len(state.results))
+			}
+			state.pickedResult = &state.results[step.Index]
+		}
+		return state.pickedResult.Title, nil
+	case setting.WorkflowStepTypeExecuteAction:
+		result := state.pickedResult
+		if result == nil {
+			if len(state.results) != 1 {
+				return "", fmt.Errorf("no result picked, add a pick_result step before")
+			}
+			result = &state.results[0]
+		}
+
+		action, found := lo.Find(result.Actions, func(action QueryResultActionUI) bool {
+			if value == "" {
+				return action.IsDefault
+			}
+			return strings.EqualFold(action.Name, value)
+		})
+		if !found {
+			if value == "" {
+				return "", fmt.Errorf("result %s has no default action", result.Title)
+			}
+			return "", fmt.Errorf("result %s has no action named: %s", result.Title, value)
+		}
+		if executeErr := m.ExecuteAction(ctx, result.Id, action.Id); executeErr != nil {
+			return "", executeErr
+		}
+
+		if result.ContextData != "" {
+			return result.ContextData, nil
+		}
+		return result.Title, nil
+	case setting.WorkflowStepTypeTransform:
+		return transformWorkflowValue(step, value), nil
+	case setting.WorkflowStepTypePaste:
+		if writeErr := clipboard.WriteText(value); writeErr != nil {
+			return "", writeErr
+		}
+		// wait for clipboard to be ready, see snippets plugin
+		time.Sleep(time.Millisecond * 100)
+		if pasteErr := keyboard.SimulatePaste(); pasteErr != nil {
+			return "", pasteErr
+		}
+		return value, nil
+	case setting.WorkflowStepTypeNotify:
+		notifier.Notify(value)
+		return value, nil
+	}
+
+	return "", fmt.Errorf("unknown step type: %s", step.Type)
+}
+
+// expandWorkflowValue replaces query variables and then {$name} with outputs of previous steps.
+// Empty value of transform, paste and notify steps means output of previous step
+func (m *Manager) expandWorkflowValue(ctx context.Context, step setting.WorkflowStep, state *workflowState) string {
+	value := step.Value
+	if value == "" {
+		if lo.Contains([]setting.WorkflowStepType{setting.WorkflowStepTypeTransform, setting.WorkflowStepTypePaste, setting.WorkflowStepTypeNotify}, step.Type) {
+			return state.variables["last"]
+		}
+		return ""
+	}
+
+	value = m.ReplaceQueryVariable(ctx, value)
+	return workflowVariableRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := workflowVariableRegex.FindStringSubmatch(placeholder)[1]
+		if variable, exist := state.variables[name]; exist {
+			return variable
+		}
+		return placeholder
+	})
+}
+
+func transformWorkflowValue(step setting.WorkflowStep, value string) string {
+	switch step.Transform {
+	case setting.WorkflowTransformUpper:
+		return strings.ToUpper(value)
+	case setting.WorkflowTransformLower:
+		return strings.ToLower(value)
+	case setting.WorkflowTransformTrim:
+		return strings.TrimSpace(value)
+	case setting.WorkflowTransformUrlEncode:
+		return url.QueryEscape(value)
+	case setting.WorkflowTransformReplace:
+		// pattern is checked in step validation
+		return regexp.MustCompile(step.Pattern).ReplaceAllString(value, step.Replacement)
+	}
+
+	return value
+}
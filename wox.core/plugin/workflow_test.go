@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"wox/setting"
+	"wox/util"
+)
+
+func Test_WorkflowValue(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+	state := &workflowState{variables: map[string]string{"last": "b", "name": "wox"}}
+
+	assert.Equal(t, "hello wox b {$unknown}", m.expandWorkflowValue(ctx, setting.WorkflowStep{Type: setting.WorkflowStepTypeNotify, Value: "hello {$name} {$last} {$unknown}"}, state))
+	assert.Equal(t, "b", m.expandWorkflowValue(ctx, setting.WorkflowStep{Type: setting.WorkflowStepTypePaste}, state))
+	assert.Equal(t, "", m.expandWorkflowValue(ctx, setting.WorkflowStep{Type: setting.WorkflowStepTypeExecuteAction}, state))
+
+	assert.Equal(t, "a+b", transformWorkflowValue(setting.WorkflowStep{Transform: setting.WorkflowTransformUrlEncode}, "a b"))
+	assert.Equal(t, "w-x", transformWorkflowValue(setting.WorkflowStep{Transform: setting.WorkflowTransformReplace, Pattern: "o+", Replacement: "-"}, "woox"))
+}
+
+func Test_RunWorkflow(t *testing.T) {
+	ctx := util.NewTraceContext()
+	m := GetPluginManager()
+
+	assert.Nil(t, m.RunWorkflow(ctx, []setting.WorkflowStep{
+		{Type: setting.WorkflowStepTypeTransform, Value: " wox ", Transform: setting.WorkflowTransformTrim, Output: "name"},
+		{Type: setting.WorkflowStepTypeTransform, Value: "{$name}", Transform: setting.WorkflowTransformUpper},
+	}))
+
+	err := m.RunWorkflow(ctx, []setting.WorkflowStep{
+		{Type: setting.WorkflowStepTypeTransform, Value: "wox", Transform: setting.WorkflowTransformLower},
+		{Type: setting.WorkflowStepTypePickResult, Value: "wox"},
+	})
+	assert.ErrorContains(t, err, "step 2 (pick_result)")
+
+	err = m.RunWorkflow(ctx, []setting.WorkflowStep{{Type: "unknown"}})
+	assert.ErrorContains(t, err, "unknown step type")
+}
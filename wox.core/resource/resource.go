@@ -19,6 +19,9 @@ var LangFS embed.FS
 //go:embed ui
 var UIFS embed.FS
 
+//go:embed templates
+var TemplateFS embed.FS
+
 //go:embed app.png
 var appIcon []byte
 
@@ -27,16 +30,19 @@ var appIconWindows []byte
 
 var embedThemes = []string{}
 
-func Extract(ctx context.Context) error {
+// Extract extracts plugin hosts and flutter ui app, ui app is not needed in headless mode
+func Extract(ctx context.Context, extractUI bool) error {
 	start := util.GetSystemTimestamp()
 	extractHostErr := extractFiles(ctx, HostFS, util.GetLocation().GetHostDirectory(), "hosts", false)
 	if extractHostErr != nil {
 		return extractHostErr
 	}
 
-	flutterErr := extractFiles(ctx, UIFS, util.GetLocation().GetUIDirectory(), "ui/flutter", true)
-	if flutterErr != nil {
-		return flutterErr
+	if extractUI {
+		flutterErr := extractFiles(ctx, UIFS, util.GetLocation().GetUIDirectory(), "ui/flutter", true)
+		if flutterErr != nil {
+			return flutterErr
+		}
 	}
 
 	themeErr := parseThemes(ctx)
@@ -87,6 +93,11 @@ func extractFiles(ctx context.Context, fs embed.FS, extractDirectory string, fil
 	return nil
 }
 
+// ExtractPluginTemplate extracts bundled plugin template into extractDirectory/templateId
+func ExtractPluginTemplate(ctx context.Context, templateId string, extractDirectory string) error {
+	return extractFiles(ctx, TemplateFS, extractDirectory, path.Join("templates", templateId), true)
+}
+
 func parseThemes(ctx context.Context) error {
 	dir, err := UIFS.ReadDir(path.Join("ui", "themes"))
 	if err != nil {
@@ -0,0 +1,20 @@
+import type { Context, Plugin, PluginInitParams, PublicAPI, Query, Result } from "@wox-launcher/wox-plugin"
+
+let api: PublicAPI
+
+// Wox looks up this export to load the plugin
+export const plugin: Plugin = {
+  init: async (ctx: Context, initParams: PluginInitParams) => {
+    api = initParams.API
+  },
+
+  query: async (ctx: Context, query: Query): Promise<Result[]> => {
+    return [
+      {
+        Title: `Hello ${query.Search}`,
+        SubTitle: "Edit src/index.ts and run `npm run build` to build your own plugin",
+        Icon: { ImageType: "emoji", ImageData: "👋" }
+      }
+    ]
+  }
+}
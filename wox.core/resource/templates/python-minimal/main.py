@@ -0,0 +1,23 @@
+from typing import List
+
+from wox_plugin import Context, Plugin, PluginInitParams, PublicAPI, Query, Result, WoxImage, WoxImageType
+
+
+class MinimalPlugin(Plugin):
+    api: PublicAPI
+
+    async def init(self, ctx: Context, init_params: PluginInitParams) -> None:
+        self.api = init_params.api
+
+    async def query(self, ctx: Context, query: Query) -> List[Result]:
+        return [
+            Result(
+                title=f"Hello {query.search}",
+                sub_title="Edit main.py to build your own plugin",
+                icon=WoxImage(image_type=WoxImageType.EMOJI, image_data="👋"),
+            )
+        ]
+
+
+# Wox looks up this variable to load the plugin
+plugin = MinimalPlugin()
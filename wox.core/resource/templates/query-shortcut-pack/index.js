@@ -0,0 +1,5 @@
+// This plugin only contributes QueryShortcuts defined in plugin.json, it doesn't return any results
+exports.plugin = {
+  init: async (ctx, initParams) => {},
+  query: async (ctx, query) => []
+}
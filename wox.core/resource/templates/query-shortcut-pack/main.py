@@ -0,0 +1,16 @@
+from typing import List
+
+from wox_plugin import Context, Plugin, PluginInitParams, Query, Result
+
+
+class QueryShortcutPack(Plugin):
+    """This plugin only contributes QueryShortcuts defined in plugin.json, it doesn't return any results"""
+
+    async def init(self, ctx: Context, init_params: PluginInitParams) -> None:
+        pass
+
+    async def query(self, ctx: Context, query: Query) -> List[Result]:
+        return []
+
+
+plugin = QueryShortcutPack()
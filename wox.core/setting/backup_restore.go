@@ -1,37 +1,108 @@
 package setting
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"github.com/google/uuid"
-	cp "github.com/otiai10/copy"
+	"io"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
 	"wox/util"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
 )
 
 type BackupType string
 
 const (
-	BackupTypeAuto   BackupType = "auto"
-	BackupTypeManual BackupType = "manual"
-	BackupTypeUpdate BackupType = "update" // backup before update Wox
+	BackupTypeAuto      BackupType = "auto"
+	BackupTypeManual    BackupType = "manual"
+	BackupTypeUpdate    BackupType = "update"    // backup before update Wox
+	BackupTypeMigration BackupType = "migration" // backup before migrating setting schema
+	BackupTypeRestore   BackupType = "restore"   // backup before restoring another backup, so restore can be undone
 )
 
 type Backup struct {
 	Id        string
-	Name      string // backup folder name
+	Name      string // snapshot file name, without extension
 	Timestamp int64
 	Type      BackupType
+	FileCount int
+	Size      int64 // uncompressed size of all files
+}
+
+// BackupRetention keeps the latest backup of the last N days, weeks and months (grandfather-father-son)
+type BackupRetention struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+var defaultBackupRetention = BackupRetention{Daily: 7, Weekly: 4, Monthly: 6}
+
+type BackupChangeType = string
+
+const (
+	BackupChangeAdded    BackupChangeType = "added"    // file doesn't exist now, will be created by restore
+	BackupChangeModified BackupChangeType = "modified" // file content is different, will be overwritten by restore
+	BackupChangeRemoved  BackupChangeType = "removed"  // file doesn't exist in backup, will be removed by restore
+)
+
+type BackupChange struct {
+	Path string // relative to user data directory
+	Type BackupChangeType
+}
+
+// backups are stored as snapshots referencing compressed, content addressed objects, so unchanged files are stored only once
+//
+//	backup/objects/<hash[:2]>/<hash>    gzip compressed file content, hash is sha256 of uncompressed content
+//	backup/snapshots/<name>.json        backup info and files in backup
+type backupSnapshot struct {
+	Backup
+	Files []backupFile
+}
+
+type backupFile struct {
+	Path string // relative to user data directory, slash separated
+	Hash string
+	Size int64
+	Mode fs.FileMode
+}
+
+type backupHashCacheEntry struct {
+	Size    int64
+	ModTime int64
+	Hash    string
+}
+
+// files or directories matching these patterns are caches which can be rebuilt, they are never backed up
+var backupExcludePatterns = []string{
+	"cache",
+	"caches",
+	".cache",
+	"__pycache__",
+	".venv",
+	".DS_Store",
+	"*.log",
+	"*.tmp",
 }
 
 func (m *Manager) StartAutoBackup(ctx context.Context) {
 	util.Go(ctx, "backup", func() {
-		for range time.NewTimer(24 * time.Hour).C {
+		m.convertLegacyBackups(ctx)
+
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
 			backupErr := m.Backup(ctx, BackupTypeAuto)
 			if backupErr != nil {
 				logger.Error(ctx, fmt.Sprintf("failed to backup data: %s", backupErr.Error()))
@@ -41,195 +112,652 @@ func (m *Manager) StartAutoBackup(ctx context.Context) {
 }
 
 func (m *Manager) Backup(ctx context.Context, backupType BackupType) error {
+	m.backupLock.Lock()
+	defer m.backupLock.Unlock()
+
+	_, backupErr := m.backup(ctx, backupType)
+	if backupErr != nil {
+		return backupErr
+	}
+
+	// read before cleaning in background, setting may be loaded or changed meanwhile
+	retention := m.getBackupRetention()
+	util.Go(ctx, "clean backups", func() {
+		m.cleanBackups(ctx, retention)
+	})
+
+	return nil
+}
+
+// getBackupRetention returns the retention in setting, or the default one if setting is not loaded yet, e.g. when backing up before migrating setting
+func (m *Manager) getBackupRetention() BackupRetention {
+	m.settingLock.RLock()
+	defer m.settingLock.RUnlock()
+
+	if m.woxSetting == nil || m.woxSetting.BackupRetention == (BackupRetention{}) {
+		return defaultBackupRetention
+	}
+	return m.woxSetting.BackupRetention
+}
+
+func (m *Manager) backup(ctx context.Context, backupType BackupType) (backupSnapshot, error) {
 	logger.Info(ctx, fmt.Sprintf("backing up data: %s", backupType))
 
-	ts := util.GetSystemTimestamp()
-	backupName := fmt.Sprintf("%d", ts)
-	backupPath := path.Join(util.GetLocation().GetBackupDirectory(), backupName)
-	logger.Info(ctx, fmt.Sprintf("backup path: %s", backupPath))
+	// secrets are kept in secret store outside of user data directory, so backups never contain them
+	files, indexErr := m.indexBackupFiles(ctx, util.GetLocation().GetUserDataDirectory())
+	if indexErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to index user data: %s", indexErr.Error()))
+		return backupSnapshot{}, indexErr
+	}
 
-	err := cp.Copy(util.GetLocation().GetUserDataDirectory(), backupPath)
-	if err != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to backup data: %s", err.Error()))
-		return err
+	ts := util.GetSystemTimestamp()
+	snapshot := backupSnapshot{
+		Backup: Backup{
+			Id:        uuid.New().String(),
+			Name:      fmt.Sprintf("%d", ts),
+			Timestamp: ts,
+			Type:      backupType,
+		},
+		Files: files,
 	}
+	return snapshot, m.saveBackupSnapshot(ctx, util.GetLocation().GetUserDataDirectory(), snapshot)
+}
 
-	backup := Backup{
-		Id:        uuid.New().String(),
-		Name:      backupName,
-		Timestamp: ts,
-		Type:      backupType,
+// saveBackupSnapshot stores objects of files which are not stored yet, then writes the snapshot
+func (m *Manager) saveBackupSnapshot(ctx context.Context, sourceDirectory string, snapshot backupSnapshot) error {
+	storedCount := 0
+	for _, file := range snapshot.Files {
+		stored, storeErr := storeBackupObject(path.Join(sourceDirectory, file.Path), file.Hash)
+		if storeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to store backup file %s: %s", file.Path, storeErr.Error()))
+			return storeErr
+		}
+		if stored {
+			storedCount++
+		}
+		snapshot.FileCount++
+		snapshot.Size += file.Size
 	}
-	marshal, marshalErr := json.Marshal(backup)
+
+	snapshotJson, marshalErr := json.Marshal(snapshot)
 	if marshalErr != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to marshal backup data: %s", marshalErr.Error()))
-		// remove backup data
-		rmErr := os.RemoveAll(backupPath)
-		if rmErr != nil {
-			logger.Error(ctx, fmt.Sprintf("failed to remove backup data: %s", rmErr.Error()))
-		}
 		return marshalErr
 	}
 
-	backupInfoPath := path.Join(backupPath, "backup.json")
-	writeErr := os.WriteFile(backupInfoPath, marshal, 0644)
-	if writeErr != nil {
+	// write to temp file first, so an interrupted backup never leaves a partial snapshot
+	snapshotPath := getBackupSnapshotPath(snapshot.Name)
+	if mkdirErr := os.MkdirAll(path.Dir(snapshotPath), 0755); mkdirErr != nil {
+		return mkdirErr
+	}
+	if writeErr := os.WriteFile(snapshotPath+".tmp", snapshotJson, 0644); writeErr != nil {
 		logger.Error(ctx, fmt.Sprintf("failed to write backup info: %s", writeErr.Error()))
-		// remove backup data
-		rmErr := os.RemoveAll(backupPath)
-		if rmErr != nil {
-			logger.Error(ctx, fmt.Sprintf("failed to remove backup data: %s", rmErr.Error()))
-		}
 		return writeErr
 	}
+	if renameErr := os.Rename(snapshotPath+".tmp", snapshotPath); renameErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to write backup info: %s", renameErr.Error()))
+		return renameErr
+	}
 
-	logger.Info(ctx, "backup data saved successfully")
-
-	util.Go(ctx, "clean backups", func() {
-		m.cleanBackups(ctx)
-	})
-
+	logger.Info(ctx, fmt.Sprintf("backup data saved successfully, files: %d, new objects: %d", snapshot.FileCount, storedCount))
 	return nil
 }
 
 func (m *Manager) Restore(ctx context.Context, backupId string) error {
 	logger.Info(ctx, fmt.Sprintf("restoring backup data: %s", backupId))
-	backups, getErr := m.FindAllBackups(ctx)
-	if getErr != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to get all backups: %s", getErr.Error()))
-		return getErr
-	}
+	m.backupLock.Lock()
+	defer m.backupLock.Unlock()
 
-	var backupName string
-	for _, backup := range backups {
-		if backup.Id == backupId {
-			backupName = backup.Name
-			break
-		}
+	snapshot, findErr := m.findBackupSnapshot(ctx, backupId)
+	if findErr != nil {
+		logger.Error(ctx, findErr.Error())
+		return findErr
 	}
-	if backupName == "" {
-		logger.Error(ctx, fmt.Sprintf("backup not found: %s", backupId))
-		return fmt.Errorf("backup not found: %s", backupId)
+
+	// never restore a damaged backup, it would leave user data half restored
+	if verifyErr := verifyBackupSnapshot(snapshot); verifyErr != nil {
+		logger.Error(ctx, fmt.Sprintf("backup is damaged: %s", verifyErr.Error()))
+		return verifyErr
 	}
 
-	// backup current data to temp directory
-	tempBackupName := fmt.Sprintf("temp_%d", util.GetSystemTimestamp())
-	tempBackupPath := path.Join(util.GetLocation().GetWoxDataDirectory(), tempBackupName)
-	cpErr := cp.Copy(util.GetLocation().GetUserDataDirectory(), tempBackupPath)
-	if cpErr != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to backup current data to temp directory: %s", cpErr.Error()))
-		return cpErr
+	// backup current data, so a failed restore can be rolled back and user can go back if restored data is not what they want
+	currentSnapshot, backupErr := m.backup(ctx, BackupTypeRestore)
+	if backupErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to backup current data before restore: %s", backupErr.Error()))
+		return backupErr
 	}
 
-	// first remove current data
-	rmErr := os.Remove(util.GetLocation().GetUserDataDirectory())
-	if rmErr != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to remove user data directory: %s", rmErr.Error()))
-		return rmErr
+	restoreErr := m.restoreBackupSnapshot(ctx, snapshot)
+	if restoreErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to restore backup, rolling back: %s", restoreErr.Error()))
+		if rollbackErr := m.restoreBackupSnapshot(ctx, currentSnapshot); rollbackErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to roll back restore: %s", rollbackErr.Error()))
+			return fmt.Errorf("failed to restore backup: %w, rolling back to backup %s also failed: %s", restoreErr, currentSnapshot.Id, rollbackErr.Error())
+		}
+		return restoreErr
 	}
 
-	backupPath := path.Join(util.GetLocation().GetBackupDirectory(), backupName)
-	cpErr = cp.Copy(backupPath, util.GetLocation().GetUserDataDirectory())
-	if cpErr != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to restore backup data to user data directory: %s", cpErr.Error()))
-		return cpErr
+	// backups made by older versions may contain plaintext secrets, they are moved to secret store on next load
+
+	//TODO: reload data / plugins
+
+	return nil
+}
+
+// restoreBackupSnapshot changes user data to the files of given snapshot
+func (m *Manager) restoreBackupSnapshot(ctx context.Context, snapshot backupSnapshot) error {
+	changes, diffErr := m.diffBackupSnapshot(ctx, snapshot)
+	if diffErr != nil {
+		return diffErr
 	}
 
-	// remove backup info if exists
-	backupInfoPath := path.Join(backupPath, "backup.json")
-	if _, statErr := os.Stat(backupInfoPath); !os.IsNotExist(statErr) {
-		rmErr = os.Remove(backupInfoPath)
-		if rmErr != nil {
-			logger.Error(ctx, fmt.Sprintf("failed to remove backup info: %s", rmErr.Error()))
-			return rmErr
+	userDataDirectory := util.GetLocation().GetUserDataDirectory()
+	snapshotFiles := lo.SliceToMap(snapshot.Files, func(file backupFile) (string, backupFile) {
+		return file.Path, file
+	})
+	for _, change := range changes {
+		filePath := path.Join(userDataDirectory, change.Path)
+		if change.Type == BackupChangeRemoved {
+			if rmErr := os.Remove(filePath); rmErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to remove file: %s", rmErr.Error()))
+				return rmErr
+			}
+			continue
+		}
+
+		if restoreErr := restoreBackupObject(snapshotFiles[change.Path], filePath); restoreErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to restore file %s: %s", change.Path, restoreErr.Error()))
+			return restoreErr
 		}
 	}
 
-	// remove temp backup data
-	rmErr = os.RemoveAll(tempBackupPath)
-	if rmErr != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to remove temp backup data: %s", rmErr.Error()))
-		return rmErr
+	logger.Info(ctx, fmt.Sprintf("backup data restored successfully, changed files: %d", len(changes)))
+	return nil
+}
+
+// PreviewRestore returns files which would be changed by restoring given backup, without changing anything
+func (m *Manager) PreviewRestore(ctx context.Context, backupId string) ([]BackupChange, error) {
+	m.backupLock.Lock()
+	defer m.backupLock.Unlock()
+
+	snapshot, findErr := m.findBackupSnapshot(ctx, backupId)
+	if findErr != nil {
+		return nil, findErr
 	}
 
-	logger.Info(ctx, "backup data restored successfully")
+	return m.diffBackupSnapshot(ctx, snapshot)
+}
 
-	//TODO: reload data / plugins
+// VerifyBackup checks all files of given backup can be read and their content is not changed
+func (m *Manager) VerifyBackup(ctx context.Context, backupId string) error {
+	m.backupLock.Lock()
+	defer m.backupLock.Unlock()
+
+	snapshot, findErr := m.findBackupSnapshot(ctx, backupId)
+	if findErr != nil {
+		return findErr
+	}
 
+	verifyErr := verifyBackupSnapshot(snapshot)
+	if verifyErr != nil {
+		logger.Error(ctx, fmt.Sprintf("backup %s is damaged: %s", backupId, verifyErr.Error()))
+		return verifyErr
+	}
+
+	logger.Info(ctx, fmt.Sprintf("backup %s verified, files: %d", backupId, len(snapshot.Files)))
 	return nil
 }
 
 func (m *Manager) FindAllBackups(ctx context.Context) ([]Backup, error) {
-	var backupList []Backup
+	snapshots, findErr := m.findAllBackupSnapshots(ctx)
+	if findErr != nil {
+		return nil, findErr
+	}
 
-	backupDir := util.GetLocation().GetBackupDirectory()
-	backupDirEntries, readDirErr := os.ReadDir(backupDir)
+	return lo.Map(snapshots, func(snapshot backupSnapshot, _ int) Backup {
+		return snapshot.Backup
+	}), nil
+}
+
+func (m *Manager) findAllBackupSnapshots(ctx context.Context) ([]backupSnapshot, error) {
+	var snapshots []backupSnapshot
+
+	snapshotDirectory := getBackupSnapshotDirectory()
+	entries, readDirErr := os.ReadDir(snapshotDirectory)
 	if readDirErr != nil {
+		if os.IsNotExist(readDirErr) {
+			return snapshots, nil
+		}
 		logger.Error(ctx, fmt.Sprintf("failed to read backup directory: %s", readDirErr.Error()))
 		return nil, readDirErr
 	}
 
-	for _, entry := range backupDirEntries {
-		if strings.HasPrefix(entry.Name(), "temp_") {
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
 			continue
 		}
 
-		//  read backup info file
-		backupInfoPath := path.Join(backupDir, entry.Name(), "backup.json")
-		file, readErr := os.ReadFile(backupInfoPath)
+		file, readErr := os.ReadFile(path.Join(snapshotDirectory, entry.Name()))
 		if readErr != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to read backup info file: %s", readErr.Error()))
 			continue
 		}
 
-		var backupInfo Backup
-		decodeErr := json.Unmarshal(file, &backupInfo)
+		var snapshot backupSnapshot
+		decodeErr := json.Unmarshal(file, &snapshot)
 		if decodeErr != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to unmarshal backup info: %s", decodeErr.Error()))
 			continue
 		}
 
-		backupList = append(backupList, backupInfo)
+		snapshots = append(snapshots, snapshot)
 	}
 
-	return backupList, nil
+	return snapshots, nil
 }
 
-func (m *Manager) cleanBackups(ctx context.Context) error {
-	logger.Info(ctx, "cleaning backups")
-	maxBackups := 5
+func (m *Manager) findBackupSnapshot(ctx context.Context, backupId string) (backupSnapshot, error) {
+	snapshots, findErr := m.findAllBackupSnapshots(ctx)
+	if findErr != nil {
+		return backupSnapshot{}, findErr
+	}
 
-	backups, getErr := m.FindAllBackups(ctx)
-	if getErr != nil {
-		logger.Error(ctx, fmt.Sprintf("failed to get all backups: %s", getErr.Error()))
-		return getErr
+	snapshot, found := lo.Find(snapshots, func(item backupSnapshot) bool {
+		return item.Id == backupId
+	})
+	if !found {
+		return backupSnapshot{}, fmt.Errorf("backup not found: %s", backupId)
 	}
+	return snapshot, nil
+}
+
+// diffBackupSnapshot compares backup with current user data, excluded files are never changed
+func (m *Manager) diffBackupSnapshot(ctx context.Context, snapshot backupSnapshot) ([]BackupChange, error) {
+	currentFiles, indexErr := m.indexBackupFiles(ctx, util.GetLocation().GetUserDataDirectory())
+	if indexErr != nil {
+		return nil, indexErr
+	}
+	currentHashes := lo.SliceToMap(currentFiles, func(file backupFile) (string, string) {
+		return file.Path, file.Hash
+	})
+
+	var changes []BackupChange
+	for _, file := range snapshot.Files {
+		currentHash, exist := currentHashes[file.Path]
+		if !exist {
+			changes = append(changes, BackupChange{Path: file.Path, Type: BackupChangeAdded})
+		} else if currentHash != file.Hash {
+			changes = append(changes, BackupChange{Path: file.Path, Type: BackupChangeModified})
+		}
+		delete(currentHashes, file.Path)
+	}
+	for filePath := range currentHashes {
+		changes = append(changes, BackupChange{Path: filePath, Type: BackupChangeRemoved})
+	}
+
+	slices.SortFunc(changes, func(a, b BackupChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes, nil
+}
 
-	// keep 5 backups
-	if len(backups) <= maxBackups {
+// indexBackupFiles hashes all files which should be backed up in given directory.
+// Hashes are cached by size and modification time, so unchanged files are not read again
+func (m *Manager) indexBackupFiles(ctx context.Context, directory string) ([]backupFile, error) {
+	if m.backupHashCache == nil {
+		m.backupHashCache = map[string]backupHashCacheEntry{}
+	}
+
+	var files []backupFile
+	walkErr := filepath.WalkDir(directory, func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if filePath == directory {
+			return nil
+		}
+		if isBackupExcluded(filePath, entry.Name()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return infoErr
+		}
+		cached, found := m.backupHashCache[filePath]
+		if !found || cached.Size != info.Size() || cached.ModTime != info.ModTime().UnixNano() {
+			hash, hashErr := hashBackupFile(filePath)
+			if hashErr != nil {
+				return hashErr
+			}
+			cached = backupHashCacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Hash: hash}
+			m.backupHashCache[filePath] = cached
+		}
+
+		relativePath, relErr := filepath.Rel(directory, filePath)
+		if relErr != nil {
+			return relErr
+		}
+		files = append(files, backupFile{
+			Path: filepath.ToSlash(relativePath),
+			Hash: cached.Hash,
+			Size: info.Size(),
+			Mode: info.Mode().Perm(),
+		})
 		return nil
+	})
+
+	return files, walkErr
+}
+
+func isBackupExcluded(filePath string, name string) bool {
+	// user data directory may contain wox data directory, never backup backups or caches
+	cleanPath := filepath.Clean(filePath)
+	if cleanPath == filepath.Clean(util.GetLocation().GetBackupDirectory()) || cleanPath == filepath.Clean(util.GetLocation().GetCacheDirectory()) {
+		return true
 	}
 
-	// sort backups by timestamp
-	slices.SortFunc(backups, func(i, j Backup) int {
-		return int(i.Timestamp - j.Timestamp)
+	return lo.ContainsBy(backupExcludePatterns, func(pattern string) bool {
+		matched, _ := path.Match(pattern, name)
+		return matched
 	})
+}
+
+func (m *Manager) cleanBackups(ctx context.Context, retention BackupRetention) error {
+	logger.Info(ctx, "cleaning backups")
+	m.backupLock.Lock()
+	defer m.backupLock.Unlock()
+
+	snapshots, getErr := m.findAllBackupSnapshots(ctx)
+	if getErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to get all backups: %s", getErr.Error()))
+		return getErr
+	}
+
+	keep := selectBackupsToKeep(lo.Map(snapshots, func(snapshot backupSnapshot, _ int) Backup {
+		return snapshot.Backup
+	}), retention)
 
 	// remove old backups
 	removedCount := 0
-	for i := 0; i < len(backups)-maxBackups; i++ {
-		backup := backups[i]
-		backupPath := path.Join(util.GetLocation().GetBackupDirectory(), backup.Name)
-		rmErr := os.RemoveAll(backupPath)
+	referencedObjects := map[string]bool{}
+	for _, snapshot := range snapshots {
+		if keep[snapshot.Id] {
+			for _, file := range snapshot.Files {
+				referencedObjects[file.Hash] = true
+			}
+			continue
+		}
+
+		rmErr := os.Remove(getBackupSnapshotPath(snapshot.Name))
 		if rmErr != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to remove backup: %s", rmErr.Error()))
+			for _, file := range snapshot.Files {
+				referencedObjects[file.Hash] = true
+			}
 			continue
-		} else {
-			removedCount++
-			logger.Info(ctx, fmt.Sprintf("backup removed: %s, date: %s", backup.Id, util.FormatTimestamp(backup.Timestamp)))
 		}
+		removedCount++
+		logger.Info(ctx, fmt.Sprintf("backup removed: %s, date: %s", snapshot.Id, util.FormatTimestamp(snapshot.Timestamp)))
 	}
 
-	logger.Info(ctx, fmt.Sprintf("backups cleaned successfully, removed count: %d", removedCount))
+	// remove objects which are not used by any backup
+	removedObjectCount := 0
+	walkErr := filepath.WalkDir(getBackupObjectDirectory(), func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.IsDir() || referencedObjects[strings.TrimSuffix(entry.Name(), ".tmp")] {
+			return nil
+		}
+		if rmErr := os.Remove(filePath); rmErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to remove backup object: %s", rmErr.Error()))
+			return nil
+		}
+		removedObjectCount++
+		return nil
+	})
+	if walkErr != nil && !os.IsNotExist(walkErr) {
+		logger.Error(ctx, fmt.Sprintf("failed to clean backup objects: %s", walkErr.Error()))
+	}
+
+	logger.Info(ctx, fmt.Sprintf("backups cleaned successfully, removed count: %d, removed objects: %d", removedCount, removedObjectCount))
 	return nil
 }
+
+// selectBackupsToKeep returns ids of the latest backup of each of the last N days, weeks and months. The latest backup is always kept
+func selectBackupsToKeep(backups []Backup, retention BackupRetention) map[string]bool {
+	sorted := slices.Clone(backups)
+	slices.SortFunc(sorted, func(a, b Backup) int {
+		return int(b.Timestamp - a.Timestamp)
+	})
+
+	keep := map[string]bool{}
+	if len(sorted) > 0 {
+		keep[sorted[0].Id] = true
+	}
+
+	keepLatestOfPeriods := func(count int, getPeriod func(t time.Time) string) {
+		periods := map[string]bool{}
+		for _, backup := range sorted {
+			period := getPeriod(time.UnixMilli(backup.Timestamp))
+			if periods[period] {
+				continue
+			}
+			if len(periods) >= count {
+				return
+			}
+			periods[period] = true
+			keep[backup.Id] = true
+		}
+	}
+	keepLatestOfPeriods(retention.Daily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepLatestOfPeriods(retention.Weekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	keepLatestOfPeriods(retention.Monthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	return keep
+}
+
+// convertLegacyBackups converts backups made by older versions, which copied the whole user data directory, to snapshots
+func (m *Manager) convertLegacyBackups(ctx context.Context) {
+	m.backupLock.Lock()
+	defer m.backupLock.Unlock()
+
+	backupDir := util.GetLocation().GetBackupDirectory()
+	entries, readDirErr := os.ReadDir(backupDir)
+	if readDirErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to read backup directory: %s", readDirErr.Error()))
+		return
+	}
+
+	for _, entry := range entries {
+		legacyPath := path.Join(backupDir, entry.Name())
+		if !entry.IsDir() || !util.IsFileExists(path.Join(legacyPath, "backup.json")) {
+			continue
+		}
+
+		file, readErr := os.ReadFile(path.Join(legacyPath, "backup.json"))
+		if readErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to read legacy backup info file: %s", readErr.Error()))
+			continue
+		}
+		var backup Backup
+		if decodeErr := json.Unmarshal(file, &backup); decodeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to unmarshal legacy backup info: %s", decodeErr.Error()))
+			continue
+		}
+
+		files, indexErr := m.indexBackupFiles(ctx, legacyPath)
+		if indexErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to index legacy backup %s: %s", entry.Name(), indexErr.Error()))
+			continue
+		}
+		files = lo.Filter(files, func(file backupFile, _ int) bool {
+			return file.Path != "backup.json"
+		})
+		saveErr := m.saveBackupSnapshot(ctx, legacyPath, backupSnapshot{Backup: backup, Files: files})
+		if saveErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to convert legacy backup %s: %s", entry.Name(), saveErr.Error()))
+			continue
+		}
+		for _, file := range files {
+			delete(m.backupHashCache, path.Join(legacyPath, file.Path))
+		}
+
+		if rmErr := os.RemoveAll(legacyPath); rmErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to remove legacy backup: %s", rmErr.Error()))
+		}
+		logger.Info(ctx, fmt.Sprintf("legacy backup converted: %s, date: %s", backup.Id, util.FormatTimestamp(backup.Timestamp)))
+	}
+}
+
+func verifyBackupSnapshot(snapshot backupSnapshot) error {
+	verified := map[string]bool{}
+	for _, file := range snapshot.Files {
+		if verified[file.Hash] {
+			continue
+		}
+
+		hash, hashErr := hashBackupObject(file.Hash)
+		if hashErr != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Path, hashErr)
+		}
+		if hash != file.Hash {
+			return fmt.Errorf("content of %s is changed", file.Path)
+		}
+		verified[file.Hash] = true
+	}
+
+	return nil
+}
+
+// storeBackupObject compresses file into object store, returns false if object is already stored
+func storeBackupObject(filePath string, hash string) (bool, error) {
+	objectPath := getBackupObjectPath(hash)
+	if util.IsFileExists(objectPath) {
+		return false, nil
+	}
+	if mkdirErr := os.MkdirAll(path.Dir(objectPath), 0755); mkdirErr != nil {
+		return false, mkdirErr
+	}
+
+	source, openErr := os.Open(filePath)
+	if openErr != nil {
+		return false, openErr
+	}
+	defer source.Close()
+
+	object, createErr := os.Create(objectPath + ".tmp")
+	if createErr != nil {
+		return false, createErr
+	}
+	gzipWriter := gzip.NewWriter(object)
+	_, copyErr := io.Copy(gzipWriter, source)
+	closeErr := gzipWriter.Close()
+	object.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		os.Remove(objectPath + ".tmp")
+		return false, copyErr
+	}
+
+	return true, os.Rename(objectPath+".tmp", objectPath)
+}
+
+func restoreBackupObject(file backupFile, targetPath string) error {
+	object, openErr := os.Open(getBackupObjectPath(file.Hash))
+	if openErr != nil {
+		return openErr
+	}
+	defer object.Close()
+
+	gzipReader, readerErr := gzip.NewReader(object)
+	if readerErr != nil {
+		return readerErr
+	}
+	defer gzipReader.Close()
+
+	if mkdirErr := os.MkdirAll(path.Dir(targetPath), 0755); mkdirErr != nil {
+		return mkdirErr
+	}
+	target, createErr := os.OpenFile(targetPath+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, file.Mode|0200)
+	if createErr != nil {
+		return createErr
+	}
+	_, copyErr := io.Copy(target, gzipReader)
+	target.Close()
+	if copyErr == nil {
+		copyErr = os.Rename(targetPath+".tmp", targetPath)
+	}
+	if copyErr != nil {
+		os.Remove(targetPath + ".tmp")
+		return copyErr
+	}
+
+	return nil
+}
+
+func hashBackupFile(filePath string) (string, error) {
+	file, openErr := os.Open(filePath)
+	if openErr != nil {
+		return "", openErr
+	}
+	defer file.Close()
+
+	return hashReader(file)
+}
+
+func hashBackupObject(hash string) (string, error) {
+	object, openErr := os.Open(getBackupObjectPath(hash))
+	if openErr != nil {
+		return "", openErr
+	}
+	defer object.Close()
+
+	gzipReader, readerErr := gzip.NewReader(object)
+	if readerErr != nil {
+		return "", readerErr
+	}
+	defer gzipReader.Close()
+
+	return hashReader(gzipReader)
+}
+
+func hashReader(reader io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, copyErr := io.Copy(hasher, reader); copyErr != nil {
+		return "", copyErr
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func getBackupObjectDirectory() string {
+	return path.Join(util.GetLocation().GetBackupDirectory(), "objects")
+}
+
+func getBackupObjectPath(hash string) string {
+	return path.Join(getBackupObjectDirectory(), hash[:2], hash)
+}
+
+func getBackupSnapshotDirectory() string {
+	return path.Join(util.GetLocation().GetBackupDirectory(), "snapshots")
+}
+
+func getBackupSnapshotPath(name string) string {
+	return path.Join(getBackupSnapshotDirectory(), fmt.Sprintf("%s.json", name))
+}
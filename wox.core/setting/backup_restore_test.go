@@ -0,0 +1,209 @@
+package setting
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+	"wox/util"
+)
+
+func TestSelectBackupsToKeep(t *testing.T) {
+	// two backups per day in the last 100 days
+	now := time.Date(2024, 6, 30, 20, 0, 0, 0, time.Local)
+	var backups []Backup
+	for day := 0; day < 100; day++ {
+		for _, hour := range []int{0, 10} {
+			ts := now.AddDate(0, 0, -day).Add(-time.Duration(hour) * time.Hour)
+			backups = append(backups, Backup{Id: fmt.Sprintf("%d-%d", day, hour), Timestamp: ts.UnixMilli()})
+		}
+	}
+
+	keep := selectBackupsToKeep(backups, BackupRetention{Daily: 3, Weekly: 2, Monthly: 3})
+	// daily: latest backup of 06-30, 06-29, 06-28
+	assert.True(t, keep["0-0"])
+	assert.True(t, keep["1-0"])
+	assert.True(t, keep["2-0"])
+	assert.False(t, keep["0-10"])
+	assert.False(t, keep["3-0"])
+	// weekly: 06-30 is Sunday, previous week ends at 06-23
+	assert.True(t, keep["7-0"])
+	assert.False(t, keep["14-0"])
+	// monthly: june, may and april
+	assert.True(t, keep["30-0"])
+	assert.True(t, keep["61-0"])
+	assert.False(t, keep["91-0"])
+	assert.Len(t, keep, 6)
+
+	assert.Len(t, selectBackupsToKeep(backups, BackupRetention{}), 1)
+	assert.Len(t, selectBackupsToKeep(nil, BackupRetention{Daily: 1}), 0)
+}
+
+func writeTestUserDataFile(t *testing.T, relativePath string, content string) {
+	filePath := path.Join(util.GetLocation().GetUserDataDirectory(), relativePath)
+	assert.Nil(t, os.MkdirAll(path.Dir(filePath), 0755))
+	assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+}
+
+func readTestUserDataFile(t *testing.T, relativePath string) string {
+	content, readErr := os.ReadFile(path.Join(util.GetLocation().GetUserDataDirectory(), relativePath))
+	assert.Nil(t, readErr)
+	return string(content)
+}
+
+func countTestBackupObjects(t *testing.T) int {
+	count := 0
+	walkErr := filepath.WalkDir(getBackupObjectDirectory(), func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr == nil && !entry.IsDir() {
+			count++
+		}
+		return walkErr
+	})
+	assert.Nil(t, walkErr)
+	return count
+}
+
+// newTestBackup makes a backup, snapshot names are timestamps so backups made in the same millisecond would collide
+func newTestBackup(t *testing.T, m *Manager) backupSnapshot {
+	time.Sleep(2 * time.Millisecond)
+	snapshot, backupErr := m.backup(context.Background(), BackupTypeManual)
+	assert.Nil(t, backupErr)
+	time.Sleep(2 * time.Millisecond)
+	return snapshot
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	writeTestUserDataFile(t, "settings/a.json", "a")
+	writeTestUserDataFile(t, "themes/b.json", "b")
+	writeTestUserDataFile(t, "settings/removed.json", "removed")
+	writeTestUserDataFile(t, "plugins/p/cache/ignored.txt", "cache is never backed up")
+	snapshot := newTestBackup(t, m)
+	assert.Len(t, snapshot.Files, 3)
+
+	writeTestUserDataFile(t, "settings/a.json", "a changed")
+	writeTestUserDataFile(t, "settings/new.json", "new")
+	assert.Nil(t, os.Remove(path.Join(util.GetLocation().GetUserDataDirectory(), "settings/removed.json")))
+
+	changes, previewErr := m.PreviewRestore(ctx, snapshot.Id)
+	assert.Nil(t, previewErr)
+	assert.Equal(t, []BackupChange{
+		{Path: "settings/a.json", Type: BackupChangeModified},
+		{Path: "settings/new.json", Type: BackupChangeRemoved},
+		{Path: "settings/removed.json", Type: BackupChangeAdded},
+	}, changes)
+	assert.Equal(t, "a changed", readTestUserDataFile(t, "settings/a.json"))
+
+	assert.Nil(t, m.Restore(ctx, snapshot.Id))
+	assert.Equal(t, "a", readTestUserDataFile(t, "settings/a.json"))
+	assert.Equal(t, "b", readTestUserDataFile(t, "themes/b.json"))
+	assert.Equal(t, "removed", readTestUserDataFile(t, "settings/removed.json"))
+	assert.NoFileExists(t, path.Join(util.GetLocation().GetUserDataDirectory(), "settings/new.json"))
+	assert.Equal(t, "cache is never backed up", readTestUserDataFile(t, "plugins/p/cache/ignored.txt"))
+
+	// data before restore is backed up, so restore can be undone
+	backups, findErr := m.FindAllBackups(ctx)
+	assert.Nil(t, findErr)
+	restoreBackup, found := lo.Find(backups, func(backup Backup) bool {
+		return backup.Type == BackupTypeRestore
+	})
+	assert.True(t, found)
+	time.Sleep(2 * time.Millisecond)
+	assert.Nil(t, m.Restore(ctx, restoreBackup.Id))
+	assert.Equal(t, "a changed", readTestUserDataFile(t, "settings/a.json"))
+	assert.Equal(t, "new", readTestUserDataFile(t, "settings/new.json"))
+	assert.NoFileExists(t, path.Join(util.GetLocation().GetUserDataDirectory(), "settings/removed.json"))
+}
+
+func TestBackupStoresUnchangedFilesOnce(t *testing.T) {
+	m := newTestManager(t)
+	writeTestUserDataFile(t, "settings/a.json", "same")
+	writeTestUserDataFile(t, "themes/copy.json", "same")
+	newTestBackup(t, m)
+	newTestBackup(t, m)
+	assert.Equal(t, 1, countTestBackupObjects(t))
+
+	writeTestUserDataFile(t, "settings/a.json", "changed content")
+	newTestBackup(t, m)
+	assert.Equal(t, 2, countTestBackupObjects(t))
+}
+
+func TestCleanBackupsRemovesUnreferencedObjects(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	writeTestUserDataFile(t, "settings/a.json", "old content")
+	writeTestUserDataFile(t, "themes/b.json", "kept")
+	newTestBackup(t, m)
+	writeTestUserDataFile(t, "settings/a.json", "new content!")
+	latest := newTestBackup(t, m)
+	assert.Equal(t, 3, countTestBackupObjects(t))
+
+	assert.Nil(t, m.cleanBackups(ctx, BackupRetention{Daily: 1}))
+	backups, findErr := m.FindAllBackups(ctx)
+	assert.Nil(t, findErr)
+	assert.Equal(t, []string{latest.Id}, lo.Map(backups, func(backup Backup, _ int) string {
+		return backup.Id
+	}))
+	assert.Equal(t, 2, countTestBackupObjects(t))
+	assert.Nil(t, m.VerifyBackup(ctx, latest.Id))
+}
+
+func TestDamagedBackupIsNotRestored(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	writeTestUserDataFile(t, "settings/a.json", "original")
+	snapshot := newTestBackup(t, m)
+	assert.Nil(t, m.VerifyBackup(ctx, snapshot.Id))
+	writeTestUserDataFile(t, "settings/a.json", "current")
+
+	// object is still a valid gzip file, but its content doesn't match the hash
+	object, createErr := os.Create(getBackupObjectPath(snapshot.Files[0].Hash))
+	assert.Nil(t, createErr)
+	gzipWriter := gzip.NewWriter(object)
+	gzipWriter.Write([]byte("tampered"))
+	gzipWriter.Close()
+	object.Close()
+
+	assert.NotNil(t, m.VerifyBackup(ctx, snapshot.Id))
+	assert.NotNil(t, m.Restore(ctx, snapshot.Id))
+	assert.Equal(t, "current", readTestUserDataFile(t, "settings/a.json"))
+
+	assert.Nil(t, os.Remove(getBackupObjectPath(snapshot.Files[0].Hash)))
+	assert.NotNil(t, m.VerifyBackup(ctx, snapshot.Id))
+	assert.NotNil(t, m.Restore(ctx, snapshot.Id))
+}
+
+func TestFailedRestoreIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	writeTestUserDataFile(t, "settings/a.json", "backup")
+	writeTestUserDataFile(t, "settings/x", "backup file")
+	snapshot := newTestBackup(t, m)
+
+	// settings/a.json is restored first, then restoring settings/x fails because it's a directory now
+	writeTestUserDataFile(t, "settings/a.json", "current")
+	assert.Nil(t, os.Remove(path.Join(util.GetLocation().GetUserDataDirectory(), "settings/x")))
+	writeTestUserDataFile(t, "settings/x/y", "current directory")
+
+	assert.NotNil(t, m.Restore(ctx, snapshot.Id))
+	assert.Equal(t, "current", readTestUserDataFile(t, "settings/a.json"))
+	assert.Equal(t, "current directory", readTestUserDataFile(t, "settings/x/y"))
+	assert.NoFileExists(t, path.Join(util.GetLocation().GetUserDataDirectory(), "settings/x.tmp"))
+}
+
+func TestBackupRetentionBeforeSettingLoaded(t *testing.T) {
+	// backup before migrating wox setting is cleaned with default retention
+	m := &Manager{}
+	assert.Equal(t, defaultBackupRetention, m.getBackupRetention())
+
+	m.woxSetting = &WoxSetting{BackupRetention: BackupRetention{Daily: 1}}
+	assert.Equal(t, BackupRetention{Daily: 1}, m.getBackupRetention())
+}
@@ -20,6 +20,11 @@ const (
 	PluginSettingDefinitionTypeLabel         PluginSettingDefinitionType = "label"
 	PluginSettingDefinitionTypeNewLine       PluginSettingDefinitionType = "newline"
 	PluginSettingDefinitionTypeTable         PluginSettingDefinitionType = "table"
+	PluginSettingDefinitionTypeNumber        PluginSettingDefinitionType = "number"
+	PluginSettingDefinitionTypePassword      PluginSettingDefinitionType = "password"
+	PluginSettingDefinitionTypeHotkey        PluginSettingDefinitionType = "hotkey"
+	PluginSettingDefinitionTypeFilePath      PluginSettingDefinitionType = "filepath"
+	PluginSettingDefinitionTypeColor         PluginSettingDefinitionType = "color"
 
 	// dynamic setting will be replaced by the actual setting when retrieved
 	// this is useful when the setting is dynamic. E.g. a list of plugins for select
@@ -33,11 +38,43 @@ type PluginSettingDefinitionValue interface {
 	Translate(translator func(ctx context.Context, key string) string)
 }
 
+// PluginSettingDefinitionValueValidatable is implemented by setting values which can be validated before saving
+type PluginSettingDefinitionValueValidatable interface {
+	Validate(value string) error
+}
+
 type PluginSettingDefinitionItem struct {
 	Type                PluginSettingDefinitionType
 	Value               PluginSettingDefinitionValue
 	DisabledInPlatforms []util.Platform
-	IsPlatformSpecific  bool // if true, this setting may be different in different platforms
+	IsPlatformSpecific  bool                    // if true, this setting may be different in different platforms
+	ShowWhen            *PluginSettingCondition // if set, this setting is only shown when the condition is satisfied
+	IsSecret            bool                    // if true, value is kept in secret store instead of plugin setting file, password setting is always secret
+}
+
+// IsSecretSetting returns true if the value should be kept in secret store
+func (n *PluginSettingDefinitionItem) IsSecretSetting() bool {
+	return n.IsSecret || n.Type == PluginSettingDefinitionTypePassword
+}
+
+// PluginSettingCondition is satisfied when the value of setting Key is one of Values
+type PluginSettingCondition struct {
+	Key    string
+	Values []string
+}
+
+func (c *PluginSettingCondition) IsSatisfied(getValue func(key string) string) bool {
+	if c == nil {
+		return true
+	}
+
+	value := getValue(c.Key)
+	for _, v := range c.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 type PluginSettingValueStyle struct {
@@ -115,10 +152,62 @@ func (n *PluginSettingDefinitionItem) UnmarshalJSON(b []byte) error {
 			return unmarshalErr
 		}
 		n.Value = &v
+	case "number":
+		n.Type = PluginSettingDefinitionTypeNumber
+		var v PluginSettingValueNumber
+		unmarshalErr := json.Unmarshal([]byte(contentResult.String()), &v)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+		n.Value = &v
+	case "password":
+		n.Type = PluginSettingDefinitionTypePassword
+		var v PluginSettingValuePassword
+		unmarshalErr := json.Unmarshal([]byte(contentResult.String()), &v)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+		n.Value = &v
+	case "hotkey":
+		n.Type = PluginSettingDefinitionTypeHotkey
+		var v PluginSettingValueHotkey
+		unmarshalErr := json.Unmarshal([]byte(contentResult.String()), &v)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+		n.Value = &v
+	case "filepath":
+		n.Type = PluginSettingDefinitionTypeFilePath
+		var v PluginSettingValueFilePath
+		unmarshalErr := json.Unmarshal([]byte(contentResult.String()), &v)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+		n.Value = &v
+	case "color":
+		n.Type = PluginSettingDefinitionTypeColor
+		var v PluginSettingValueColor
+		unmarshalErr := json.Unmarshal([]byte(contentResult.String()), &v)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+		n.Value = &v
 	default:
 		return errors.New("unknown setting type: " + value.String())
 	}
 
+	n.IsSecret = gjson.GetBytes(b, "IsSecret").Bool()
+
+	showWhenResult := gjson.GetBytes(b, "ShowWhen")
+	if showWhenResult.Exists() && showWhenResult.Type != gjson.Null {
+		var condition PluginSettingCondition
+		unmarshalErr := json.Unmarshal([]byte(showWhenResult.Raw), &condition)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+		n.ShowWhen = &condition
+	}
+
 	return nil
 }
 
@@ -134,6 +223,32 @@ func (c PluginSettingDefinitions) GetDefaultValue(key string) (string, bool) {
 	return "", false
 }
 
+func (c PluginSettingDefinitions) GetDefinition(key string) (PluginSettingDefinitionItem, bool) {
+	for _, item := range c {
+		if item.Value != nil && item.Value.GetKey() == key {
+			return item, true
+		}
+	}
+
+	return PluginSettingDefinitionItem{}, false
+}
+
+// Validate checks value of the given key before saving. Unknown keys and hidden settings are not validated
+func (c PluginSettingDefinitions) Validate(key string, value string, getValue func(key string) string) error {
+	item, found := c.GetDefinition(key)
+	if !found {
+		return nil
+	}
+	if !item.ShowWhen.IsSatisfied(getValue) {
+		return nil
+	}
+
+	if v, ok := item.Value.(PluginSettingDefinitionValueValidatable); ok {
+		return v.Validate(value)
+	}
+	return nil
+}
+
 func (c PluginSettingDefinitions) GetAllDefaults() (settings *util.HashMap[string, string]) {
 	settings = util.NewHashMap[string, string]()
 	for _, item := range c {
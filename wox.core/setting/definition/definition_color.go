@@ -0,0 +1,42 @@
+package definition
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+var colorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// PluginSettingValueColor stores color in hex format, E.g. #RRGGBB or #AARRGGBB
+type PluginSettingValueColor struct {
+	Key          string
+	Label        string
+	DefaultValue string
+	Tooltip      string
+
+	Style PluginSettingValueStyle
+}
+
+func (p *PluginSettingValueColor) GetPluginSettingType() PluginSettingDefinitionType {
+	return PluginSettingDefinitionTypeColor
+}
+
+func (p *PluginSettingValueColor) GetKey() string {
+	return p.Key
+}
+
+func (p *PluginSettingValueColor) GetDefaultValue() string {
+	return p.DefaultValue
+}
+
+func (p *PluginSettingValueColor) Translate(translator func(ctx context.Context, key string) string) {
+	p.Label = translator(context.Background(), p.Label)
+}
+
+func (p *PluginSettingValueColor) Validate(value string) error {
+	if value == "" || colorRegex.MatchString(value) {
+		return nil
+	}
+	return fmt.Errorf("%s is not a valid color, expect #RRGGBB or #AARRGGBB", value)
+}
@@ -0,0 +1,38 @@
+package definition
+
+import (
+	"context"
+	"wox/setting/validator"
+)
+
+type PluginSettingValueFilePath struct {
+	Key          string
+	Label        string
+	DefaultValue string
+	Tooltip      string
+	IsDirectory  bool                               // if true, UI will pick a directory instead of a file
+	Extensions   []string                           // allowed file extensions without dot, E.g. ["png", "jpg"], only used when IsDirectory is false
+	Validators   []validator.PluginSettingValidator // validators for this setting, every validator should be satisfied
+
+	Style PluginSettingValueStyle
+}
+
+func (p *PluginSettingValueFilePath) GetPluginSettingType() PluginSettingDefinitionType {
+	return PluginSettingDefinitionTypeFilePath
+}
+
+func (p *PluginSettingValueFilePath) GetKey() string {
+	return p.Key
+}
+
+func (p *PluginSettingValueFilePath) GetDefaultValue() string {
+	return p.DefaultValue
+}
+
+func (p *PluginSettingValueFilePath) Translate(translator func(ctx context.Context, key string) string) {
+	p.Label = translator(context.Background(), p.Label)
+}
+
+func (p *PluginSettingValueFilePath) Validate(value string) error {
+	return validator.Validate(p.Validators, value)
+}
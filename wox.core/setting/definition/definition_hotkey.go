@@ -0,0 +1,32 @@
+package definition
+
+import (
+	"context"
+)
+
+// PluginSettingValueHotkey is recorded by hotkey recorder in UI, E.g. "ctrl+shift+space".
+// Availability of the hotkey is checked when user updates it
+type PluginSettingValueHotkey struct {
+	Key          string
+	Label        string
+	DefaultValue string
+	Tooltip      string
+
+	Style PluginSettingValueStyle
+}
+
+func (p *PluginSettingValueHotkey) GetPluginSettingType() PluginSettingDefinitionType {
+	return PluginSettingDefinitionTypeHotkey
+}
+
+func (p *PluginSettingValueHotkey) GetKey() string {
+	return p.Key
+}
+
+func (p *PluginSettingValueHotkey) GetDefaultValue() string {
+	return p.DefaultValue
+}
+
+func (p *PluginSettingValueHotkey) Translate(translator func(ctx context.Context, key string) string) {
+	p.Label = translator(context.Background(), p.Label)
+}
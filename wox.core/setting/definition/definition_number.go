@@ -0,0 +1,61 @@
+package definition
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"wox/setting/validator"
+)
+
+type PluginSettingValueNumber struct {
+	Key          string
+	Label        string
+	Suffix       string
+	DefaultValue string
+	Tooltip      string
+	Min          *float64                           // optional lower bound, inclusive
+	Max          *float64                           // optional upper bound, inclusive
+	Step         float64                            // step used by UI spinner, default 1
+	IsInteger    bool                               // if true, only integer is allowed
+	Validators   []validator.PluginSettingValidator // validators for this setting, every validator should be satisfied
+
+	Style PluginSettingValueStyle
+}
+
+func (p *PluginSettingValueNumber) GetPluginSettingType() PluginSettingDefinitionType {
+	return PluginSettingDefinitionTypeNumber
+}
+
+func (p *PluginSettingValueNumber) GetKey() string {
+	return p.Key
+}
+
+func (p *PluginSettingValueNumber) GetDefaultValue() string {
+	return p.DefaultValue
+}
+
+func (p *PluginSettingValueNumber) Translate(translator func(ctx context.Context, key string) string) {
+	p.Label = translator(context.Background(), p.Label)
+	p.Suffix = translator(context.Background(), p.Suffix)
+}
+
+func (p *PluginSettingValueNumber) Validate(value string) error {
+	if strings.TrimSpace(value) != "" {
+		number, parseErr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if parseErr != nil {
+			return fmt.Errorf("%s is not a number", value)
+		}
+		if p.IsInteger && number != float64(int64(number)) {
+			return fmt.Errorf("%s is not an integer", value)
+		}
+		if p.Min != nil && number < *p.Min {
+			return fmt.Errorf("%s is less than %v", value, *p.Min)
+		}
+		if p.Max != nil && number > *p.Max {
+			return fmt.Errorf("%s is greater than %v", value, *p.Max)
+		}
+	}
+
+	return validator.Validate(p.Validators, value)
+}
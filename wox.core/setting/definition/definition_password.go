@@ -0,0 +1,43 @@
+package definition
+
+import (
+	"context"
+	"wox/setting/validator"
+)
+
+// PluginSettingSecretMask is sent to UI instead of the real value of a secret setting.
+// Submitting the mask back means the value is unchanged
+const PluginSettingSecretMask = "********"
+
+// PluginSettingValuePassword is always kept in secret store instead of plugin setting file
+type PluginSettingValuePassword struct {
+	Key        string
+	Label      string
+	Suffix     string
+	Tooltip    string
+	Validators []validator.PluginSettingValidator // validators for this setting, every validator should be satisfied
+
+	Style PluginSettingValueStyle
+}
+
+func (p *PluginSettingValuePassword) GetPluginSettingType() PluginSettingDefinitionType {
+	return PluginSettingDefinitionTypePassword
+}
+
+func (p *PluginSettingValuePassword) GetKey() string {
+	return p.Key
+}
+
+// GetDefaultValue returns empty, password can't have a default value
+func (p *PluginSettingValuePassword) GetDefaultValue() string {
+	return ""
+}
+
+func (p *PluginSettingValuePassword) Translate(translator func(ctx context.Context, key string) string) {
+	p.Label = translator(context.Background(), p.Label)
+	p.Suffix = translator(context.Background(), p.Suffix)
+}
+
+func (p *PluginSettingValuePassword) Validate(value string) error {
+	return validator.Validate(p.Validators, value)
+}
@@ -2,6 +2,7 @@ package definition
 
 import (
 	"context"
+	"fmt"
 	"wox/setting/validator"
 )
 
@@ -41,3 +42,20 @@ func (p *PluginSettingValueSelect) Translate(translator func(ctx context.Context
 		p.Options[i].Label = translator(context.Background(), p.Options[i].Label)
 	}
 }
+
+func (p *PluginSettingValueSelect) Validate(value string) error {
+	if value != "" && len(p.Options) > 0 {
+		isOption := false
+		for _, option := range p.Options {
+			if option.Value == value {
+				isOption = true
+				break
+			}
+		}
+		if !isOption {
+			return fmt.Errorf("%s is not a valid option", value)
+		}
+	}
+
+	return validator.Validate(p.Validators, value)
+}
@@ -32,3 +32,7 @@ func (p *PluginSettingValueSelectAiModel) Translate(translator func(ctx context.
 	p.Label = translator(context.Background(), p.Label)
 	p.Suffix = translator(context.Background(), p.Suffix)
 }
+
+func (p *PluginSettingValueSelectAiModel) Validate(value string) error {
+	return validator.Validate(p.Validators, value)
+}
@@ -2,6 +2,8 @@ package definition
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"wox/setting/validator"
 )
 
@@ -66,3 +68,31 @@ func (p *PluginSettingValueTable) Translate(translator func(ctx context.Context,
 		p.Columns[i].Tooltip = translator(context.Background(), p.Columns[i].Tooltip)
 	}
 }
+
+// Validate checks every row against column validators. Only text and select columns are validated
+func (p *PluginSettingValueTable) Validate(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	var rows []map[string]any
+	unmarshalErr := json.Unmarshal([]byte(value), &rows)
+	if unmarshalErr != nil {
+		return fmt.Errorf("table value must be a json array: %w", unmarshalErr)
+	}
+
+	for i, row := range rows {
+		for _, column := range p.Columns {
+			if column.Type != PluginSettingValueTableColumnTypeText && column.Type != PluginSettingValueTableColumnTypeSelect {
+				continue
+			}
+
+			cellValue, _ := row[column.Key].(string)
+			if err := validator.Validate(column.Validators, cellValue); err != nil {
+				return fmt.Errorf("row %d, column %s: %w", i+1, column.Label, err)
+			}
+		}
+	}
+
+	return nil
+}
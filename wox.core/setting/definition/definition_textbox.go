@@ -32,3 +32,7 @@ func (p *PluginSettingValueTextBox) Translate(translator func(ctx context.Contex
 	p.Label = translator(context.Background(), p.Label)
 	p.Suffix = translator(context.Background(), p.Suffix)
 }
+
+func (p *PluginSettingValueTextBox) Validate(value string) error {
+	return validator.Validate(p.Validators, value)
+}
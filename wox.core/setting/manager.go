@@ -8,12 +8,12 @@ import (
 	"path"
 	"slices"
 	"sync"
-	"wox/i18n"
+	"sync/atomic"
 	"wox/setting/definition"
 	"wox/share"
 	"wox/util"
 	"wox/util/autostart"
-	"wox/util/hotkey"
+	"wox/util/notifier"
 
 	"github.com/tidwall/pretty"
 )
@@ -23,16 +23,37 @@ var managerOnce sync.Once
 var logger *util.Log
 
 type Manager struct {
-	woxSetting *WoxSetting
-	woxAppData *WoxAppData
+	woxSetting          *WoxSetting                // setting saved in setting file, without profile overrides
+	effectiveWoxSetting atomic.Pointer[WoxSetting] // setting in effect, base setting with active profile overrides applied. Replaced as a whole on change
+	woxAppData          *WoxAppData
+
+	aiProviderApiKeys        map[string]string // ai provider secret id => api key
+	aiProviderApiKeysLoadErr error             // api keys are not saved when they failed to load, otherwise they would be lost
+
+	activeProfileId         string       // profile in effect, may be activated by rules
+	ruleMatchedProfileId    string       // profile matched by rules on last check
+	settingLock             sync.RWMutex // guards base setting, profiles and their overrides, active profile
+	profileChangedCallbacks []func(ctx context.Context, change ProfileChange)
+	settingChangedListeners []woxSettingChangedListener
+
+	syncer                    syncer
+	settingsImportedCallbacks []func(ctx context.Context, event SettingsImportedEvent)
+
+	backupLock      sync.Mutex                      // guards backup store, only one backup, restore or clean at a time
+	backupHashCache map[string]backupHashCacheEntry // file path => hash, so unchanged files are not hashed again
+
+	migrationBackupOnce sync.Once // only backup once before migrating, even multiple setting files need migration
 }
 
 func GetSettingManager() *Manager {
 	managerOnce.Do(func() {
+		// default app data makes favorite and actioned results lookup safe before Init, e.g. in plugin tests
+		defaultAppData := GetDefaultWoxAppData(context.Background())
 		managerInstance = &Manager{
 			woxSetting: &WoxSetting{},
-			woxAppData: &WoxAppData{},
+			woxAppData: &defaultAppData,
 		}
+		managerInstance.effectiveWoxSetting.Store(&WoxSetting{})
 		logger = util.GetLogger()
 	})
 	return managerInstance
@@ -52,7 +73,19 @@ func (m *Manager) Init(ctx context.Context) error {
 		m.woxAppData = &defaultWoxAppData
 	}
 
+	m.initActiveProfile(ctx)
+
 	m.StartAutoBackup(ctx)
+	m.StartProfileAutoSwitch(ctx)
+	m.StartSync(ctx)
+	m.SubscribeWoxSettingChanged(func(ctx context.Context, key string, value string) {
+		m.StartSync(ctx)
+	}, "SyncType", "SyncPath")
+	m.SubscribeWoxSettingChanged(func(ctx context.Context, key string, value string) {
+		if definition, found := GetWoxSettingDefinition(key); found && !definition.IsLocal {
+			m.RequestSync(ctx)
+		}
+	})
 
 	//check autostart status, if not match, update the setting
 	actualAutostart, err := autostart.IsAutostart(ctx)
@@ -62,7 +95,10 @@ func (m *Manager) Init(ctx context.Context) error {
 		configAutostart := m.woxSetting.EnableAutostart.Get()
 		if actualAutostart != configAutostart {
 			util.GetLogger().Warn(ctx, fmt.Sprintf("Autostart setting mismatch: config %v, actual %v. Updating config.", configAutostart, actualAutostart))
+			m.settingLock.Lock()
 			m.woxSetting.EnableAutostart.Set(actualAutostart)
+			m.refreshEffectiveWoxSetting(ctx)
+			m.settingLock.Unlock()
 			err := m.SaveWoxSetting(ctx)
 			if err != nil {
 				util.GetLogger().Error(ctx, fmt.Sprintf("Failed to save updated autostart setting: %s", err.Error()))
@@ -89,17 +125,17 @@ func (m *Manager) loadWoxSetting(ctx context.Context) error {
 		}
 	}
 
-	woxSettingFile, openErr := os.Open(woxSettingPath)
-	if openErr != nil {
-		return openErr
+	woxSettingContent, readErr := os.ReadFile(woxSettingPath)
+	if readErr != nil {
+		return readErr
 	}
-	defer woxSettingFile.Close()
 
 	woxSetting := &WoxSetting{}
-	decodeErr := json.NewDecoder(woxSettingFile).Decode(woxSetting)
+	migrated, unknownFields, decodeErr := m.loadWithMigration(ctx, WoxSettingSchema, woxSettingContent, woxSetting)
 	if decodeErr != nil {
 		return decodeErr
 	}
+	woxSetting.UnknownFields = unknownFields
 	// some settings were added later, json file may not have them, so we need to set them to default value
 	if woxSetting.MainHotkey.Get() == "" {
 		woxSetting.MainHotkey.Set(defaultWoxSetting.MainHotkey.Get())
@@ -119,8 +155,25 @@ func (m *Manager) loadWoxSetting(ctx context.Context) error {
 	if woxSetting.ThemeId == "" {
 		woxSetting.ThemeId = defaultWoxSetting.ThemeId
 	}
+	if woxSetting.BackupRetention == (BackupRetention{}) {
+		woxSetting.BackupRetention = defaultWoxSetting.BackupRetention
+	}
 
+	hasPlaintextSecrets := m.loadAIProviderApiKeys(ctx, woxSetting)
+
+	m.settingLock.Lock()
 	m.woxSetting = woxSetting
+	m.settingLock.Unlock()
+
+	if hasPlaintextSecrets {
+		// api keys were saved in plaintext by older versions, move them to secret store
+		logger.Info(ctx, "plaintext api keys found in wox setting, move them to secret store")
+	}
+	if hasPlaintextSecrets || migrated {
+		if saveErr := m.SaveWoxSetting(ctx); saveErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to save migrated wox setting: %s", saveErr.Error()))
+		}
+	}
 
 	return nil
 }
@@ -140,17 +193,17 @@ func (m *Manager) loadWoxAppData(ctx context.Context) error {
 		}
 	}
 
-	woxAppDataFile, openErr := os.Open(woxAppDataPath)
-	if openErr != nil {
-		return openErr
+	woxAppDataContent, readErr := os.ReadFile(woxAppDataPath)
+	if readErr != nil {
+		return readErr
 	}
-	defer woxAppDataFile.Close()
 
 	woxAppData := &WoxAppData{}
-	decodeErr := json.NewDecoder(woxAppDataFile).Decode(woxAppData)
+	migrated, unknownFields, decodeErr := m.loadWithMigration(ctx, WoxAppDataSchema, woxAppDataContent, woxAppData)
 	if decodeErr != nil {
 		return decodeErr
 	}
+	woxAppData.UnknownFields = unknownFields
 	if woxAppData.ActionedResults == nil {
 		woxAppData.ActionedResults = util.NewHashMap[ResultHash, []ActionedResult]()
 	}
@@ -165,11 +218,24 @@ func (m *Manager) loadWoxAppData(ctx context.Context) error {
 
 	m.woxAppData = woxAppData
 
+	if migrated {
+		m.saveWoxAppData(ctx, "schema migrated")
+	}
+
 	return nil
 }
 
+// GetWoxSetting returns the setting in effect, which includes overrides of active profile.
+// Don't modify it directly, use UpdateWoxSetting instead. Changes replace the whole setting, so get it again to see them
 func (m *Manager) GetWoxSetting(ctx context.Context) *WoxSetting {
-	return m.woxSetting
+	return m.effectiveWoxSetting.Load()
+}
+
+// getBaseWoxSettingValue returns the value of a setting without profile overrides
+func (m *Manager) getBaseWoxSettingValue(key string) string {
+	m.settingLock.RLock()
+	defer m.settingLock.RUnlock()
+	return getWoxSettingValue(m.woxSetting, key)
 }
 
 func (m *Manager) UpdateWoxSetting(ctx context.Context, key, value string) error {
@@ -177,71 +243,72 @@ func (m *Manager) UpdateWoxSetting(ctx context.Context, key, value string) error
 		return fmt.Errorf("key is empty")
 	}
 
-	if key == "EnableAutostart" {
-		m.woxSetting.EnableAutostart.Set(value == "true")
-	} else if key == "MainHotkey" {
-		if value != "" {
-			isAvailable := hotkey.IsHotkeyAvailable(ctx, value)
-			if !isAvailable {
-				return fmt.Errorf("hotkey is not available: %s", value)
-			}
-		}
-		m.woxSetting.MainHotkey.Set(value)
-	} else if key == "SelectionHotkey" {
-		isAvailable := hotkey.IsHotkeyAvailable(ctx, value)
-		if !isAvailable {
-			return fmt.Errorf("hotkey is not available: %s", value)
-		}
-		m.woxSetting.SelectionHotkey.Set(value)
-	} else if key == "UsePinYin" {
-		m.woxSetting.UsePinYin = value == "true"
-	} else if key == "SwitchInputMethodABC" {
-		m.woxSetting.SwitchInputMethodABC = value == "true"
-	} else if key == "HideOnStart" {
-		m.woxSetting.HideOnStart = value == "true"
-	} else if key == "HideOnLostFocus" {
-		m.woxSetting.HideOnLostFocus = value == "true"
-	} else if key == "ShowTray" {
-		m.woxSetting.ShowTray = value == "true"
-	} else if key == "LangCode" {
-		newLangCode := i18n.LangCode(value)
-		langErr := i18n.GetI18nManager().UpdateLang(ctx, newLangCode)
-		if langErr != nil {
-			return langErr
-		}
-		m.woxSetting.LangCode = newLangCode
-	} else if key == "LastQueryMode" {
-		m.woxSetting.LastQueryMode = value
-	} else if key == "ThemeId" {
-		m.woxSetting.ThemeId = value
-	} else if key == "QueryHotkeys" {
+	if key == "ActiveProfileId" {
+		return m.SwitchProfile(ctx, value)
+	}
+	if key == "Profiles" {
 		// value is a json string
-		var queryHotkeys []QueryHotkey
-		if unmarshalErr := json.Unmarshal([]byte(value), &queryHotkeys); unmarshalErr != nil {
+		var profiles []Profile
+		if unmarshalErr := json.Unmarshal([]byte(value), &profiles); unmarshalErr != nil {
 			return unmarshalErr
 		}
-		m.woxSetting.QueryHotkeys.Set(queryHotkeys)
-	} else if key == "QueryShortcuts" {
-		// value is a json string
-		var queryShortcuts []QueryShortcut
-		if unmarshalErr := json.Unmarshal([]byte(value), &queryShortcuts); unmarshalErr != nil {
-			return unmarshalErr
+		if validateErr := validateProfiles(profiles); validateErr != nil {
+			return validateErr
 		}
 
-		m.woxSetting.QueryShortcuts = queryShortcuts
-	} else if key == "AIProviders" {
-		// value is a json string
-		var aiModels []AIProvider
-		if unmarshalErr := json.Unmarshal([]byte(value), &aiModels); unmarshalErr != nil {
-			return unmarshalErr
-		}
+		m.applyProfileChange(ctx, func() {
+			m.woxSetting.Profiles = profiles
+			if _, exist := m.getProfile(m.woxSetting.ActiveProfileId); !exist {
+				m.woxSetting.ActiveProfileId = ""
+			}
+		})
+		return m.SaveWoxSetting(ctx)
+	}
 
-		m.woxSetting.AIProviders = aiModels
-	} else {
+	return m.updateWoxSetting(ctx, key, value, true)
+}
+
+// updateWoxSetting validates and applies a registered setting. If allowProfileOverride is true and active profile overrides
+// this setting, the override is updated instead of the base setting
+func (m *Manager) updateWoxSetting(ctx context.Context, key, value string, allowProfileOverride bool) error {
+	definition, found := GetWoxSettingDefinition(key)
+	if !found {
 		return fmt.Errorf("unknown key: %s", key)
 	}
+	if definition.Validator != nil {
+		if validateErr := definition.Validator(ctx, value); validateErr != nil {
+			return validateErr
+		}
+	}
 
-	return m.SaveWoxSetting(ctx)
+	// if active profile overrides this setting, update the override instead of the base setting
+	m.settingLock.Lock()
+	target := m.woxSetting
+	profile, isOverridden := m.getProfile(m.activeProfileId)
+	if isOverridden && allowProfileOverride {
+		_, isOverridden = profile.WoxSettings[key]
+	}
+	isOverridden = isOverridden && allowProfileOverride
+	if isOverridden {
+		target = &WoxSetting{}
+	}
+	if applyErr := applyWoxSettingValue(target, key, value); applyErr != nil {
+		m.settingLock.Unlock()
+		return applyErr
+	}
+	if isOverridden {
+		profile.WoxSettings[key] = value
+	}
+	m.refreshEffectiveWoxSetting(ctx)
+	m.settingLock.Unlock()
+
+	saveErr := m.SaveWoxSetting(ctx)
+	if saveErr != nil {
+		return saveErr
+	}
+
+	m.notifyWoxSettingChanged(ctx, key, getWoxSettingValue(m.effectiveWoxSetting.Load(), key))
+	return nil
 }
 
 func (m *Manager) GetWoxAppData(ctx context.Context) *WoxAppData {
@@ -249,8 +316,29 @@ func (m *Manager) GetWoxAppData(ctx context.Context) *WoxAppData {
 }
 
 func (m *Manager) SaveWoxSetting(ctx context.Context) error {
+	m.settingLock.Lock()
+	// api keys are kept in secret store, never write them to setting file.
+	// Failing to save them is reported on its own, other settings are still saved
+	if secretErr := m.saveAIProviderApiKeys(ctx, m.woxSetting); secretErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to save api keys to secret store: %s", secretErr.Error()))
+		notifier.Notify(fmt.Sprintf("Failed to save AI api keys to secret store: %s", secretErr.Error()))
+	}
+	woxSetting := *m.woxSetting
+	woxSetting.AIProviders = make([]AIProvider, len(m.woxSetting.AIProviders))
+	for i, provider := range m.woxSetting.AIProviders {
+		provider.ApiKey = ""
+		woxSetting.AIProviders[i] = provider
+	}
+	woxSetting.Profiles = cloneProfiles(m.woxSetting.Profiles)
+	for i, profile := range woxSetting.Profiles {
+		if providersJson, overridden := profile.WoxSettings["AIProviders"]; overridden {
+			woxSetting.Profiles[i].WoxSettings["AIProviders"] = stripAIProviderApiKeys(providersJson)
+		}
+	}
+	m.settingLock.Unlock()
+
 	woxSettingPath := util.GetLocation().GetWoxSettingPath()
-	settingJson, marshalErr := json.Marshal(m.woxSetting)
+	settingJson, marshalErr := marshalWithUnknownFields(woxSetting, woxSetting.UnknownFields)
 	if marshalErr != nil {
 		logger.Error(ctx, marshalErr.Error())
 		return marshalErr
@@ -268,7 +356,7 @@ func (m *Manager) SaveWoxSetting(ctx context.Context) error {
 
 func (m *Manager) saveWoxAppData(ctx context.Context, reason string) error {
 	woxAppDataPath := util.GetLocation().GetWoxAppDataPath()
-	settingJson, marshalErr := json.Marshal(m.woxAppData)
+	settingJson, marshalErr := marshalWithUnknownFields(m.woxAppData, m.woxAppData.UnknownFields)
 	if marshalErr != nil {
 		logger.Error(ctx, marshalErr.Error())
 		return marshalErr
@@ -288,8 +376,9 @@ func (m *Manager) LoadPluginSetting(ctx context.Context, pluginId string, plugin
 	pluginSettingPath := path.Join(util.GetLocation().GetPluginSettingDirectory(), fmt.Sprintf("%s.json", pluginId))
 	if _, statErr := os.Stat(pluginSettingPath); os.IsNotExist(statErr) {
 		return &PluginSetting{
-			Name:     pluginName,
-			Settings: defaultSettings.GetAllDefaults(),
+			SchemaVersion: PluginSettingSchema.CurrentVersion(),
+			Name:          pluginName,
+			Settings:      defaultSettings.GetAllDefaults(),
 		}, nil
 	}
 
@@ -299,10 +388,11 @@ func (m *Manager) LoadPluginSetting(ctx context.Context, pluginId string, plugin
 	}
 
 	var pluginSetting = &PluginSetting{}
-	decodeErr := json.Unmarshal(fileContent, pluginSetting)
+	migrated, unknownFields, decodeErr := m.loadWithMigration(ctx, PluginSettingSchema, fileContent, pluginSetting)
 	if decodeErr != nil {
 		return &PluginSetting{}, decodeErr
 	}
+	pluginSetting.UnknownFields = unknownFields
 	if pluginSetting.Settings == nil {
 		pluginSetting.Settings = defaultSettings.GetAllDefaults()
 	}
@@ -317,12 +407,18 @@ func (m *Manager) LoadPluginSetting(ctx context.Context, pluginId string, plugin
 	})
 
 	pluginSetting.Name = pluginName
+	if migrated {
+		m.SavePluginSetting(ctx, pluginId, pluginSetting)
+	}
+
+	m.migratePluginSecrets(ctx, pluginId, pluginSetting, defaultSettings)
+
 	return pluginSetting, nil
 }
 
 func (m *Manager) SavePluginSetting(ctx context.Context, pluginId string, pluginSetting *PluginSetting) error {
 	pluginSettingPath := path.Join(util.GetLocation().GetPluginSettingDirectory(), fmt.Sprintf("%s.json", pluginId))
-	pluginSettingJson, marshalErr := json.Marshal(pluginSetting)
+	pluginSettingJson, marshalErr := marshalWithUnknownFields(pluginSetting, pluginSetting.UnknownFields)
 	if marshalErr != nil {
 		logger.Error(ctx, marshalErr.Error())
 		return marshalErr
@@ -335,6 +431,7 @@ func (m *Manager) SavePluginSetting(ctx context.Context, pluginId string, plugin
 	}
 
 	logger.Info(ctx, fmt.Sprintf("plugin setting saved: %s", pluginId))
+	m.RequestSync(ctx)
 	return nil
 }
 
@@ -0,0 +1,47 @@
+package setting
+
+import (
+	"context"
+	"os"
+	"testing"
+	"wox/util"
+	"wox/util/secret"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain points user data directory to a temporary home, so tests never touch real settings of current user
+func TestMain(m *testing.M) {
+	home, tempErr := os.MkdirTemp("", "wox-setting-test")
+	if tempErr != nil {
+		panic(tempErr)
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+	os.Setenv(secret.PassphraseEnvName, "wox-setting-test")
+	if initErr := util.GetLocation().Init(); initErr != nil {
+		panic(initErr)
+	}
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// newTestManager returns a manager with default settings, an empty user data directory and no backups
+func newTestManager(t *testing.T) *Manager {
+	ctx := context.Background()
+	assert.Nil(t, os.RemoveAll(util.GetLocation().GetUserDataDirectory()))
+	assert.Nil(t, os.RemoveAll(util.GetLocation().GetBackupDirectory()))
+	assert.Nil(t, util.GetLocation().Init())
+
+	GetSettingManager() // initializes package logger
+	woxSetting := GetDefaultWoxSetting(ctx)
+	woxAppData := GetDefaultWoxAppData(ctx)
+	m := &Manager{
+		woxSetting: &woxSetting,
+		woxAppData: &woxAppData,
+	}
+	m.initActiveProfile(ctx)
+	return m
+}
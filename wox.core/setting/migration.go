@@ -0,0 +1,210 @@
+package setting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"wox/util"
+
+	"github.com/samber/lo"
+)
+
+// SettingMigration upgrades raw setting json from previous schema version to Version.
+// Migrations work on raw json instead of structs, so renamed or restructured fields can still be read
+type SettingMigration struct {
+	Version     int // schema version after this migration is applied
+	Description string
+	Migrate     func(ctx context.Context, data map[string]any) error
+}
+
+// SettingSchema holds ordered migrations of a setting file
+type SettingSchema struct {
+	Name       string
+	migrations []SettingMigration
+}
+
+var (
+	WoxSettingSchema    = &SettingSchema{Name: "wox setting"}
+	WoxAppDataSchema    = &SettingSchema{Name: "wox app data"}
+	PluginSettingSchema = &SettingSchema{Name: "plugin setting"}
+)
+
+const schemaVersionKey = "SchemaVersion"
+
+// Register adds a migration, versions must be unique
+func (s *SettingSchema) Register(migration SettingMigration) {
+	if migration.Version <= 0 {
+		panic(fmt.Sprintf("invalid %s migration version: %d", s.Name, migration.Version))
+	}
+	if lo.ContainsBy(s.migrations, func(item SettingMigration) bool { return item.Version == migration.Version }) {
+		panic(fmt.Sprintf("duplicated %s migration version: %d", s.Name, migration.Version))
+	}
+
+	s.migrations = append(s.migrations, migration)
+	slices.SortFunc(s.migrations, func(a, b SettingMigration) int {
+		return a.Version - b.Version
+	})
+}
+
+// CurrentVersion is the schema version after all migrations are applied
+func (s *SettingSchema) CurrentVersion() int {
+	if len(s.migrations) == 0 {
+		return 0
+	}
+	return s.migrations[len(s.migrations)-1].Version
+}
+
+// Migrate runs pending migrations on data in order, returns applied migrations
+func (s *SettingSchema) Migrate(ctx context.Context, data map[string]any) ([]SettingMigration, error) {
+	version := getSchemaVersion(data)
+	pending := lo.Filter(s.migrations, func(item SettingMigration, _ int) bool {
+		return item.Version > version
+	})
+
+	for _, migration := range pending {
+		util.GetLogger().Info(ctx, fmt.Sprintf("migrating %s to version %d: %s", s.Name, migration.Version, migration.Description))
+		if migrateErr := migration.Migrate(ctx, data); migrateErr != nil {
+			return nil, fmt.Errorf("failed to migrate %s to version %d: %w", s.Name, migration.Version, migrateErr)
+		}
+		data[schemaVersionKey] = migration.Version
+	}
+
+	return pending, nil
+}
+
+func getSchemaVersion(data map[string]any) int {
+	if version, ok := data[schemaVersionKey].(json.Number); ok {
+		v, _ := version.Int64()
+		return int(v)
+	}
+	if version, ok := data[schemaVersionKey].(int); ok {
+		return version
+	}
+	return 0
+}
+
+// loadWithMigration migrates setting json to current schema version and decodes it into target (pointer to struct).
+// Returns whether migrations were applied, in which case the setting should be saved, and keys unknown to target
+func (m *Manager) loadWithMigration(ctx context.Context, schema *SettingSchema, content []byte, target any) (migrated bool, unknownFields map[string]json.RawMessage, err error) {
+	data := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber() // keep large numbers such as timestamps untouched
+	if decodeErr := decoder.Decode(&data); decodeErr != nil {
+		return false, nil, decodeErr
+	}
+
+	version := getSchemaVersion(data)
+	if version > schema.CurrentVersion() {
+		logger.Warn(ctx, fmt.Sprintf("%s schema version %d is newer than supported version %d, it may be created by a newer Wox", schema.Name, version, schema.CurrentVersion()))
+	}
+	if version < schema.CurrentVersion() {
+		// take a backup before touching user data, so a broken migration can be restored
+		if backupErr := m.backupBeforeMigration(ctx); backupErr != nil {
+			return false, nil, fmt.Errorf("failed to backup before migrating %s: %w", schema.Name, backupErr)
+		}
+
+		applied, migrateErr := schema.Migrate(ctx, data)
+		if migrateErr != nil {
+			logger.Error(ctx, migrateErr.Error())
+			return false, nil, migrateErr
+		}
+		migrated = len(applied) > 0
+	}
+
+	migratedContent, marshalErr := json.Marshal(data)
+	if marshalErr != nil {
+		return false, nil, marshalErr
+	}
+	if unmarshalErr := json.Unmarshal(migratedContent, target); unmarshalErr != nil {
+		return false, nil, unmarshalErr
+	}
+
+	unknownFields = findUnknownFields(data, target)
+	if len(unknownFields) > 0 {
+		logger.Warn(ctx, fmt.Sprintf("unknown keys found in %s, they are kept as is: %s", schema.Name, strings.Join(lo.Keys(unknownFields), ", ")))
+	}
+
+	return migrated, unknownFields, nil
+}
+
+func (m *Manager) backupBeforeMigration(ctx context.Context) error {
+	var backupErr error
+	m.migrationBackupOnce.Do(func() {
+		backupErr = m.Backup(ctx, BackupTypeMigration)
+	})
+	return backupErr
+}
+
+// findUnknownFields returns top level keys in data which don't match any field of target struct
+func findUnknownFields(data map[string]any, target any) map[string]json.RawMessage {
+	targetType := reflect.TypeOf(target)
+	for targetType.Kind() == reflect.Pointer {
+		targetType = targetType.Elem()
+	}
+
+	knownFields := map[string]bool{}
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		// encoding/json matches keys case insensitively
+		knownFields[strings.ToLower(name)] = true
+	}
+
+	unknownFields := map[string]json.RawMessage{}
+	for key, value := range data {
+		if knownFields[strings.ToLower(key)] {
+			continue
+		}
+		valueJson, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			continue
+		}
+		unknownFields[key] = valueJson
+	}
+
+	return unknownFields
+}
+
+// marshalWithUnknownFields marshals v and writes unknown fields back, so keys we don't understand are not dropped
+func marshalWithUnknownFields(v any, unknownFields map[string]json.RawMessage) ([]byte, error) {
+	content, marshalErr := json.Marshal(v)
+	if marshalErr != nil || len(unknownFields) == 0 {
+		return content, marshalErr
+	}
+
+	data := map[string]json.RawMessage{}
+	if unmarshalErr := json.Unmarshal(content, &data); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	for key, value := range unknownFields {
+		if _, exist := data[key]; !exist {
+			data[key] = value
+		}
+	}
+
+	return json.Marshal(data)
+}
+
+func init() {
+	// files saved before schema versioning are version 0, their structure is the same as version 1
+	noop := func(ctx context.Context, data map[string]any) error { return nil }
+	WoxSettingSchema.Register(SettingMigration{Version: 1, Description: "add schema version", Migrate: noop})
+	WoxAppDataSchema.Register(SettingMigration{Version: 1, Description: "add schema version", Migrate: noop})
+	PluginSettingSchema.Register(SettingMigration{Version: 1, Description: "add schema version", Migrate: noop})
+}
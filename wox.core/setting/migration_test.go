@@ -0,0 +1,52 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSettingSchemaMigrate(t *testing.T) {
+	schema := &SettingSchema{Name: "test"}
+	schema.Register(SettingMigration{Version: 2, Description: "rename Hotkey to MainHotkey", Migrate: func(ctx context.Context, data map[string]any) error {
+		data["MainHotkey"] = data["Hotkey"]
+		delete(data, "Hotkey")
+		return nil
+	}})
+	schema.Register(SettingMigration{Version: 1, Description: "noop", Migrate: func(ctx context.Context, data map[string]any) error {
+		return nil
+	}})
+	assert.Equal(t, 2, schema.CurrentVersion())
+	assert.Panics(t, func() {
+		schema.Register(SettingMigration{Version: 2})
+	})
+
+	data := map[string]any{"Hotkey": "alt+space"}
+	applied, err := schema.Migrate(context.Background(), data)
+	assert.Nil(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, 1, applied[0].Version)
+	assert.Equal(t, "alt+space", data["MainHotkey"])
+	assert.Equal(t, 2, getSchemaVersion(data))
+
+	// already migrated
+	applied, err = schema.Migrate(context.Background(), data)
+	assert.Nil(t, err)
+	assert.Len(t, applied, 0)
+}
+
+func TestUnknownFields(t *testing.T) {
+	data := map[string]any{"Name": "test", "disabled": true, "NewFieldOfNextVersion": []any{"a"}}
+	unknownFields := findUnknownFields(data, &PluginSetting{})
+	assert.Len(t, unknownFields, 1)
+	assert.Equal(t, `["a"]`, string(unknownFields["NewFieldOfNextVersion"]))
+
+	content, err := marshalWithUnknownFields(&PluginSetting{Name: "test", UnknownFields: unknownFields}, unknownFields)
+	assert.Nil(t, err)
+	var saved map[string]any
+	assert.Nil(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, "test", saved["Name"])
+	assert.Equal(t, []any{"a"}, saved["NewFieldOfNextVersion"])
+	assert.NotContains(t, saved, "UnknownFields")
+}
@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"encoding/json"
 	"wox/util"
 )
 
@@ -10,11 +11,15 @@ type PluginQueryCommand struct {
 }
 
 type PluginSetting struct {
-	Name string // readonly, for display purpose
+	SchemaVersion int    // see PluginSettingSchema
+	Name          string // readonly, for display purpose
 
 	// Is this plugin disabled by user
 	Disabled bool
 
+	// Run this plugin in its own dedicated host process, even if plugin.json doesn't declare isolatedHost feature
+	IsolatedHost bool
+
 	// User defined keywords, will be used to trigger this plugin. User may not set custom trigger keywords, which will cause this property to be null
 	//
 	// So don't use this property directly, use Instance.TriggerKeywords instead
@@ -27,6 +32,8 @@ type PluginSetting struct {
 	QueryCommands []PluginQueryCommand
 
 	Settings *util.HashMap[string, string]
+
+	UnknownFields map[string]json.RawMessage `json:"-"` // keys in plugin setting file unknown to this version, kept as is when saving
 }
 
 func (p *PluginSetting) GetSetting(key string) (string, bool) {
@@ -110,3 +110,101 @@ func TestMarshalPluginSetting(t *testing.T) {
 	assert.Equal(t, ps.Disabled, ps1.Disabled)
 	assert.Equal(t, ps1.Settings.Len(), 2)
 }
+
+func TestValidatePluginSetting(t *testing.T) {
+	jsonStr := `
+[
+	{
+		"Type":"select",
+		"Value":{
+			"Key":"Mode",
+			"DefaultValue":"simple",
+			"Options":[
+				{"Label":"Simple", "Value":"simple"},
+				{"Label":"Advanced", "Value":"advanced"}
+			]
+		}
+	},
+	{
+		"Type":"textbox",
+		"ShowWhen":{"Key":"Mode", "Values":["advanced"]},
+		"Value":{
+			"Key":"Endpoint",
+			"Validators":[
+				{"Type":"not_empty"},
+				{"Type":"url"}
+			]
+		}
+	},
+	{
+		"Type":"number",
+		"Value":{
+			"Key":"Timeout",
+			"Min":1,
+			"Max":60,
+			"IsInteger":true
+		}
+	},
+	{
+		"Type":"textbox",
+		"Value":{
+			"Key":"Name",
+			"Validators":[
+				{"Type":"regex", "Value":{"Pattern":"^[a-z]+$"}},
+				{"Type":"length", "Value":{"Min":2, "Max":5}}
+			]
+		}
+	},
+	{
+		"Type":"password",
+		"Value":{"Key":"Token"}
+	},
+	{
+		"Type":"color",
+		"Value":{"Key":"Color", "DefaultValue":"#FF0000"}
+	}
+]
+`
+
+	var definitions definition.PluginSettingDefinitions
+	err := json.Unmarshal([]byte(jsonStr), &definitions)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(definitions))
+	assert.Equal(t, definition.PluginSettingDefinitionTypeNumber, definitions[2].Type)
+	assert.Equal(t, definition.PluginSettingDefinitionTypePassword, definitions[4].Type)
+
+	mode := "simple"
+	getValue := func(key string) string {
+		if key == "Mode" {
+			return mode
+		}
+		return ""
+	}
+
+	assert.NotNil(t, definitions.Validate("Mode", "unknown", getValue))
+	assert.Nil(t, definitions.Validate("Mode", "advanced", getValue))
+
+	// hidden setting is not validated
+	assert.Nil(t, definitions.Validate("Endpoint", "", getValue))
+	mode = "advanced"
+	assert.NotNil(t, definitions.Validate("Endpoint", "", getValue))
+	assert.NotNil(t, definitions.Validate("Endpoint", "not a url", getValue))
+	assert.Nil(t, definitions.Validate("Endpoint", "https://example.com", getValue))
+
+	assert.NotNil(t, definitions.Validate("Timeout", "0", getValue))
+	assert.NotNil(t, definitions.Validate("Timeout", "1.5", getValue))
+	assert.Nil(t, definitions.Validate("Timeout", "30", getValue))
+
+	assert.NotNil(t, definitions.Validate("Name", "ABC", getValue))
+	assert.NotNil(t, definitions.Validate("Name", "abcdef", getValue))
+	assert.Nil(t, definitions.Validate("Name", "abc", getValue))
+	assert.Nil(t, definitions.Validate("Name", "", getValue))
+
+	assert.NotNil(t, definitions.Validate("Color", "red", getValue))
+	assert.Nil(t, definitions.Validate("Color", "#80FF0000", getValue))
+
+	assert.Nil(t, definitions.Validate("NotDefined", "anything", getValue))
+
+	_, marshalErr := json.Marshal(definitions)
+	assert.Nil(t, marshalErr)
+}
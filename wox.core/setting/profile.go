@@ -0,0 +1,399 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"wox/util"
+
+	"github.com/jinzhu/copier"
+	"github.com/samber/lo"
+)
+
+// Profile overrides a subset of wox settings and plugin settings, E.g. a "Work" profile with different hotkeys and enabled plugins
+type Profile struct {
+	Id             string
+	Name           string
+	WoxSettings    map[string]string            // overridden wox settings, key and value are the same as UpdateWoxSetting
+	PluginSettings map[string]map[string]string // overridden plugin settings, plugin id => setting key => value. Use key "Disabled" to enable/disable a plugin
+	Rules          []ProfileRule                // profile is activated automatically when any rule matches
+}
+
+type ProfileRuleType = string
+
+const (
+	ProfileRuleTypeTime ProfileRuleType = "time"
+	ProfileRuleTypeEnv  ProfileRuleType = "env"
+)
+
+// ProfilePluginDisabledKey is the plugin setting key to override whether a plugin is disabled
+const ProfilePluginDisabledKey = "Disabled"
+
+type ProfileRule struct {
+	Type ProfileRuleType
+
+	StartTime string // HH:mm, for time rule
+	EndTime   string // HH:mm, for time rule. Can be earlier than StartTime to span midnight, E.g. 22:00 - 06:00
+	Weekdays  []int  // for time rule, 0 is Sunday. Empty means every day
+
+	EnvName  string // for env rule
+	EnvValue string // for env rule, empty means the env var only needs to be present
+}
+
+// ProfileChange describes what changed after active profile switched or active profile was modified.
+// Changed wox settings are published to SubscribeWoxSettingChanged subscribers
+type ProfileChange struct {
+	ProfileId      string              // new active profile id, empty means no profile is active
+	PluginSettings map[string][]string // plugin id => overridden setting keys that may have changed
+}
+
+func (r *ProfileRule) IsMatch(now time.Time) bool {
+	if r.Type == ProfileRuleTypeEnv {
+		if r.EnvName == "" {
+			return false
+		}
+		value, exist := os.LookupEnv(r.EnvName)
+		if !exist {
+			return false
+		}
+		return r.EnvValue == "" || r.EnvValue == value
+	}
+
+	if r.Type == ProfileRuleTypeTime {
+		start, startErr := parseProfileRuleTime(r.StartTime)
+		end, endErr := parseProfileRuleTime(r.EndTime)
+		if startErr != nil || endErr != nil {
+			return false
+		}
+
+		minutes := now.Hour()*60 + now.Minute()
+		weekday := int(now.Weekday())
+		if start <= end {
+			if minutes < start || minutes >= end {
+				return false
+			}
+		} else {
+			if minutes < start && minutes >= end {
+				return false
+			}
+			// after midnight part belongs to the day the time range started
+			if minutes < end {
+				weekday = (weekday + 6) % 7
+			}
+		}
+
+		return len(r.Weekdays) == 0 || slices.Contains(r.Weekdays, weekday)
+	}
+
+	return false
+}
+
+func (r *ProfileRule) Validate() error {
+	if r.Type == ProfileRuleTypeEnv {
+		if r.EnvName == "" {
+			return fmt.Errorf("env name of profile rule is empty")
+		}
+		return nil
+	}
+
+	if r.Type == ProfileRuleTypeTime {
+		if _, err := parseProfileRuleTime(r.StartTime); err != nil {
+			return err
+		}
+		if _, err := parseProfileRuleTime(r.EndTime); err != nil {
+			return err
+		}
+		for _, weekday := range r.Weekdays {
+			if weekday < 0 || weekday > 6 {
+				return fmt.Errorf("invalid weekday of profile rule: %d", weekday)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown profile rule type: %s", r.Type)
+}
+
+// parseProfileRuleTime parses HH:mm into minutes of the day
+func parseProfileRuleTime(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time, expect HH:mm: %s", value)
+	}
+	hour, hourErr := strconv.Atoi(parts[0])
+	minute, minuteErr := strconv.Atoi(parts[1])
+	if hourErr != nil || minuteErr != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid time, expect HH:mm: %s", value)
+	}
+
+	return hour*60 + minute, nil
+}
+
+func (m *Manager) GetProfiles(ctx context.Context) []Profile {
+	m.settingLock.RLock()
+	defer m.settingLock.RUnlock()
+	return cloneProfiles(m.woxSetting.Profiles)
+}
+
+// GetActiveProfileId returns the id of profile in effect, which may be activated by rules. Empty means no profile is active
+func (m *Manager) GetActiveProfileId(ctx context.Context) string {
+	m.settingLock.RLock()
+	defer m.settingLock.RUnlock()
+	return m.activeProfileId
+}
+
+// getProfile returns the profile in base setting, caller must hold settingLock
+func (m *Manager) getProfile(profileId string) (*Profile, bool) {
+	if profileId == "" {
+		return nil, false
+	}
+	for i := range m.woxSetting.Profiles {
+		if m.woxSetting.Profiles[i].Id == profileId {
+			return &m.woxSetting.Profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// SwitchProfile activates given profile and remembers it as the selected profile. Empty profile id switches back to default settings
+func (m *Manager) SwitchProfile(ctx context.Context, profileId string) error {
+	if profileId != "" {
+		m.settingLock.RLock()
+		_, exist := m.getProfile(profileId)
+		m.settingLock.RUnlock()
+		if !exist {
+			return fmt.Errorf("profile not found: %s", profileId)
+		}
+	}
+
+	logger.Info(ctx, fmt.Sprintf("switch profile to: %s", profileId))
+	m.applyProfileChange(ctx, func() {
+		m.woxSetting.ActiveProfileId = profileId
+		m.activeProfileId = profileId
+	})
+
+	return m.SaveWoxSetting(ctx)
+}
+
+// OnProfileChanged registers a callback which is invoked after effective settings changed because of profile, so subsystems can reload
+func (m *Manager) OnProfileChanged(callback func(ctx context.Context, change ProfileChange)) {
+	m.profileChangedCallbacks = append(m.profileChangedCallbacks, callback)
+}
+
+// GetProfilePluginSetting returns the value of a plugin setting overridden by active profile
+func (m *Manager) GetProfilePluginSetting(pluginId string, key string) (string, bool) {
+	m.settingLock.RLock()
+	defer m.settingLock.RUnlock()
+
+	profile, exist := m.getProfile(m.activeProfileId)
+	if !exist {
+		return "", false
+	}
+	value, overridden := profile.PluginSettings[pluginId][key]
+	return value, overridden
+}
+
+// UpdateProfilePluginSetting updates the plugin setting override of active profile, returns false if active profile doesn't override this setting
+func (m *Manager) UpdateProfilePluginSetting(ctx context.Context, pluginId string, key string, value string) (bool, error) {
+	m.settingLock.Lock()
+	profile, exist := m.getProfile(m.activeProfileId)
+	if !exist {
+		m.settingLock.Unlock()
+		return false, nil
+	}
+	if _, overridden := profile.PluginSettings[pluginId][key]; !overridden {
+		m.settingLock.Unlock()
+		return false, nil
+	}
+
+	profile.PluginSettings[pluginId][key] = value
+	m.settingLock.Unlock()
+	return true, m.SaveWoxSetting(ctx)
+}
+
+// StartProfileAutoSwitch checks profile rules every minute and switches profile when matched profile changes
+func (m *Manager) StartProfileAutoSwitch(ctx context.Context) {
+	util.Go(ctx, "profile auto switch", func() {
+		for range time.NewTicker(time.Minute).C {
+			m.checkProfileRules(util.NewTraceContext())
+		}
+	})
+}
+
+func (m *Manager) checkProfileRules(ctx context.Context) {
+	m.settingLock.Lock()
+	matchedProfileId := m.findRuleMatchedProfileId(time.Now())
+	if matchedProfileId == m.ruleMatchedProfileId {
+		m.settingLock.Unlock()
+		return
+	}
+
+	// only switch when matched profile changes, so manual switching is respected until rules change again
+	m.ruleMatchedProfileId = matchedProfileId
+	targetProfileId := matchedProfileId
+	if targetProfileId == "" {
+		targetProfileId = m.woxSetting.ActiveProfileId
+	}
+	if targetProfileId == m.activeProfileId {
+		m.settingLock.Unlock()
+		return
+	}
+	m.settingLock.Unlock()
+
+	logger.Info(ctx, fmt.Sprintf("profile rules changed, switch profile to: %s", targetProfileId))
+	m.applyProfileChange(ctx, func() {
+		m.activeProfileId = targetProfileId
+	})
+}
+
+// findRuleMatchedProfileId returns the first profile which has a matched rule, caller must hold settingLock
+func (m *Manager) findRuleMatchedProfileId(now time.Time) string {
+	for _, profile := range m.woxSetting.Profiles {
+		for _, rule := range profile.Rules {
+			if rule.IsMatch(now) {
+				return profile.Id
+			}
+		}
+	}
+	return ""
+}
+
+// initActiveProfile decides which profile is active on startup, no callbacks are invoked because subsystems are not started yet
+func (m *Manager) initActiveProfile(ctx context.Context) {
+	m.settingLock.Lock()
+	defer m.settingLock.Unlock()
+
+	m.ruleMatchedProfileId = m.findRuleMatchedProfileId(time.Now())
+	m.activeProfileId = m.ruleMatchedProfileId
+	if m.activeProfileId == "" {
+		if _, exist := m.getProfile(m.woxSetting.ActiveProfileId); exist {
+			m.activeProfileId = m.woxSetting.ActiveProfileId
+		}
+	}
+	if m.activeProfileId != "" {
+		logger.Info(ctx, fmt.Sprintf("active profile: %s", m.activeProfileId))
+	}
+
+	m.refreshEffectiveWoxSetting(ctx)
+}
+
+// applyProfileChange runs modify func with settingLock held, then recomputes effective settings and notifies subsystems about the changed settings
+func (m *Manager) applyProfileChange(ctx context.Context, modify func()) {
+	m.settingLock.Lock()
+	oldEffective := m.effectiveWoxSetting.Load()
+	oldProfile := Profile{}
+	if profile, exist := m.getProfile(m.activeProfileId); exist {
+		copier.CopyWithOption(&oldProfile, profile, copier.Option{DeepCopy: true})
+	}
+
+	modify()
+	if _, exist := m.getProfile(m.activeProfileId); !exist {
+		m.activeProfileId = ""
+	}
+	m.refreshEffectiveWoxSetting(ctx)
+
+	newProfile := Profile{}
+	if profile, exist := m.getProfile(m.activeProfileId); exist {
+		newProfile = *profile
+	}
+
+	change := ProfileChange{
+		ProfileId:      m.activeProfileId,
+		PluginSettings: map[string][]string{},
+	}
+	changedWoxSettings := map[string]string{}
+	woxSettingKeys := lo.Uniq(append(lo.Keys(oldProfile.WoxSettings), lo.Keys(newProfile.WoxSettings)...))
+	for _, key := range woxSettingKeys {
+		newValue := getWoxSettingValue(m.effectiveWoxSetting.Load(), key)
+		if getWoxSettingValue(oldEffective, key) != newValue {
+			changedWoxSettings[key] = newValue
+		}
+	}
+	pluginIds := lo.Uniq(append(lo.Keys(oldProfile.PluginSettings), lo.Keys(newProfile.PluginSettings)...))
+	for _, pluginId := range pluginIds {
+		change.PluginSettings[pluginId] = lo.Uniq(append(lo.Keys(oldProfile.PluginSettings[pluginId]), lo.Keys(newProfile.PluginSettings[pluginId])...))
+	}
+	m.settingLock.Unlock()
+
+	for key, value := range changedWoxSettings {
+		logger.Info(ctx, fmt.Sprintf("setting %s changed by profile", key))
+		m.notifyWoxSettingChanged(ctx, key, value)
+	}
+	for _, callback := range m.profileChangedCallbacks {
+		callback(ctx, change)
+	}
+}
+
+// refreshEffectiveWoxSetting recomputes effective setting from base setting and active profile, caller must hold settingLock
+func (m *Manager) refreshEffectiveWoxSetting(ctx context.Context) {
+	effective := WoxSetting{}
+	copyErr := copier.CopyWithOption(&effective, m.woxSetting, copier.Option{DeepCopy: true})
+	if copyErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to copy wox setting: %s", copyErr.Error()))
+		return
+	}
+
+	if profile, exist := m.getProfile(m.activeProfileId); exist {
+		for key, value := range profile.WoxSettings {
+			if applyErr := applyWoxSettingValue(&effective, key, value); applyErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to apply setting %s of profile %s: %s", key, profile.Name, applyErr.Error()))
+			}
+		}
+		m.fillAIProviderApiKeys(effective.AIProviders)
+	}
+
+	// replace instead of modifying in place, readers may be using the previous setting
+	m.effectiveWoxSetting.Store(&effective)
+}
+
+// cloneProfiles deep copies profiles, so they can be used without holding settingLock
+func cloneProfiles(profiles []Profile) []Profile {
+	cloned := make([]Profile, len(profiles))
+	for i, profile := range profiles {
+		profile.WoxSettings = maps.Clone(profile.WoxSettings)
+		pluginSettings := profile.PluginSettings
+		if pluginSettings != nil {
+			profile.PluginSettings = make(map[string]map[string]string, len(pluginSettings))
+			for pluginId, settings := range pluginSettings {
+				profile.PluginSettings[pluginId] = maps.Clone(settings)
+			}
+		}
+		profile.Rules = slices.Clone(profile.Rules)
+		cloned[i] = profile
+	}
+	return cloned
+}
+
+func validateProfiles(profiles []Profile) error {
+	ids := map[string]bool{}
+	for _, profile := range profiles {
+		if profile.Id == "" || profile.Name == "" {
+			return fmt.Errorf("profile id and name can't be empty")
+		}
+		if ids[profile.Id] {
+			return fmt.Errorf("duplicated profile id: %s", profile.Id)
+		}
+		ids[profile.Id] = true
+
+		for key, value := range profile.WoxSettings {
+			if key == "Profiles" || key == "ActiveProfileId" {
+				return fmt.Errorf("profile can't override setting: %s", key)
+			}
+			if applyErr := applyWoxSettingValue(&WoxSetting{}, key, value); applyErr != nil {
+				return fmt.Errorf("invalid setting %s of profile %s: %w", key, profile.Name, applyErr)
+			}
+		}
+		for _, rule := range profile.Rules {
+			if ruleErr := rule.Validate(); ruleErr != nil {
+				return fmt.Errorf("invalid rule of profile %s: %w", profile.Name, ruleErr)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package setting
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProfileRuleIsMatch(t *testing.T) {
+	// 2024-01-01 is Monday
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	workHours := ProfileRule{Type: ProfileRuleTypeTime, StartTime: "09:00", EndTime: "18:00", Weekdays: []int{1, 2, 3, 4, 5}}
+	assert.True(t, workHours.IsMatch(monday(9, 0)))
+	assert.True(t, workHours.IsMatch(monday(17, 59)))
+	assert.False(t, workHours.IsMatch(monday(18, 0)))
+	assert.False(t, workHours.IsMatch(monday(8, 59)))
+	assert.False(t, workHours.IsMatch(monday(10, 0).AddDate(0, 0, -1)))
+
+	// overnight range belongs to the day it starts
+	night := ProfileRule{Type: ProfileRuleTypeTime, StartTime: "22:00", EndTime: "06:00", Weekdays: []int{0}}
+	assert.True(t, night.IsMatch(monday(5, 0)))
+	assert.False(t, night.IsMatch(monday(23, 0)))
+	assert.False(t, night.IsMatch(monday(12, 0)))
+
+	t.Setenv("WOX_PROFILE_TEST", "office")
+	assert.True(t, (&ProfileRule{Type: ProfileRuleTypeEnv, EnvName: "WOX_PROFILE_TEST"}).IsMatch(monday(0, 0)))
+	assert.True(t, (&ProfileRule{Type: ProfileRuleTypeEnv, EnvName: "WOX_PROFILE_TEST", EnvValue: "office"}).IsMatch(monday(0, 0)))
+	assert.False(t, (&ProfileRule{Type: ProfileRuleTypeEnv, EnvName: "WOX_PROFILE_TEST", EnvValue: "home"}).IsMatch(monday(0, 0)))
+	assert.False(t, (&ProfileRule{Type: ProfileRuleTypeEnv, EnvName: "WOX_PROFILE_TEST_NOT_EXIST"}).IsMatch(monday(0, 0)))
+}
+
+func TestValidateProfiles(t *testing.T) {
+	assert.Nil(t, validateProfiles([]Profile{{
+		Id:          "work",
+		Name:        "Work",
+		WoxSettings: map[string]string{"ThemeId": "dark", "QueryShortcuts": `[{"Shortcut":"gh","Query":"github"}]`},
+		Rules:       []ProfileRule{{Type: ProfileRuleTypeTime, StartTime: "09:00", EndTime: "18:00"}},
+	}}))
+	assert.NotNil(t, validateProfiles([]Profile{{Id: "work", Name: "Work"}, {Id: "work", Name: "Work2"}}))
+	assert.NotNil(t, validateProfiles([]Profile{{Id: "work", Name: "Work", WoxSettings: map[string]string{"NotExist": "1"}}}))
+	assert.NotNil(t, validateProfiles([]Profile{{Id: "work", Name: "Work", WoxSettings: map[string]string{"QueryShortcuts": "not json"}}}))
+	assert.NotNil(t, validateProfiles([]Profile{{Id: "work", Name: "Work", Rules: []ProfileRule{{Type: ProfileRuleTypeTime, StartTime: "25:00", EndTime: "18:00"}}}}))
+}
+
+func TestSwitchProfileWhileReadingSettings(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	baseThemeId := m.GetWoxSetting(ctx).ThemeId
+	profiles := `[{"Id":"work","Name":"Work","WoxSettings":{"ThemeId":"work-theme"},"PluginSettings":{"plugin":{"Disabled":"true","key":"work"}}}]`
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "Profiles", profiles))
+
+	done := make(chan struct{})
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				// effective setting is replaced as a whole, so readers never see a half applied profile
+				themeId := m.GetWoxSetting(ctx).ThemeId
+				assert.Contains(t, []string{baseThemeId, "work-theme"}, themeId)
+				value, _ := m.GetWoxSettingValue(ctx, "ThemeId")
+				assert.Contains(t, []string{baseThemeId, "work-theme"}, value)
+				m.GetProfilePluginSetting("plugin", "key")
+				m.GetActiveProfileId(ctx)
+				m.GetProfiles(ctx)
+			}
+		}()
+	}
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			m.UpdateProfilePluginSetting(ctx, "plugin", "key", "work")
+			m.checkProfileRules(ctx)
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, m.SwitchProfile(ctx, "work"))
+		assert.Nil(t, m.SwitchProfile(ctx, ""))
+	}
+	close(done)
+	waitGroup.Wait()
+
+	assert.Nil(t, m.SwitchProfile(ctx, "work"))
+	assert.Equal(t, "work-theme", m.GetWoxSetting(ctx).ThemeId)
+	value, overridden := m.GetProfilePluginSetting("plugin", "key")
+	assert.True(t, overridden)
+	assert.Equal(t, "work", value)
+	assert.Nil(t, m.SwitchProfile(ctx, ""))
+	assert.Equal(t, baseThemeId, m.GetWoxSetting(ctx).ThemeId)
+}
@@ -0,0 +1,175 @@
+package setting
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QueryShortcut expands a short query to a full query when user input starts with Shortcut.
+//
+// Query supports placeholders, arguments after the shortcut are split by spaces, quote an argument to include spaces:
+//
+//	{0}, {1}          positional parameter, E.g. "wi" => "wpm install {0}"
+//	{from}            named parameter, named parameters take arguments in the order they first appear
+//	{to:USD}          parameter with default value, used when argument is not given
+//	{args...}         rest parameter, takes all remaining arguments
+//	{wox:clipboard}   query variable, see plugin.QueryVariable
+//
+// E.g. shortcut "cc" => "convert {amount} {from} to {to:USD}", input `cc 10 EUR` expands to "convert 10 EUR to USD"
+type QueryShortcut struct {
+	Shortcut string
+	Query    string
+}
+
+type QueryShortcutParameter struct {
+	Name       string
+	Default    string
+	HasDefault bool
+	IsRest     bool
+}
+
+// name, rest marker and default of a placeholder, query variables such as {wox:clipboard} are matched too and skipped by name
+var queryShortcutPlaceholderRegex = regexp.MustCompile(`\{([A-Za-z0-9_]+)(\.\.\.)?(?::([^{}]*))?\}`)
+
+const queryVariablePlaceholderName = "wox"
+
+func (q *QueryShortcut) HasPlaceholder() bool {
+	return len(q.Parameters()) > 0
+}
+
+func (q *QueryShortcut) PlaceholderCount() int {
+	return len(q.Parameters())
+}
+
+// Parameters returns distinct parameters in the order they first appear in Query
+func (q *QueryShortcut) Parameters() []QueryShortcutParameter {
+	var parameters []QueryShortcutParameter
+	for _, match := range queryShortcutPlaceholderRegex.FindAllStringSubmatchIndex(q.Query, -1) {
+		parameter := parseQueryShortcutParameter(q.Query, match)
+		if parameter.Name == queryVariablePlaceholderName {
+			continue
+		}
+		isDuplicated := false
+		for _, existing := range parameters {
+			if existing.Name == parameter.Name {
+				isDuplicated = true
+				break
+			}
+		}
+		if !isDuplicated {
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+// Expand fills parameters of Query with arguments, which is the user input after shortcut.
+// Parameters without argument and default value are kept as is, arguments without parameter are appended to the end.
+// Returns expanded query and values of filled parameters
+func (q *QueryShortcut) Expand(arguments string) (string, map[string]string) {
+	return q.ExpandWithVariables(arguments, nil)
+}
+
+// ExpandWithVariables is like Expand, and replaces query variables of Query with resolveVariable in the same pass,
+// so arguments and variable values are never parsed as placeholders. Query variables are kept as is if resolveVariable is nil
+func (q *QueryShortcut) ExpandWithVariables(arguments string, resolveVariable func(placeholder string) string) (string, map[string]string) {
+	args := SplitQueryShortcutArguments(arguments)
+	values := map[string]string{}
+
+	nextArgIndex := 0
+	usedArgCount := 0
+	for _, parameter := range q.Parameters() {
+		value, found := "", false
+		if parameter.IsRest {
+			if nextArgIndex < len(args) {
+				value, found = strings.Join(args[nextArgIndex:], " "), true
+			}
+			nextArgIndex = len(args)
+		} else if index, parseErr := strconv.Atoi(parameter.Name); parseErr == nil {
+			if index < len(args) {
+				value, found = args[index], true
+			}
+			usedArgCount = max(usedArgCount, index+1)
+		} else {
+			if nextArgIndex < len(args) {
+				value, found = args[nextArgIndex], true
+			}
+			nextArgIndex++
+		}
+		usedArgCount = max(usedArgCount, nextArgIndex)
+
+		if !found && parameter.HasDefault {
+			value, found = parameter.Default, true
+		}
+		if found {
+			values[parameter.Name] = value
+		}
+	}
+
+	expanded := queryShortcutPlaceholderRegex.ReplaceAllStringFunc(q.Query, func(placeholder string) string {
+		match := queryShortcutPlaceholderRegex.FindStringSubmatchIndex(placeholder)
+		parameter := parseQueryShortcutParameter(placeholder, match)
+		if parameter.Name == queryVariablePlaceholderName {
+			if resolveVariable != nil {
+				return resolveVariable(placeholder)
+			}
+			return placeholder
+		}
+		if value, found := values[parameter.Name]; found {
+			return value
+		}
+		return placeholder
+	})
+	if usedArgCount < len(args) {
+		expanded += " " + strings.Join(args[usedArgCount:], " ")
+	}
+
+	return expanded, values
+}
+
+// SplitQueryShortcutArguments splits arguments by spaces, text in single or double quotes is kept as one argument.
+// A quote only starts quoting at the beginning of an argument and must be closed, otherwise it's kept as a literal char, e.g. apostrophe in it's
+func SplitQueryShortcutArguments(arguments string) []string {
+	var args []string
+	var current strings.Builder
+	hasArg := false
+	for i := 0; i < len(arguments); i++ {
+		char := arguments[i]
+		if !hasArg && (char == '"' || char == '\'') {
+			if end := strings.IndexByte(arguments[i+1:], char); end >= 0 {
+				current.WriteString(arguments[i+1 : i+1+end])
+				hasArg = true
+				i += end + 1
+				continue
+			}
+		}
+
+		if char == ' ' {
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+			continue
+		}
+		current.WriteByte(char)
+		hasArg = true
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+func parseQueryShortcutParameter(text string, match []int) QueryShortcutParameter {
+	parameter := QueryShortcutParameter{
+		Name:   text[match[2]:match[3]],
+		IsRest: match[4] >= 0,
+	}
+	if match[6] >= 0 {
+		parameter.Default = text[match[6]:match[7]]
+		parameter.HasDefault = true
+	}
+	return parameter
+}
@@ -0,0 +1,64 @@
+package setting
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQueryShortcutExpand(t *testing.T) {
+	convert := QueryShortcut{Shortcut: "cc", Query: "convert {amount} {from} to {to:USD}"}
+	expanded, values := convert.Expand("10 EUR")
+	assert.Equal(t, "convert 10 EUR to USD", expanded)
+	assert.Equal(t, map[string]string{"amount": "10", "from": "EUR", "to": "USD"}, values)
+
+	expanded, _ = convert.Expand("10 EUR CNY extra")
+	assert.Equal(t, "convert 10 EUR to CNY extra", expanded)
+
+	expanded, _ = convert.Expand("10")
+	assert.Equal(t, "convert 10 {from} to USD", expanded)
+
+	search := QueryShortcut{Shortcut: "gh", Query: "github {repo} {keywords...}"}
+	expanded, _ = search.Expand(`wox "query shortcut" 'named parameter'`)
+	assert.Equal(t, "github wox query shortcut named parameter", expanded)
+
+	quoted := QueryShortcut{Shortcut: "t", Query: "translate {text} to {lang:en} {wox:clipboard}"}
+	expanded, _ = quoted.Expand(`"hello world"`)
+	assert.Equal(t, "translate hello world to en {wox:clipboard}", expanded)
+	assert.Len(t, quoted.Parameters(), 2)
+
+	positional := QueryShortcut{Shortcut: "wix", Query: "wpm install {0} x {1} {0}"}
+	expanded, _ = positional.Expand("1 2 3")
+	assert.Equal(t, "wpm install 1 x 2 1 3", expanded)
+	assert.Equal(t, 2, positional.PlaceholderCount())
+
+	translate := QueryShortcut{Shortcut: "tr", Query: "translate {0}"}
+	expanded, _ = translate.Expand("it's fine")
+	assert.Equal(t, "translate it's fine", expanded)
+}
+
+func TestQueryShortcutExpandWithVariables(t *testing.T) {
+	shortcut := QueryShortcut{Shortcut: "rp", Query: "replace {from} with {wox:clipboard} in {wox:env:HOME}"}
+	resolve := func(placeholder string) string {
+		if placeholder == "{wox:clipboard}" {
+			return "{from}"
+		}
+		return placeholder
+	}
+
+	// values of variables and arguments are never parsed as placeholders again
+	expanded, values := shortcut.ExpandWithVariables("{wox:clipboard}", resolve)
+	assert.Equal(t, "replace {wox:clipboard} with {from} in {wox:env:HOME}", expanded)
+	assert.Equal(t, map[string]string{"from": "{wox:clipboard}"}, values)
+
+	expanded, _ = shortcut.Expand("a")
+	assert.Equal(t, "replace a with {wox:clipboard} in {wox:env:HOME}", expanded)
+}
+
+func TestSplitQueryShortcutArguments(t *testing.T) {
+	assert.Nil(t, SplitQueryShortcutArguments("  "))
+	assert.Equal(t, []string{"a", "b c", "", "d'e"}, SplitQueryShortcutArguments(`a  "b c" "" "d'e"`))
+	assert.Equal(t, []string{"it's", "fine"}, SplitQueryShortcutArguments("it's fine"))
+	assert.Equal(t, []string{`say"hi"`, "there"}, SplitQueryShortcutArguments(`say"hi" there`))
+	assert.Equal(t, []string{"'tis", "fine"}, SplitQueryShortcutArguments("'tis fine"))
+	assert.Equal(t, []string{`"unclosed`, "quote"}, SplitQueryShortcutArguments(`"unclosed quote`))
+}
@@ -0,0 +1,258 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"wox/i18n"
+	"wox/util/hotkey"
+
+	"github.com/samber/lo"
+)
+
+type WoxSettingValueType = string
+
+const (
+	WoxSettingValueTypeBool   WoxSettingValueType = "bool"
+	WoxSettingValueTypeString WoxSettingValueType = "string"
+	WoxSettingValueTypeInt    WoxSettingValueType = "int"
+	WoxSettingValueTypeJson   WoxSettingValueType = "json" // lists and structs, value is a json string
+)
+
+// WoxSettingDefinition declares a WoxSetting field which can be read and updated by key
+type WoxSettingDefinition struct {
+	Key       string                                        // field name of WoxSetting
+	Validator func(ctx context.Context, value string) error // optional, called before value is applied
+	OnChanged func(ctx context.Context, value string)       // optional, called after value changed and before subscribers are notified
+	IsLocal   bool                                          // machine specific setting, never exported or synced
+
+	Type               WoxSettingValueType // resolved from field type
+	IsPlatformSpecific bool                // resolved from field type, true if field is PlatformSettingValue
+}
+
+var woxSettingDefinitions = []*WoxSettingDefinition{
+	{Key: "EnableAutostart", IsLocal: true},
+	{Key: "MainHotkey", Validator: validateHotkeyAvailable(true)},
+	{Key: "SelectionHotkey", Validator: validateHotkeyAvailable(false)},
+	{Key: "UsePinYin"},
+	{Key: "SwitchInputMethodABC"},
+	{Key: "HideOnStart"},
+	{Key: "HideOnLostFocus"},
+	{Key: "ShowTray"},
+	{Key: "LangCode", Validator: validateLangCode, OnChanged: updateLang},
+	{Key: "LastQueryMode"},
+	{Key: "ThemeId"},
+	{Key: "QueryHotkeys", Validator: validateQueryHotkeys},
+	{Key: "QueryShortcuts"},
+	{Key: "AIProviders"},
+	{Key: "PluginStoreSources"},
+	{Key: "CustomPythonPath", IsLocal: true},
+	{Key: "CustomNodejsPath", IsLocal: true},
+	{Key: "SyncType", IsLocal: true, Validator: validateSyncType},
+	{Key: "SyncPath", IsLocal: true},
+	{Key: "BackupRetention", IsLocal: true, Validator: validateBackupRetention},
+}
+
+func init() {
+	woxSettingType := reflect.TypeOf(WoxSetting{})
+	for _, definition := range woxSettingDefinitions {
+		field, found := woxSettingType.FieldByName(definition.Key)
+		if !found {
+			panic(fmt.Sprintf("wox setting definition has no field: %s", definition.Key))
+		}
+
+		valueType := field.Type
+		if isPlatformSettingValue(field.Type) {
+			definition.IsPlatformSpecific = true
+			valueType = field.Type.Field(0).Type
+		}
+		definition.Type = getWoxSettingValueType(valueType)
+	}
+}
+
+// GetWoxSettingDefinitions returns all wox settings which can be updated by key
+func GetWoxSettingDefinitions() []WoxSettingDefinition {
+	var definitions []WoxSettingDefinition
+	for _, definition := range woxSettingDefinitions {
+		definitions = append(definitions, *definition)
+	}
+	return definitions
+}
+
+func GetWoxSettingDefinition(key string) (*WoxSettingDefinition, bool) {
+	for _, definition := range woxSettingDefinitions {
+		if definition.Key == key {
+			return definition, true
+		}
+	}
+	return nil, false
+}
+
+// GetWoxSettingValue returns the effective value of a setting in the same format as UpdateWoxSetting accepts
+func (m *Manager) GetWoxSettingValue(ctx context.Context, key string) (string, error) {
+	if _, found := GetWoxSettingDefinition(key); !found {
+		return "", fmt.Errorf("unknown key: %s", key)
+	}
+	return getWoxSettingValue(m.effectiveWoxSetting.Load(), key), nil
+}
+
+// SubscribeWoxSettingChanged registers a callback invoked after settings changed, either updated by user or switched by profile.
+// If no keys are given, callback is invoked for all settings
+func (m *Manager) SubscribeWoxSettingChanged(callback func(ctx context.Context, key string, value string), keys ...string) {
+	m.settingChangedListeners = append(m.settingChangedListeners, woxSettingChangedListener{
+		keys:     keys,
+		callback: callback,
+	})
+}
+
+type woxSettingChangedListener struct {
+	keys     []string
+	callback func(ctx context.Context, key string, value string)
+}
+
+func (m *Manager) notifyWoxSettingChanged(ctx context.Context, key string, value string) {
+	if definition, found := GetWoxSettingDefinition(key); found && definition.OnChanged != nil {
+		definition.OnChanged(ctx, value)
+	}
+
+	for _, listener := range m.settingChangedListeners {
+		if len(listener.keys) == 0 || lo.Contains(listener.keys, key) {
+			listener.callback(ctx, key, value)
+		}
+	}
+}
+
+// applyWoxSettingValue parses value and assigns it to given setting without any side effect
+func applyWoxSettingValue(woxSetting *WoxSetting, key, value string) error {
+	if _, found := GetWoxSettingDefinition(key); !found {
+		return fmt.Errorf("unknown key: %s", key)
+	}
+
+	field := reflect.ValueOf(woxSetting).Elem().FieldByName(key)
+	if isPlatformSettingValue(field.Type()) {
+		setMethod := field.Addr().MethodByName("Set")
+		parsed, parseErr := parseWoxSettingValue(value, setMethod.Type().In(0))
+		if parseErr != nil {
+			return parseErr
+		}
+		setMethod.Call([]reflect.Value{parsed})
+		return nil
+	}
+
+	parsed, parseErr := parseWoxSettingValue(value, field.Type())
+	if parseErr != nil {
+		return parseErr
+	}
+	field.Set(parsed)
+	return nil
+}
+
+// getWoxSettingValue returns the value of a setting in the same format as UpdateWoxSetting accepts
+func getWoxSettingValue(woxSetting *WoxSetting, key string) string {
+	if _, found := GetWoxSettingDefinition(key); !found {
+		return ""
+	}
+
+	field := reflect.ValueOf(woxSetting).Elem().FieldByName(key)
+	if isPlatformSettingValue(field.Type()) {
+		field = field.Addr().MethodByName("Get").Call(nil)[0]
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool())
+	case reflect.String:
+		return field.String()
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10)
+	default:
+		valueJson, _ := json.Marshal(field.Interface())
+		return string(valueJson)
+	}
+}
+
+func parseWoxSettingValue(value string, valueType reflect.Type) (reflect.Value, error) {
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return reflect.ValueOf(value == "true").Convert(valueType), nil
+	case reflect.String:
+		return reflect.ValueOf(value).Convert(valueType), nil
+	case reflect.Int, reflect.Int64:
+		number, parseErr := strconv.ParseInt(value, 10, 64)
+		if parseErr != nil {
+			return reflect.Value{}, parseErr
+		}
+		return reflect.ValueOf(number).Convert(valueType), nil
+	default:
+		// value is a json string
+		parsed := reflect.New(valueType)
+		if unmarshalErr := json.Unmarshal([]byte(value), parsed.Interface()); unmarshalErr != nil {
+			return reflect.Value{}, unmarshalErr
+		}
+		return parsed.Elem(), nil
+	}
+}
+
+func getWoxSettingValueType(valueType reflect.Type) WoxSettingValueType {
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return WoxSettingValueTypeBool
+	case reflect.String:
+		return WoxSettingValueTypeString
+	case reflect.Int, reflect.Int64:
+		return WoxSettingValueTypeInt
+	default:
+		return WoxSettingValueTypeJson
+	}
+}
+
+func isPlatformSettingValue(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Struct && strings.HasPrefix(fieldType.Name(), "PlatformSettingValue[")
+}
+
+func validateHotkeyAvailable(allowEmpty bool) func(ctx context.Context, value string) error {
+	return func(ctx context.Context, value string) error {
+		if value == "" && allowEmpty {
+			return nil
+		}
+		if !hotkey.IsHotkeyAvailable(ctx, value) {
+			return fmt.Errorf("hotkey is not available: %s", value)
+		}
+		return nil
+	}
+}
+
+func validateLangCode(ctx context.Context, value string) error {
+	if !i18n.IsSupportedLangCode(value) {
+		return fmt.Errorf("unsupported language: %s", value)
+	}
+	return nil
+}
+
+func updateLang(ctx context.Context, value string) {
+	langErr := i18n.GetI18nManager().UpdateLang(ctx, i18n.LangCode(value))
+	if langErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to update lang: %s", langErr.Error()))
+	}
+}
+
+func validateSyncType(ctx context.Context, value string) error {
+	if value != SyncTypeNone && value != SyncTypeFolder && value != SyncTypeGit {
+		return fmt.Errorf("unknown sync type: %s", value)
+	}
+	return nil
+}
+
+func validateBackupRetention(ctx context.Context, value string) error {
+	var retention BackupRetention
+	if unmarshalErr := json.Unmarshal([]byte(value), &retention); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	if retention.Daily < 0 || retention.Weekly < 0 || retention.Monthly < 0 {
+		return fmt.Errorf("backup retention can't be negative")
+	}
+	return nil
+}
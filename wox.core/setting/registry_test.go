@@ -0,0 +1,47 @@
+package setting
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWoxSettingDefinitions(t *testing.T) {
+	definition, found := GetWoxSettingDefinition("MainHotkey")
+	assert.True(t, found)
+	assert.True(t, definition.IsPlatformSpecific)
+	assert.Equal(t, WoxSettingValueTypeString, definition.Type)
+
+	definition, found = GetWoxSettingDefinition("QueryHotkeys")
+	assert.True(t, found)
+	assert.True(t, definition.IsPlatformSpecific)
+	assert.Equal(t, WoxSettingValueTypeJson, definition.Type)
+
+	definition, found = GetWoxSettingDefinition("ShowTray")
+	assert.True(t, found)
+	assert.False(t, definition.IsPlatformSpecific)
+	assert.Equal(t, WoxSettingValueTypeBool, definition.Type)
+
+	_, found = GetWoxSettingDefinition("SchemaVersion")
+	assert.False(t, found)
+}
+
+func TestApplyWoxSettingValue(t *testing.T) {
+	woxSetting := &WoxSetting{}
+	for key, value := range map[string]string{
+		"ShowTray":        "true",
+		"ThemeId":         "dark",
+		"LangCode":        "zh_CN",
+		"MainHotkey":      "alt+space",
+		"EnableAutostart": "true",
+		"QueryShortcuts":  `[{"Shortcut":"gh","Query":"github"}]`,
+		"QueryHotkeys":    `[{"Hotkey":"ctrl+1","Query":"wpm","IsSilentExecution":false}]`,
+	} {
+		assert.Nil(t, applyWoxSettingValue(woxSetting, key, value))
+		assert.Equal(t, value, getWoxSettingValue(woxSetting, key))
+	}
+	assert.Equal(t, "alt+space", woxSetting.MainHotkey.Get())
+	assert.Equal(t, "github", woxSetting.QueryShortcuts[0].Query)
+
+	assert.NotNil(t, applyWoxSettingValue(woxSetting, "QueryShortcuts", "not json"))
+	assert.NotNil(t, applyWoxSettingValue(woxSetting, "NotExist", "1"))
+}
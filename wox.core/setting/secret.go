@@ -0,0 +1,175 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"wox/setting/definition"
+	"wox/util"
+	"wox/util/notifier"
+	"wox/util/secret"
+)
+
+const aiProviderApiKeysSecretKey = "setting/AIProviders"
+
+// GetPluginSecretKey returns the key of a plugin secret setting in secret store
+func GetPluginSecretKey(pluginId string, settingKey string) string {
+	return fmt.Sprintf("plugin/%s/%s", pluginId, settingKey)
+}
+
+func getAIProviderSecretId(provider AIProvider) string {
+	return provider.Name + "@" + provider.Host
+}
+
+// loadAIProviderApiKeys fills api keys from secret store, returns true if plaintext api keys are found in setting file
+func (m *Manager) loadAIProviderApiKeys(ctx context.Context, woxSetting *WoxSetting) (hasPlaintext bool) {
+	apiKeys := map[string]string{}
+	apiKeysJson, getErr := secret.GetSecretManager().Get(ctx, aiProviderApiKeysSecretKey)
+	if getErr == nil {
+		if unmarshalErr := json.Unmarshal([]byte(apiKeysJson), &apiKeys); unmarshalErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to unmarshal ai provider api keys: %s", unmarshalErr.Error()))
+		}
+	} else if !errors.Is(getErr, secret.ErrNotFound) {
+		// don't overwrite api keys we failed to read, E.g. wrong passphrase. Other settings are still saved
+		logger.Error(ctx, fmt.Sprintf("failed to load ai provider api keys: %s", getErr.Error()))
+		notifier.Notify(fmt.Sprintf("Failed to read AI api keys from secret store, changes of api keys won't be saved until Wox restarts: %s", getErr.Error()))
+		m.aiProviderApiKeysLoadErr = getErr
+	}
+	m.aiProviderApiKeys = apiKeys
+
+	for i := range woxSetting.AIProviders {
+		if woxSetting.AIProviders[i].ApiKey != "" {
+			hasPlaintext = true
+			continue
+		}
+		woxSetting.AIProviders[i].ApiKey = apiKeys[getAIProviderSecretId(woxSetting.AIProviders[i])]
+	}
+
+	// profiles may override ai providers as well
+	for _, profile := range woxSetting.Profiles {
+		if providersJson, overridden := profile.WoxSettings["AIProviders"]; overridden {
+			if stripAIProviderApiKeys(providersJson) != providersJson {
+				hasPlaintext = true
+			}
+		}
+	}
+
+	return hasPlaintext
+}
+
+// saveAIProviderApiKeys writes api keys to secret store, it's skipped if api keys failed to load, otherwise they would be lost
+func (m *Manager) saveAIProviderApiKeys(ctx context.Context, woxSetting *WoxSetting) error {
+	if m.aiProviderApiKeysLoadErr != nil {
+		logger.Warn(ctx, fmt.Sprintf("skip saving ai provider api keys, they can't be read from secret store: %s", m.aiProviderApiKeysLoadErr.Error()))
+		return nil
+	}
+
+	providers := slices.Clone(woxSetting.AIProviders)
+	for _, profile := range woxSetting.Profiles {
+		if providersJson, overridden := profile.WoxSettings["AIProviders"]; overridden {
+			var profileProviders []AIProvider
+			if unmarshalErr := json.Unmarshal([]byte(providersJson), &profileProviders); unmarshalErr == nil {
+				m.fillAIProviderApiKeys(profileProviders)
+				providers = append(providers, profileProviders...)
+			}
+		}
+	}
+
+	apiKeys := map[string]string{}
+	for _, provider := range providers {
+		if provider.ApiKey != "" {
+			apiKeys[getAIProviderSecretId(provider)] = provider.ApiKey
+		}
+	}
+	m.aiProviderApiKeys = apiKeys
+	if len(apiKeys) == 0 {
+		return secret.GetSecretManager().Delete(ctx, aiProviderApiKeysSecretKey)
+	}
+
+	apiKeysJson, marshalErr := json.Marshal(apiKeys)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	return secret.GetSecretManager().Set(ctx, aiProviderApiKeysSecretKey, string(apiKeysJson))
+}
+
+// fillAIProviderApiKeys fills empty api keys with keys loaded from secret store
+func (m *Manager) fillAIProviderApiKeys(providers []AIProvider) {
+	for i := range providers {
+		if providers[i].ApiKey == "" {
+			providers[i].ApiKey = m.aiProviderApiKeys[getAIProviderSecretId(providers[i])]
+		}
+	}
+}
+
+// fillAIProviderApiKeysJson fills empty api keys of ai providers json, which is used by imported settings
+func (m *Manager) fillAIProviderApiKeysJson(providersJson string) string {
+	var providers []AIProvider
+	if unmarshalErr := json.Unmarshal([]byte(providersJson), &providers); unmarshalErr != nil {
+		return providersJson
+	}
+
+	m.fillAIProviderApiKeys(providers)
+	filledJson, marshalErr := json.Marshal(providers)
+	if marshalErr != nil {
+		return providersJson
+	}
+	return string(filledJson)
+}
+
+// stripAIProviderApiKeys removes api keys from ai providers json, which is used by profile overrides
+func stripAIProviderApiKeys(providersJson string) string {
+	var providers []AIProvider
+	if unmarshalErr := json.Unmarshal([]byte(providersJson), &providers); unmarshalErr != nil {
+		return providersJson
+	}
+
+	hasApiKey := false
+	for i := range providers {
+		if providers[i].ApiKey != "" {
+			hasApiKey = true
+			providers[i].ApiKey = ""
+		}
+	}
+	if !hasApiKey {
+		return providersJson
+	}
+
+	strippedJson, marshalErr := json.Marshal(providers)
+	if marshalErr != nil {
+		return providersJson
+	}
+	return string(strippedJson)
+}
+
+// migratePluginSecrets moves plaintext secret settings from older versions to secret store
+func (m *Manager) migratePluginSecrets(ctx context.Context, pluginId string, pluginSetting *PluginSetting, definitions definition.PluginSettingDefinitions) {
+	migrated := false
+	for _, item := range definitions {
+		if item.Value == nil || !item.IsSecretSetting() {
+			continue
+		}
+
+		for _, key := range []string{item.Value.GetKey(), item.Value.GetKey() + "@" + util.GetCurrentPlatform()} {
+			value, exist := pluginSetting.Settings.Load(key)
+			if !exist || value == "" || value == item.Value.GetDefaultValue() {
+				continue
+			}
+
+			setErr := secret.GetSecretManager().Set(ctx, GetPluginSecretKey(pluginId, key), value)
+			if setErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to migrate plugin secret %s: %s", key, setErr.Error()))
+				continue
+			}
+			pluginSetting.Settings.Delete(key)
+			migrated = true
+		}
+	}
+
+	if migrated {
+		logger.Info(ctx, fmt.Sprintf("plaintext secrets of plugin %s are moved to secret store", pluginId))
+		m.SavePluginSetting(ctx, pluginId, pluginSetting)
+	}
+}
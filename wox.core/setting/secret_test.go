@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"wox/util"
+	"wox/util/secret"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveWoxSettingWhenApiKeysFailedToLoad(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	assert.Nil(t, secret.GetSecretManager().Set(ctx, aiProviderApiKeysSecretKey, `{"openai@https://api.openai.com":"sk-stored"}`))
+	t.Cleanup(func() {
+		secret.GetSecretManager().Delete(ctx, aiProviderApiKeysSecretKey)
+	})
+
+	// E.g. wrong passphrase at startup, api keys we can't read must not be overwritten, but other settings are still saved
+	m.aiProviderApiKeysLoadErr = errors.New("wrong passphrase")
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "AIProviders", `[{"Name":"openai","ApiKey":"sk-new","Host":"https://api.openai.com"}]`))
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "ThemeId", "saved-theme"))
+
+	content, readErr := os.ReadFile(util.GetLocation().GetWoxSettingPath())
+	assert.Nil(t, readErr)
+	assert.Contains(t, string(content), "saved-theme")
+	assert.NotContains(t, string(content), "sk-new")
+	assert.NotContains(t, string(content), "sk-stored")
+
+	stored, getErr := secret.GetSecretManager().Get(ctx, aiProviderApiKeysSecretKey)
+	assert.Nil(t, getErr)
+	assert.Equal(t, `{"openai@https://api.openai.com":"sk-stored"}`, stored)
+}
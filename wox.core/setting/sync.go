@@ -0,0 +1,436 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"wox/util"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/pretty"
+)
+
+type SyncType = string
+
+const (
+	SyncTypeNone   SyncType = ""
+	SyncTypeFolder SyncType = "folder" // sync through a folder, user can sync the folder with any tool, E.g. Dropbox or Syncthing
+	SyncTypeGit    SyncType = "git"    // same as folder, but every sync is committed to a local git repository
+)
+
+const syncDirectoryName = "wox-sync"
+
+// tombstones are dropped afterwards, a machine offline for longer may bring deleted entries back
+const syncTombstoneExpiry = 90 * 24 * time.Hour
+
+// every machine writes its own file in sync folder, so sync tools never need to merge a file
+type syncMachineFile struct {
+	MachineId   string
+	MachineName string
+	UpdatedAt   int64
+	Entries     map[string]syncEntry
+}
+
+type syncEntry struct {
+	Value     string
+	Timestamp int64 // when this value was changed or deleted
+	IsDeleted bool  `json:",omitempty"` // tombstone, so other machines delete the entry too
+}
+
+// local sync state, kept in wox data directory because it's machine specific
+type syncState struct {
+	MachineId  string
+	LastSyncAt int64
+	Entries    map[string]syncStateEntry
+}
+
+type syncStateEntry struct {
+	Hash      string // hash of current value, used to detect local changes
+	Timestamp int64  // when the value was changed or deleted, on this machine or on the machine it's synced from
+	IsDeleted bool
+	IsLocal   bool // entry exists on this machine, only local entries become tombstones when they disappear
+}
+
+// recordChanges stamps entries changed or deleted since they were recorded last time with timestamp, returns whether anything is recorded
+func (s *syncState) recordChanges(localEntries map[string]string, timestamp int64) bool {
+	changed := false
+	for key, value := range localEntries {
+		hash := util.Md5([]byte(value))
+		entry, exist := s.Entries[key]
+		if exist && !entry.IsDeleted && entry.Hash == hash {
+			if !entry.IsLocal {
+				entry.IsLocal = true
+				s.Entries[key] = entry
+				changed = true
+			}
+			continue
+		}
+		s.Entries[key] = syncStateEntry{Hash: hash, Timestamp: timestamp, IsLocal: true}
+		changed = true
+	}
+	for key, entry := range s.Entries {
+		if _, exist := localEntries[key]; exist || entry.IsDeleted || !entry.IsLocal {
+			continue
+		}
+		s.Entries[key] = syncStateEntry{Timestamp: timestamp, IsDeleted: true}
+		changed = true
+	}
+	return changed
+}
+
+type syncer struct {
+	syncLock         sync.Mutex // only one sync at a time
+	lock             sync.Mutex // guards stopChan, pendingTimer and pendingChangedAt
+	stopChan         chan struct{}
+	pendingTimer     *time.Timer
+	pendingChangedAt int64 // time of the last setting change which is not recorded yet
+}
+
+// StartSync syncs settings periodically and shortly after settings changed, if sync is enabled
+func (m *Manager) StartSync(ctx context.Context) {
+	m.syncer.lock.Lock()
+	defer m.syncer.lock.Unlock()
+
+	if m.syncer.stopChan != nil {
+		close(m.syncer.stopChan)
+		m.syncer.stopChan = nil
+	}
+	if syncType, syncPath := m.getSyncTarget(); syncType == SyncTypeNone || syncPath == "" {
+		return
+	}
+
+	stopChan := make(chan struct{})
+	m.syncer.stopChan = stopChan
+	util.Go(ctx, "sync settings", func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for {
+			if syncErr := m.SyncNow(util.NewTraceContext()); syncErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to sync settings: %s", syncErr.Error()))
+			}
+			select {
+			case <-ticker.C:
+			case <-stopChan:
+				return
+			}
+		}
+	})
+}
+
+// RequestSync records the time settings were changed and syncs after a short delay, so multiple changes in a row are synced once.
+// Setting manager calls it after saving settings, settings saved elsewhere (E.g. themes) should call it after they are changed
+func (m *Manager) RequestSync(ctx context.Context) {
+	if syncType, _ := m.getSyncTarget(); syncType == SyncTypeNone {
+		return
+	}
+
+	m.syncer.lock.Lock()
+	m.syncer.pendingChangedAt = util.GetSystemTimestamp()
+	m.syncer.lock.Unlock()
+
+	// settings applied by a running sync are saved while it holds the lock, the sync scheduled below records them
+	if m.syncer.syncLock.TryLock() {
+		m.recordPendingSettingChanges(ctx)
+		m.syncer.syncLock.Unlock()
+	}
+
+	m.syncer.lock.Lock()
+	defer m.syncer.lock.Unlock()
+	if m.syncer.pendingTimer != nil {
+		m.syncer.pendingTimer.Stop()
+	}
+	m.syncer.pendingTimer = time.AfterFunc(10*time.Second, func() {
+		if syncErr := m.SyncNow(util.NewTraceContext()); syncErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to sync settings: %s", syncErr.Error()))
+		}
+	})
+}
+
+// recordPendingSettingChanges stamps settings changed since they were recorded last time with the time of last change, it must be called with syncLock held
+func (m *Manager) recordPendingSettingChanges(ctx context.Context) {
+	m.syncer.lock.Lock()
+	changedAt := m.syncer.pendingChangedAt
+	m.syncer.pendingChangedAt = 0
+	m.syncer.lock.Unlock()
+	if changedAt == 0 {
+		return
+	}
+
+	localEntries, collectErr := m.collectSettingEntries(ctx, GetSettingSections())
+	if collectErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to record setting changes: %s", collectErr.Error()))
+		return
+	}
+
+	state := m.loadSyncState(ctx)
+	if state.recordChanges(localEntries, changedAt) {
+		m.saveSyncState(ctx, state)
+	}
+}
+
+// SyncNow merges settings of this machine with settings of other machines in sync folder.
+// Each entry, including deletions, is resolved by last writer wins, conflicts are written to sync conflict log
+func (m *Manager) SyncNow(ctx context.Context) error {
+	syncType, syncPath := m.getSyncTarget()
+	if syncType == SyncTypeNone || syncPath == "" {
+		return fmt.Errorf("sync is not enabled")
+	}
+	if syncType != SyncTypeFolder && syncType != SyncTypeGit {
+		return fmt.Errorf("unknown sync type: %s", syncType)
+	}
+
+	m.syncer.syncLock.Lock()
+	defer m.syncer.syncLock.Unlock()
+	m.recordPendingSettingChanges(ctx)
+
+	logger.Info(ctx, fmt.Sprintf("start syncing settings with %s: %s", syncType, syncPath))
+	syncDirectory := path.Join(syncPath, syncDirectoryName)
+	if mkdirErr := os.MkdirAll(syncDirectory, 0755); mkdirErr != nil {
+		return mkdirErr
+	}
+
+	state := m.loadSyncState(ctx)
+	now := util.GetSystemTimestamp()
+	isFirstSync := state.LastSyncAt == 0
+
+	// changes are recorded when settings are saved, changes not recorded (E.g. made while sync was disabled) are stamped with sync time
+	localEntries, collectErr := m.collectSettingEntries(ctx, GetSettingSections())
+	if collectErr != nil {
+		return collectErr
+	}
+	state.recordChanges(localEntries, now)
+
+	// pick the latest value of each entry from other machines
+	remoteMachines, readErr := readSyncMachineFiles(syncDirectory, state.MachineId)
+	if readErr != nil {
+		return readErr
+	}
+	latestRemote := map[string]syncEntry{}
+	latestRemoteMachine := map[string]string{}
+	for _, machine := range remoteMachines {
+		for key, entry := range machine.Entries {
+			if existing, exist := latestRemote[key]; !exist || entry.Timestamp > existing.Timestamp {
+				latestRemote[key] = entry
+				latestRemoteMachine[key] = machine.MachineName
+			}
+		}
+	}
+
+	toApply := map[string]string{}
+	var toDelete []string
+	var conflicts []string
+	for key, remoteEntry := range latestRemote {
+		remoteHash := ""
+		if !remoteEntry.IsDeleted {
+			remoteHash = util.Md5([]byte(remoteEntry.Value))
+		}
+		localEntry, exist := state.Entries[key]
+		if exist && localEntry.IsDeleted == remoteEntry.IsDeleted && localEntry.Hash == remoteHash {
+			continue
+		}
+		if !exist && remoteEntry.IsDeleted {
+			// deleted before this machine got it
+			continue
+		}
+
+		// both sides changed since last sync, last writer wins.
+		// On first sync we can't tell local changes from defaults, let other machines win so a new machine adopts synced settings
+		localChanged := !isFirstSync && exist && localEntry.Timestamp > state.LastSyncAt
+		if localChanged && remoteEntry.Timestamp > state.LastSyncAt {
+			winner := "local"
+			if remoteEntry.Timestamp > localEntry.Timestamp {
+				winner = latestRemoteMachine[key]
+			}
+			conflicts = append(conflicts, fmt.Sprintf("%s\t%s\tlocal %s at %s, %s %s at %s, keep %s", util.FormatTimestamp(now), key, getSyncChangeName(localEntry.IsDeleted), util.FormatTimestamp(localEntry.Timestamp), latestRemoteMachine[key], getSyncChangeName(remoteEntry.IsDeleted), util.FormatTimestamp(remoteEntry.Timestamp), winner))
+		}
+
+		if !exist || isFirstSync || remoteEntry.Timestamp > localEntry.Timestamp {
+			if remoteEntry.IsDeleted {
+				toDelete = append(toDelete, key)
+			} else {
+				toApply[key] = remoteEntry.Value
+			}
+			state.Entries[key] = syncStateEntry{Hash: remoteHash, Timestamp: remoteEntry.Timestamp, IsDeleted: remoteEntry.IsDeleted, IsLocal: localEntry.IsLocal && !remoteEntry.IsDeleted}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		logger.Warn(ctx, fmt.Sprintf("%d sync conflicts resolved, see %s", len(conflicts), util.GetLocation().GetSyncConflictLogPath()))
+		appendSyncConflicts(ctx, conflicts)
+	}
+	if len(toApply) > 0 {
+		logger.Info(ctx, fmt.Sprintf("applying %d synced settings", len(toApply)))
+		m.applySettingEntries(ctx, toApply)
+	}
+	if len(toDelete) > 0 {
+		logger.Info(ctx, fmt.Sprintf("deleting %d synced settings", len(toDelete)))
+		m.deleteSettingEntries(ctx, toDelete)
+	}
+
+	// write local state for other machines, values just applied are written with their original timestamp
+	machineFile := syncMachineFile{
+		MachineId:   state.MachineId,
+		MachineName: getSyncMachineName(),
+		UpdatedAt:   now,
+		Entries:     map[string]syncEntry{},
+	}
+	for key, entry := range state.Entries {
+		if entry.IsDeleted {
+			if now-entry.Timestamp > syncTombstoneExpiry.Milliseconds() {
+				delete(state.Entries, key)
+				continue
+			}
+			machineFile.Entries[key] = syncEntry{Timestamp: entry.Timestamp, IsDeleted: true}
+			continue
+		}
+
+		value, exist := toApply[key]
+		if !exist {
+			value, exist = localEntries[key]
+		}
+		if !exist {
+			// synced from another machine but not available here, E.g. setting of another platform
+			continue
+		}
+		machineFile.Entries[key] = syncEntry{Value: value, Timestamp: entry.Timestamp}
+	}
+	machineFileJson, marshalErr := json.Marshal(machineFile)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	machineFilePath := path.Join(syncDirectory, fmt.Sprintf("%s.json", state.MachineId))
+	if writeErr := os.WriteFile(machineFilePath, pretty.Pretty(machineFileJson), 0644); writeErr != nil {
+		return writeErr
+	}
+
+	if syncType == SyncTypeGit {
+		if commitErr := commitSyncRepository(ctx, syncPath); commitErr != nil {
+			return commitErr
+		}
+	}
+
+	state.LastSyncAt = now
+	m.saveSyncState(ctx, state)
+	logger.Info(ctx, "settings synced")
+	return nil
+}
+
+// getSyncTarget returns sync type and path of base setting, sync settings are local so profiles never override them
+func (m *Manager) getSyncTarget() (SyncType, string) {
+	m.settingLock.RLock()
+	defer m.settingLock.RUnlock()
+	return m.woxSetting.SyncType, m.woxSetting.SyncPath.Get()
+}
+
+func readSyncMachineFiles(syncDirectory string, currentMachineId string) ([]syncMachineFile, error) {
+	files, readDirErr := os.ReadDir(syncDirectory)
+	if readDirErr != nil {
+		return nil, readDirErr
+	}
+
+	var machines []syncMachineFile
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || file.Name() == currentMachineId+".json" {
+			continue
+		}
+		content, readErr := os.ReadFile(path.Join(syncDirectory, file.Name()))
+		if readErr != nil {
+			return nil, readErr
+		}
+		var machine syncMachineFile
+		if unmarshalErr := json.Unmarshal(content, &machine); unmarshalErr != nil {
+			// file may be partially synced by sync tool, try again next time
+			logger.Warn(context.Background(), fmt.Sprintf("failed to parse sync file %s: %s", file.Name(), unmarshalErr.Error()))
+			continue
+		}
+		machines = append(machines, machine)
+	}
+
+	return machines, nil
+}
+
+func commitSyncRepository(ctx context.Context, syncPath string) error {
+	if !util.IsDirExists(path.Join(syncPath, ".git")) {
+		if _, initErr := util.ShellRunOutput("git", "-C", syncPath, "init"); initErr != nil {
+			return fmt.Errorf("failed to init git repository: %w", initErr)
+		}
+	}
+	if _, addErr := util.ShellRunOutput("git", "-C", syncPath, "add", syncDirectoryName); addErr != nil {
+		return fmt.Errorf("failed to add sync files: %w", addErr)
+	}
+	// nothing to commit if status is empty
+	status, statusErr := util.ShellRunOutput("git", "-C", syncPath, "status", "--porcelain", syncDirectoryName)
+	if statusErr != nil {
+		return fmt.Errorf("failed to get git status: %w", statusErr)
+	}
+	if strings.TrimSpace(string(status)) == "" {
+		return nil
+	}
+	if _, commitErr := util.ShellRunOutput("git", "-C", syncPath, "commit", "-m", fmt.Sprintf("Sync Wox settings from %s", getSyncMachineName())); commitErr != nil {
+		return fmt.Errorf("failed to commit sync files: %w", commitErr)
+	}
+
+	logger.Info(ctx, "sync files committed")
+	return nil
+}
+
+func (m *Manager) loadSyncState(ctx context.Context) *syncState {
+	state := &syncState{}
+	content, readErr := os.ReadFile(util.GetLocation().GetSyncStatePath())
+	if readErr == nil {
+		if unmarshalErr := json.Unmarshal(content, state); unmarshalErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to parse sync state: %s", unmarshalErr.Error()))
+		}
+	}
+	if state.MachineId == "" {
+		state.MachineId = uuid.NewString()
+	}
+	if state.Entries == nil {
+		state.Entries = map[string]syncStateEntry{}
+	}
+	return state
+}
+
+func (m *Manager) saveSyncState(ctx context.Context, state *syncState) {
+	stateJson, marshalErr := json.Marshal(state)
+	if marshalErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to marshal sync state: %s", marshalErr.Error()))
+		return
+	}
+	if writeErr := os.WriteFile(util.GetLocation().GetSyncStatePath(), stateJson, 0644); writeErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to save sync state: %s", writeErr.Error()))
+	}
+}
+
+func appendSyncConflicts(ctx context.Context, conflicts []string) {
+	logFile, openErr := os.OpenFile(util.GetLocation().GetSyncConflictLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to open sync conflict log: %s", openErr.Error()))
+		return
+	}
+	defer logFile.Close()
+
+	for _, conflict := range conflicts {
+		logFile.WriteString(conflict + "\n")
+	}
+}
+
+func getSyncChangeName(isDeleted bool) string {
+	if isDeleted {
+		return "deleted"
+	}
+	return "changed"
+}
+
+func getSyncMachineName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return hostname
+}
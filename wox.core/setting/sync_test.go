@@ -0,0 +1,213 @@
+package setting
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSyncManager(t *testing.T) (*Manager, string) {
+	m := newTestManager(t)
+	// sync state is kept in wox data directory, which newTestManager doesn't clean
+	assert.Nil(t, os.RemoveAll(util.GetLocation().GetSyncStatePath()))
+	assert.Nil(t, os.RemoveAll(util.GetLocation().GetSyncConflictLogPath()))
+	syncPath := t.TempDir()
+	m.woxSetting.SyncType = SyncTypeFolder
+	m.woxSetting.SyncPath.Set(syncPath)
+	t.Cleanup(func() {
+		m.syncer.lock.Lock()
+		defer m.syncer.lock.Unlock()
+		if m.syncer.pendingTimer != nil {
+			m.syncer.pendingTimer.Stop()
+		}
+	})
+	return m, syncPath
+}
+
+func writeTestSyncMachineFile(t *testing.T, syncPath string, machineName string, entries map[string]syncEntry) {
+	machineFileJson, marshalErr := json.Marshal(syncMachineFile{
+		MachineId:   machineName,
+		MachineName: machineName,
+		UpdatedAt:   util.GetSystemTimestamp(),
+		Entries:     entries,
+	})
+	assert.Nil(t, marshalErr)
+	assert.Nil(t, os.MkdirAll(path.Join(syncPath, syncDirectoryName), 0755))
+	assert.Nil(t, os.WriteFile(path.Join(syncPath, syncDirectoryName, machineName+".json"), machineFileJson, 0644))
+}
+
+func readTestSyncMachineFile(t *testing.T, m *Manager, syncPath string) syncMachineFile {
+	state := m.loadSyncState(context.Background())
+	content, readErr := os.ReadFile(path.Join(syncPath, syncDirectoryName, state.MachineId+".json"))
+	assert.Nil(t, readErr)
+	var machine syncMachineFile
+	assert.Nil(t, json.Unmarshal(content, &machine))
+	return machine
+}
+
+func TestSyncNowLastWriterWins(t *testing.T) {
+	ctx := context.Background()
+	m, syncPath := newTestSyncManager(t)
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "LastQueryMode", "local-mode"))
+
+	// first sync adopts values of other machines, unrecorded values only this machine has are stamped with sync time
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{
+		"wox/ThemeId": {Value: "remote-theme", Timestamp: 1000},
+	})
+	assert.Nil(t, m.SyncNow(ctx))
+	assert.Equal(t, "remote-theme", m.GetWoxSetting(ctx).ThemeId)
+	firstSyncAt := m.loadSyncState(ctx).LastSyncAt
+	machine := readTestSyncMachineFile(t, m, syncPath)
+	assert.Equal(t, syncEntry{Value: "remote-theme", Timestamp: 1000}, machine.Entries["wox/ThemeId"])
+	assert.Equal(t, syncEntry{Value: "local-mode", Timestamp: firstSyncAt}, machine.Entries["wox/LastQueryMode"])
+	_, conflictErr := os.Stat(util.GetLocation().GetSyncConflictLogPath())
+	assert.True(t, os.IsNotExist(conflictErr))
+
+	// each key is resolved on its own, newer remote value wins and older remote value is ignored
+	time.Sleep(5 * time.Millisecond)
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{
+		"wox/ThemeId":       {Value: "remote-theme-2", Timestamp: firstSyncAt + 1},
+		"wox/LastQueryMode": {Value: "stale-mode", Timestamp: firstSyncAt - 1},
+	})
+	assert.Nil(t, m.SyncNow(ctx))
+	assert.Equal(t, "remote-theme-2", m.GetWoxSetting(ctx).ThemeId)
+	assert.Equal(t, "local-mode", m.GetWoxSetting(ctx).LastQueryMode)
+	machine = readTestSyncMachineFile(t, m, syncPath)
+	assert.Equal(t, syncEntry{Value: "remote-theme-2", Timestamp: firstSyncAt + 1}, machine.Entries["wox/ThemeId"])
+	assert.Equal(t, syncEntry{Value: "local-mode", Timestamp: firstSyncAt}, machine.Entries["wox/LastQueryMode"])
+}
+
+func TestSyncNowConflicts(t *testing.T) {
+	ctx := context.Background()
+	m, syncPath := newTestSyncManager(t)
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{})
+	assert.Nil(t, m.SyncNow(ctx))
+	firstSyncAt := m.loadSyncState(ctx).LastSyncAt
+
+	// both sides changed since last sync, remote changed later
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "ThemeId", "local-theme"))
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "LastQueryMode", "local-mode"))
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{
+		"wox/ThemeId":       {Value: "remote-theme", Timestamp: util.GetSystemTimestamp() + time.Hour.Milliseconds()},
+		"wox/LastQueryMode": {Value: "remote-mode", Timestamp: firstSyncAt + 1},
+	})
+	assert.Nil(t, m.SyncNow(ctx))
+	assert.Equal(t, "remote-theme", m.GetWoxSetting(ctx).ThemeId)
+	assert.Equal(t, "local-mode", m.GetWoxSetting(ctx).LastQueryMode)
+
+	conflictLog, readErr := os.ReadFile(util.GetLocation().GetSyncConflictLogPath())
+	assert.Nil(t, readErr)
+	assert.Regexp(t, `\twox/ThemeId\tlocal changed at .*, keep remote\n`, string(conflictLog))
+	assert.Regexp(t, `\twox/LastQueryMode\tlocal changed at .*, keep local\n`, string(conflictLog))
+
+	// resolved conflicts are not logged again
+	assert.Nil(t, m.SyncNow(ctx))
+	secondLog, _ := os.ReadFile(util.GetLocation().GetSyncConflictLogPath())
+	assert.Equal(t, string(conflictLog), string(secondLog))
+}
+
+func TestSyncNowUsesRecordedChangeTime(t *testing.T) {
+	ctx := context.Background()
+	m, syncPath := newTestSyncManager(t)
+	assert.Nil(t, m.SyncNow(ctx))
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "ThemeId", "local-theme"))
+	m.RequestSync(ctx)
+	changedAt := m.loadSyncState(ctx).Entries["wox/ThemeId"].Timestamp
+
+	// remote changed after local change but before sync, so remote wins
+	time.Sleep(5 * time.Millisecond)
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{
+		"wox/ThemeId": {Value: "remote-theme", Timestamp: changedAt + 1},
+	})
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, m.SyncNow(ctx))
+	assert.Equal(t, "remote-theme", m.GetWoxSetting(ctx).ThemeId)
+	machine := readTestSyncMachineFile(t, m, syncPath)
+	assert.Equal(t, syncEntry{Value: "remote-theme", Timestamp: changedAt + 1}, machine.Entries["wox/ThemeId"])
+}
+
+func TestSyncNowDeletions(t *testing.T) {
+	ctx := context.Background()
+	m, syncPath := newTestSyncManager(t)
+	themePath := path.Join(util.GetLocation().GetThemeDirectory(), "local-theme.json")
+	pluginSettingPath := path.Join(util.GetLocation().GetPluginSettingDirectory(), "plugin-id.json")
+	assert.Nil(t, os.MkdirAll(util.GetLocation().GetThemeDirectory(), 0755))
+	assert.Nil(t, os.MkdirAll(util.GetLocation().GetPluginSettingDirectory(), 0755))
+	assert.Nil(t, os.WriteFile(themePath, []byte(`{"ThemeId":"local-theme"}`), 0644))
+	assert.Nil(t, os.WriteFile(pluginSettingPath, []byte(`{"Settings":{"a":"1","b":"2"}}`), 0644))
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{})
+	assert.Nil(t, m.SyncNow(ctx))
+
+	// local deletion is published as tombstone with the time it was recorded
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, os.Remove(themePath))
+	m.RequestSync(ctx)
+	deletedAt := m.loadSyncState(ctx).Entries["theme/local-theme"].Timestamp
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, m.SyncNow(ctx))
+	machine := readTestSyncMachineFile(t, m, syncPath)
+	assert.Equal(t, syncEntry{Timestamp: deletedAt, IsDeleted: true}, machine.Entries["theme/local-theme"])
+
+	// newer remote tombstone deletes local entry
+	var event SettingsImportedEvent
+	m.OnSettingsImported(func(ctx context.Context, e SettingsImportedEvent) {
+		event = e
+	})
+	writeTestSyncMachineFile(t, syncPath, "remote", map[string]syncEntry{
+		"plugin/plugin-id/a": {Timestamp: util.GetSystemTimestamp(), IsDeleted: true},
+	})
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, m.SyncNow(ctx))
+	pluginSetting, readErr := readPluginSettingFile(pluginSettingPath)
+	assert.Nil(t, readErr)
+	assert.False(t, pluginSetting.Settings.Exist("a"))
+	assert.True(t, pluginSetting.Settings.Exist("b"))
+	assert.Equal(t, map[string][]string{"plugin-id": {"a"}}, event.DeletedPluginSettings)
+	machine = readTestSyncMachineFile(t, m, syncPath)
+	assert.True(t, machine.Entries["plugin/plugin-id/a"].IsDeleted)
+}
+
+func TestExportImportSettings(t *testing.T) {
+	ctx := context.Background()
+	archivePath := path.Join(t.TempDir(), "settings.zip")
+
+	m := newTestManager(t)
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "ThemeId", "exported-theme"))
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "AIProviders", `[{"Name":"openai","ApiKey":"sk-secret","Host":"https://api.openai.com"}]`))
+	assert.Nil(t, m.UpdateWoxSetting(ctx, "CustomPythonPath", "/local/python"))
+	assert.Nil(t, m.mergePluginSettingFile(ctx, "plugin-id", map[string]string{"key": "value"}))
+	assert.Nil(t, m.ExportSettings(ctx, archivePath, GetSettingSections()))
+
+	archive, openErr := zip.OpenReader(archivePath)
+	assert.Nil(t, openErr)
+	settingsFile, findErr := archive.Open("settings.json")
+	assert.Nil(t, findErr)
+	content, readErr := io.ReadAll(settingsFile)
+	assert.Nil(t, readErr)
+	archive.Close()
+	assert.NotContains(t, string(content), "sk-secret")
+	assert.NotContains(t, string(content), "CustomPythonPath")
+	assert.NotContains(t, string(content), "/local/python")
+
+	imported := newTestManager(t)
+	assert.Nil(t, imported.ImportSettings(ctx, archivePath, nil))
+	assert.Equal(t, "exported-theme", imported.GetWoxSetting(ctx).ThemeId)
+	assert.Equal(t, []AIProvider{{Name: "openai", Host: "https://api.openai.com"}}, imported.GetWoxSetting(ctx).AIProviders)
+	assert.Equal(t, "", imported.GetWoxSetting(ctx).CustomPythonPath.Get())
+	pluginSetting, loadErr := readPluginSettingFile(path.Join(util.GetLocation().GetPluginSettingDirectory(), "plugin-id.json"))
+	assert.Nil(t, loadErr)
+	value, exist := pluginSetting.Settings.Load("key")
+	assert.True(t, exist)
+	assert.Equal(t, "value", value)
+}
@@ -0,0 +1,457 @@
+package setting
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"wox/updater"
+	"wox/util"
+
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/pretty"
+)
+
+// SettingSection groups setting entries so users can choose what to export, import or sync
+type SettingSection = string
+
+const (
+	SettingSectionWox            SettingSection = "wox"             // wox settings, except query shortcuts
+	SettingSectionQueryShortcuts SettingSection = "query_shortcuts" // wox query shortcuts
+	SettingSectionPlugins        SettingSection = "plugins"         // settings of plugins which don't have their own section
+	SettingSectionThemes         SettingSection = "themes"          // user installed themes
+)
+
+const (
+	settingEntryWoxPrefix    = "wox/"
+	settingEntryPluginPrefix = "plugin/"
+	settingEntryThemePrefix  = "theme/"
+	settingArchiveVersion    = 1
+)
+
+// plugin id => section, so plugin data such as snippets can be selected separately
+var pluginSettingSections = map[string]SettingSection{}
+
+// RegisterPluginSettingSection puts all settings of a plugin into given section instead of SettingSectionPlugins
+func RegisterPluginSettingSection(pluginId string, section SettingSection) {
+	pluginSettingSections[pluginId] = section
+}
+
+// GetSettingSections returns all sections which can be exported, imported or synced
+func GetSettingSections() []SettingSection {
+	sections := []SettingSection{SettingSectionWox, SettingSectionQueryShortcuts, SettingSectionPlugins, SettingSectionThemes}
+	for _, section := range pluginSettingSections {
+		if !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// SettingsImportedEvent is sent to subscribers after entries were applied by import or sync,
+// wox settings are already applied by UpdateWoxSetting, plugin settings and themes are written to files
+type SettingsImportedEvent struct {
+	PluginSettings        map[string]map[string]string // plugin id => setting key => value
+	ThemeIds              []string
+	DeletedPluginSettings map[string][]string // plugin id => setting keys, deleted by sync
+	DeletedThemeIds       []string
+}
+
+// OnSettingsImported registers a callback invoked after import or sync changed plugin settings or themes, so loaded data can be refreshed
+func (m *Manager) OnSettingsImported(callback func(ctx context.Context, event SettingsImportedEvent)) {
+	m.settingsImportedCallbacks = append(m.settingsImportedCallbacks, callback)
+}
+
+type settingArchiveManifest struct {
+	Version    int
+	WoxVersion string
+	ExportedAt int64
+	Sections   []SettingSection
+}
+
+// ExportSettings writes settings of given sections to a portable zip archive. Secrets such as api keys are never exported
+func (m *Manager) ExportSettings(ctx context.Context, archivePath string, sections []SettingSection) error {
+	logger.Info(ctx, fmt.Sprintf("exporting settings to %s, sections: %s", archivePath, strings.Join(sections, ",")))
+	entries, collectErr := m.collectSettingEntries(ctx, sections)
+	if collectErr != nil {
+		return collectErr
+	}
+
+	archiveFile, createErr := os.Create(archivePath)
+	if createErr != nil {
+		return createErr
+	}
+	defer archiveFile.Close()
+
+	zipWriter := zip.NewWriter(archiveFile)
+	manifest := settingArchiveManifest{
+		Version:    settingArchiveVersion,
+		WoxVersion: updater.CURRENT_VERSION,
+		ExportedAt: util.GetSystemTimestamp(),
+		Sections:   sections,
+	}
+	for name, content := range map[string]any{"manifest.json": manifest, "settings.json": entries} {
+		contentJson, marshalErr := json.Marshal(content)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		writer, writerErr := zipWriter.Create(name)
+		if writerErr != nil {
+			return writerErr
+		}
+		if _, writeErr := writer.Write(pretty.Pretty(contentJson)); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+// ImportSettings applies settings of given sections from an archive created by ExportSettings. Empty sections means all sections in archive
+func (m *Manager) ImportSettings(ctx context.Context, archivePath string, sections []SettingSection) error {
+	logger.Info(ctx, fmt.Sprintf("importing settings from %s, sections: %s", archivePath, strings.Join(sections, ",")))
+	zipReader, openErr := zip.OpenReader(archivePath)
+	if openErr != nil {
+		return openErr
+	}
+	defer zipReader.Close()
+
+	var manifest settingArchiveManifest
+	entries := map[string]string{}
+	for name, target := range map[string]any{"manifest.json": &manifest, "settings.json": &entries} {
+		file, findErr := zipReader.Open(name)
+		if findErr != nil {
+			return fmt.Errorf("invalid setting archive, %s not found", name)
+		}
+		content, readErr := io.ReadAll(file)
+		file.Close()
+		if readErr != nil {
+			return readErr
+		}
+		if unmarshalErr := json.Unmarshal(content, target); unmarshalErr != nil {
+			return fmt.Errorf("invalid setting archive, failed to parse %s: %w", name, unmarshalErr)
+		}
+	}
+	if manifest.Version > settingArchiveVersion {
+		return fmt.Errorf("setting archive version %d is not supported, please upgrade Wox", manifest.Version)
+	}
+
+	if len(sections) > 0 {
+		entries = lo.PickBy(entries, func(key string, value string) bool {
+			return slices.Contains(sections, getSettingEntrySection(key))
+		})
+	}
+
+	m.applySettingEntries(ctx, entries)
+	return nil
+}
+
+// collectSettingEntries flattens settings of given sections into entry key => value
+//
+//	wox/<setting key>[@platform]          value of wox setting, platform specific settings have platform suffix
+//	plugin/<plugin id>/<setting key>      value of plugin setting, secret settings are kept in secret store so they are not included
+//	theme/<theme id>                      theme json
+func (m *Manager) collectSettingEntries(ctx context.Context, sections []SettingSection) (map[string]string, error) {
+	entries := map[string]string{}
+
+	for _, definition := range woxSettingDefinitions {
+		if definition.IsLocal {
+			continue
+		}
+		value := m.getBaseWoxSettingValue(definition.Key)
+		if definition.Key == "AIProviders" {
+			value = stripAIProviderApiKeys(value)
+		}
+		entries[getWoxSettingEntryKey(definition)] = value
+	}
+
+	pluginSettingDirectory := util.GetLocation().GetPluginSettingDirectory()
+	pluginSettingFiles, readDirErr := os.ReadDir(pluginSettingDirectory)
+	if readDirErr != nil {
+		return nil, readDirErr
+	}
+	for _, file := range pluginSettingFiles {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		pluginId := strings.TrimSuffix(file.Name(), ".json")
+		pluginSetting, readErr := readPluginSettingFile(path.Join(pluginSettingDirectory, file.Name()))
+		if readErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to read plugin setting %s: %s", pluginId, readErr.Error()))
+			continue
+		}
+		pluginSetting.Settings.Range(func(key string, value string) bool {
+			entries[settingEntryPluginPrefix+pluginId+"/"+key] = value
+			return true
+		})
+	}
+
+	themeFiles, readThemeDirErr := os.ReadDir(util.GetLocation().GetThemeDirectory())
+	if readThemeDirErr == nil {
+		for _, file := range themeFiles {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+				continue
+			}
+			themeJson, readErr := os.ReadFile(path.Join(util.GetLocation().GetThemeDirectory(), file.Name()))
+			if readErr != nil {
+				continue
+			}
+			themeId := gjson.GetBytes(themeJson, "ThemeId").String()
+			if themeId == "" {
+				continue
+			}
+			entries[settingEntryThemePrefix+themeId] = string(themeJson)
+		}
+	}
+
+	return lo.PickBy(entries, func(key string, value string) bool {
+		return slices.Contains(sections, getSettingEntrySection(key))
+	}), nil
+}
+
+// applySettingEntries applies entries to current settings, entries which are not changed are skipped.
+// Wox settings are applied last, so subscribers can find imported themes, E.g. ThemeId changed to an imported theme
+func (m *Manager) applySettingEntries(ctx context.Context, entries map[string]string) {
+	event := SettingsImportedEvent{
+		PluginSettings: map[string]map[string]string{},
+	}
+
+	woxSettings := map[string]string{}
+	for key, value := range entries {
+		if strings.HasPrefix(key, settingEntryWoxPrefix) {
+			settingKey := strings.TrimPrefix(key, settingEntryWoxPrefix)
+			if strings.Contains(settingKey, "@") {
+				// platform specific values of other platforms are kept for machines of that platform
+				if !strings.HasSuffix(settingKey, "@"+util.GetCurrentPlatform()) {
+					continue
+				}
+				settingKey = strings.Split(settingKey, "@")[0]
+			}
+			definition, found := GetWoxSettingDefinition(settingKey)
+			if !found || definition.IsLocal {
+				continue
+			}
+			if settingKey == "AIProviders" {
+				value = m.fillAIProviderApiKeysJson(value)
+			}
+			woxSettings[settingKey] = value
+		} else if strings.HasPrefix(key, settingEntryPluginPrefix) {
+			pluginId, settingKey, found := strings.Cut(strings.TrimPrefix(key, settingEntryPluginPrefix), "/")
+			if !found {
+				continue
+			}
+			if !isValidSettingEntryId(pluginId) {
+				logger.Warn(ctx, fmt.Sprintf("skip setting of invalid plugin id: %s", pluginId))
+				continue
+			}
+			if _, exist := event.PluginSettings[pluginId]; !exist {
+				event.PluginSettings[pluginId] = map[string]string{}
+			}
+			event.PluginSettings[pluginId][settingKey] = value
+		} else if strings.HasPrefix(key, settingEntryThemePrefix) {
+			themeId := strings.TrimPrefix(key, settingEntryThemePrefix)
+			// entries come from archives or sync files, never let them write outside theme directory
+			if !isValidSettingEntryId(themeId) {
+				logger.Warn(ctx, fmt.Sprintf("skip theme of invalid id: %s", themeId))
+				continue
+			}
+			if gjson.Get(value, "ThemeId").String() != themeId {
+				logger.Warn(ctx, fmt.Sprintf("skip theme %s, its ThemeId doesn't match", themeId))
+				continue
+			}
+			themePath := path.Join(util.GetLocation().GetThemeDirectory(), fmt.Sprintf("%s.json", themeId))
+			if existing, readErr := os.ReadFile(themePath); readErr == nil && string(existing) == value {
+				continue
+			}
+			if writeErr := os.WriteFile(themePath, []byte(value), 0644); writeErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to write theme %s: %s", themeId, writeErr.Error()))
+				continue
+			}
+			event.ThemeIds = append(event.ThemeIds, themeId)
+		}
+	}
+
+	for pluginId, settings := range event.PluginSettings {
+		if mergeErr := m.mergePluginSettingFile(ctx, pluginId, settings); mergeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to apply plugin setting %s: %s", pluginId, mergeErr.Error()))
+			delete(event.PluginSettings, pluginId)
+		}
+	}
+
+	if len(event.PluginSettings) > 0 || len(event.ThemeIds) > 0 {
+		for _, callback := range m.settingsImportedCallbacks {
+			callback(ctx, event)
+		}
+	}
+
+	for settingKey, value := range woxSettings {
+		if m.getBaseWoxSettingValue(settingKey) == value {
+			continue
+		}
+		// imported values are always base settings, even if active profile overrides them
+		if updateErr := m.updateWoxSetting(ctx, settingKey, value, false); updateErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to apply setting %s: %s", settingKey, updateErr.Error()))
+		}
+	}
+}
+
+// mergePluginSettingFile writes given settings into plugin setting file, plugin may not be installed yet
+// deleteSettingEntries deletes entries deleted on other machines.
+// Only plugin settings and themes can be deleted, wox settings always have a value
+func (m *Manager) deleteSettingEntries(ctx context.Context, keys []string) {
+	event := SettingsImportedEvent{
+		DeletedPluginSettings: map[string][]string{},
+	}
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, settingEntryPluginPrefix) {
+			pluginId, settingKey, found := strings.Cut(strings.TrimPrefix(key, settingEntryPluginPrefix), "/")
+			if !found || !isValidSettingEntryId(pluginId) {
+				continue
+			}
+			event.DeletedPluginSettings[pluginId] = append(event.DeletedPluginSettings[pluginId], settingKey)
+		} else if strings.HasPrefix(key, settingEntryThemePrefix) {
+			themeId := strings.TrimPrefix(key, settingEntryThemePrefix)
+			if !isValidSettingEntryId(themeId) {
+				continue
+			}
+			themePath := path.Join(util.GetLocation().GetThemeDirectory(), fmt.Sprintf("%s.json", themeId))
+			if removeErr := os.Remove(themePath); removeErr != nil {
+				if !os.IsNotExist(removeErr) {
+					logger.Error(ctx, fmt.Sprintf("failed to delete theme %s: %s", themeId, removeErr.Error()))
+				}
+				continue
+			}
+			event.DeletedThemeIds = append(event.DeletedThemeIds, themeId)
+		}
+	}
+
+	for pluginId, settingKeys := range event.DeletedPluginSettings {
+		if deleteErr := m.deletePluginSettingKeys(ctx, pluginId, settingKeys); deleteErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to delete plugin setting %s: %s", pluginId, deleteErr.Error()))
+			delete(event.DeletedPluginSettings, pluginId)
+		}
+	}
+
+	if len(event.DeletedPluginSettings) > 0 || len(event.DeletedThemeIds) > 0 {
+		for _, callback := range m.settingsImportedCallbacks {
+			callback(ctx, event)
+		}
+	}
+}
+
+func (m *Manager) deletePluginSettingKeys(ctx context.Context, pluginId string, settingKeys []string) error {
+	pluginSettingPath := path.Join(util.GetLocation().GetPluginSettingDirectory(), fmt.Sprintf("%s.json", pluginId))
+	if !util.IsFileExists(pluginSettingPath) {
+		return nil
+	}
+	pluginSetting, readErr := readPluginSettingFile(pluginSettingPath)
+	if readErr != nil {
+		return readErr
+	}
+
+	changed := false
+	for _, key := range settingKeys {
+		if pluginSetting.Settings.Exist(key) {
+			pluginSetting.Settings.Delete(key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	return m.SavePluginSetting(ctx, pluginId, pluginSetting)
+}
+
+func (m *Manager) mergePluginSettingFile(ctx context.Context, pluginId string, settings map[string]string) error {
+	pluginSettingPath := path.Join(util.GetLocation().GetPluginSettingDirectory(), fmt.Sprintf("%s.json", pluginId))
+	pluginSetting := &PluginSetting{
+		SchemaVersion: PluginSettingSchema.CurrentVersion(),
+		Settings:      util.NewHashMap[string, string](),
+	}
+	if util.IsFileExists(pluginSettingPath) {
+		existing, readErr := readPluginSettingFile(pluginSettingPath)
+		if readErr != nil {
+			return readErr
+		}
+		pluginSetting = existing
+	}
+
+	changed := false
+	for key, value := range settings {
+		if existValue, exist := pluginSetting.Settings.Load(key); exist && existValue == value {
+			continue
+		}
+		pluginSetting.Settings.Store(key, value)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	return m.SavePluginSetting(ctx, pluginId, pluginSetting)
+}
+
+func readPluginSettingFile(pluginSettingPath string) (*PluginSetting, error) {
+	content, readErr := os.ReadFile(pluginSettingPath)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	data := map[string]any{}
+	if unmarshalErr := json.Unmarshal(content, &data); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	pluginSetting := &PluginSetting{}
+	if unmarshalErr := json.Unmarshal(content, pluginSetting); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	pluginSetting.UnknownFields = findUnknownFields(data, pluginSetting)
+	if pluginSetting.Settings == nil {
+		pluginSetting.Settings = util.NewHashMap[string, string]()
+	}
+	return pluginSetting, nil
+}
+
+// isValidSettingEntryId reports whether id of an entry can be used as a file name
+func isValidSettingEntryId(id string) bool {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return false
+	}
+	return filepath.Base(id) == id
+}
+
+func getWoxSettingEntryKey(definition *WoxSettingDefinition) string {
+	if definition.IsPlatformSpecific {
+		return settingEntryWoxPrefix + definition.Key + "@" + util.GetCurrentPlatform()
+	}
+	return settingEntryWoxPrefix + definition.Key
+}
+
+func getSettingEntrySection(entryKey string) SettingSection {
+	if strings.HasPrefix(entryKey, settingEntryWoxPrefix) {
+		if entryKey == settingEntryWoxPrefix+"QueryShortcuts" {
+			return SettingSectionQueryShortcuts
+		}
+		return SettingSectionWox
+	}
+	if strings.HasPrefix(entryKey, settingEntryPluginPrefix) {
+		pluginId, _, _ := strings.Cut(strings.TrimPrefix(entryKey, settingEntryPluginPrefix), "/")
+		if section, exist := pluginSettingSections[pluginId]; exist {
+			return section
+		}
+		return SettingSectionPlugins
+	}
+	if strings.HasPrefix(entryKey, settingEntryThemePrefix) {
+		return SettingSectionThemes
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package setting
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+	"wox/util"
+)
+
+func TestGetSettingEntrySection(t *testing.T) {
+	RegisterPluginSettingSection("snippets-plugin-id", "snippets")
+
+	assert.Equal(t, SettingSectionWox, getSettingEntrySection("wox/ThemeId"))
+	assert.Equal(t, SettingSectionWox, getSettingEntrySection("wox/MainHotkey@darwin"))
+	assert.Equal(t, SettingSectionQueryShortcuts, getSettingEntrySection("wox/QueryShortcuts"))
+	assert.Equal(t, "snippets", getSettingEntrySection("plugin/snippets-plugin-id/snippets"))
+	assert.Equal(t, SettingSectionPlugins, getSettingEntrySection("plugin/other-plugin-id/key"))
+	assert.Equal(t, SettingSectionThemes, getSettingEntrySection("theme/theme-id"))
+	assert.Contains(t, GetSettingSections(), "snippets")
+}
+
+func TestApplySettingEntriesRejectsInvalidIds(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userDataDirectory := util.GetLocation().GetUserDataDirectory()
+
+	m.applySettingEntries(ctx, map[string]string{
+		"theme/../escaped":           `{"ThemeId":"../escaped"}`,
+		"theme/mismatch":             `{"ThemeId":"other"}`,
+		"theme/good":                 `{"ThemeId":"good"}`,
+		"plugin/../escaped-plugin/k": "v",
+	})
+
+	assert.NoFileExists(t, path.Join(userDataDirectory, "escaped.json"))
+	assert.NoFileExists(t, path.Join(util.GetLocation().GetPluginSettingDirectory(), "...json"))
+	assert.NoFileExists(t, path.Join(util.GetLocation().GetThemeDirectory(), "mismatch.json"))
+	content, readErr := os.ReadFile(path.Join(util.GetLocation().GetThemeDirectory(), "good.json"))
+	assert.Nil(t, readErr)
+	assert.Equal(t, `{"ThemeId":"good"}`, string(content))
+
+	assert.True(t, isValidSettingEntryId("wox-theme-dark"))
+	assert.False(t, isValidSettingEntryId(".."))
+	assert.False(t, isValidSettingEntryId(`a\b`))
+	assert.False(t, isValidSettingEntryId(""))
+}
@@ -1,10 +1,24 @@
 package validator
 
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
 type PluginSettingValidatorType string
 
 const (
-	PluginSettingValidatorTypeIsNumber PluginSettingValidatorType = "is_number"
-	PluginSettingValidatorTypeNotEmpty PluginSettingValidatorType = "not_empty"
+	PluginSettingValidatorTypeIsNumber   PluginSettingValidatorType = "is_number"
+	PluginSettingValidatorTypeNotEmpty   PluginSettingValidatorType = "not_empty"
+	PluginSettingValidatorTypeRegex      PluginSettingValidatorType = "regex"
+	PluginSettingValidatorTypeMin        PluginSettingValidatorType = "min"
+	PluginSettingValidatorTypeMax        PluginSettingValidatorType = "max"
+	PluginSettingValidatorTypeLength     PluginSettingValidatorType = "length"
+	PluginSettingValidatorTypeUrl        PluginSettingValidatorType = "url"
+	PluginSettingValidatorTypePathExists PluginSettingValidatorType = "path_exists"
 )
 
 type PluginSettingValidator struct {
@@ -14,4 +28,65 @@ type PluginSettingValidator struct {
 
 type PluginSettingValidatorValue interface {
 	GetValidatorType() PluginSettingValidatorType
+	// Validate returns an error describing why the value is invalid
+	Validate(value string) error
+}
+
+func (p *PluginSettingValidator) UnmarshalJSON(b []byte) error {
+	typeResult := gjson.GetBytes(b, "Type")
+	if !typeResult.Exists() {
+		return errors.New("validator must have Type property")
+	}
+
+	var v PluginSettingValidatorValue
+	switch PluginSettingValidatorType(typeResult.String()) {
+	case PluginSettingValidatorTypeIsNumber:
+		v = &PluginSettingValidatorIsNumber{}
+	case PluginSettingValidatorTypeNotEmpty:
+		v = &PluginSettingValidatorNotEmpty{}
+	case PluginSettingValidatorTypeRegex:
+		v = &PluginSettingValidatorRegex{}
+	case PluginSettingValidatorTypeMin:
+		v = &PluginSettingValidatorMin{}
+	case PluginSettingValidatorTypeMax:
+		v = &PluginSettingValidatorMax{}
+	case PluginSettingValidatorTypeLength:
+		v = &PluginSettingValidatorLength{}
+	case PluginSettingValidatorTypeUrl:
+		v = &PluginSettingValidatorUrl{}
+	case PluginSettingValidatorTypePathExists:
+		v = &PluginSettingValidatorPathExists{}
+	default:
+		return errors.New("unknown validator type: " + typeResult.String())
+	}
+
+	valueResult := gjson.GetBytes(b, "Value")
+	if valueResult.Exists() && valueResult.Type != gjson.Null {
+		unmarshalErr := json.Unmarshal([]byte(valueResult.Raw), v)
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+	}
+
+	p.Type = v.GetValidatorType()
+	p.Value = v
+	return nil
+}
+
+// Validate checks value against every validator and returns the first failure.
+// Empty value is only checked by not_empty, so optional settings can be left blank
+func Validate(validators []PluginSettingValidator, value string) error {
+	for _, v := range validators {
+		if v.Value == nil {
+			continue
+		}
+		if strings.TrimSpace(value) == "" && v.Type != PluginSettingValidatorTypeNotEmpty {
+			continue
+		}
+		if err := v.Value.Validate(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
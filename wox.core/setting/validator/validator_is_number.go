@@ -1,5 +1,11 @@
 package validator
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type PluginSettingValidatorIsNumber struct {
 	IsInteger bool
 	IsFloat   bool
@@ -8,3 +14,18 @@ type PluginSettingValidatorIsNumber struct {
 func (p *PluginSettingValidatorIsNumber) GetValidatorType() PluginSettingValidatorType {
 	return PluginSettingValidatorTypeIsNumber
 }
+
+func (p *PluginSettingValidatorIsNumber) Validate(value string) error {
+	value = strings.TrimSpace(value)
+	if p.IsInteger && !p.IsFloat {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%s is not an integer", value)
+		}
+		return nil
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return fmt.Errorf("%s is not a number", value)
+	}
+	return nil
+}
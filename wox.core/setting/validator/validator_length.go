@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type PluginSettingValidatorLength struct {
+	Min int
+	Max int // 0 means no upper limit
+}
+
+func (p *PluginSettingValidatorLength) GetValidatorType() PluginSettingValidatorType {
+	return PluginSettingValidatorTypeLength
+}
+
+func (p *PluginSettingValidatorLength) Validate(value string) error {
+	length := utf8.RuneCountInString(value)
+	if length < p.Min {
+		return fmt.Errorf("length must be at least %d", p.Min)
+	}
+	if p.Max > 0 && length > p.Max {
+		return fmt.Errorf("length must be at most %d", p.Max)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type PluginSettingValidatorMin struct {
+	Min float64
+}
+
+func (p *PluginSettingValidatorMin) GetValidatorType() PluginSettingValidatorType {
+	return PluginSettingValidatorTypeMin
+}
+
+func (p *PluginSettingValidatorMin) Validate(value string) error {
+	number, parseErr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if parseErr != nil {
+		return fmt.Errorf("%s is not a number", value)
+	}
+	if number < p.Min {
+		return fmt.Errorf("%s is less than %v", value, p.Min)
+	}
+	return nil
+}
+
+type PluginSettingValidatorMax struct {
+	Max float64
+}
+
+func (p *PluginSettingValidatorMax) GetValidatorType() PluginSettingValidatorType {
+	return PluginSettingValidatorTypeMax
+}
+
+func (p *PluginSettingValidatorMax) Validate(value string) error {
+	number, parseErr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if parseErr != nil {
+		return fmt.Errorf("%s is not a number", value)
+	}
+	if number > p.Max {
+		return fmt.Errorf("%s is greater than %v", value, p.Max)
+	}
+	return nil
+}
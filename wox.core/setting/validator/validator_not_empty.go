@@ -1,8 +1,20 @@
 package validator
 
+import (
+	"errors"
+	"strings"
+)
+
 type PluginSettingValidatorNotEmpty struct {
 }
 
 func (p *PluginSettingValidatorNotEmpty) GetValidatorType() PluginSettingValidatorType {
 	return PluginSettingValidatorTypeNotEmpty
 }
+
+func (p *PluginSettingValidatorNotEmpty) Validate(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("value can not be empty")
+	}
+	return nil
+}
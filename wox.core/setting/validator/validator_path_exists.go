@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+)
+
+type PluginSettingValidatorPathExists struct {
+	IsDirectory bool // if true, the path must be a directory
+}
+
+func (p *PluginSettingValidatorPathExists) GetValidatorType() PluginSettingValidatorType {
+	return PluginSettingValidatorTypePathExists
+}
+
+func (p *PluginSettingValidatorPathExists) Validate(value string) error {
+	stat, statErr := os.Stat(value)
+	if statErr != nil {
+		return fmt.Errorf("path %s doesn't exist", value)
+	}
+	if p.IsDirectory && !stat.IsDir() {
+		return fmt.Errorf("path %s is not a directory", value)
+	}
+	return nil
+}
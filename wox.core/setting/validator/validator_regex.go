@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type PluginSettingValidatorRegex struct {
+	Pattern string
+	Message string // optional message shown when value doesn't match, default message will be used if empty
+}
+
+func (p *PluginSettingValidatorRegex) GetValidatorType() PluginSettingValidatorType {
+	return PluginSettingValidatorTypeRegex
+}
+
+func (p *PluginSettingValidatorRegex) Validate(value string) error {
+	re, compileErr := regexp.Compile(p.Pattern)
+	if compileErr != nil {
+		return fmt.Errorf("invalid regex pattern %s: %w", p.Pattern, compileErr)
+	}
+	if !re.MatchString(value) {
+		if p.Message != "" {
+			return fmt.Errorf("%s", p.Message)
+		}
+		return fmt.Errorf("%s doesn't match pattern %s", value, p.Pattern)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type PluginSettingValidatorUrl struct {
+}
+
+func (p *PluginSettingValidatorUrl) GetValidatorType() PluginSettingValidatorType {
+	return PluginSettingValidatorTypeUrl
+}
+
+func (p *PluginSettingValidatorUrl) Validate(value string) error {
+	u, parseErr := url.Parse(strings.TrimSpace(value))
+	if parseErr != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%s is not a valid url", value)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package setting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/samber/lo"
+)
+
+type WorkflowStepType = string
+
+const (
+	WorkflowStepTypeQuery         WorkflowStepType = "query"          // run Value as query and wait for all results
+	WorkflowStepTypePickResult    WorkflowStepType = "pick_result"    // pick a result of last query whose title contains Value, or by Index if Value is empty
+	WorkflowStepTypeExecuteAction WorkflowStepType = "execute_action" // execute action named Value of picked result, default action if Value is empty
+	WorkflowStepTypeTransform     WorkflowStepType = "transform"      // apply Transform to Value
+	WorkflowStepTypePaste         WorkflowStepType = "paste"          // paste Value to active window
+	WorkflowStepTypeNotify        WorkflowStepType = "notify"         // show Value as notification
+)
+
+type WorkflowTransform = string
+
+const (
+	WorkflowTransformUpper     WorkflowTransform = "upper"
+	WorkflowTransformLower     WorkflowTransform = "lower"
+	WorkflowTransformTrim      WorkflowTransform = "trim"
+	WorkflowTransformUrlEncode WorkflowTransform = "url_encode"
+	WorkflowTransformReplace   WorkflowTransform = "replace" // replace matches of regex Pattern with Replacement
+)
+
+// WorkflowStep is one step of a query hotkey workflow.
+// Value supports query variables like {wox:clipboard} and outputs of previous steps: {$last} is the output of previous step,
+// {$name} is the output of the step whose Output is name
+type WorkflowStep struct {
+	Type        WorkflowStepType
+	Value       string
+	Index       int               // pick_result only, zero based index of the result to pick
+	Transform   WorkflowTransform // transform only
+	Pattern     string            // transform replace only
+	Replacement string            // transform replace only
+	Output      string            // optional, save output of this step as a variable
+}
+
+var workflowOutputNameRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+func (s WorkflowStep) Validate() error {
+	switch s.Type {
+	case WorkflowStepTypeQuery:
+		if s.Value == "" {
+			return fmt.Errorf("query step must have a query")
+		}
+	case WorkflowStepTypePickResult:
+		if s.Index < 0 {
+			return fmt.Errorf("result index can't be negative")
+		}
+	case WorkflowStepTypeExecuteAction, WorkflowStepTypePaste, WorkflowStepTypeNotify:
+	case WorkflowStepTypeTransform:
+		if !lo.Contains([]WorkflowTransform{WorkflowTransformUpper, WorkflowTransformLower, WorkflowTransformTrim, WorkflowTransformUrlEncode, WorkflowTransformReplace}, s.Transform) {
+			return fmt.Errorf("unknown transform: %s", s.Transform)
+		}
+		if s.Transform == WorkflowTransformReplace {
+			if _, compileErr := regexp.Compile(s.Pattern); compileErr != nil {
+				return fmt.Errorf("invalid replace pattern: %w", compileErr)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown step type: %s", s.Type)
+	}
+
+	if s.Output != "" && (!workflowOutputNameRegex.MatchString(s.Output) || s.Output == "last") {
+		return fmt.Errorf("invalid output name: %s", s.Output)
+	}
+	return nil
+}
+
+func validateQueryHotkeys(ctx context.Context, value string) error {
+	var queryHotkeys []QueryHotkey
+	if unmarshalErr := json.Unmarshal([]byte(value), &queryHotkeys); unmarshalErr != nil {
+		return unmarshalErr
+	}
+
+	for _, queryHotkey := range queryHotkeys {
+		for i, step := range queryHotkey.Workflow {
+			if stepErr := step.Validate(); stepErr != nil {
+				return fmt.Errorf("invalid workflow of hotkey %s, step %d: %w", queryHotkey.Hotkey, i+1, stepErr)
+			}
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"wox/share"
 	"wox/util"
@@ -10,9 +11,12 @@ import (
 type ResultHash string
 
 type WoxAppData struct {
+	SchemaVersion   int // see WoxAppDataSchema
 	QueryHistories  []QueryHistory
 	ActionedResults *util.HashMap[ResultHash, []ActionedResult]
 	FavoriteResults *util.HashMap[ResultHash, bool]
+
+	UnknownFields map[string]json.RawMessage `json:"-"` // keys in app data file unknown to this version, kept as is when saving
 }
 
 type QueryHistory struct {
@@ -30,6 +34,7 @@ func NewResultHash(pluginId string, title, subTitle string) ResultHash {
 
 func GetDefaultWoxAppData(ctx context.Context) WoxAppData {
 	return WoxAppData{
+		SchemaVersion:   WoxAppDataSchema.CurrentVersion(),
 		QueryHistories:  []QueryHistory{},
 		ActionedResults: util.NewHashMap[ResultHash, []ActionedResult](),
 		FavoriteResults: util.NewHashMap[ResultHash, bool](),
@@ -2,8 +2,8 @@ package setting
 
 import (
 	"context"
+	"encoding/json"
 	"os"
-	"regexp"
 	"runtime"
 	"strings"
 	"wox/i18n"
@@ -11,6 +11,7 @@ import (
 )
 
 type WoxSetting struct {
+	SchemaVersion        int // see WoxSettingSchema
 	EnableAutostart      PlatformSettingValue[bool]
 	MainHotkey           PlatformSettingValue[string]
 	SelectionHotkey      PlatformSettingValue[string]
@@ -24,10 +25,20 @@ type WoxSetting struct {
 	QueryShortcuts       []QueryShortcut
 	LastQueryMode        LastQueryMode
 	AIProviders          []AIProvider
+	PluginStoreSources   []PluginStoreSource
+	CustomPythonPath     PlatformSettingValue[string] // python interpreter to run python plugins, empty means auto discover
+	CustomNodejsPath     PlatformSettingValue[string] // nodejs interpreter to run nodejs plugins, empty means auto discover
+	Profiles             []Profile
+	ActiveProfileId      string                       // profile selected by user, empty means default settings. Profile rules may activate another profile temporarily
+	SyncType             SyncType                     // see SyncType, empty means sync is disabled
+	SyncPath             PlatformSettingValue[string] // folder or local git repository to sync settings with
+	BackupRetention      BackupRetention
 
 	// UI related
 	AppWidth int
 	ThemeId  string
+
+	UnknownFields map[string]json.RawMessage `json:"-"` // keys in setting file unknown to this version, kept as is when saving
 }
 
 type LastQueryMode = string
@@ -41,29 +52,24 @@ const (
 	DefaultThemeId = "e4006bd3-6bfe-4020-8d1c-4c32a8e567e5"
 )
 
-type QueryShortcut struct {
-	Shortcut string // support index placeholder, e.g. shortcut "wi" => "wpm install {0} to {1}", when user input "wi 1 2", the query will be "wpm install 1 to 2"
-	Query    string
-}
-
-func (q *QueryShortcut) HasPlaceholder() bool {
-	return strings.Contains(q.Query, "{0}")
-}
-
-func (q *QueryShortcut) PlaceholderCount() int {
-	return len(regexp.MustCompile(`(?m){\d}`).FindAllString(q.Query, -1))
-}
-
 type AIProvider struct {
 	Name   string // see ai.ProviderName
 	ApiKey string
 	Host   string
 }
 
+type PluginStoreSource struct {
+	Name     string
+	Url      string // http(s) url of a store manifest json, or a local manifest file/directory (file:// prefix is optional)
+	Priority int    // when multiple sources provide the same plugin, the source with higher priority wins
+	Disabled bool
+}
+
 type QueryHotkey struct {
 	Hotkey            string
-	Query             string // Support plugin.QueryVariable
-	IsSilentExecution bool   // If true, the query will be executed without showing the query in the input box
+	Query             string         // Support plugin.QueryVariable
+	IsSilentExecution bool           // If true, the query will be executed without showing the query in the input box
+	Workflow          []WorkflowStep `json:",omitempty"` // If not empty, steps are executed in order instead of running Query
 }
 
 func GetDefaultWoxSetting(ctx context.Context) WoxSetting {
@@ -77,6 +83,7 @@ func GetDefaultWoxSetting(ctx context.Context) WoxSetting {
 	}
 
 	return WoxSetting{
+		SchemaVersion: WoxSettingSchema.CurrentVersion(),
 		MainHotkey: PlatformSettingValue[string]{
 			WinValue:   "alt+space",
 			MacValue:   "command+space",
@@ -95,6 +102,7 @@ func GetDefaultWoxSetting(ctx context.Context) WoxSetting {
 		LastQueryMode:        LastQueryModeEmpty,
 		AppWidth:             800,
 		ThemeId:              DefaultThemeId,
+		BackupRetention:      defaultBackupRetention,
 		EnableAutostart: PlatformSettingValue[bool]{
 			WinValue:   false,
 			MacValue:   false,
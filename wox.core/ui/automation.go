@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"wox/plugin"
+	"wox/share"
+	"wox/ui/dto"
+	"wox/util"
+
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+)
+
+// routes for scripts and other tools, every request must have "Authorization: Bearer <token>" header,
+// token is stored in util.GetLocation().GetAutomationTokenPath(). See docs/automation.md
+var automationRouters = map[string]func(w http.ResponseWriter, r *http.Request){
+	"/api/v1/query":        handleAutomationQuery,
+	"/api/v1/query/change": handleAutomationChangeQuery,
+	"/api/v1/action":       handleAutomationAction,
+	"/api/v1/show":         handleAutomationShow,
+	"/api/v1/hide":         handleAutomationHide,
+	"/api/v1/toggle":       handleAutomationToggle,
+	"/api/v1/plugins":      handleAutomationPlugins,
+
+	// only available in headless mode
+	"/api/v1/headless/calls":     handleHeadlessCalls,
+	"/api/v1/headless/pickfiles": handleHeadlessPickFiles,
+}
+
+var automationToken string
+
+// GetAutomationToken reads token of automation api, a new token is created if not exist
+func GetAutomationToken(ctx context.Context) (string, error) {
+	tokenPath := util.GetLocation().GetAutomationTokenPath()
+	if util.IsFileExists(tokenPath) {
+		token, readErr := os.ReadFile(tokenPath)
+		if readErr != nil {
+			return "", readErr
+		}
+		if strings.TrimSpace(string(token)) != "" {
+			return strings.TrimSpace(string(token)), nil
+		}
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, randErr := rand.Read(tokenBytes); randErr != nil {
+		return "", randErr
+	}
+	token := hex.EncodeToString(tokenBytes)
+	if writeErr := os.WriteFile(tokenPath, []byte(token), 0600); writeErr != nil {
+		return "", writeErr
+	}
+
+	util.GetLogger().Info(ctx, fmt.Sprintf("automation token created: %s", tokenPath))
+	return token, nil
+}
+
+func withAutomationAuth(callback func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if automationToken == "" || !found || subtle.ConstantTimeCompare([]byte(token), []byte(automationToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			writeErrorResponse(w, "invalid automation token")
+			return
+		}
+
+		callback(w, r)
+	}
+}
+
+func handleAutomationQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+
+	body, _ := io.ReadAll(r.Body)
+	queryResult := gjson.GetBytes(body, "query")
+	if !queryResult.Exists() || queryResult.String() == "" {
+		writeErrorResponse(w, "query is empty")
+		return
+	}
+
+	query, _, queryErr := plugin.GetPluginManager().NewQuery(ctx, share.PlainQuery{
+		QueryType: plugin.QueryTypeInput,
+		QueryText: queryResult.String(),
+	})
+	if queryErr != nil {
+		writeErrorResponse(w, queryErr.Error())
+		return
+	}
+
+	results, waitErr := plugin.GetPluginManager().QueryAndWait(ctx, query)
+	if waitErr != nil {
+		writeErrorResponse(w, waitErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, lo.Map(results, func(result plugin.QueryResultUI, _ int) dto.AutomationResultDto {
+		return dto.AutomationResultDto{
+			Id:          result.Id,
+			Title:       result.Title,
+			SubTitle:    result.SubTitle,
+			Score:       result.Score,
+			Group:       result.Group,
+			ContextData: result.ContextData,
+			Actions: lo.Map(result.Actions, func(action plugin.QueryResultActionUI, _ int) dto.AutomationActionDto {
+				return dto.AutomationActionDto{
+					Id:        action.Id,
+					Name:      action.Name,
+					IsDefault: action.IsDefault,
+				}
+			}),
+		}
+	}))
+}
+
+func handleAutomationChangeQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+
+	body, _ := io.ReadAll(r.Body)
+	queryResult := gjson.GetBytes(body, "query")
+	if !queryResult.Exists() {
+		writeErrorResponse(w, "query is empty")
+		return
+	}
+
+	ui := GetUIManager().GetUI(ctx)
+	ui.ChangeQuery(ctx, share.PlainQuery{
+		QueryType: plugin.QueryTypeInput,
+		QueryText: queryResult.String(),
+	})
+	ui.ShowApp(ctx, share.ShowContext{SelectAll: false})
+	writeSuccessResponse(w, "")
+}
+
+func handleAutomationAction(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+
+	body, _ := io.ReadAll(r.Body)
+	resultId := gjson.GetBytes(body, "resultId")
+	if !resultId.Exists() {
+		writeErrorResponse(w, "resultId is empty")
+		return
+	}
+	actionId := gjson.GetBytes(body, "actionId")
+	if !actionId.Exists() {
+		writeErrorResponse(w, "actionId is empty")
+		return
+	}
+
+	executeErr := plugin.GetPluginManager().ExecuteAction(ctx, resultId.String(), actionId.String())
+	if executeErr != nil {
+		writeErrorResponse(w, executeErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, "")
+}
+
+func handleAutomationShow(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+	GetUIManager().GetUI(ctx).ShowApp(ctx, share.ShowContext{SelectAll: true})
+	writeSuccessResponse(w, "")
+}
+
+func handleAutomationHide(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+	GetUIManager().GetUI(ctx).HideApp(ctx)
+	writeSuccessResponse(w, "")
+}
+
+func handleAutomationToggle(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+	GetUIManager().GetUI(ctx).ToggleApp(ctx)
+	writeSuccessResponse(w, "")
+}
+
+func handleAutomationPlugins(w http.ResponseWriter, r *http.Request) {
+	writeSuccessResponse(w, lo.Map(plugin.GetPluginManager().GetPluginInstances(), func(instance *plugin.Instance, _ int) dto.AutomationPluginDto {
+		return dto.AutomationPluginDto{
+			Id:              instance.Metadata.Id,
+			Name:            instance.Metadata.Name,
+			Version:         instance.Metadata.Version,
+			Runtime:         string(instance.Metadata.Runtime),
+			Description:     instance.Metadata.Description,
+			TriggerKeywords: instance.GetTriggerKeywords(),
+			IsSystem:        instance.IsSystemPlugin,
+			IsDisable:       instance.IsDisabled(),
+		}
+	}))
+}
+
+func getHeadlessUI(w http.ResponseWriter) (*headlessUI, bool) {
+	ui, ok := GetUIManager().GetUI(util.NewTraceContext()).(*headlessUI)
+	if !ok {
+		writeErrorResponse(w, "wox is not running in headless mode")
+		return nil, false
+	}
+	return ui, true
+}
+
+// handleHeadlessCalls returns ui calls recorded in headless mode, add clear=true query parameter to clear them after returned
+func handleHeadlessCalls(w http.ResponseWriter, r *http.Request) {
+	ui, ok := getHeadlessUI(w)
+	if !ok {
+		return
+	}
+
+	writeSuccessResponse(w, ui.GetCalls(r.URL.Query().Get("clear") == "true"))
+}
+
+func handleHeadlessPickFiles(w http.ResponseWriter, r *http.Request) {
+	ui, ok := getHeadlessUI(w)
+	if !ok {
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	files := lo.Map(gjson.GetBytes(body, "files").Array(), func(file gjson.Result, _ int) string {
+		return file.String()
+	})
+	ui.SetPickFilesResult(files)
+	writeSuccessResponse(w, "")
+}
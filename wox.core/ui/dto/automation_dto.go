@@ -0,0 +1,28 @@
+package dto
+
+type AutomationResultDto struct {
+	Id          string // pass to /api/v1/action with action id to execute an action
+	Title       string
+	SubTitle    string
+	Score       int64
+	Group       string
+	ContextData string
+	Actions     []AutomationActionDto
+}
+
+type AutomationActionDto struct {
+	Id        string
+	Name      string
+	IsDefault bool
+}
+
+type AutomationPluginDto struct {
+	Id              string
+	Name            string
+	Version         string
+	Runtime         string
+	Description     string
+	TriggerKeywords []string
+	IsSystem        bool
+	IsDisable       bool
+}
@@ -21,11 +21,20 @@ type PluginDto struct {
 	TriggerKeywords    []string //User can add/update/delete trigger keywords
 	Commands           []plugin.MetadataCommand
 	SupportedOS        []string
+	Tags               []string
 	SettingDefinitions definition.PluginSettingDefinitions // only available when plugin is installed
 	Setting            setting.PluginSetting               // only available when plugin is installed
 	Features           []plugin.MetadataFeature            // only available when plugin is installed
 	IsSystem           bool
 	IsDev              bool
 	IsInstalled        bool
-	IsDisable          bool // only available when plugin is installed
+	IsDisable          bool   // only available when plugin is installed
+	SourceName         string // only available when plugin is from store
+	SourceUrl          string // only available when plugin is from store
+}
+
+type PluginStoreSearchDto struct {
+	Plugins []PluginDto
+	Total   int      // total count of matched plugins before pagination
+	Tags    []string // all tags in store, used as categories
 }
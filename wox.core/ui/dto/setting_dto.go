@@ -19,8 +19,22 @@ type WoxSettingDto struct {
 	QueryShortcuts       []setting.QueryShortcut
 	LastQueryMode        setting.LastQueryMode
 	AIProviders          []setting.AIProvider
+	PluginStoreSources   []setting.PluginStoreSource
+	CustomPythonPath     string
+	CustomNodejsPath     string
+	Profiles             []setting.Profile
+	ActiveProfileId      string // profile in effect, may be activated by profile rules
+	SyncType             setting.SyncType
+	SyncPath             string
 
 	// UI related
 	AppWidth int
 	ThemeId  string
 }
+
+type WoxSettingItemDto struct {
+	Key                string
+	Type               setting.WoxSettingValueType
+	IsPlatformSpecific bool
+	Value              string // same format as /setting/wox/update accepts, json string for lists
+}
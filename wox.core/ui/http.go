@@ -84,6 +84,22 @@ func serveAndWait(ctx context.Context, port int) {
 		})
 	}
 
+	token, tokenErr := GetAutomationToken(ctx)
+	if tokenErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to get automation token, automation api is disabled: %s", tokenErr.Error()))
+	}
+	automationToken = token
+	for path, callback := range automationRouters {
+		authCallback := withAutomationAuth(callback)
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			defer util.GoRecover(ctx, "automation request panic", func(err error) {
+				writeErrorResponse(w, err.Error())
+			})
+
+			authCallback(w, r)
+		})
+	}
+
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		m.HandleRequest(w, r)
 	})
@@ -232,6 +248,9 @@ func convertPluginDto(ctx context.Context, pluginDto dto.PluginDto, pluginInstan
 		for _, item := range pluginDto.SettingDefinitions {
 			if item.Value != nil {
 				settingValue := pluginInstance.API.GetSetting(ctx, item.Value.GetKey())
+				if item.IsSecretSetting() && settingValue != "" {
+					settingValue = definition.PluginSettingSecretMask
+				}
 				definitionSettings.Store(item.Value.GetKey(), settingValue)
 			}
 		}
@@ -18,6 +18,7 @@ import (
 	"wox/util/autostart"
 	"wox/util/hotkey"
 	"wox/util/ime"
+	"wox/util/notifier"
 	"wox/util/tray"
 
 	"github.com/Masterminds/semver/v3"
@@ -40,6 +41,7 @@ type Manager struct {
 	themes           *util.HashMap[string, share.Theme]
 	systemThemeIds   []string
 	isUIReadyHandled bool
+	isHeadless       bool // no flutter ui, tray and hotkeys, see EnableHeadless
 
 	activeWindowName string //active window name before wox is activated
 	activeWindowPid  int    //active window pid before wox is activated
@@ -59,6 +61,16 @@ func GetUIManager() *Manager {
 	return managerInstance
 }
 
+// EnableHeadless replaces the flutter ui with a recording ui, must be called before Start
+func (m *Manager) EnableHeadless() {
+	m.isHeadless = true
+	m.ui = newHeadlessUI()
+}
+
+func (m *Manager) IsHeadless() bool {
+	return m.isHeadless
+}
+
 func (m *Manager) Start(ctx context.Context) error {
 	//load embed themes
 	embedThemes := resource.GetEmbedThemes(ctx)
@@ -151,6 +163,9 @@ func (m *Manager) Start(ctx context.Context) error {
 		GetStoreManager().Start(util.NewTraceContext())
 	})
 
+	m.subscribeSettingChanges(ctx)
+	setting.GetSettingManager().OnSettingsImported(m.onSettingsImported)
+
 	return nil
 }
 
@@ -236,6 +251,19 @@ func (m *Manager) RegisterQueryHotkey(ctx context.Context, queryHotkey setting.Q
 
 	err := hk.Register(ctx, queryHotkey.Hotkey, func() {
 		newCtx := util.NewTraceContext()
+		if len(queryHotkey.Workflow) > 0 {
+			util.Go(newCtx, "run query hotkey workflow", func() {
+				workflowErr := plugin.GetPluginManager().RunWorkflow(newCtx, queryHotkey.Workflow)
+				if workflowErr != nil {
+					logger.Error(newCtx, fmt.Sprintf("failed to run workflow of hotkey %s: %s", queryHotkey.Hotkey, workflowErr.Error()))
+					notifier.Notify(fmt.Sprintf(i18n.GetI18nManager().TranslateWox(newCtx, "ui_workflow_failed"), workflowErr.Error()))
+				}
+			})
+			return
+		}
+
+		// query may be expanded by query shortcuts, which use variables taken before wox has focus
+		plugin.GetPluginManager().SnapshotQueryVariables(newCtx, queryHotkey.Query)
 		query := plugin.GetPluginManager().ReplaceQueryVariable(newCtx, queryHotkey.Query)
 		plainQuery := share.PlainQuery{
 			QueryType: plugin.QueryTypeInput,
@@ -414,6 +442,7 @@ func (m *Manager) PostOnShow(ctx context.Context) {
 
 func (m *Manager) PostOnHide(ctx context.Context, query share.PlainQuery) {
 	setting.GetSettingManager().AddQueryHistory(ctx, query)
+	plugin.GetPluginManager().ClearQueryVariableSnapshot()
 }
 
 func (m *Manager) IsSystemTheme(id string) bool {
@@ -460,21 +489,56 @@ func (m *Manager) HideTray() {
 	tray.RemoveTray()
 }
 
-func (m *Manager) PostSettingUpdate(ctx context.Context, key, value string) {
-	if key == "ShowTray" {
+// subscribeSettingChanges reloads subsystems after settings changed, either updated by user or switched by setting profile
+func (m *Manager) subscribeSettingChanges(ctx context.Context) {
+	settingManager := setting.GetSettingManager()
+	if !m.isHeadless {
+		m.subscribeDesktopSettingChanges(ctx)
+	}
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
+		runtime := plugin.PLUGIN_RUNTIME_PYTHON
+		if key == "CustomNodejsPath" {
+			runtime = plugin.PLUGIN_RUNTIME_NODEJS
+		}
+		util.Go(ctx, fmt.Sprintf("restart %s host after interpreter changed", runtime), func() {
+			restartErr := plugin.GetPluginManager().RestartHost(util.NewTraceContext(), runtime)
+			if restartErr != nil {
+				logger.Error(ctx, fmt.Sprintf("failed to restart %s host: %s", runtime, restartErr.Error()))
+			}
+		})
+	}, "CustomPythonPath", "CustomNodejsPath")
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
+		util.Go(ctx, "refresh store plugins after store sources changed", func() {
+			plugin.GetStoreManager().Refresh(util.NewTraceContext())
+		})
+	}, "PluginStoreSources")
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
+		theme := m.GetThemeById(value)
+		if ui, ok := m.GetUI(ctx).(*uiImpl); ok {
+			util.Go(ctx, "notify ui to change theme", func() {
+				ui.invokeWebsocketMethod(ctx, "ChangeTheme", theme)
+			})
+		}
+	}, "ThemeId")
+}
+
+// subscribeDesktopSettingChanges updates tray, hotkeys and autostart, they are not available in headless mode
+func (m *Manager) subscribeDesktopSettingChanges(ctx context.Context) {
+	settingManager := setting.GetSettingManager()
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
 		if value == "true" {
 			m.ShowTray()
 		} else {
 			m.HideTray()
 		}
-	}
-	if key == "MainHotkey" {
+	}, "ShowTray")
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
 		m.RegisterMainHotkey(ctx, value)
-	}
-	if key == "SelectionHotkey" {
+	}, "MainHotkey")
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
 		m.RegisterSelectionHotkey(ctx, value)
-	}
-	if key == "QueryHotkeys" {
+	}, "SelectionHotkey")
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
 		// unregister previous hotkeys
 		logger.Info(ctx, "post update query hotkeys, unregister previous query hotkeys")
 		for _, hk := range m.queryHotkeys {
@@ -486,13 +550,45 @@ func (m *Manager) PostSettingUpdate(ctx context.Context, key, value string) {
 		for _, queryHotkey := range queryHotkeys {
 			m.RegisterQueryHotkey(ctx, queryHotkey)
 		}
-	}
-	if key == "EnableAutostart" {
+	}, "QueryHotkeys")
+	settingManager.SubscribeWoxSettingChanged(func(ctx context.Context, key, value string) {
 		enabled := value == "true"
 		err := autostart.SetAutostart(ctx, enabled)
 		if err != nil {
 			logger.Error(ctx, fmt.Sprintf("failed to set autostart: %s", err.Error()))
 		}
+	}, "EnableAutostart")
+}
+
+// onSettingsImported reloads themes imported, synced or deleted by other machines
+func (m *Manager) onSettingsImported(ctx context.Context, event setting.SettingsImportedEvent) {
+	for _, themeId := range event.DeletedThemeIds {
+		isCurrentTheme := m.GetCurrentTheme(ctx).ThemeId == themeId
+		m.themes.Delete(themeId)
+		logger.Info(ctx, fmt.Sprintf("theme deleted by sync: %s", themeId))
+		if isCurrentTheme {
+			m.ChangeToDefaultTheme(ctx)
+		}
+	}
+
+	for _, themeId := range event.ThemeIds {
+		themePath := path.Join(util.GetLocation().GetThemeDirectory(), fmt.Sprintf("%s.json", themeId))
+		themeData, readThemeErr := os.ReadFile(themePath)
+		if readThemeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to read imported theme: %s, %s", themePath, readThemeErr.Error()))
+			continue
+		}
+
+		theme, themeErr := m.parseTheme(string(themeData))
+		if themeErr != nil {
+			logger.Error(ctx, fmt.Sprintf("failed to parse imported theme: %s, %s", themePath, themeErr.Error()))
+			continue
+		}
+		m.themes.Store(theme.ThemeId, theme)
+		logger.Info(ctx, fmt.Sprintf("theme imported: %s", theme.ThemeName))
+		if m.GetCurrentTheme(ctx).ThemeId == theme.ThemeId {
+			m.ChangeTheme(ctx, theme)
+		}
 	}
 }
 
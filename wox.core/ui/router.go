@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"wox/i18n"
 	"wox/plugin"
 	"wox/setting"
+	"wox/setting/definition"
 	"wox/share"
 	"wox/ui/dto"
 	"wox/util"
@@ -23,12 +25,13 @@ import (
 
 var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	// plugins
-	"/plugin/store":     handlePluginStore,
-	"/plugin/installed": handlePluginInstalled,
-	"/plugin/install":   handlePluginInstall,
-	"/plugin/uninstall": handlePluginUninstall,
-	"/plugin/disable":   handlePluginDisable,
-	"/plugin/enable":    handlePluginEnable,
+	"/plugin/store":        handlePluginStore,
+	"/plugin/store/search": handlePluginStoreSearch,
+	"/plugin/installed":    handlePluginInstalled,
+	"/plugin/install":      handlePluginInstall,
+	"/plugin/uninstall":    handlePluginUninstall,
+	"/plugin/disable":      handlePluginDisable,
+	"/plugin/enable":       handlePluginEnable,
 
 	//	themes
 	"/theme":           handleTheme,
@@ -40,7 +43,13 @@ var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	// settings
 	"/setting/wox":           handleSettingWox,
 	"/setting/wox/update":    handleSettingWoxUpdate,
+	"/setting/wox/get":       handleSettingWoxGet,
+	"/setting/wox/list":      handleSettingWoxList,
 	"/setting/plugin/update": handleSettingPluginUpdate,
+	"/setting/sections":      handleSettingSections,
+	"/setting/export":        handleSettingExport,
+	"/setting/import":        handleSettingImport,
+	"/setting/sync/now":      handleSettingSyncNow,
 
 	// events
 	"/on/focus/lost": handleOnFocusLost,
@@ -68,8 +77,11 @@ var routers = map[string]func(w http.ResponseWriter, r *http.Request){
 	"/backup/now":       handleBackupNow,
 	"/backup/restore":   handleBackupRestore,
 	"/backup/all":       handleBackupAll,
+	"/backup/verify":    handleBackupVerify,
+	"/backup/preview":   handleBackupPreview,
 	"/hotkey/available": handleHotkeyAvailable,
 	"/query/icon":       handleQueryIcon,
+	"/query/variables":  handleQueryVariables,
 	"/deeplink":         handleDeeplink,
 }
 
@@ -126,12 +138,62 @@ func handleTheme(w http.ResponseWriter, r *http.Request) {
 
 func handlePluginStore(w http.ResponseWriter, r *http.Request) {
 	getCtx := util.NewTraceContext()
-	manifests := plugin.GetStoreManager().GetStorePluginManifests(util.NewTraceContext())
+
+	body, _ := io.ReadAll(r.Body)
+	plugins, _, searchErr := searchStorePlugins(getCtx, parseStoreSearchOptions(body))
+	if searchErr != nil {
+		writeErrorResponse(w, searchErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, plugins)
+}
+
+func handlePluginStoreSearch(w http.ResponseWriter, r *http.Request) {
+	getCtx := util.NewTraceContext()
+
+	body, _ := io.ReadAll(r.Body)
+	plugins, total, searchErr := searchStorePlugins(getCtx, parseStoreSearchOptions(body))
+	if searchErr != nil {
+		writeErrorResponse(w, searchErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, dto.PluginStoreSearchDto{
+		Plugins: plugins,
+		Total:   total,
+		Tags:    plugin.GetStoreManager().GetTags(getCtx),
+	})
+}
+
+// searchStorePlugins searches store with the given filters and returns matched plugins and total count before paging
+func searchStorePlugins(ctx context.Context, options plugin.StoreSearchOptions) ([]dto.PluginDto, int, error) {
+	searchResult := plugin.GetStoreManager().Search(ctx, options)
+	plugins, convertErr := convertStorePluginDtos(ctx, searchResult.Manifests)
+	if convertErr != nil {
+		return nil, 0, convertErr
+	}
+
+	return plugins, searchResult.Total, nil
+}
+
+// parseStoreSearchOptions parses optional store filters from request body, all filters are optional
+func parseStoreSearchOptions(body []byte) plugin.StoreSearchOptions {
+	return plugin.StoreSearchOptions{
+		Keyword: gjson.GetBytes(body, "keyword").String(),
+		Tag:     gjson.GetBytes(body, "tag").String(),
+		OS:      gjson.GetBytes(body, "os").String(),
+		Runtime: plugin.ConvertToRuntime(gjson.GetBytes(body, "runtime").String()),
+		Offset:  int(gjson.GetBytes(body, "offset").Int()),
+		Limit:   int(gjson.GetBytes(body, "limit").Int()),
+	}
+}
+
+func convertStorePluginDtos(ctx context.Context, manifests []plugin.StorePluginManifest) ([]dto.PluginDto, error) {
 	var plugins = make([]dto.PluginDto, len(manifests))
 	copyErr := copier.Copy(&plugins, &manifests)
 	if copyErr != nil {
-		writeErrorResponse(w, copyErr.Error())
-		return
+		return nil, copyErr
 	}
 
 	for i, storePlugin := range plugins {
@@ -140,10 +202,10 @@ func handlePluginStore(w http.ResponseWriter, r *http.Request) {
 		})
 		plugins[i].Icon = plugin.NewWoxImageUrl(manifests[i].IconUrl)
 		plugins[i].IsInstalled = isInstalled
-		plugins[i] = convertPluginDto(getCtx, plugins[i], pluginInstance)
+		plugins[i] = convertPluginDto(ctx, plugins[i], pluginInstance)
 	}
 
-	writeSuccessResponse(w, plugins)
+	return plugins, nil
 }
 
 func handlePluginInstalled(w http.ResponseWriter, r *http.Request) {
@@ -162,7 +224,7 @@ func handlePluginInstalled(w http.ResponseWriter, r *http.Request) {
 		installedPlugin.IsSystem = pluginInstance.IsSystemPlugin
 		installedPlugin.IsDev = pluginInstance.IsDevPlugin
 		installedPlugin.IsInstalled = true
-		installedPlugin.IsDisable = pluginInstance.Setting.Disabled
+		installedPlugin.IsDisable = pluginInstance.IsDisabled()
 
 		//load screenshot urls from store if exist
 		storePlugin, foundErr := plugin.GetStoreManager().GetStorePluginManifestById(getCtx, pluginInstance.Metadata.Id)
@@ -279,8 +341,7 @@ func handlePluginDisable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	findPlugin.Setting.Disabled = true
-	err := findPlugin.SaveSetting(ctx)
+	err := findPlugin.SetDisabled(ctx, true)
 	if err != nil {
 		writeErrorResponse(w, "can't disable plugin: "+err.Error())
 		return
@@ -310,8 +371,7 @@ func handlePluginEnable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	findPlugin.Setting.Disabled = false
-	err := findPlugin.SaveSetting(ctx)
+	err := findPlugin.SetDisabled(ctx, false)
 	if err != nil {
 		writeErrorResponse(w, "can't enable plugin: "+err.Error())
 		return
@@ -443,6 +503,10 @@ func handleSettingWox(w http.ResponseWriter, r *http.Request) {
 	settingDto.MainHotkey = woxSetting.MainHotkey.Get()
 	settingDto.SelectionHotkey = woxSetting.SelectionHotkey.Get()
 	settingDto.QueryHotkeys = woxSetting.QueryHotkeys.Get()
+	settingDto.CustomPythonPath = woxSetting.CustomPythonPath.Get()
+	settingDto.CustomNodejsPath = woxSetting.CustomNodejsPath.Get()
+	settingDto.SyncPath = woxSetting.SyncPath.Get()
+	settingDto.ActiveProfileId = setting.GetSettingManager().GetActiveProfileId(util.NewTraceContext())
 
 	writeSuccessResponse(w, settingDto)
 }
@@ -467,11 +531,48 @@ func handleSettingWoxUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	GetUIManager().PostSettingUpdate(util.NewTraceContext(), kv.Key, kv.Value)
-
 	writeSuccessResponse(w, "")
 }
 
+func handleSettingWoxGet(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	keyResult := gjson.GetBytes(body, "key")
+	if !keyResult.Exists() {
+		writeErrorResponse(w, "key is empty")
+		return
+	}
+
+	value, getErr := setting.GetSettingManager().GetWoxSettingValue(util.NewTraceContext(), keyResult.String())
+	if getErr != nil {
+		writeErrorResponse(w, getErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, value)
+}
+
+func handleSettingWoxList(w http.ResponseWriter, r *http.Request) {
+	ctx := util.NewTraceContext()
+
+	var items []dto.WoxSettingItemDto
+	for _, definition := range setting.GetWoxSettingDefinitions() {
+		value, getErr := setting.GetSettingManager().GetWoxSettingValue(ctx, definition.Key)
+		if getErr != nil {
+			writeErrorResponse(w, getErr.Error())
+			return
+		}
+
+		items = append(items, dto.WoxSettingItemDto{
+			Key:                definition.Key,
+			Type:               definition.Type,
+			IsPlatformSpecific: definition.IsPlatformSpecific,
+			Value:              value,
+		})
+	}
+
+	writeSuccessResponse(w, items)
+}
+
 func handleSettingPluginUpdate(w http.ResponseWriter, r *http.Request) {
 	ctx := util.NewTraceContext()
 
@@ -501,20 +602,66 @@ func handleSettingPluginUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if kv.Key == "Disabled" {
-		pluginInstance.Setting.Disabled = kv.Value == "true"
-		pluginInstance.SaveSetting(ctx)
+		if disableErr := pluginInstance.SetDisabled(ctx, kv.Value == "true"); disableErr != nil {
+			writeErrorResponse(w, disableErr.Error())
+			return
+		}
 	} else if kv.Key == "TriggerKeywords" {
 		pluginInstance.Setting.TriggerKeywords = strings.Split(kv.Value, ",")
-		pluginInstance.SaveSetting(ctx)
+		if saveErr := pluginInstance.SaveSetting(ctx); saveErr != nil {
+			writeErrorResponse(w, saveErr.Error())
+			return
+		}
+	} else if kv.Key == "IsolatedHost" {
+		pluginInstance.Setting.IsolatedHost = kv.Value == "true"
+		if saveErr := pluginInstance.SaveSetting(ctx); saveErr != nil {
+			writeErrorResponse(w, saveErr.Error())
+			return
+		}
+
+		// reload plugin to move it between shared host and dedicated host
+		reloadErr := plugin.GetPluginManager().ReloadPlugin(ctx, plugin.MetadataWithDirectory{
+			Metadata:           pluginInstance.Metadata,
+			Directory:          pluginInstance.PluginDirectory,
+			IsDev:              pluginInstance.IsDevPlugin,
+			DevPluginDirectory: pluginInstance.DevPluginDirectory,
+		})
+		if reloadErr != nil {
+			writeErrorResponse(w, reloadErr.Error())
+			return
+		}
 	} else {
 		var isPlatformSpecific = false
-		for _, settingDefinition := range pluginInstance.Metadata.SettingDefinitions {
-			if settingDefinition.Value != nil && settingDefinition.Value.GetKey() == kv.Key {
-				isPlatformSpecific = settingDefinition.IsPlatformSpecific
-				break
+		settingDefinition, found := pluginInstance.Metadata.SettingDefinitions.GetDefinition(kv.Key)
+		if found {
+			isPlatformSpecific = settingDefinition.IsPlatformSpecific
+
+			// UI only has the mask of a saved secret, nothing changed
+			if settingDefinition.IsSecretSetting() && kv.Value == definition.PluginSettingSecretMask {
+				writeSuccessResponse(w, "")
+				return
+			}
+		}
+
+		validateErr := pluginInstance.Metadata.SettingDefinitions.Validate(kv.Key, kv.Value, func(key string) string {
+			return pluginInstance.API.GetSetting(ctx, key)
+		})
+		if validateErr != nil {
+			writeErrorResponse(w, validateErr.Error())
+			return
+		}
+		if found && settingDefinition.Type == definition.PluginSettingDefinitionTypeHotkey && kv.Value != "" && kv.Value != pluginInstance.API.GetSetting(ctx, kv.Key) {
+			if !hotkey.IsHotkeyAvailable(ctx, kv.Value) {
+				writeErrorResponse(w, "hotkey is not available")
+				return
 			}
 		}
-		pluginInstance.API.SaveSetting(util.NewTraceContext(), kv.Key, kv.Value, isPlatformSpecific)
+
+		saveErr := pluginInstance.API.SaveSetting(ctx, kv.Key, kv.Value, isPlatformSpecific)
+		if saveErr != nil {
+			writeErrorResponse(w, saveErr.Error())
+			return
+		}
 	}
 
 	writeSuccessResponse(w, "")
@@ -533,6 +680,69 @@ func handleOpenUrl(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, "")
 }
 
+func handleSettingSections(w http.ResponseWriter, r *http.Request) {
+	writeSuccessResponse(w, setting.GetSettingSections())
+}
+
+func handleSettingExport(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	pathResult := gjson.GetBytes(body, "path")
+	if !pathResult.Exists() {
+		writeErrorResponse(w, "path is empty")
+		return
+	}
+
+	sections := setting.GetSettingSections()
+	if sectionsResult := gjson.GetBytes(body, "sections"); sectionsResult.Exists() {
+		sections = lo.Map(sectionsResult.Array(), func(item gjson.Result, _ int) string {
+			return item.String()
+		})
+	}
+
+	exportErr := setting.GetSettingManager().ExportSettings(util.NewTraceContext(), pathResult.String(), sections)
+	if exportErr != nil {
+		writeErrorResponse(w, exportErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, "")
+}
+
+func handleSettingImport(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	pathResult := gjson.GetBytes(body, "path")
+	if !pathResult.Exists() {
+		writeErrorResponse(w, "path is empty")
+		return
+	}
+
+	// empty sections means all sections in archive
+	var sections []string
+	if sectionsResult := gjson.GetBytes(body, "sections"); sectionsResult.Exists() {
+		sections = lo.Map(sectionsResult.Array(), func(item gjson.Result, _ int) string {
+			return item.String()
+		})
+	}
+
+	importErr := setting.GetSettingManager().ImportSettings(util.NewTraceContext(), pathResult.String(), sections)
+	if importErr != nil {
+		writeErrorResponse(w, importErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, "")
+}
+
+func handleSettingSyncNow(w http.ResponseWriter, r *http.Request) {
+	syncErr := setting.GetSettingManager().SyncNow(util.NewTraceContext())
+	if syncErr != nil {
+		writeErrorResponse(w, syncErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, "")
+}
+
 func handleBackupNow(w http.ResponseWriter, r *http.Request) {
 	backupErr := setting.GetSettingManager().Backup(util.NewTraceContext(), setting.BackupTypeManual)
 	if backupErr != nil {
@@ -561,6 +771,41 @@ func handleBackupRestore(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, "")
 }
 
+func handleBackupVerify(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	idResult := gjson.GetBytes(body, "id")
+	if !idResult.Exists() {
+		writeErrorResponse(w, "id is empty")
+		return
+	}
+
+	verifyErr := setting.GetSettingManager().VerifyBackup(util.NewTraceContext(), idResult.String())
+	if verifyErr != nil {
+		writeErrorResponse(w, verifyErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, "")
+}
+
+// handleBackupPreview returns files which would be changed by restoring the backup
+func handleBackupPreview(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	idResult := gjson.GetBytes(body, "id")
+	if !idResult.Exists() {
+		writeErrorResponse(w, "id is empty")
+		return
+	}
+
+	changes, previewErr := setting.GetSettingManager().PreviewRestore(util.NewTraceContext(), idResult.String())
+	if previewErr != nil {
+		writeErrorResponse(w, previewErr.Error())
+		return
+	}
+
+	writeSuccessResponse(w, changes)
+}
+
 func handleBackupAll(w http.ResponseWriter, r *http.Request) {
 	backups, err := setting.GetSettingManager().FindAllBackups(util.NewTraceContext())
 	if err != nil {
@@ -665,6 +910,10 @@ func handleOnHide(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, "")
 }
 
+func handleQueryVariables(w http.ResponseWriter, r *http.Request) {
+	writeSuccessResponse(w, plugin.GetPluginManager().GetQueryVariables())
+}
+
 func handleQueryIcon(w http.ResponseWriter, r *http.Request) {
 	ctx := util.NewTraceContext()
 
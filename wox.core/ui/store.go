@@ -8,6 +8,7 @@ import (
 	"path"
 	"sync"
 	"time"
+	"wox/setting"
 	"wox/share"
 	"wox/util"
 
@@ -116,6 +117,7 @@ func (s *Store) Install(ctx context.Context, theme share.Theme) error {
 	}
 
 	GetUIManager().AddTheme(ctx, theme)
+	setting.GetSettingManager().RequestSync(ctx)
 
 	return nil
 }
@@ -135,6 +137,7 @@ func (s *Store) Uninstall(ctx context.Context, theme share.Theme) error {
 	}
 
 	GetUIManager().RemoveTheme(ctx, theme)
+	setting.GetSettingManager().RequestSync(ctx)
 
 	return nil
 }
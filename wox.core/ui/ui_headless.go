@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"wox/share"
+	"wox/util"
+)
+
+// HeadlessUICall is a ui method invoked in headless mode
+type HeadlessUICall struct {
+	Method    string
+	Data      any
+	Timestamp int64
+}
+
+// headlessUI implements share.UI without flutter ui, every call is recorded so plugin tests can check what was shown to user.
+// See /api/v1/headless routes
+type headlessUI struct {
+	lock            sync.Mutex
+	calls           []HeadlessUICall
+	pickFilesResult []string
+}
+
+func newHeadlessUI() *headlessUI {
+	return &headlessUI{}
+}
+
+func (u *headlessUI) record(ctx context.Context, method string, data any) {
+	logger.Info(ctx, fmt.Sprintf("[headless ui] %s: %v", method, data))
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.calls = append(u.calls, HeadlessUICall{
+		Method:    method,
+		Data:      data,
+		Timestamp: util.GetSystemTimestamp(),
+	})
+}
+
+// GetCalls returns recorded calls, calls are cleared if clear is true
+func (u *headlessUI) GetCalls(clear bool) []HeadlessUICall {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	calls := append([]HeadlessUICall{}, u.calls...)
+	if clear {
+		u.calls = nil
+	}
+	return calls
+}
+
+// SetPickFilesResult sets files returned by PickFiles, since there is no user to pick them
+func (u *headlessUI) SetPickFilesResult(files []string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.pickFilesResult = files
+}
+
+func (u *headlessUI) ChangeQuery(ctx context.Context, query share.PlainQuery) {
+	u.record(ctx, "ChangeQuery", query)
+}
+
+func (u *headlessUI) HideApp(ctx context.Context) {
+	u.record(ctx, "HideApp", nil)
+}
+
+func (u *headlessUI) ShowApp(ctx context.Context, showContext share.ShowContext) {
+	u.record(ctx, "ShowApp", showContext)
+}
+
+func (u *headlessUI) ToggleApp(ctx context.Context) {
+	u.record(ctx, "ToggleApp", nil)
+}
+
+func (u *headlessUI) OpenSettingWindow(ctx context.Context, windowContext share.SettingWindowContext) {
+	u.record(ctx, "OpenSettingWindow", windowContext)
+}
+
+func (u *headlessUI) PickFiles(ctx context.Context, params share.PickFilesParams) []string {
+	u.record(ctx, "PickFiles", params)
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.pickFilesResult
+}
+
+func (u *headlessUI) GetActiveWindowName() string {
+	return ""
+}
+
+func (u *headlessUI) GetActiveWindowPid() int {
+	return 0
+}
+
+func (u *headlessUI) GetServerPort(ctx context.Context) int {
+	return GetUIManager().serverPort
+}
+
+func (u *headlessUI) GetAllThemes(ctx context.Context) []share.Theme {
+	return GetUIManager().GetAllThemes(ctx)
+}
+
+func (u *headlessUI) ChangeTheme(ctx context.Context, theme share.Theme) {
+	u.record(ctx, "ChangeTheme", theme.ThemeId)
+}
+
+func (u *headlessUI) InstallTheme(ctx context.Context, theme share.Theme) {
+	u.record(ctx, "InstallTheme", theme.ThemeId)
+}
+
+func (u *headlessUI) UninstallTheme(ctx context.Context, theme share.Theme) {
+	u.record(ctx, "UninstallTheme", theme.ThemeId)
+}
+
+func (u *headlessUI) RestoreTheme(ctx context.Context) {
+	u.record(ctx, "RestoreTheme", nil)
+}
+
+func (u *headlessUI) Notify(ctx context.Context, msg share.NotifyMsg) {
+	u.record(ctx, "Notify", msg)
+}
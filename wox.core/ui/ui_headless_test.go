@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wox/share"
+	"wox/util"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func enableTestHeadlessUI(t *testing.T) *headlessUI {
+	m := GetUIManager()
+	previousUI, previousIsHeadless := m.ui, m.isHeadless
+	m.EnableHeadless()
+	t.Cleanup(func() {
+		m.ui, m.isHeadless = previousUI, previousIsHeadless
+	})
+
+	previousToken := automationToken
+	automationToken = "test-token"
+	t.Cleanup(func() {
+		automationToken = previousToken
+	})
+
+	return m.GetUI(util.NewTraceContext()).(*headlessUI)
+}
+
+func callAutomationRoute(t *testing.T, method string, path string, body string) RestResponse {
+	route, _, _ := strings.Cut(path, "?")
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer test-token")
+	recorder := httptest.NewRecorder()
+	withAutomationAuth(automationRouters[route])(recorder, request)
+
+	var response RestResponse
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return response
+}
+
+func TestHeadlessUI(t *testing.T) {
+	ctx := util.NewTraceContext()
+	ui := enableTestHeadlessUI(t)
+
+	// files to pick are given by test, since there is no user to pick them
+	assert.True(t, callAutomationRoute(t, http.MethodPost, "/api/v1/headless/pickfiles", `{"files": ["/tmp/a.txt", "/tmp/b.txt"]}`).Success)
+	assert.Equal(t, []string{"/tmp/a.txt", "/tmp/b.txt"}, ui.PickFiles(ctx, share.PickFilesParams{IsDirectory: false}))
+
+	ui.ChangeQuery(ctx, share.PlainQuery{QueryType: "input", QueryText: "wpm install"})
+	ui.Notify(ctx, share.NotifyMsg{Text: "installed"})
+
+	response := callAutomationRoute(t, http.MethodGet, "/api/v1/headless/calls?clear=true", "")
+	assert.True(t, response.Success)
+	callsJson, _ := json.Marshal(response.Data)
+	var calls []HeadlessUICall
+	assert.Nil(t, json.Unmarshal(callsJson, &calls))
+	assert.Equal(t, []string{"PickFiles", "ChangeQuery", "Notify"}, lo.Map(calls, func(call HeadlessUICall, _ int) string {
+		return call.Method
+	}))
+	assert.Equal(t, "wpm install", calls[1].Data.(map[string]any)["QueryText"])
+	assert.Equal(t, "installed", calls[2].Data.(map[string]any)["Text"])
+
+	// calls are cleared after fetched with clear=true
+	assert.Empty(t, ui.GetCalls(false))
+}
+
+func TestHeadlessRoutesRequireToken(t *testing.T) {
+	enableTestHeadlessUI(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/headless/calls", nil)
+	request.Header.Set("Authorization", "Bearer wrong-token")
+	recorder := httptest.NewRecorder()
+	withAutomationAuth(automationRouters["/api/v1/headless/calls"])(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -33,12 +33,14 @@ func (u *uiImpl) HideApp(ctx context.Context) {
 func (u *uiImpl) ShowApp(ctx context.Context, showContext share.ShowContext) {
 	GetUIManager().SetActiveWindowName(window.GetActiveWindowName())
 	GetUIManager().SetActiveWindowPid(window.GetActiveWindowPid())
+	plugin.GetPluginManager().SnapshotQueryVariables(ctx, "")
 	u.invokeWebsocketMethod(ctx, "ShowApp", getShowAppParams(ctx, showContext.SelectAll))
 }
 
 func (u *uiImpl) ToggleApp(ctx context.Context) {
 	GetUIManager().SetActiveWindowName(window.GetActiveWindowName())
 	GetUIManager().SetActiveWindowPid(window.GetActiveWindowPid())
+	plugin.GetPluginManager().SnapshotQueryVariables(ctx, "")
 	u.invokeWebsocketMethod(ctx, "ToggleApp", getShowAppParams(ctx, true))
 }
 
@@ -48,10 +50,11 @@ func (u *uiImpl) GetServerPort(ctx context.Context) int {
 
 func (u *uiImpl) ChangeTheme(ctx context.Context, theme share.Theme) {
 	logger.Info(ctx, fmt.Sprintf("change theme: %s", theme.ThemeName))
-	woxSetting := setting.GetSettingManager().GetWoxSetting(ctx)
-	woxSetting.ThemeId = theme.ThemeId
-	setting.GetSettingManager().SaveWoxSetting(ctx)
-	u.invokeWebsocketMethod(ctx, "ChangeTheme", theme)
+	// ui is notified to change theme by ThemeId change subscriber
+	updateErr := setting.GetSettingManager().UpdateWoxSetting(ctx, "ThemeId", theme.ThemeId)
+	if updateErr != nil {
+		logger.Error(ctx, fmt.Sprintf("failed to save theme: %s", updateErr.Error()))
+	}
 }
 
 func (u *uiImpl) InstallTheme(ctx context.Context, theme share.Theme) {
@@ -233,7 +236,12 @@ func onUIWebsocketResponse(ctx context.Context, response WebsocketMsg) {
 		return
 	}
 
-	resultChan, exist := GetUIManager().GetUI(ctx).(*uiImpl).requestMap.Load(requestID)
+	ui, ok := GetUIManager().GetUI(ctx).(*uiImpl)
+	if !ok {
+		logger.Error(ctx, fmt.Sprintf("got ui response in headless mode: %s", requestID))
+		return
+	}
+	resultChan, exist := ui.requestMap.Load(requestID)
 	if !exist {
 		logger.Error(ctx, fmt.Sprintf("response id not found: %s", requestID))
 		return
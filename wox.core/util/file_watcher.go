@@ -7,17 +7,24 @@ import (
 )
 
 func WatchDirectoryChanges(ctx context.Context, directory string, callback func(event fsnotify.Event)) (*fsnotify.Watcher, error) {
+	return WatchDirectoriesChanges(ctx, []string{directory}, callback)
+}
+
+// WatchDirectoriesChanges watches multiple directories with a single watcher, sub directories are not watched automatically
+func WatchDirectoriesChanges(ctx context.Context, directories []string, callback func(event fsnotify.Event)) (*fsnotify.Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		GetLogger().Error(ctx, fmt.Sprintf("failed to create directory watcher: %s", err.Error()))
 		return nil, err
 	}
 
-	err = watcher.Add(directory)
-	if err != nil {
-		GetLogger().Error(ctx, fmt.Sprintf("failed to add directory to watcher: %s", err.Error()))
-		watcher.Close()
-		return nil, err
+	for _, directory := range directories {
+		err = watcher.Add(directory)
+		if err != nil {
+			GetLogger().Error(ctx, fmt.Sprintf("failed to add directory to watcher: %s", err.Error()))
+			watcher.Close()
+			return nil, err
+		}
 	}
 
 	Go(ctx, "watch directory change", func() {
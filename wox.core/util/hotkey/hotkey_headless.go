@@ -0,0 +1,26 @@
+//go:build headless
+
+package hotkey
+
+import (
+	"context"
+	"errors"
+)
+
+// headless builds don't link the hotkey library, it opens the X11 display when loaded and panics on servers without a display
+
+var errHotkeyNotSupported = errors.New("hotkeys are not supported in headless build")
+
+type Hotkey struct {
+}
+
+func (h *Hotkey) Register(ctx context.Context, combineKey string, callback func()) error {
+	return errHotkeyNotSupported
+}
+
+func (h *Hotkey) Unregister(ctx context.Context) {
+}
+
+func IsHotkeyAvailable(ctx context.Context, hotkeyStr string) (isAvailable bool) {
+	return false
+}
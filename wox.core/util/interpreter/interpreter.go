@@ -0,0 +1,198 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"wox/util"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/mitchellh/go-homedir"
+)
+
+type Type string
+
+const (
+	TypePython Type = "python"
+	TypeNodejs Type = "nodejs"
+)
+
+// where the interpreter is found
+type Source = string
+
+const (
+	SourceSetting Source = "setting"
+	SourcePath    Source = "PATH"
+	SourcePyenv   Source = "pyenv"
+	SourceAsdf    Source = "asdf"
+	SourceConda   Source = "conda"
+	SourceNvm     Source = "nvm"
+	SourceVolta   Source = "volta"
+	SourceNix     Source = "nix"
+	SourceSystem  Source = "system"
+)
+
+var minVersions = map[Type]string{
+	TypePython: "3.10.0",
+	TypeNodejs: "16.0.0",
+}
+
+type Candidate struct {
+	Path    string
+	Source  Source
+	Version string // empty if failed to get version
+	Error   string // why this candidate is not usable
+}
+
+type DiscoveryResult struct {
+	Type       Type
+	Path       string // empty if no usable interpreter is found
+	Version    string
+	Source     Source
+	MinVersion string
+	Candidates []Candidate // all probed interpreters, for diagnosis
+}
+
+func (d DiscoveryResult) IsFound() bool {
+	return d.Path != ""
+}
+
+// Discover finds the interpreter to run plugin hosts.
+// User defined path takes precedence if it's usable, otherwise the first interpreter satisfying minimum version is used,
+// candidates are probed in order of PATH, version managers (pyenv/asdf/conda/nvm/volta/nix) and common install locations,
+// so the interpreter user chose by PATH or shims wins over newer ones installed elsewhere.
+func Discover(ctx context.Context, interpreterType Type, customPath string) DiscoveryResult {
+	result := DiscoveryResult{
+		Type:       interpreterType,
+		MinVersion: minVersions[interpreterType],
+	}
+	minVersion, _ := semver.NewVersion(result.MinVersion)
+
+	for _, candidate := range getCandidates(interpreterType, customPath) {
+		version, versionErr := getVersion(interpreterType, candidate.Path)
+		if versionErr != nil {
+			candidate.Error = versionErr.Error()
+			result.Candidates = append(result.Candidates, candidate)
+			continue
+		}
+		candidate.Version = version.String()
+		if version.LessThan(minVersion) {
+			candidate.Error = fmt.Sprintf("version is lower than %s", result.MinVersion)
+			result.Candidates = append(result.Candidates, candidate)
+			continue
+		}
+		result.Candidates = append(result.Candidates, candidate)
+		util.GetLogger().Debug(ctx, fmt.Sprintf("found %s interpreter: %s, version: %s, source: %s", interpreterType, candidate.Path, candidate.Version, candidate.Source))
+
+		// user defined interpreter is always the first candidate, remaining candidates are still probed for diagnosis
+		if !result.IsFound() {
+			result.Path = candidate.Path
+			result.Version = candidate.Version
+			result.Source = candidate.Source
+		}
+	}
+
+	if result.IsFound() {
+		util.GetLogger().Info(ctx, fmt.Sprintf("use %s interpreter: %s, version: %s, source: %s", interpreterType, result.Path, result.Version, result.Source))
+	} else {
+		util.GetLogger().Warn(ctx, fmt.Sprintf("no usable %s interpreter found, probed %d candidates", interpreterType, len(result.Candidates)))
+	}
+	return result
+}
+
+func getCandidates(interpreterType Type, customPath string) []Candidate {
+	var candidates []Candidate
+	add := func(path string, source Source) {
+		if path == "" {
+			return
+		}
+		for _, c := range candidates {
+			if c.Path == path {
+				return
+			}
+		}
+		if source != SourceSetting && !util.IsFileExists(path) {
+			return
+		}
+		candidates = append(candidates, Candidate{Path: path, Source: source})
+	}
+	addGlob := func(pattern string, source Source) {
+		pattern, _ = homedir.Expand(pattern)
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			add(match, source)
+		}
+	}
+	expand := func(path string) string {
+		expanded, _ := homedir.Expand(path)
+		return expanded
+	}
+
+	if customPath != "" {
+		add(expand(customPath), SourceSetting)
+	}
+
+	if interpreterType == TypePython {
+		for _, name := range []string{"python3", "python"} {
+			if p, err := exec.LookPath(name); err == nil {
+				add(p, SourcePath)
+			}
+		}
+		add(expand("~/.pyenv/shims/python3"), SourcePyenv)
+		addGlob("~/.pyenv/versions/*/bin/python3", SourcePyenv)
+		add(expand("~/.asdf/shims/python3"), SourceAsdf)
+		addGlob("~/.asdf/installs/python/*/bin/python3", SourceAsdf)
+		for _, conda := range []string{"miniconda3", "anaconda3", "miniforge3", "mambaforge"} {
+			add(expand(fmt.Sprintf("~/%s/bin/python3", conda)), SourceConda)
+		}
+		add(expand("~/.nix-profile/bin/python3"), SourceNix)
+		add("/run/current-system/sw/bin/python3", SourceNix)
+		for _, p := range []string{"/opt/homebrew/bin/python3", "/usr/local/bin/python3", "/usr/bin/python3", "/usr/local/python3"} {
+			add(p, SourceSystem)
+		}
+		if util.IsWindows() {
+			addGlob(filepath.Join(os.Getenv("LOCALAPPDATA"), "Programs", "Python", "Python3*", "python.exe"), SourceSystem)
+		}
+	}
+
+	if interpreterType == TypeNodejs {
+		if p, err := exec.LookPath("node"); err == nil {
+			add(p, SourcePath)
+		}
+		addGlob("~/.nvm/versions/node/*/bin/node", SourceNvm)
+		add(expand("~/.asdf/shims/node"), SourceAsdf)
+		addGlob("~/.asdf/installs/nodejs/*/bin/node", SourceAsdf)
+		add(expand("~/.volta/bin/node"), SourceVolta)
+		add(expand("~/.nix-profile/bin/node"), SourceNix)
+		add("/run/current-system/sw/bin/node", SourceNix)
+		for _, p := range []string{"/opt/homebrew/bin/node", "/usr/local/bin/node", "/usr/bin/node", "/usr/local/node"} {
+			add(p, SourceSystem)
+		}
+		if util.IsWindows() {
+			add(filepath.Join(os.Getenv("ProgramFiles"), "nodejs", "node.exe"), SourceSystem)
+		}
+	}
+
+	return candidates
+}
+
+func getVersion(interpreterType Type, path string) (*semver.Version, error) {
+	output, runErr := util.ShellRunOutput(path, "--version")
+	if runErr != nil {
+		return nil, fmt.Errorf("failed to get version: %w", runErr)
+	}
+
+	// python outputs "Python 3.9.0", nodejs outputs "v20.1.0"
+	version := strings.TrimSpace(string(output))
+	version = strings.TrimPrefix(version, "Python ")
+	version = strings.TrimPrefix(version, "v")
+	parsed, parseErr := semver.NewVersion(version)
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to parse version %s: %w", version, parseErr)
+	}
+
+	return parsed, nil
+}
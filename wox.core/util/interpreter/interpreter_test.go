@@ -0,0 +1,54 @@
+package interpreter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"wox/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiscoverCustomPath(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("shell script is not available on windows")
+	}
+
+	ctx := util.NewTraceContext()
+	directory := t.TempDir()
+
+	newPython := filepath.Join(directory, "python-new")
+	assert.Nil(t, os.WriteFile(newPython, []byte("#!/bin/sh\necho 'Python 3.99.0'\n"), 0755))
+	result := Discover(ctx, TypePython, newPython)
+	assert.True(t, result.IsFound())
+	assert.Equal(t, newPython, result.Path)
+	assert.Equal(t, SourceSetting, result.Source)
+	assert.Equal(t, "3.99.0", result.Version)
+
+	// custom interpreter which doesn't satisfy minimum version is skipped
+	oldPython := filepath.Join(directory, "python-old")
+	assert.Nil(t, os.WriteFile(oldPython, []byte("#!/bin/sh\necho 'Python 2.7.18'\n"), 0755))
+	result = Discover(ctx, TypePython, oldPython)
+	assert.NotEqual(t, oldPython, result.Path)
+	assert.NotEmpty(t, result.Candidates[0].Error)
+}
+
+func Test_DiscoverPrefersPathOrder(t *testing.T) {
+	if util.IsWindows() {
+		t.Skip("shell script is not available on windows")
+	}
+
+	ctx := util.NewTraceContext()
+	firstDirectory := t.TempDir()
+	secondDirectory := t.TempDir()
+	t.Setenv("PATH", firstDirectory+string(os.PathListSeparator)+secondDirectory)
+
+	// first usable interpreter in PATH wins even if a newer one exists
+	pathPython := filepath.Join(firstDirectory, "python3")
+	assert.Nil(t, os.WriteFile(pathPython, []byte("#!/bin/sh\necho 'Python 3.11.0'\n"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(secondDirectory, "python"), []byte("#!/bin/sh\necho 'Python 3.99.0'\n"), 0755))
+	result := Discover(ctx, TypePython, "")
+	assert.Equal(t, pathPython, result.Path)
+	assert.Equal(t, SourcePath, result.Source)
+	assert.Equal(t, "3.11.0", result.Version)
+}
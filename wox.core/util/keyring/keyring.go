@@ -0,0 +1,51 @@
+package keyring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"wox/util"
+
+	"github.com/zalando/go-keyring"
+)
+
+// all wox secrets are stored under this service name in OS keyring
+// (Keychain on macOS, Credential Manager on Windows, Secret Service on Linux)
+const serviceName = "Wox"
+
+var ErrNotFound = errors.New("secret not found in keyring")
+
+func Set(ctx context.Context, key string, value string) error {
+	setErr := keyring.Set(serviceName, key, value)
+	if setErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to save %s to keyring: %s", key, setErr.Error()))
+		return setErr
+	}
+	return nil
+}
+
+func Get(ctx context.Context, key string) (string, error) {
+	value, getErr := keyring.Get(serviceName, key)
+	if getErr != nil {
+		if errors.Is(getErr, keyring.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to get %s from keyring: %s", key, getErr.Error()))
+		return "", getErr
+	}
+	return value, nil
+}
+
+func Delete(ctx context.Context, key string) error {
+	deleteErr := keyring.Delete(serviceName, key)
+	if deleteErr != nil && !errors.Is(deleteErr, keyring.ErrNotFound) {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to delete %s from keyring: %s", key, deleteErr.Error()))
+		return deleteErr
+	}
+	return nil
+}
+
+// MockInit replaces OS keyring with an in-memory one, used in tests
+func MockInit() {
+	keyring.MockInit()
+}
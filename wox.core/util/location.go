@@ -194,6 +194,29 @@ func (l *Location) GetUIAppPath() string {
 	return ""
 }
 
+// GetSecretPath returns the encrypted secret file, it's outside of user data directory so backups never include it
+func (l *Location) GetSecretPath() string {
+	return path.Join(l.woxDataDirectory, "secrets.enc")
+}
+
+func (l *Location) GetSecretKeyPath() string {
+	return path.Join(l.woxDataDirectory, ".secret.key")
+}
+
+// GetSyncStatePath returns the local sync state, it's machine specific so it's never synced
+func (l *Location) GetSyncStatePath() string {
+	return path.Join(l.woxDataDirectory, "sync_state.json")
+}
+
+func (l *Location) GetSyncConflictLogPath() string {
+	return path.Join(l.woxDataDirectory, "sync_conflicts.log")
+}
+
+// GetAutomationTokenPath returns the token file of automation api, only readable by current user
+func (l *Location) GetAutomationTokenPath() string {
+	return path.Join(l.woxDataDirectory, ".automation.token")
+}
+
 func (l *Location) GetAppLockPath() string {
 	return path.Join(l.GetWoxDataDirectory(), "wox.lock")
 }
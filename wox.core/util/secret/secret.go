@@ -0,0 +1,100 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"wox/util"
+	"wox/util/keyring"
+)
+
+// PassphraseEnvName is the env variable of user passphrase. If set, secrets are stored in a file encrypted with
+// a key derived from the passphrase instead of OS keyring
+const PassphraseEnvName = "WOX_SECRET_PASSPHRASE"
+
+var ErrNotFound = errors.New("secret not found")
+
+type Store interface {
+	Name() string
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string) error
+	Delete(ctx context.Context, key string) error
+}
+
+var managerInstance *Manager
+var managerOnce sync.Once
+
+// Manager stores secrets such as api keys and plugin tokens outside of setting files.
+// It uses OS keyring when available, otherwise an encrypted file in wox data directory
+type Manager struct {
+	store Store
+}
+
+func GetSecretManager() *Manager {
+	managerOnce.Do(func() {
+		managerInstance = &Manager{
+			store: newStore(util.NewTraceContext()),
+		}
+	})
+	return managerInstance
+}
+
+func newStore(ctx context.Context) Store {
+	passphrase := os.Getenv(PassphraseEnvName)
+	if passphrase != "" {
+		util.GetLogger().Info(ctx, "secret passphrase is set, use encrypted file to store secrets")
+		return newFileStore(util.GetLocation().GetSecretPath(), util.GetLocation().GetSecretKeyPath(), passphrase)
+	}
+
+	// keyring is available if it can answer a query, E.g. secret service may be missing on linux
+	_, probeErr := keyring.Get(ctx, "probe")
+	if probeErr == nil || errors.Is(probeErr, keyring.ErrNotFound) {
+		return &keyringStore{}
+	}
+
+	util.GetLogger().Warn(ctx, fmt.Sprintf("OS keyring is not available, use encrypted file to store secrets: %s", probeErr.Error()))
+	return newFileStore(util.GetLocation().GetSecretPath(), util.GetLocation().GetSecretKeyPath(), "")
+}
+
+func (m *Manager) StoreName() string {
+	return m.store.Name()
+}
+
+// Get returns ErrNotFound if the secret doesn't exist
+func (m *Manager) Get(ctx context.Context, key string) (string, error) {
+	return m.store.Get(ctx, key)
+}
+
+func (m *Manager) Set(ctx context.Context, key string, value string) error {
+	return m.store.Set(ctx, key, value)
+}
+
+// Delete does nothing if the secret doesn't exist
+func (m *Manager) Delete(ctx context.Context, key string) error {
+	return m.store.Delete(ctx, key)
+}
+
+type keyringStore struct {
+}
+
+func (k *keyringStore) Name() string {
+	return "keyring"
+}
+
+func (k *keyringStore) Get(ctx context.Context, key string) (string, error) {
+	value, getErr := keyring.Get(ctx, key)
+	if errors.Is(getErr, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	return value, getErr
+}
+
+func (k *keyringStore) Set(ctx context.Context, key string, value string) error {
+	return keyring.Set(ctx, key, value)
+}
+
+func (k *keyringStore) Delete(ctx context.Context, key string) error {
+	return keyring.Delete(ctx, key)
+}
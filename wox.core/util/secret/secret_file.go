@@ -0,0 +1,243 @@
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"wox/util"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keySourcePassphrase = "passphrase"
+	keySourceMachine    = "machine"
+)
+
+type encryptedFile struct {
+	Version   int
+	KeySource string // passphrase or machine
+	Salt      []byte
+	Nonce     []byte
+	Data      []byte // AES-256-GCM encrypted json of map[string]string
+}
+
+// fileStore keeps all secrets in one file encrypted with a key derived from user passphrase.
+// Without passphrase, a random machine secret generated on first use is used instead, it's never backed up
+type fileStore struct {
+	path       string
+	keyPath    string // path of machine secret
+	passphrase string
+
+	lock    sync.Mutex
+	secrets map[string]string // decrypted secrets, nil if not loaded yet
+	salt    []byte
+	key     []byte
+}
+
+func newFileStore(path string, keyPath string, passphrase string) *fileStore {
+	return &fileStore{
+		path:       path,
+		keyPath:    keyPath,
+		passphrase: passphrase,
+	}
+}
+
+func (f *fileStore) Name() string {
+	return "file"
+}
+
+func (f *fileStore) Get(ctx context.Context, key string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if loadErr := f.load(ctx); loadErr != nil {
+		return "", loadErr
+	}
+
+	value, exist := f.secrets[key]
+	if !exist {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *fileStore) Set(ctx context.Context, key string, value string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if loadErr := f.load(ctx); loadErr != nil {
+		return loadErr
+	}
+
+	f.secrets[key] = value
+	return f.save(ctx)
+}
+
+func (f *fileStore) Delete(ctx context.Context, key string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if loadErr := f.load(ctx); loadErr != nil {
+		return loadErr
+	}
+	if _, exist := f.secrets[key]; !exist {
+		return nil
+	}
+
+	delete(f.secrets, key)
+	return f.save(ctx)
+}
+
+func (f *fileStore) keySource() string {
+	if f.passphrase != "" {
+		return keySourcePassphrase
+	}
+	return keySourceMachine
+}
+
+func (f *fileStore) load(ctx context.Context) error {
+	if f.secrets != nil {
+		return nil
+	}
+
+	content, readErr := os.ReadFile(f.path)
+	if errors.Is(readErr, os.ErrNotExist) {
+		salt := make([]byte, 16)
+		if _, randErr := rand.Read(salt); randErr != nil {
+			return randErr
+		}
+		key, keyErr := f.deriveKey(salt)
+		if keyErr != nil {
+			return keyErr
+		}
+
+		f.salt = salt
+		f.key = key
+		f.secrets = map[string]string{}
+		return nil
+	}
+	if readErr != nil {
+		return readErr
+	}
+
+	var file encryptedFile
+	if unmarshalErr := json.Unmarshal(content, &file); unmarshalErr != nil {
+		return fmt.Errorf("failed to parse secret file: %w", unmarshalErr)
+	}
+	if file.KeySource != f.keySource() {
+		return fmt.Errorf("secret file is encrypted with %s key, but %s key is provided", file.KeySource, f.keySource())
+	}
+
+	key, keyErr := f.deriveKey(file.Salt)
+	if keyErr != nil {
+		return keyErr
+	}
+	gcm, gcmErr := newGCM(key)
+	if gcmErr != nil {
+		return gcmErr
+	}
+	plain, openErr := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if openErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to decrypt secret file: %s", openErr.Error()))
+		return errors.New("failed to decrypt secret file, passphrase may be wrong")
+	}
+
+	var secrets map[string]string
+	if unmarshalErr := json.Unmarshal(plain, &secrets); unmarshalErr != nil {
+		return fmt.Errorf("failed to parse secrets: %w", unmarshalErr)
+	}
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	f.salt = file.Salt
+	f.key = key
+	f.secrets = secrets
+	return nil
+}
+
+func (f *fileStore) save(ctx context.Context) error {
+	plain, marshalErr := json.Marshal(f.secrets)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	gcm, gcmErr := newGCM(f.key)
+	if gcmErr != nil {
+		return gcmErr
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, randErr := rand.Read(nonce); randErr != nil {
+		return randErr
+	}
+
+	content, marshalErr := json.Marshal(encryptedFile{
+		Version:   1,
+		KeySource: f.keySource(),
+		Salt:      f.salt,
+		Nonce:     nonce,
+		Data:      gcm.Seal(nil, nonce, plain, nil),
+	})
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	// write to temp file first, so a crash won't leave a broken secret file
+	tempPath := f.path + ".tmp"
+	if writeErr := os.WriteFile(tempPath, content, 0600); writeErr != nil {
+		util.GetLogger().Error(ctx, fmt.Sprintf("failed to write secret file: %s", writeErr.Error()))
+		return writeErr
+	}
+	return os.Rename(tempPath, f.path)
+}
+
+func (f *fileStore) deriveKey(salt []byte) ([]byte, error) {
+	material := []byte(f.passphrase)
+	if f.passphrase == "" {
+		machineSecret, secretErr := f.getMachineSecret()
+		if secretErr != nil {
+			return nil, secretErr
+		}
+		material = machineSecret
+	}
+
+	return scrypt.Key(material, salt, 1<<15, 8, 1, 32)
+}
+
+// getMachineSecret returns random bytes generated on first use, only readable by current user
+func (f *fileStore) getMachineSecret() ([]byte, error) {
+	machineSecret, readErr := os.ReadFile(f.keyPath)
+	if readErr == nil && len(machineSecret) == 32 {
+		return machineSecret, nil
+	}
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return nil, readErr
+	}
+	if _, statErr := os.Stat(f.path); statErr == nil {
+		return nil, errors.New("machine secret is missing, secret file can't be decrypted")
+	}
+
+	machineSecret = make([]byte, 32)
+	if _, randErr := rand.Read(machineSecret); randErr != nil {
+		return nil, randErr
+	}
+	if writeErr := os.WriteFile(f.keyPath, machineSecret, 0600); writeErr != nil {
+		return nil, writeErr
+	}
+	return machineSecret, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, blockErr := aes.NewCipher(key)
+	if blockErr != nil {
+		return nil, blockErr
+	}
+	return cipher.NewGCM(block)
+}
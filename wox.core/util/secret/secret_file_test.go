@@ -0,0 +1,64 @@
+package secret
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	secretPath := path.Join(dir, "secrets.enc")
+	keyPath := path.Join(dir, ".secret.key")
+
+	store := newFileStore(secretPath, keyPath, "")
+	_, getErr := store.Get(ctx, "token")
+	assert.ErrorIs(t, getErr, ErrNotFound)
+
+	assert.Nil(t, store.Set(ctx, "token", "my-secret-token"))
+	content, readErr := os.ReadFile(secretPath)
+	assert.Nil(t, readErr)
+	assert.False(t, strings.Contains(string(content), "my-secret-token"))
+
+	// a new store reads the same secret with machine secret
+	value, getErr := newFileStore(secretPath, keyPath, "").Get(ctx, "token")
+	assert.Nil(t, getErr)
+	assert.Equal(t, "my-secret-token", value)
+
+	// file encrypted with machine secret can't be read with passphrase
+	_, getErr = newFileStore(secretPath, keyPath, "passphrase").Get(ctx, "token")
+	assert.NotNil(t, getErr)
+
+	assert.Nil(t, store.Delete(ctx, "token"))
+	_, getErr = newFileStore(secretPath, keyPath, "").Get(ctx, "token")
+	assert.ErrorIs(t, getErr, ErrNotFound)
+}
+
+func Test_FileStorePassphrase(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	secretPath := path.Join(dir, "secrets.enc")
+	keyPath := path.Join(dir, ".secret.key")
+
+	assert.Nil(t, newFileStore(secretPath, keyPath, "correct").Set(ctx, "token", "value"))
+	_, statErr := os.Stat(keyPath)
+	assert.True(t, os.IsNotExist(statErr))
+
+	value, getErr := newFileStore(secretPath, keyPath, "correct").Get(ctx, "token")
+	assert.Nil(t, getErr)
+	assert.Equal(t, "value", value)
+
+	wrongStore := newFileStore(secretPath, keyPath, "wrong")
+	_, getErr = wrongStore.Get(ctx, "token")
+	assert.NotNil(t, getErr)
+	assert.NotNil(t, wrongStore.Set(ctx, "token", "overwrite"))
+
+	value, getErr = newFileStore(secretPath, keyPath, "correct").Get(ctx, "token")
+	assert.Nil(t, getErr)
+	assert.Equal(t, "value", value)
+}
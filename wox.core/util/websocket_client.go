@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,9 +15,12 @@ type WebsocketClient struct {
 	conn                 *websocket.Conn
 	cancelReceiveMsgChan chan bool
 	onReceiveMsg         func(data []byte)
+	onReceiveBinaryMsg   func(data []byte)
 	reconnectCount       int
-	isConnected          bool
+	isConnected          atomic.Bool
+	isClosed             atomic.Bool // closed by caller, don't reconnect anymore
 	mu                   sync.RWMutex
+	connectLock          sync.Mutex // receiving goroutine reconnects while caller may close the client at the same time
 }
 
 func NewWebsocketClient(url string) *WebsocketClient {
@@ -24,54 +28,70 @@ func NewWebsocketClient(url string) *WebsocketClient {
 }
 
 func (w *WebsocketClient) Connect(ctx context.Context) error {
+	w.connectLock.Lock()
+	defer w.connectLock.Unlock()
+
 	w.disconnect(ctx)
+	if w.isClosed.Load() {
+		return fmt.Errorf("websocket client is closed")
+	}
 
 	conn, _, dialErr := websocket.DefaultDialer.Dial(w.url, nil)
 	if dialErr != nil {
 		return dialErr
 	}
+	w.mu.Lock()
 	w.conn = conn
-	w.cancelReceiveMsgChan = make(chan bool)
-	w.isConnected = true
+	w.mu.Unlock()
+	cancelChan := make(chan bool)
+	w.cancelReceiveMsgChan = cancelChan
+	w.isConnected.Store(true)
 
+	// goroutines keep their own connection and cancel channel, fields are replaced by the next Connect
 	Go(ctx, "receive websocket msg", func() {
-		w.receiveMsg(ctx)
+		w.receiveMsg(ctx, conn, cancelChan)
 	})
 
 	Go(ctx, "ping websocket server", func() {
-		w.ping(ctx)
+		w.ping(ctx, cancelChan)
 	})
 
 	return nil
 }
 
 func (w *WebsocketClient) IsConnected() bool {
-	return w.isConnected
+	return w.isConnected.Load()
 }
 
-func (w *WebsocketClient) ping(ctx context.Context) {
+func (w *WebsocketClient) ping(ctx context.Context, cancelChan chan bool) {
 	for {
 		select {
 		case <-time.NewTicker(time.Second).C:
-			if w.conn != nil && w.isConnected {
+			if w.isConnected.Load() {
 				w.sendMsg(ctx, websocket.PingMessage, []byte{})
 			}
-		case <-w.cancelReceiveMsgChan:
+		case <-cancelChan:
 			GetLogger().Info(ctx, "disconnect signal received, stop pinging")
 			return
 		}
 	}
 }
 
-func (w *WebsocketClient) receiveMsg(ctx context.Context) {
+func (w *WebsocketClient) receiveMsg(ctx context.Context, conn *websocket.Conn, cancelChan chan bool) {
 	for {
 		select {
-		case <-w.cancelReceiveMsgChan:
+		case <-cancelChan:
 			GetLogger().Info(ctx, "disconnect signal received, stop receiving message")
 			return
 		default:
-			messageType, messageData, err := w.conn.ReadMessage()
+			messageType, messageData, err := conn.ReadMessage()
 			if err != nil {
+				select {
+				case <-cancelChan:
+					// connection was closed by disconnect, not by server
+					return
+				default:
+				}
 				w.reconnect(ctx, fmt.Sprintf("failed to read message from websocket server (%s)", err.Error()))
 				return
 			}
@@ -81,11 +101,21 @@ func (w *WebsocketClient) receiveMsg(ctx context.Context) {
 					w.onReceiveMsg(messageData)
 				}
 			}
+			if messageType == websocket.BinaryMessage {
+				if w.onReceiveBinaryMsg != nil {
+					w.onReceiveBinaryMsg(messageData)
+				}
+			}
 		}
 	}
 }
 
 func (w *WebsocketClient) reconnect(ctx context.Context, reason string) {
+	if w.isClosed.Load() {
+		GetLogger().Info(ctx, fmt.Sprintf("%s, websocket client is closed, skip reconnecting", reason))
+		return
+	}
+
 	GetLogger().Info(ctx, fmt.Sprintf("%s, try reconnecting", reason))
 	connErr := w.Connect(ctx)
 	if connErr != nil {
@@ -104,6 +134,15 @@ func (w *WebsocketClient) reconnect(ctx context.Context, reason string) {
 	}
 }
 
+// Close disconnects from server and stops reconnecting, the client can't be used anymore
+func (w *WebsocketClient) Close(ctx context.Context) {
+	w.connectLock.Lock()
+	defer w.connectLock.Unlock()
+
+	w.isClosed.Store(true)
+	w.disconnect(ctx)
+}
+
 func (w *WebsocketClient) close(ctx context.Context) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -118,15 +157,23 @@ func (w *WebsocketClient) Send(ctx context.Context, data []byte) error {
 	return w.sendMsg(ctx, websocket.TextMessage, data)
 }
 
+func (w *WebsocketClient) SendBinary(ctx context.Context, data []byte) error {
+	return w.sendMsg(ctx, websocket.BinaryMessage, data)
+}
+
 func (w *WebsocketClient) sendMsg(ctx context.Context, msgType int, data []byte) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.conn == nil {
+		return fmt.Errorf("websocket is not connected")
+	}
 	return w.conn.WriteMessage(msgType, data)
 }
 
+// disconnect must be called with connectLock held
 func (w *WebsocketClient) disconnect(ctx context.Context) {
-	if w.cancelReceiveMsgChan == nil && w.conn == nil && !w.isConnected {
+	if w.cancelReceiveMsgChan == nil && w.conn == nil && !w.isConnected.Load() {
 		return
 	}
 
@@ -144,9 +191,13 @@ func (w *WebsocketClient) disconnect(ctx context.Context) {
 
 	w.close(ctx)
 
-	w.isConnected = false
+	w.isConnected.Store(false)
 }
 
 func (w *WebsocketClient) OnMessage(ctx context.Context, callback func(data []byte)) {
 	w.onReceiveMsg = callback
 }
+
+func (w *WebsocketClient) OnBinaryMessage(ctx context.Context, callback func(data []byte)) {
+	w.onReceiveBinaryMsg = callback
+}
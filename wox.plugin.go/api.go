@@ -0,0 +1,187 @@
+package wox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type LogLevel = string
+
+const (
+	LogLevelInfo    LogLevel = "Info"
+	LogLevelError   LogLevel = "Error"
+	LogLevelDebug   LogLevel = "Debug"
+	LogLevelWarning LogLevel = "Warning"
+)
+
+// API exposed to go plugins, it mirrors plugin.API in wox.core.
+// Every call is a request to Wox, failures are written to the plugin log.
+type API interface {
+	ChangeQuery(ctx context.Context, query PlainQuery)
+	HideApp(ctx context.Context)
+	ShowApp(ctx context.Context)
+	Notify(ctx context.Context, description string)
+	Log(ctx context.Context, level LogLevel, msg string)
+	GetTranslation(ctx context.Context, key string) string
+	GetSetting(ctx context.Context, key string) string
+	SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool)
+	OnSettingChanged(ctx context.Context, callback func(key string, value string))
+	OnGetDynamicSetting(ctx context.Context, callback func(key string) string)
+	OnDeepLink(ctx context.Context, callback func(arguments map[string]string))
+	OnUnload(ctx context.Context, callback func())
+	RegisterQueryCommands(ctx context.Context, commands []MetadataCommand)
+	PushResults(ctx context.Context, queryId string, results []QueryResult) error
+	RegisterQueryVariable(ctx context.Context, name string, description string, resolver func(ctx context.Context, arg string) (string, error)) error
+}
+
+type apiImpl struct {
+	host *host
+
+	settingChangeCallbacks  map[string]func(key string, value string)
+	dynamicSettingCallbacks map[string]func(key string) string
+	deepLinkCallbacks       map[string]func(arguments map[string]string)
+	unloadCallbacks         map[string]func()
+	queryVariableCallbacks  map[string]func(ctx context.Context, arg string) (string, error)
+}
+
+func newAPI(h *host) *apiImpl {
+	return &apiImpl{
+		host:                    h,
+		settingChangeCallbacks:  map[string]func(key string, value string){},
+		dynamicSettingCallbacks: map[string]func(key string) string{},
+		deepLinkCallbacks:       map[string]func(arguments map[string]string){},
+		unloadCallbacks:         map[string]func(){},
+		queryVariableCallbacks:  map[string]func(ctx context.Context, arg string) (string, error){},
+	}
+}
+
+func (a *apiImpl) invoke(ctx context.Context, method string, params map[string]string) any {
+	result, err := a.host.invokeMethod(ctx, method, params)
+	if err != nil {
+		a.host.log(ctx, "error", fmt.Sprintf("failed to invoke %s: %s", method, err))
+		return nil
+	}
+	return result
+}
+
+func (a *apiImpl) ChangeQuery(ctx context.Context, query PlainQuery) {
+	selectionJson, marshalErr := json.Marshal(query.QuerySelection)
+	if marshalErr != nil {
+		a.host.log(ctx, "error", fmt.Sprintf("failed to marshal query selection: %s", marshalErr))
+		return
+	}
+
+	a.invoke(ctx, "ChangeQuery", map[string]string{
+		"queryType":      query.QueryType,
+		"queryText":      query.QueryText,
+		"querySelection": string(selectionJson),
+	})
+}
+
+func (a *apiImpl) HideApp(ctx context.Context) {
+	a.invoke(ctx, "HideApp", map[string]string{})
+}
+
+func (a *apiImpl) ShowApp(ctx context.Context) {
+	a.invoke(ctx, "ShowApp", map[string]string{})
+}
+
+func (a *apiImpl) Notify(ctx context.Context, description string) {
+	a.invoke(ctx, "Notify", map[string]string{"message": description})
+}
+
+func (a *apiImpl) Log(ctx context.Context, level LogLevel, msg string) {
+	a.invoke(ctx, "Log", map[string]string{"level": level, "msg": msg})
+}
+
+func (a *apiImpl) GetTranslation(ctx context.Context, key string) string {
+	result, _ := a.invoke(ctx, "GetTranslation", map[string]string{"key": key}).(string)
+	return result
+}
+
+func (a *apiImpl) GetSetting(ctx context.Context, key string) string {
+	result, _ := a.invoke(ctx, "GetSetting", map[string]string{"key": key}).(string)
+	return result
+}
+
+func (a *apiImpl) SaveSetting(ctx context.Context, key string, value string, isPlatformSpecific bool) {
+	a.invoke(ctx, "SaveSetting", map[string]string{
+		"key":                key,
+		"value":              value,
+		"isPlatformSpecific": fmt.Sprintf("%t", isPlatformSpecific),
+	})
+}
+
+func (a *apiImpl) OnSettingChanged(ctx context.Context, callback func(key string, value string)) {
+	callbackId := uuid.NewString()
+	a.host.lock.Lock()
+	a.settingChangeCallbacks[callbackId] = callback
+	a.host.lock.Unlock()
+	a.invoke(ctx, "OnPluginSettingChanged", map[string]string{"callbackId": callbackId})
+}
+
+func (a *apiImpl) OnGetDynamicSetting(ctx context.Context, callback func(key string) string) {
+	callbackId := uuid.NewString()
+	a.host.lock.Lock()
+	a.dynamicSettingCallbacks[callbackId] = callback
+	a.host.lock.Unlock()
+	a.invoke(ctx, "OnGetDynamicSetting", map[string]string{"callbackId": callbackId})
+}
+
+func (a *apiImpl) OnDeepLink(ctx context.Context, callback func(arguments map[string]string)) {
+	callbackId := uuid.NewString()
+	a.host.lock.Lock()
+	a.deepLinkCallbacks[callbackId] = callback
+	a.host.lock.Unlock()
+	a.invoke(ctx, "OnDeepLink", map[string]string{"callbackId": callbackId})
+}
+
+func (a *apiImpl) OnUnload(ctx context.Context, callback func()) {
+	callbackId := uuid.NewString()
+	a.host.lock.Lock()
+	a.unloadCallbacks[callbackId] = callback
+	a.host.lock.Unlock()
+	a.invoke(ctx, "OnUnload", map[string]string{"callbackId": callbackId})
+}
+
+func (a *apiImpl) RegisterQueryCommands(ctx context.Context, commands []MetadataCommand) {
+	commandsJson, marshalErr := json.Marshal(commands)
+	if marshalErr != nil {
+		a.host.log(ctx, "error", fmt.Sprintf("failed to marshal query commands: %s", marshalErr))
+		return
+	}
+
+	a.invoke(ctx, "RegisterQueryCommands", map[string]string{"commands": string(commandsJson)})
+}
+
+// PushResults pushes a batch of results to a running query, it fails once Query has returned
+func (a *apiImpl) PushResults(ctx context.Context, queryId string, results []QueryResult) error {
+	resultsJson, marshalErr := json.Marshal(a.host.prepareResults(results))
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal results: %w", marshalErr)
+	}
+
+	_, invokeErr := a.host.invokeMethod(ctx, "PushResults", map[string]string{
+		"queryId": queryId,
+		"results": string(resultsJson),
+	})
+	return invokeErr
+}
+
+// RegisterQueryVariable adds a {wox:<name>} variable, resolver gets the text after "{wox:<name>:" as arg
+func (a *apiImpl) RegisterQueryVariable(ctx context.Context, name string, description string, resolver func(ctx context.Context, arg string) (string, error)) error {
+	callbackId := uuid.NewString()
+	a.host.lock.Lock()
+	a.queryVariableCallbacks[callbackId] = resolver
+	a.host.lock.Unlock()
+
+	_, invokeErr := a.host.invokeMethod(ctx, "RegisterQueryVariable", map[string]string{
+		"callbackId":  callbackId,
+		"name":        name,
+		"description": description,
+	})
+	return invokeErr
+}
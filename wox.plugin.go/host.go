@@ -0,0 +1,446 @@
+package wox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// see wox.core/plugin/host/jsonrpc.go
+const (
+	jsonRpcTypeRequest   = "WOX_JSONRPC_REQUEST"
+	jsonRpcTypeResponse  = "WOX_JSONRPC_RESPONSE"
+	jsonRpcTypeSystemLog = "WOX_JSONRPC_SYSTEM_LOG"
+
+	protocolVersion = 2
+	encodingJson    = "json"
+
+	invokeTimeout = time.Second * 30 // max time to wait for Wox to answer an API request
+)
+
+// jsonRpcMessage is the envelope of all messages exchanged with Wox, fields are filled according to Type
+type jsonRpcMessage struct {
+	TraceId    string
+	Id         string
+	PluginId   string
+	PluginName string
+	Method     string
+	Type       string
+	Params     map[string]string
+	Result     any
+	Error      string
+	Level      string
+	Message    string
+}
+
+type traceIdKey struct{}
+
+func newTraceContext(traceId string) context.Context {
+	if traceId == "" {
+		traceId = uuid.NewString()
+	}
+	return context.WithValue(context.Background(), traceIdKey{}, traceId)
+}
+
+func getTraceId(ctx context.Context) string {
+	if traceId, ok := ctx.Value(traceIdKey{}).(string); ok {
+		return traceId
+	}
+	return uuid.NewString()
+}
+
+type host struct {
+	plugin     Plugin
+	logger     *log.Logger
+	conn       *websocket.Conn
+	writeLock  sync.Mutex
+	pluginId   string
+	pluginName string
+	api        *apiImpl
+
+	lock      sync.Mutex
+	actions   map[string]func(ctx context.Context, actionContext ActionContext)
+	refreshes map[string]func(ctx context.Context, current RefreshableResult) RefreshableResult
+	responses map[string]chan jsonRpcMessage
+}
+
+func newHost(p Plugin, logger *log.Logger) *host {
+	h := &host{
+		plugin:    p,
+		logger:    logger,
+		actions:   map[string]func(ctx context.Context, actionContext ActionContext){},
+		refreshes: map[string]func(ctx context.Context, current RefreshableResult) RefreshableResult{},
+		responses: map[string]chan jsonRpcMessage{},
+	}
+	h.api = newAPI(h)
+	return h
+}
+
+// Run serves the plugin until Wox exits, it should be called in main function of the plugin executable
+func Run(p Plugin) {
+	if len(os.Args) < 4 {
+		fmt.Fprintln(os.Stderr, "Usage: <plugin executable> <port> <logDirectory> <woxPid>")
+		os.Exit(1)
+	}
+	port := os.Args[1]
+	logDirectory := os.Args[2]
+	woxPid, pidErr := strconv.Atoi(os.Args[3])
+	if pidErr != nil {
+		fmt.Fprintf(os.Stderr, "invalid wox pid: %s\n", os.Args[3])
+		os.Exit(1)
+	}
+
+	logFile, logErr := os.OpenFile(filepath.Join(logDirectory, fmt.Sprintf("go-%d.log", os.Getpid())), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if logErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to open log file: %s\n", logErr)
+		os.Exit(1)
+	}
+	defer logFile.Close()
+
+	h := newHost(p, log.New(logFile, "", log.LstdFlags))
+	ctx := newTraceContext("")
+	h.log(ctx, "info", "----------------------------------------")
+	h.log(ctx, "info", fmt.Sprintf("start go plugin host, port: %s, wox pid: %d", port, woxPid))
+
+	// check wox process is alive, otherwise exit
+	go func() {
+		for {
+			time.Sleep(time.Second)
+			if !isProcessAlive(woxPid) {
+				h.log(ctx, "error", "wox process is not alive, exit")
+				os.Exit(1)
+			}
+		}
+	}()
+
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	serveErr := http.ListenAndServe(fmt.Sprintf("localhost:%s", port), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, upgradeErr := upgrader.Upgrade(w, r, nil)
+		if upgradeErr != nil {
+			h.log(ctx, "error", fmt.Sprintf("failed to upgrade connection: %s", upgradeErr))
+			return
+		}
+		h.serve(conn)
+	}))
+	h.log(ctx, "error", fmt.Sprintf("websocket server stopped: %s", serveErr))
+	os.Exit(1)
+}
+
+func isProcessAlive(pid int) bool {
+	process, findErr := os.FindProcess(pid)
+	if findErr != nil {
+		return false
+	}
+	defer process.Release()
+
+	// FindProcess fails on windows if process doesn't exist, but always succeeds on unix, send signal 0 to check it
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// serve reads messages from Wox until connection is closed
+func (h *host) serve(conn *websocket.Conn) {
+	h.writeLock.Lock()
+	h.conn = conn
+	h.writeLock.Unlock()
+
+	for {
+		messageType, data, readErr := conn.ReadMessage()
+		if readErr != nil {
+			h.log(newTraceContext(""), "info", fmt.Sprintf("connection closed: %s", readErr))
+			h.writeLock.Lock()
+			if h.conn == conn {
+				h.conn = nil
+			}
+			h.writeLock.Unlock()
+			return
+		}
+		if messageType != websocket.TextMessage {
+			h.log(newTraceContext(""), "error", "only json text messages are supported")
+			continue
+		}
+
+		var message jsonRpcMessage
+		if unmarshalErr := json.Unmarshal(data, &message); unmarshalErr != nil {
+			h.log(newTraceContext(""), "error", fmt.Sprintf("failed to decode message: %s", unmarshalErr))
+			continue
+		}
+
+		switch message.Type {
+		case jsonRpcTypeRequest:
+			go h.handleRequest(message)
+		case jsonRpcTypeResponse:
+			h.lock.Lock()
+			responseChan, exist := h.responses[message.Id]
+			h.lock.Unlock()
+			if exist {
+				responseChan <- message
+			}
+		default:
+			h.log(newTraceContext(""), "error", fmt.Sprintf("unknown message type: %s", message.Type))
+		}
+	}
+}
+
+func (h *host) handleRequest(request jsonRpcMessage) {
+	ctx := newTraceContext(request.TraceId)
+	response := jsonRpcMessage{
+		TraceId: request.TraceId,
+		Id:      request.Id,
+		Method:  request.Method,
+		Type:    jsonRpcTypeResponse,
+	}
+
+	result, handleErr := h.handleRequestFromWox(ctx, request)
+	if handleErr != nil {
+		h.log(ctx, "error", fmt.Sprintf("failed to handle %s request: %s", request.Method, handleErr))
+		response.Error = handleErr.Error()
+	} else {
+		response.Result = result
+	}
+
+	if sendErr := h.send(response); sendErr != nil {
+		h.log(ctx, "error", fmt.Sprintf("failed to send %s response: %s", request.Method, sendErr))
+	}
+}
+
+// handleRequestFromWox mirrors handleRequestFromWox of nodejs host
+func (h *host) handleRequestFromWox(ctx context.Context, request jsonRpcMessage) (any, error) {
+	switch request.Method {
+	case "handshake":
+		// go plugins only speak json, and stream results with PushResults instead of streamResults capability
+		woxVersion, _ := strconv.Atoi(request.Params["ProtocolVersion"])
+		return map[string]any{
+			"ProtocolVersion": min(woxVersion, protocolVersion),
+			"Encoding":        encodingJson,
+			"Capabilities":    []string{},
+		}, nil
+	case "ping":
+		return "pong", nil
+	case "loadPlugin":
+		h.pluginId = request.PluginId
+		h.pluginName = request.PluginName
+		return nil, nil
+	case "unloadPlugin":
+		return nil, nil
+	case "init":
+		h.plugin.Init(ctx, InitParams{API: h.api, PluginDirectory: request.Params["PluginDirectory"]})
+		return nil, nil
+	case "query":
+		return h.query(ctx, request.Params)
+	case "action":
+		h.lock.Lock()
+		action, exist := h.actions[request.Params["ActionId"]]
+		h.lock.Unlock()
+		if !exist {
+			return nil, fmt.Errorf("action not found: %s", request.Params["ActionId"])
+		}
+		action(ctx, ActionContext{ContextData: request.Params["ContextData"]})
+		return nil, nil
+	case "refresh":
+		return h.refresh(ctx, request.Params)
+	case "onPluginSettingChange":
+		h.lock.Lock()
+		callback, exist := h.api.settingChangeCallbacks[request.Params["CallbackId"]]
+		h.lock.Unlock()
+		if exist {
+			callback(request.Params["Key"], request.Params["Value"])
+		}
+		return nil, nil
+	case "onGetDynamicSetting":
+		h.lock.Lock()
+		callback, exist := h.api.dynamicSettingCallbacks[request.Params["CallbackId"]]
+		h.lock.Unlock()
+		if !exist {
+			return nil, fmt.Errorf("dynamic setting callback not found: %s", request.Params["CallbackId"])
+		}
+		return callback(request.Params["Key"]), nil
+	case "onDeepLink":
+		var arguments map[string]string
+		if unmarshalErr := json.Unmarshal([]byte(request.Params["Arguments"]), &arguments); unmarshalErr != nil {
+			return nil, fmt.Errorf("failed to unmarshal deep link arguments: %w", unmarshalErr)
+		}
+		h.lock.Lock()
+		callback, exist := h.api.deepLinkCallbacks[request.Params["CallbackId"]]
+		h.lock.Unlock()
+		if exist {
+			callback(arguments)
+		}
+		return nil, nil
+	case "onQueryVariable":
+		h.lock.Lock()
+		callback, exist := h.api.queryVariableCallbacks[request.Params["CallbackId"]]
+		h.lock.Unlock()
+		if !exist {
+			return nil, fmt.Errorf("query variable callback not found: %s", request.Params["CallbackId"])
+		}
+		return callback(ctx, request.Params["Arg"])
+	case "onUnload":
+		h.lock.Lock()
+		callback, exist := h.api.unloadCallbacks[request.Params["CallbackId"]]
+		h.lock.Unlock()
+		if exist {
+			callback()
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown method: %s", request.Method)
+	}
+}
+
+func (h *host) query(ctx context.Context, params map[string]string) ([]QueryResult, error) {
+	query := Query{
+		Id:             params["Id"],
+		Type:           params["Type"],
+		RawQuery:       params["RawQuery"],
+		TriggerKeyword: params["TriggerKeyword"],
+		Command:        params["Command"],
+		Search:         params["Search"],
+	}
+	if unmarshalErr := json.Unmarshal([]byte(params["Selection"]), &query.Selection); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal query selection: %w", unmarshalErr)
+	}
+	if unmarshalErr := json.Unmarshal([]byte(params["Env"]), &query.Env); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal query env: %w", unmarshalErr)
+	}
+
+	// clean action cache of previous query
+	h.lock.Lock()
+	clear(h.actions)
+	clear(h.refreshes)
+	h.lock.Unlock()
+
+	return h.prepareResults(h.plugin.Query(ctx, query)), nil
+}
+
+// prepareResults assigns missing ids, and caches actions and refreshes so Wox can invoke them later
+func (h *host) prepareResults(results []QueryResult) []QueryResult {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for i := range results {
+		if results[i].Id == "" {
+			results[i].Id = uuid.NewString()
+		}
+		h.cacheActions(results[i].Actions)
+		if results[i].RefreshInterval > 0 && results[i].OnRefresh != nil {
+			h.refreshes[results[i].Id] = results[i].OnRefresh
+		}
+	}
+
+	return results
+}
+
+// cacheActions must be called with lock held
+func (h *host) cacheActions(actions []QueryResultAction) {
+	for i := range actions {
+		if actions[i].Id == "" {
+			actions[i].Id = uuid.NewString()
+		}
+		if actions[i].Action != nil {
+			h.actions[actions[i].Id] = actions[i].Action
+		}
+	}
+}
+
+func (h *host) refresh(ctx context.Context, params map[string]string) (refreshableResultWithResultId, error) {
+	var current refreshableResultWithResultId
+	if unmarshalErr := json.Unmarshal([]byte(params["RefreshableResult"]), &current); unmarshalErr != nil {
+		return current, fmt.Errorf("failed to unmarshal refreshable result: %w", unmarshalErr)
+	}
+
+	h.lock.Lock()
+	refresh, exist := h.refreshes[params["ResultId"]]
+	for i := range current.Actions {
+		current.Actions[i].Action = h.actions[current.Actions[i].Id]
+	}
+	h.lock.Unlock()
+	if !exist {
+		return current, fmt.Errorf("refresh not found: %s", params["ResultId"])
+	}
+
+	refreshed := refresh(ctx, current.RefreshableResult)
+	h.lock.Lock()
+	h.cacheActions(refreshed.Actions)
+	h.lock.Unlock()
+
+	return refreshableResultWithResultId{ResultId: current.ResultId, RefreshableResult: refreshed}, nil
+}
+
+// invokeMethod sends an API request to Wox and waits for the response
+func (h *host) invokeMethod(ctx context.Context, method string, params map[string]string) (any, error) {
+	request := jsonRpcMessage{
+		TraceId:    getTraceId(ctx),
+		Id:         uuid.NewString(),
+		PluginId:   h.pluginId,
+		PluginName: h.pluginName,
+		Method:     method,
+		Type:       jsonRpcTypeRequest,
+		Params:     params,
+	}
+
+	responseChan := make(chan jsonRpcMessage, 1)
+	h.lock.Lock()
+	h.responses[request.Id] = responseChan
+	h.lock.Unlock()
+	defer func() {
+		h.lock.Lock()
+		delete(h.responses, request.Id)
+		h.lock.Unlock()
+	}()
+
+	if sendErr := h.send(request); sendErr != nil {
+		return nil, sendErr
+	}
+
+	select {
+	case response := <-responseChan:
+		if response.Error != "" {
+			return nil, errors.New(response.Error)
+		}
+		return response.Result, nil
+	case <-time.After(invokeTimeout):
+		return nil, fmt.Errorf("timeout after %s", invokeTimeout)
+	}
+}
+
+func (h *host) send(message jsonRpcMessage) error {
+	data, marshalErr := json.Marshal(message)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	h.writeLock.Lock()
+	defer h.writeLock.Unlock()
+	if h.conn == nil {
+		return errors.New("not connected to wox")
+	}
+	return h.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// log writes to the log file, and also to Wox log if connected
+func (h *host) log(ctx context.Context, level string, msg string) {
+	traceId := getTraceId(ctx)
+	h.logger.Printf("%s [%s] %s", traceId, level, msg)
+	h.send(jsonRpcMessage{
+		TraceId: traceId,
+		Type:    jsonRpcTypeSystemLog,
+		Level:   level,
+		Message: msg,
+	})
+}
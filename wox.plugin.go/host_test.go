@@ -0,0 +1,80 @@
+package wox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	api           API
+	actionContext string
+}
+
+func (p *testPlugin) Init(ctx context.Context, initParams InitParams) {
+	p.api = initParams.API
+}
+
+func (p *testPlugin) Query(ctx context.Context, query Query) []QueryResult {
+	return []QueryResult{
+		{
+			Title:       query.Search,
+			ContextData: query.Env.ActiveWindowTitle,
+			Actions: []QueryResultAction{
+				{
+					Name: "open",
+					Action: func(ctx context.Context, actionContext ActionContext) {
+						p.actionContext = actionContext.ContextData
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_HandleRequestFromWox(t *testing.T) {
+	p := &testPlugin{}
+	h := newHost(p, log.New(io.Discard, "", 0))
+	ctx := newTraceContext("")
+
+	handshake, handshakeErr := h.handleRequestFromWox(ctx, jsonRpcMessage{Method: "handshake", Params: map[string]string{"ProtocolVersion": "3", "Encodings": "msgpack,json"}})
+	assert.Nil(t, handshakeErr)
+	assert.Equal(t, protocolVersion, handshake.(map[string]any)["ProtocolVersion"])
+	assert.Equal(t, encodingJson, handshake.(map[string]any)["Encoding"])
+
+	_, initErr := h.handleRequestFromWox(ctx, jsonRpcMessage{Method: "init", Params: map[string]string{"PluginDirectory": "/tmp"}})
+	assert.Nil(t, initErr)
+	assert.NotNil(t, p.api)
+
+	result, queryErr := h.handleRequestFromWox(ctx, jsonRpcMessage{Method: "query", Params: map[string]string{
+		"Id":        "1",
+		"Type":      QueryTypeInput,
+		"Search":    "hello",
+		"Selection": "{}",
+		"Env":       `{"ActiveWindowTitle":"Chrome"}`,
+	}})
+	assert.Nil(t, queryErr)
+	results := result.([]QueryResult)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "hello", results[0].Title)
+	assert.NotEmpty(t, results[0].Id)
+	assert.NotEmpty(t, results[0].Actions[0].Id)
+
+	// results are sent as json, callbacks must not break marshalling
+	_, marshalErr := json.Marshal(results)
+	assert.Nil(t, marshalErr)
+
+	_, actionErr := h.handleRequestFromWox(ctx, jsonRpcMessage{Method: "action", Params: map[string]string{
+		"ActionId":    results[0].Actions[0].Id,
+		"ContextData": results[0].ContextData,
+	}})
+	assert.Nil(t, actionErr)
+	assert.Equal(t, "Chrome", p.actionContext)
+
+	_, unknownErr := h.handleRequestFromWox(ctx, jsonRpcMessage{Method: "unknown"})
+	assert.NotNil(t, unknownErr)
+}
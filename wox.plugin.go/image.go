@@ -0,0 +1,68 @@
+package wox
+
+type WoxImageType = string
+
+const (
+	WoxImageTypeAbsolutePath WoxImageType = "absolute"
+	WoxImageTypeRelativePath WoxImageType = "relative"
+	WoxImageTypeBase64       WoxImageType = "base64"
+	WoxImageTypeSvg          WoxImageType = "svg"
+	WoxImageTypeLottie       WoxImageType = "lottie" // only support lottie json data
+	WoxImageTypeEmoji        WoxImageType = "emoji"
+	WoxImageTypeUrl          WoxImageType = "url"
+	WoxImageTypeTheme        WoxImageType = "theme"
+)
+
+type WoxImage struct {
+	ImageType WoxImageType
+	ImageData string
+}
+
+func NewWoxImageAbsolutePath(path string) WoxImage {
+	return WoxImage{ImageType: WoxImageTypeAbsolutePath, ImageData: path}
+}
+
+// NewWoxImageRelativePath creates an image relative to the plugin directory
+func NewWoxImageRelativePath(path string) WoxImage {
+	return WoxImage{ImageType: WoxImageTypeRelativePath, ImageData: path}
+}
+
+// NewWoxImageBase64 creates an image from base64 data with data uri prefix, e.g. "data:image/png;base64,xxx"
+func NewWoxImageBase64(data string) WoxImage {
+	return WoxImage{ImageType: WoxImageTypeBase64, ImageData: data}
+}
+
+func NewWoxImageSvg(svg string) WoxImage {
+	return WoxImage{ImageType: WoxImageTypeSvg, ImageData: svg}
+}
+
+func NewWoxImageEmoji(emoji string) WoxImage {
+	return WoxImage{ImageType: WoxImageTypeEmoji, ImageData: emoji}
+}
+
+func NewWoxImageUrl(url string) WoxImage {
+	return WoxImage{ImageType: WoxImageTypeUrl, ImageData: url}
+}
+
+type WoxPreviewType = string
+type WoxPreviewScrollPosition = string
+
+const (
+	WoxPreviewTypeMarkdown WoxPreviewType = "markdown"
+	WoxPreviewTypeText     WoxPreviewType = "text"
+	WoxPreviewTypeImage    WoxPreviewType = "image" // when type is image, data should be "<ImageType>:<ImageData>"
+	WoxPreviewTypeUrl      WoxPreviewType = "url"
+	WoxPreviewTypeFile     WoxPreviewType = "file"   // when type is file(can be *.md, *.jpg, *.pdf and so on), data should be url/filepath
+	WoxPreviewTypeRemote   WoxPreviewType = "remote" // when type is remote, data should be url to load WoxPreview
+)
+
+const (
+	WoxPreviewScrollPositionBottom WoxPreviewScrollPosition = "bottom" // scroll to bottom after preview first show
+)
+
+type WoxPreview struct {
+	PreviewType       WoxPreviewType
+	PreviewData       string
+	PreviewProperties map[string]string // key support i18n
+	ScrollPosition    WoxPreviewScrollPosition
+}
@@ -0,0 +1,23 @@
+// Package wox is the SDK for writing Wox plugins in go.
+//
+// A go plugin is a standalone executable, Wox starts it with "<port> <logDirectory> <woxPid>" arguments
+// and talks to it over the websocket host protocol, just like python and nodejs hosts.
+// Call Run in main function to serve your plugin:
+//
+//	func main() {
+//		wox.Run(&myPlugin{})
+//	}
+package wox
+
+import "context"
+
+// Plugin is the interface every go plugin must implement, it mirrors plugin.Plugin in wox.core
+type Plugin interface {
+	Init(ctx context.Context, initParams InitParams)
+	Query(ctx context.Context, query Query) []QueryResult
+}
+
+type InitParams struct {
+	API             API
+	PluginDirectory string
+}
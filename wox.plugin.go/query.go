@@ -0,0 +1,157 @@
+package wox
+
+import "context"
+
+type QueryType = string
+type SelectionType = string
+type QueryResultTailType = string
+
+const (
+	QueryTypeInput     QueryType = "input"     // user input query
+	QueryTypeSelection QueryType = "selection" // user selection query
+)
+
+const (
+	SelectionTypeText SelectionType = "text"
+	SelectionTypeFile SelectionType = "file"
+)
+
+const (
+	QueryResultTailTypeText  QueryResultTailType = "text"  // string type
+	QueryResultTailTypeImage QueryResultTailType = "image" // WoxImage type
+)
+
+// Query from Wox, it mirrors plugin.Query in wox.core
+type Query struct {
+	// Unique id of this query, plugins can push results to a running query with this id, see API.PushResults
+	Id string
+
+	// By default, Wox will only pass QueryTypeInput query to plugin.
+	// plugin author need to enable querySelection feature to handle QueryTypeSelection query
+	Type QueryType
+
+	// Raw query, this includes trigger keyword if it has.
+	// We didn't recommend use this property directly. You should always use Search property.
+	RawQuery string
+
+	// Trigger keyword of a query. It can be empty if user is using global trigger keyword.
+	//
+	// NOTE: Only available when query type is QueryTypeInput
+	TriggerKeyword string
+
+	// Command part of a query.
+	//
+	// NOTE: Only available when query type is QueryTypeInput
+	Command string
+
+	// Search part of a query.
+	Search string
+
+	// User selected or drag-drop data
+	//
+	// NOTE: Only available when query type is QueryTypeSelection
+	Selection Selection
+
+	// additional query environment data
+	Env QueryEnv
+}
+
+func (q *Query) IsGlobalQuery() bool {
+	return q.Type == QueryTypeInput && q.TriggerKeyword == ""
+}
+
+type Selection struct {
+	Type SelectionType
+	// Only available when Type is SelectionTypeText
+	Text string
+	// Only available when Type is SelectionTypeFile
+	FilePaths []string
+}
+
+type QueryEnv struct {
+	ActiveWindowTitle string // active window title when user query, empty if not available
+	ActiveWindowPid   int    // active window pid when user query, 0 if not available
+	ActiveBrowserUrl  string // active browser url when user query, empty if not available
+}
+
+// PlainQuery is used to change the query in Wox, see API.ChangeQuery
+type PlainQuery struct {
+	QueryType      QueryType
+	QueryText      string    // only available when QueryType is QueryTypeInput
+	QuerySelection Selection // only available when QueryType is QueryTypeSelection
+}
+
+// QueryResult returned from plugin, it mirrors plugin.QueryResult in wox.core
+type QueryResult struct {
+	// Result id, should be unique. It's optional, if you don't set it, a random id will be assigned
+	Id string
+	// Title support i18n
+	Title string
+	// SubTitle support i18n
+	SubTitle string
+	Icon     WoxImage
+	Preview  WoxPreview
+	// Score of the result, the higher the score, the more relevant the result is, more likely to be displayed on top
+	Score int64
+	// Group results, Wox will group results by group name
+	Group string
+	// Score of the group, the higher the score, the more relevant the group is, more likely to be displayed on top
+	GroupScore int64
+	// Tails are additional results associate with this result, can be displayed in result detail view
+	Tails []QueryResultTail
+	// Additional data associate with this result, can be retrieved in Action function
+	ContextData string
+	Actions     []QueryResultAction
+	// refresh result after specified interval, in milliseconds. If this value is 0, Wox will not refresh this result
+	RefreshInterval int
+	// refresh result by calling OnRefresh function
+	OnRefresh func(ctx context.Context, current RefreshableResult) RefreshableResult `json:"-"`
+}
+
+type QueryResultTail struct {
+	Type  QueryResultTailType
+	Text  string   // only available when type is QueryResultTailTypeText
+	Image WoxImage // only available when type is QueryResultTailTypeImage
+}
+
+type QueryResultAction struct {
+	// Action id, should be unique. It's optional, if you don't set it, a random id will be assigned
+	Id string
+	// Name support i18n
+	Name string
+	Icon WoxImage
+	// If true, Wox will use this action as default action. There can be only one default action in results
+	IsDefault bool
+	// If true, Wox will not hide after user select this result
+	PreventHideAfterAction bool
+	Action                 func(ctx context.Context, actionContext ActionContext) `json:"-"`
+	// Hotkey to trigger this action. E.g. "ctrl+Shift+Space", "Ctrl+1", "Command+K"
+	Hotkey string
+}
+
+type ActionContext struct {
+	// Additional data associate with this result
+	ContextData string
+}
+
+type RefreshableResult struct {
+	Title           string
+	SubTitle        string
+	Icon            WoxImage
+	Preview         WoxPreview
+	Tails           []QueryResultTail
+	ContextData     string
+	RefreshInterval int // set to 0 if you don't want to refresh this result anymore
+	Actions         []QueryResultAction
+}
+
+// refreshableResultWithResultId is the refreshable result exchanged with Wox
+type refreshableResultWithResultId struct {
+	ResultId string
+	RefreshableResult
+}
+
+type MetadataCommand struct {
+	Command     string
+	Description string
+}
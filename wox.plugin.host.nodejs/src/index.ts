@@ -7,6 +7,7 @@ import Deferred from "promise-deferred"
 import { NewTraceContext, TraceIdKey } from "./trace"
 import { NewContextWithValue } from "@wox-launcher/wox-plugin"
 import { PluginJsonRpcRequest, PluginJsonRpcResponse } from "./types"
+import { decodeMessage, sendMessage } from "./protocol"
 
 if (process.argv.length < 5) {
   console.error("Usage: node node.js <port> <logDirectory> <woxPid>")
@@ -62,32 +63,29 @@ wss.on("connection", function connection(ws) {
     ws.pong()
   })
 
-  ws.on("message", function message(data) {
+  ws.on("message", function message(data, isBinary) {
+    let msg: { Type?: string }
     try {
-      const msg = `${data}`
-      // logger.debug(crypto.randomUUID(), `receive message: ${msg}`)
-
-      if (msg.indexOf(PluginJsonRpcTypeResponse) >= 0) {
-        handleResponseFromWox(msg)
-      } else if (msg.indexOf(PluginJsonRpcTypeRequest) >= 0) {
-        handleRequest(msg)
-      } else {
-        logger.error(NewTraceContext(), `unknown message type: ${msg}`)
-      }
+      msg = decodeMessage(data, isBinary) as { Type?: string }
     } catch (e) {
-      logger.error(NewTraceContext(), `receive and handle msg error: ${data}, err: ${e}`)
+      logger.error(NewTraceContext(), `error decoding message: ${e}, binary: ${isBinary}`)
+      return
     }
-  })
 
-  function handleRequest(msg: string) {
-    let jsonRpcRequest: PluginJsonRpcRequest
     try {
-      jsonRpcRequest = JSON.parse(msg) as PluginJsonRpcRequest
+      if (msg.Type === PluginJsonRpcTypeResponse) {
+        handleResponseFromWox(msg as PluginJsonRpcResponse)
+      } else if (msg.Type === PluginJsonRpcTypeRequest) {
+        handleRequest(msg as PluginJsonRpcRequest)
+      } else {
+        logger.error(NewTraceContext(), `unknown message type: ${msg.Type}`)
+      }
     } catch (e) {
-      logger.error(NewTraceContext(), `error parsing json: ${e}, data: ${msg}`)
-      return
+      logger.error(NewTraceContext(), `receive and handle msg error: ${JSON.stringify(msg)}, err: ${e}`)
     }
+  })
 
+  function handleRequest(jsonRpcRequest: PluginJsonRpcRequest) {
     if (jsonRpcRequest === undefined) {
       logger.error(NewTraceContext(), `jsonRpcRequest is undefined`)
       return
@@ -109,7 +107,7 @@ wss.on("connection", function connection(ws) {
           Result: result
         }
         //logger.info(`[${jsonRpcRequest.PluginName}] handle request successfully: ${JSON.stringify(response)}, ${ws.readyState}`)
-        ws.send(JSON.stringify(response), (error?: Error) => {
+        sendMessage(ws, response, (error?: Error) => {
           if (error) {
             logger.error(ctx, `[${jsonRpcRequest.PluginName}] send response failed: ${error.message}`)
           }
@@ -124,7 +122,7 @@ wss.on("connection", function connection(ws) {
           Error: error.message
         }
         logger.error(ctx, `[${jsonRpcRequest.PluginName}] handle request failed: ${error.message}, stack: ${error.stack}`)
-        ws.send(JSON.stringify(response), (error?: Error) => {
+        sendMessage(ws, response, (error?: Error) => {
           if (error) {
             logger.error(ctx, `[${jsonRpcRequest.PluginName}] send response failed: ${error.message}, stack: ${error.stack}`)
           }
@@ -132,15 +130,7 @@ wss.on("connection", function connection(ws) {
       })
   }
 
-  function handleResponseFromWox(msg: string) {
-    let pluginJsonRpcResponse: PluginJsonRpcResponse
-    try {
-      pluginJsonRpcResponse = JSON.parse(msg) as PluginJsonRpcResponse
-    } catch (e) {
-      logger.error(NewTraceContext(), `error parsing response json: ${e}, data: ${msg}`)
-      return
-    }
-
+  function handleResponseFromWox(pluginJsonRpcResponse: PluginJsonRpcResponse) {
     if (pluginJsonRpcResponse === undefined) {
       logger.error(NewTraceContext(), `pluginJsonRpcResponse is undefined`)
       return
@@ -159,6 +149,10 @@ wss.on("connection", function connection(ws) {
       return
     }
 
-    promiseInstance.resolve(pluginJsonRpcResponse.Result)
+    if (pluginJsonRpcResponse.Error) {
+      promiseInstance.reject(new Error(pluginJsonRpcResponse.Error))
+    } else {
+      promiseInstance.resolve(pluginJsonRpcResponse.Result)
+    }
   }
 })
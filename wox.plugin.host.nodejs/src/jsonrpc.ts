@@ -5,13 +5,15 @@ import { Context, MapString, Plugin, PluginInitParams, Query, QueryEnv, Refresha
 import { WebSocket } from "ws"
 import * as crypto from "crypto"
 import { AI } from "@wox-launcher/wox-plugin/types/ai"
-import { PluginInstance, PluginJsonRpcRequest, RefreshableResultWithResultId, ResultActionUI } from "./types"
+import { PluginInstance, PluginJsonRpcRequest, PluginJsonRpcResponse, RefreshableResultWithResultId, ResultActionUI } from "./types"
+import { CapabilityStreamResults, handshake, hasCapability, sendMessage } from "./protocol"
 
 const pluginInstances = new Map<PluginJsonRpcRequest["PluginId"], PluginInstance>()
 
 export const PluginJsonRpcTypeRequest: string = "WOX_JSONRPC_REQUEST"
 export const PluginJsonRpcTypeResponse: string = "WOX_JSONRPC_RESPONSE"
 export const PluginJsonRpcTypeSystemLog: string = "WOX_JSONRPC_SYSTEM_LOG"
+export const PluginJsonRpcTypeStream: string = "WOX_JSONRPC_STREAM"
 
 // eslint-disable-next-line @typescript-eslint/ban-ts-comment
 // @ts-ignore
@@ -19,12 +21,16 @@ export async function handleRequestFromWox(ctx: Context, request: PluginJsonRpcR
   logger.info(ctx, `invoke <${request.PluginName}> method: ${request.Method}`)
 
   switch (request.Method) {
+    case "handshake":
+      return handshake(request.Params)
+    case "ping":
+      return "pong"
     case "loadPlugin":
       return loadPlugin(ctx, request)
     case "init":
       return initPlugin(ctx, request, ws)
     case "query":
-      return query(ctx, request)
+      return query(ctx, request, ws)
     case "action":
       return action(ctx, request)
     case "refresh":
@@ -39,6 +45,8 @@ export async function handleRequestFromWox(ctx: Context, request: PluginJsonRpcR
       return onDeepLink(ctx, request)
     case "onUnload":
       return onUnload(ctx, request)
+    case "onQueryVariable":
+      return onQueryVariable(ctx, request)
     case "onLLMStream":
       return onLLMStream(ctx, request)
     default:
@@ -164,6 +172,22 @@ async function onUnload(ctx: Context, request: PluginJsonRpcRequest) {
   await plugin.API.unloadCallbacks.get(callbackId)?.()
 }
 
+async function onQueryVariable(ctx: Context, request: PluginJsonRpcRequest) {
+  const plugin = pluginInstances.get(request.PluginId)
+  if (plugin === undefined || plugin === null) {
+    logger.error(ctx, `plugin not found: ${request.PluginName}, forget to load plugin?`)
+    throw new Error(`plugin not found: ${request.PluginName}, forget to load plugin?`)
+  }
+
+  const callbackId = request.Params.CallbackId
+  const resolver = plugin.API.queryVariableCallbacks.get(callbackId)
+  if (resolver === undefined || resolver === null) {
+    throw new Error(`query variable callback not found: ${callbackId}`)
+  }
+
+  return await resolver(request.Params.Arg)
+}
+
 async function onLLMStream(ctx: Context, request: PluginJsonRpcRequest) {
   const plugin = pluginInstances.get(request.PluginId)
   if (plugin === undefined || plugin === null) {
@@ -183,7 +207,7 @@ async function onLLMStream(ctx: Context, request: PluginJsonRpcRequest) {
   callbackFunc(<AI.ChatStreamDataType>streamType, data)
 }
 
-async function query(ctx: Context, request: PluginJsonRpcRequest) {
+async function query(ctx: Context, request: PluginJsonRpcRequest, ws: WebSocket) {
   const plugin = pluginInstances.get(request.PluginId)
   if (plugin === undefined || plugin === null) {
     logger.error(ctx, `plugin not found: ${request.PluginName}, forget to load plugin?`)
@@ -196,7 +220,8 @@ async function query(ctx: Context, request: PluginJsonRpcRequest) {
   plugin.Actions.clear()
   plugin.Refreshes.clear()
 
-  const results = await query(ctx, {
+  const queryResult = (await query(ctx, {
+    Id: request.Params.Id,
     Type: request.Params.Type,
     RawQuery: request.Params.RawQuery,
     TriggerKeyword: request.Params.TriggerKeyword,
@@ -205,13 +230,46 @@ async function query(ctx: Context, request: PluginJsonRpcRequest) {
     Selection: JSON.parse(request.Params.Selection) as Selection,
     Env: JSON.parse(request.Params.Env) as QueryEnv,
     IsGlobalQuery: () => request.Params.Type === "input" && request.Params.TriggerKeyword === ""
-  } as Query)
+  } as Query)) as Result[] | AsyncIterable<Result[]>
 
-  if (!results) {
+  if (!queryResult) {
     logger.info(ctx, `plugin query didn't return results: ${request.PluginName}`)
     return []
   }
 
+  if (!(Symbol.asyncIterator in queryResult)) {
+    return prepareResults(plugin, queryResult)
+  }
+
+  // plugin yields results in batches, stream every batch to Wox if it's supported, otherwise return them all at once
+  const collectedResults: Result[] = []
+  for await (const batch of queryResult) {
+    const results = prepareResults(plugin, batch)
+    if (hasCapability(CapabilityStreamResults)) {
+      sendMessage(ws, {
+        TraceId: request.TraceId,
+        Id: request.Id,
+        Method: request.Method,
+        Type: PluginJsonRpcTypeStream,
+        Result: results
+      } as PluginJsonRpcResponse)
+    } else {
+      collectedResults.push(...results)
+    }
+  }
+  return collectedResults
+}
+
+// results pushed by plugin while querying, actions and refreshes are cached like returned results
+export function preparePushedResults(pluginId: string, results: Result[]) {
+  const plugin = pluginInstances.get(pluginId)
+  if (plugin === undefined || plugin === null) {
+    throw new Error(`plugin not found: ${pluginId}, forget to load plugin?`)
+  }
+  return prepareResults(plugin, results)
+}
+
+function prepareResults(plugin: PluginInstance, results: Result[]) {
   results.forEach(result => {
     if (result.Id === undefined || result.Id === null) {
       result.Id = crypto.randomUUID()
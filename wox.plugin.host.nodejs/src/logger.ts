@@ -4,6 +4,7 @@ import { PluginJsonRpcTypeSystemLog } from "./jsonrpc"
 import crypto from "crypto"
 import { TraceIdKey } from "./trace"
 import { Context } from "@wox-launcher/wox-plugin"
+import { sendMessage } from "./protocol"
 
 const logDirectory = process.argv[3]
 let ws: WebSocket | undefined = undefined
@@ -18,14 +19,12 @@ function log(ctx: Context, level: string, msg: string) {
   winstonLogger.log(level, `${traceId} [${level}] ${msg}`)
 
   if (ws !== undefined) {
-    ws.send(
-      JSON.stringify({
-        Type: PluginJsonRpcTypeSystemLog,
-        TraceId: traceId,
-        Level: level,
-        Message: msg
-      })
-    )
+    sendMessage(ws, {
+      Type: PluginJsonRpcTypeSystemLog,
+      TraceId: traceId,
+      Level: level,
+      Message: msg
+    })
   }
 }
 
@@ -1,4 +1,4 @@
-import { ChangeQueryParam, Context, MapString, PublicAPI } from "@wox-launcher/wox-plugin"
+import { ChangeQueryParam, Context, MapString, PublicAPI, Result } from "@wox-launcher/wox-plugin"
 import { WebSocket } from "ws"
 import * as crypto from "crypto"
 import { waitingForResponse } from "./index"
@@ -6,8 +6,9 @@ import Deferred from "promise-deferred"
 import { logger } from "./logger"
 import { MetadataCommand, PluginSettingDefinitionItem } from "@wox-launcher/wox-plugin/types/setting"
 import { AI } from "@wox-launcher/wox-plugin/types/ai"
-import { PluginJsonRpcTypeRequest } from "./jsonrpc"
+import { PluginJsonRpcTypeRequest, preparePushedResults } from "./jsonrpc"
 import { PluginJsonRpcRequest } from "./types"
+import { sendMessage } from "./protocol"
 
 export class PluginAPI implements PublicAPI {
   ws: WebSocket
@@ -18,6 +19,7 @@ export class PluginAPI implements PublicAPI {
   deepLinkCallbacks: Map<string, (params: MapString) => void>
   unloadCallbacks: Map<string, () => Promise<void>>
   llmStreamCallbacks: Map<string, AI.ChatStreamFunc>
+  queryVariableCallbacks: Map<string, (arg: string) => Promise<string> | string>
 
   constructor(ws: WebSocket, pluginId: string, pluginName: string) {
     this.ws = ws
@@ -28,6 +30,7 @@ export class PluginAPI implements PublicAPI {
     this.deepLinkCallbacks = new Map<string, (params: MapString) => void>()
     this.unloadCallbacks = new Map<string, () => Promise<void>>()
     this.llmStreamCallbacks = new Map<string, AI.ChatStreamFunc>()
+    this.queryVariableCallbacks = new Map<string, (arg: string) => Promise<string> | string>()
   }
 
   async invokeMethod(ctx: Context, method: string, params: { [key: string]: string }): Promise<unknown> {
@@ -38,17 +41,15 @@ export class PluginAPI implements PublicAPI {
       logger.info(ctx, `<${this.pluginName}> start invoke method to Wox: ${method}, id: ${requestId}`)
     }
 
-    this.ws.send(
-      JSON.stringify({
-        TraceId: traceId,
-        Id: requestId,
-        Method: method,
-        Type: PluginJsonRpcTypeRequest,
-        Params: params,
-        PluginId: this.pluginId,
-        PluginName: this.pluginName
-      } as PluginJsonRpcRequest)
-    )
+    sendMessage(this.ws, {
+      TraceId: traceId,
+      Id: requestId,
+      Method: method,
+      Type: PluginJsonRpcTypeRequest,
+      Params: params,
+      PluginId: this.pluginId,
+      PluginName: this.pluginName
+    } as PluginJsonRpcRequest)
     const deferred = new Deferred<unknown>()
     waitingForResponse[requestId] = deferred
 
@@ -119,6 +120,17 @@ export class PluginAPI implements PublicAPI {
     await this.invokeMethod(ctx, "RegisterQueryCommands", { commands: JSON.stringify(commands) })
   }
 
+  async PushResults(ctx: Context, queryId: string, results: Result[]): Promise<void> {
+    const pushedResults = preparePushedResults(this.pluginId, results)
+    await this.invokeMethod(ctx, "PushResults", { queryId, results: JSON.stringify(pushedResults) })
+  }
+
+  async RegisterQueryVariable(ctx: Context, name: string, description: string, resolver: (arg: string) => Promise<string> | string): Promise<void> {
+    const callbackId = crypto.randomUUID()
+    this.queryVariableCallbacks.set(callbackId, resolver)
+    await this.invokeMethod(ctx, "RegisterQueryVariable", { callbackId, name, description })
+  }
+
   async LLMStream(ctx: Context, conversations: AI.Conversation[], callback: AI.ChatStreamFunc): Promise<void> {
     const callbackId = crypto.randomUUID()
     this.llmStreamCallbacks.set(callbackId, callback)
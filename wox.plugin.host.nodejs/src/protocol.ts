@@ -0,0 +1,68 @@
+import { decode, encode } from "@msgpack/msgpack"
+import { MapString } from "@wox-launcher/wox-plugin"
+import { RawData, WebSocket } from "ws"
+
+// host protocol negotiated with Wox in handshake, see wox.core/plugin/host/jsonrpc.go
+export const ProtocolVersion = 2
+export const EncodingJson = "json"
+export const EncodingMsgpack = "msgpack"
+export const CapabilityStreamResults = "streamResults"
+
+const supportedEncodings = [EncodingMsgpack, EncodingJson]
+const supportedCapabilities = [CapabilityStreamResults]
+
+let encoding = EncodingJson
+let capabilities: string[] = []
+
+export function handshake(params: MapString) {
+  const woxVersion = Number.parseInt(params.ProtocolVersion)
+  const woxEncodings = (params.Encodings || "").split(",")
+  const woxCapabilities = (params.Capabilities || "").split(",")
+
+  // Wox lists encodings by preference, pick the first one we support
+  encoding = woxEncodings.find(e => supportedEncodings.includes(e)) || EncodingJson
+  capabilities = supportedCapabilities.filter(c => woxCapabilities.includes(c))
+
+  return {
+    ProtocolVersion: Math.min(woxVersion, ProtocolVersion),
+    Encoding: encoding,
+    Capabilities: capabilities
+  }
+}
+
+export function hasCapability(capability: string) {
+  return capabilities.includes(capability)
+}
+
+// messages are decoded by frame type, binary frames are always msgpack
+export function decodeMessage(data: RawData, isBinary: boolean): unknown {
+  if (isBinary) {
+    return decode(data as Buffer)
+  }
+  return JSON.parse(`${data}`)
+}
+
+// results carry callbacks like Action and OnRefresh, drop them the same way JSON.stringify does
+function omitFunctions(value: unknown): unknown {
+  if (Array.isArray(value)) {
+    return value.map(omitFunctions)
+  }
+  if (value !== null && typeof value === "object" && !(value instanceof Uint8Array)) {
+    const cleaned: { [key: string]: unknown } = {}
+    for (const [key, v] of Object.entries(value)) {
+      if (typeof v !== "function") {
+        cleaned[key] = omitFunctions(v)
+      }
+    }
+    return cleaned
+  }
+  return value
+}
+
+export function sendMessage(ws: WebSocket, message: unknown, callback?: (error?: Error) => void) {
+  if (encoding === EncodingMsgpack) {
+    ws.send(encode(omitFunctions(message)), { binary: true }, callback)
+  } else {
+    ws.send(JSON.stringify(message), callback)
+  }
+}
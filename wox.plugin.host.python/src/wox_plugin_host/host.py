@@ -1,60 +1,30 @@
 import asyncio
-import json
 import uuid
 import traceback
+from typing import Union
 from wox_plugin import Context
 import websockets
 
 from . import logger
+from .protocol import clean_for_serialization, decode_message, send_message
 from .constants import PLUGIN_JSONRPC_TYPE_REQUEST, PLUGIN_JSONRPC_TYPE_RESPONSE
 from .plugin_manager import waiting_for_response
 from .jsonrpc import handle_request_from_wox
 
 
-def _clean_for_serialization(obj):
-    """Remove non-serializable properties from any object recursively"""
-    if obj is None:
-        return obj
-
-    if isinstance(obj, (str, int, float, bool)):
-        return obj
-
-    if isinstance(obj, (list, tuple)):
-        return [_clean_for_serialization(item) for item in obj]
-
-    if isinstance(obj, dict):
-        return {k: _clean_for_serialization(v) for k, v in obj.items()}
-
-    # Handle custom objects
-    if hasattr(obj, "__dict__"):
-        # Create a copy of the object's dict
-        obj_dict = obj.__dict__.copy()
-
-        # Remove callable (methods/functions) and handle nested objects
-        cleaned_dict = {}
-        for k, v in obj_dict.items():
-            if callable(v):
-                continue
-            cleaned_dict[k] = _clean_for_serialization(v)
-
-        return cleaned_dict
-
-    # If we can't handle it, just return None
-    return None
-
-
-async def handle_message(ws: websockets.asyncio.server.ServerConnection, message: str):
+async def handle_message(ws: websockets.asyncio.server.ServerConnection, message: Union[str, bytes]):
     """Handle incoming WebSocket message"""
 
     trace_id = str(uuid.uuid4())
     try:
-        msg_data = json.loads(message)
+        msg_data = decode_message(message)
         if msg_data.get("TraceId"):
             trace_id = msg_data.get("TraceId")
 
         ctx = Context.new_with_value("TraceId", trace_id)
 
-        if PLUGIN_JSONRPC_TYPE_RESPONSE in message:
+        msg_type = msg_data.get("Type")
+        if msg_type == PLUGIN_JSONRPC_TYPE_RESPONSE:
             # Handle response from Wox
             if msg_data.get("Id") in waiting_for_response:
                 deferred = waiting_for_response[msg_data["Id"]]
@@ -63,12 +33,12 @@ async def handle_message(ws: websockets.asyncio.server.ServerConnection, message
                 else:
                     deferred.set_result(msg_data.get("Result"))
                 del waiting_for_response[msg_data["Id"]]
-        elif PLUGIN_JSONRPC_TYPE_REQUEST in message:
+        elif msg_type == PLUGIN_JSONRPC_TYPE_REQUEST:
             # Handle request from Wox
             try:
                 result = await handle_request_from_wox(ctx, msg_data, ws)
                 # Clean result for serialization
-                cleaned_result = _clean_for_serialization(result)
+                cleaned_result = clean_for_serialization(result)
 
                 response = {
                     "TraceId": trace_id,
@@ -77,7 +47,7 @@ async def handle_message(ws: websockets.asyncio.server.ServerConnection, message
                     "Type": PLUGIN_JSONRPC_TYPE_RESPONSE,
                     "Result": cleaned_result,
                 }
-                await ws.send(json.dumps(response))
+                await send_message(ws, response)
             except Exception as e:
                 error_stack = traceback.format_exc()
                 error_response = {
@@ -88,9 +58,9 @@ async def handle_message(ws: websockets.asyncio.server.ServerConnection, message
                     "Error": str(e),
                 }
                 await logger.error(trace_id, f"handle request failed: {str(e)}\nStack trace:\n{error_stack}")
-                await ws.send(json.dumps(error_response))
+                await send_message(ws, error_response)
         else:
-            await logger.error(trace_id, f"unknown message type: {message}")
+            await logger.error(trace_id, f"unknown message type: {msg_type}")
     except Exception as e:
         error_stack = traceback.format_exc()
         await logger.error(
@@ -107,7 +77,7 @@ async def handler(websocket: websockets.asyncio.server.ServerConnection):
         while True:
             try:
                 message = await websocket.recv()
-                asyncio.create_task(handle_message(websocket, str(message)))
+                asyncio.create_task(handle_message(websocket, message))
             except websockets.exceptions.ConnectionClosed:
                 await logger.info(str(uuid.uuid4()), "connection closed")
                 break
@@ -13,10 +13,13 @@ from wox_plugin import (
     PluginInitParams,
     ActionContext,
 )
-from .plugin_manager import plugin_instances, PluginInstance
+from .plugin_manager import plugin_instances, prepare_results, PluginInstance
 from .plugin_api import PluginAPI
+from .constants import PLUGIN_JSONRPC_TYPE_STREAM
+from .protocol import CAPABILITY_STREAM_RESULTS, clean_for_serialization, handshake, has_capability, send_message
 import traceback
 import asyncio
+import inspect
 
 
 async def handle_request_from_wox(ctx: Context, request: Dict[str, Any], ws: websockets.asyncio.server.ServerConnection) -> Any:
@@ -26,18 +29,24 @@ async def handle_request_from_wox(ctx: Context, request: Dict[str, Any], ws: web
 
     await logger.info(ctx.get_trace_id(), f"invoke <{plugin_name}> method: {method}")
 
-    if method == "loadPlugin":
+    if method == "handshake":
+        return handshake(request.get("Params", {}))
+    elif method == "ping":
+        return "pong"
+    elif method == "loadPlugin":
         return await load_plugin(ctx, request)
     elif method == "init":
         return await init_plugin(ctx, request, ws)
     elif method == "query":
-        return await query(ctx, request)
+        return await query(ctx, request, ws)
     elif method == "action":
         return await action(ctx, request)
     elif method == "refresh":
         return await refresh(ctx, request)
     elif method == "unloadPlugin":
         return await unload_plugin(ctx, request)
+    elif method == "onQueryVariable":
+        return await on_query_variable(ctx, request)
     else:
         await logger.info(ctx.get_trace_id(), f"unknown method handler: {method}")
         raise Exception(f"unknown method handler: {method}")
@@ -129,7 +138,7 @@ async def init_plugin(ctx: Context, request: Dict[str, Any], ws: websockets.asyn
         raise e
 
 
-async def query(ctx: Context, request: Dict[str, Any]) -> list[dict[str, Any]]:
+async def query(ctx: Context, request: Dict[str, Any], ws: websockets.asyncio.server.ServerConnection) -> list[dict[str, Any]]:
     """Handle query request"""
     plugin_id = request.get("PluginId", "")
     plugin_name = request.get("PluginName", "")
@@ -143,52 +152,28 @@ async def query(ctx: Context, request: Dict[str, Any]) -> list[dict[str, Any]]:
         plugin_instance.refreshes.clear()
 
         params: Dict[str, str] = request.get("Params", {})
-        results = await plugin_instance.plugin.query(ctx, Query.from_json(json.dumps(params)))
-
-        # Ensure each result has an ID and cache actions and refreshes
-        if results:
-            for result in results:
-                if not result.id:
-                    result.id = str(uuid.uuid4())
-                if result.actions:
-                    for action in result.actions:
-                        if action.action:
-                            if not action.id:
-                                action.id = str(uuid.uuid4())
-                            # Cache action
-                            plugin_instance.actions[action.id] = action.action
-                # Cache refresh callback if exists
-                if result.refresh_interval and result.refresh_interval > 0 and result.on_refresh:
-                    plugin_instance.refreshes[result.id] = result.on_refresh
-
-        # to avoid json serialization error, convert Result to dict and omit functions
-        return [
-            {
-                "Id": result.id,
-                "Title": result.title,
-                "SubTitle": result.sub_title,
-                "Icon": json.loads(result.icon.to_json()),
-                "Actions": [
+        query_result = plugin_instance.plugin.query(ctx, Query.from_json(json.dumps(params)))
+        if not inspect.isasyncgen(query_result):
+            return prepare_results(plugin_instance, await query_result)
+
+        # plugin yields results in batches, stream every batch to Wox if it's supported, otherwise return them all at once
+        collected_results: list[dict[str, Any]] = []
+        async for batch in query_result:
+            results = prepare_results(plugin_instance, batch)
+            if has_capability(CAPABILITY_STREAM_RESULTS):
+                await send_message(
+                    ws,
                     {
-                        "Id": action.id,
-                        "Name": action.name,
-                        "Icon": json.loads(action.icon.to_json()),
-                        "IsDefault": action.is_default,
-                        "PreventHideAfterAction": action.prevent_hide_after_action,
-                        "Hotkey": action.hotkey,
-                    }
-                    for action in result.actions
-                ],
-                "Preview": result.preview,
-                "Score": result.score,
-                "Group": result.group,
-                "GroupScore": result.group_score,
-                "Tails": [json.loads(tail.to_json()) for tail in result.tails],
-                "ContextData": result.context_data,
-                "RefreshInterval": result.refresh_interval,
-            }
-            for result in results
-        ]
+                        "TraceId": request.get("TraceId", ""),
+                        "Id": request.get("Id", ""),
+                        "Method": request.get("Method", ""),
+                        "Type": PLUGIN_JSONRPC_TYPE_STREAM,
+                        "Result": clean_for_serialization(results),
+                    },
+                )
+            else:
+                collected_results.extend(results)
+        return collected_results
     except Exception as e:
         error_stack = traceback.format_exc()
         await logger.error(
@@ -317,3 +302,20 @@ async def unload_plugin(ctx: Context, request: Dict[str, Any]) -> None:
             f"<{plugin_name}> unload plugin failed: {str(e)}\nStack trace:\n{error_stack}",
         )
         raise e
+
+
+async def on_query_variable(ctx: Context, request: Dict[str, Any]) -> str:
+    """Resolve a query variable registered by plugin"""
+    plugin_id = request.get("PluginId", "")
+    plugin_name = request.get("PluginName", "")
+    plugin_instance = plugin_instances.get(plugin_id)
+    if not plugin_instance or not isinstance(plugin_instance.api, PluginAPI):
+        raise Exception(f"plugin not found: {plugin_name}, forget to load plugin?")
+
+    params: Dict[str, str] = request.get("Params", {})
+    callback_id = params.get("CallbackId", "")
+    resolver = plugin_instance.api.query_variable_callbacks.get(callback_id)
+    if not resolver:
+        raise Exception(f"query variable callback not found: {callback_id}")
+
+    return await resolver(params.get("Arg", ""))
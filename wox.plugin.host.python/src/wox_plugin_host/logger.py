@@ -1,7 +1,7 @@
-import json
 from typing import Optional
 from loguru import logger
 from websockets.asyncio.server import ServerConnection
+from .protocol import send_message
 
 PLUGIN_JSONRPC_TYPE_SYSTEM_LOG = "WOX_JSONRPC_SYSTEM_LOG"
 websocket: Optional[ServerConnection] = None
@@ -25,15 +25,14 @@ async def log(trace_id: str, level: str, msg: str):
 
     if websocket:
         try:
-            await websocket.send(
-                json.dumps(
-                    {
-                        "Type": PLUGIN_JSONRPC_TYPE_SYSTEM_LOG,
-                        "TraceId": trace_id,
-                        "Level": level,
-                        "Message": msg,
-                    }
-                )
+            await send_message(
+                websocket,
+                {
+                    "Type": PLUGIN_JSONRPC_TYPE_SYSTEM_LOG,
+                    "TraceId": trace_id,
+                    "Level": level,
+                    "Message": msg,
+                },
             )
         except Exception as e:
             logger.error(f"Failed to send log message through websocket: {e}")
@@ -1,7 +1,7 @@
 import asyncio
 import json
 import uuid
-from typing import Any, Dict, Callable
+from typing import Any, Awaitable, Dict, Callable
 import websockets
 from . import logger
 from wox_plugin import (
@@ -9,12 +9,14 @@ from wox_plugin import (
     PublicAPI,
     ChangeQueryParam,
     MetadataCommand,
+    Result,
     Conversation,
     AIModel,
     ChatStreamCallback,
 )
 from .constants import PLUGIN_JSONRPC_TYPE_REQUEST
-from .plugin_manager import waiting_for_response
+from .plugin_manager import plugin_instances, prepare_results, waiting_for_response
+from .protocol import clean_for_serialization, send_message
 
 
 class PluginAPI(PublicAPI):
@@ -27,6 +29,7 @@ class PluginAPI(PublicAPI):
         self.deep_link_callbacks: Dict[str, Callable[[Dict[str, str]], None]] = {}
         self.unload_callbacks: Dict[str, Callable[[], None]] = {}
         self.llm_stream_callbacks: Dict[str, ChatStreamCallback] = {}
+        self.query_variable_callbacks: Dict[str, Callable[[str], Awaitable[str]]] = {}
 
     async def invoke_method(self, ctx: Context, method: str, params: Dict[str, Any]) -> Any:
         """Invoke a method on Wox"""
@@ -49,7 +52,7 @@ class PluginAPI(PublicAPI):
             "PluginName": self.plugin_name,
         }
 
-        await self.ws.send(json.dumps(request))
+        await send_message(self.ws, request)
 
         # Create a Future to wait for the response
         future: asyncio.Future[Any] = asyncio.Future()
@@ -136,6 +139,29 @@ class PluginAPI(PublicAPI):
             {"commands": json.dumps([command.__dict__ for command in commands])},
         )
 
+    async def push_results(self, ctx: Context, query_id: str, results: list[Result]) -> None:
+        """Push a batch of results to a running query before query returns"""
+        plugin_instance = plugin_instances.get(self.plugin_id)
+        if not plugin_instance:
+            raise Exception(f"plugin not found: {self.plugin_name}, forget to load plugin?")
+
+        pushed_results = prepare_results(plugin_instance, results)
+        await self.invoke_method(
+            ctx,
+            "PushResults",
+            {"queryId": query_id, "results": json.dumps(clean_for_serialization(pushed_results))},
+        )
+
+    async def register_query_variable(self, ctx: Context, name: str, description: str, resolver: Callable[[str], Awaitable[str]]) -> None:
+        """Register a {wox:<name>} query variable"""
+        callback_id = str(uuid.uuid4())
+        self.query_variable_callbacks[callback_id] = resolver
+        await self.invoke_method(
+            ctx,
+            "RegisterQueryVariable",
+            {"callbackId": callback_id, "name": name, "description": description},
+        )
+
     async def ai_chat_stream(
         self,
         ctx: Context,
@@ -1,7 +1,9 @@
 from typing import Dict, Any, Callable, Optional, Awaitable
 from dataclasses import dataclass
 import asyncio
-from wox_plugin import PublicAPI, Plugin, RefreshableResult, ActionContext
+import json
+import uuid
+from wox_plugin import PublicAPI, Plugin, RefreshableResult, ActionContext, Result
 
 
 @dataclass
@@ -16,3 +18,51 @@ class PluginInstance:
 # Global state with strong typing
 plugin_instances: Dict[str, PluginInstance] = {}
 waiting_for_response: Dict[str, asyncio.Future[Any]] = {}
+
+
+def prepare_results(plugin_instance: PluginInstance, results: list[Result]) -> list[dict[str, Any]]:
+    """Cache actions and refreshes of results, then convert them to dict"""
+    # Ensure each result has an ID and cache actions and refreshes
+    if results:
+        for result in results:
+            if not result.id:
+                result.id = str(uuid.uuid4())
+            if result.actions:
+                for action in result.actions:
+                    if action.action:
+                        if not action.id:
+                            action.id = str(uuid.uuid4())
+                        # Cache action
+                        plugin_instance.actions[action.id] = action.action
+            # Cache refresh callback if exists
+            if result.refresh_interval and result.refresh_interval > 0 and result.on_refresh:
+                plugin_instance.refreshes[result.id] = result.on_refresh
+
+    # to avoid json serialization error, convert Result to dict and omit functions
+    return [
+        {
+            "Id": result.id,
+            "Title": result.title,
+            "SubTitle": result.sub_title,
+            "Icon": json.loads(result.icon.to_json()),
+            "Actions": [
+                {
+                    "Id": action.id,
+                    "Name": action.name,
+                    "Icon": json.loads(action.icon.to_json()),
+                    "IsDefault": action.is_default,
+                    "PreventHideAfterAction": action.prevent_hide_after_action,
+                    "Hotkey": action.hotkey,
+                }
+                for action in result.actions
+            ],
+            "Preview": result.preview,
+            "Score": result.score,
+            "Group": result.group,
+            "GroupScore": result.group_score,
+            "Tails": [json.loads(tail.to_json()) for tail in result.tails],
+            "ContextData": result.context_data,
+            "RefreshInterval": result.refresh_interval,
+        }
+        for result in results
+    ]
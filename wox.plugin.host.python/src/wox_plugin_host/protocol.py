@@ -0,0 +1,100 @@
+"""Host protocol negotiated with Wox in handshake, see wox.core/plugin/host/jsonrpc.go"""
+
+import json
+from typing import Any, Dict, Optional, Union
+from websockets.asyncio.server import ServerConnection
+
+try:
+    import msgpack
+except ImportError:
+    msgpack = None
+
+PROTOCOL_VERSION = 2
+ENCODING_JSON = "json"
+ENCODING_MSGPACK = "msgpack"
+CAPABILITY_STREAM_RESULTS = "streamResults"
+
+SUPPORTED_CAPABILITIES = [CAPABILITY_STREAM_RESULTS]
+
+encoding = ENCODING_JSON
+capabilities: list[str] = []
+
+
+def supported_encodings() -> list[str]:
+    """msgpack is optional, only advertise it when the package is importable"""
+    if msgpack is not None:
+        return [ENCODING_MSGPACK, ENCODING_JSON]
+    return [ENCODING_JSON]
+
+
+def handshake(params: Dict[str, str]) -> Dict[str, Any]:
+    """Pick protocol version, encoding and capabilities from what Wox offered"""
+    global encoding, capabilities
+
+    wox_version = int(params.get("ProtocolVersion", "1"))
+    wox_encodings = params.get("Encodings", "").split(",")
+    wox_capabilities = params.get("Capabilities", "").split(",")
+
+    # Wox lists encodings by preference, pick the first one we support
+    encoding = next((e for e in wox_encodings if e in supported_encodings()), ENCODING_JSON)
+    capabilities = [c for c in SUPPORTED_CAPABILITIES if c in wox_capabilities]
+
+    return {
+        "ProtocolVersion": min(wox_version, PROTOCOL_VERSION),
+        "Encoding": encoding,
+        "Capabilities": capabilities,
+    }
+
+
+def has_capability(capability: str) -> bool:
+    return capability in capabilities
+
+
+def clean_for_serialization(obj):
+    """Remove non-serializable properties from any object recursively"""
+    if obj is None:
+        return obj
+
+    if isinstance(obj, (str, int, float, bool)):
+        return obj
+
+    if isinstance(obj, (list, tuple)):
+        return [clean_for_serialization(item) for item in obj]
+
+    if isinstance(obj, dict):
+        return {k: clean_for_serialization(v) for k, v in obj.items()}
+
+    # Handle custom objects
+    if hasattr(obj, "__dict__"):
+        # Create a copy of the object's dict
+        obj_dict = obj.__dict__.copy()
+
+        # Remove callable (methods/functions) and handle nested objects
+        cleaned_dict = {}
+        for k, v in obj_dict.items():
+            if callable(v):
+                continue
+            cleaned_dict[k] = clean_for_serialization(v)
+
+        return cleaned_dict
+
+    # If we can't handle it, just return None
+    return None
+
+
+def decode_message(message: Union[str, bytes]) -> Dict[str, Any]:
+    """Messages are decoded by frame type, binary frames are always msgpack"""
+    if isinstance(message, bytes):
+        if msgpack is None:
+            raise Exception("received msgpack message but msgpack is not installed")
+        return msgpack.unpackb(message)
+    return json.loads(message)
+
+
+async def send_message(ws: Optional[ServerConnection], message: Dict[str, Any]) -> None:
+    if ws is None:
+        return
+    if encoding == ENCODING_MSGPACK and msgpack is not None:
+        await ws.send(msgpack.packb(message))
+    else:
+        await ws.send(json.dumps(message))
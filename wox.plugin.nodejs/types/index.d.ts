@@ -35,6 +35,10 @@ export interface QueryEnv {
 }
 
 export interface Query {
+  /**
+   * Unique id of this query, use it to push results with PublicAPI.PushResults
+   */
+  Id: string
   /**
    *  By default, Wox will only pass input query to plugin.
    *  plugin author need to enable MetadataFeatureQuerySelection feature to handle selection query
@@ -233,6 +237,19 @@ export interface PublicAPI {
    */
   RegisterQueryCommands: (ctx: Context, commands: MetadataCommand[]) => Promise<void>
 
+  /**
+   * Register a query variable which can be used as {wox:<name>} or {wox:<name>:<arg>} in query hotkeys, query shortcuts, web search urls and AI command prompts
+   *
+   * @resolver Returns the value of the variable, arg is the text after "{wox:<name>:", empty if not given
+   */
+  RegisterQueryVariable: (ctx: Context, name: string, description: string, resolver: (arg: string) => Promise<string> | string) => Promise<void>
+
+  /**
+   * Push a batch of results to a running query before query returns, so slow plugins can show their first results immediately.
+   * It throws if the query is already finished
+   */
+  PushResults: (ctx: Context, queryId: string, results: Result[]) => Promise<void>
+
   /**
    * Chat using LLM
    */
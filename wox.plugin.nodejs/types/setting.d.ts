@@ -1,6 +1,19 @@
 import { Context, Platform } from "./index.js"
 
-export type PluginSettingDefinitionType = "head" | "textbox" | "checkbox" | "select" | "label" | "newline" | "table" | "dynamic"
+export type PluginSettingDefinitionType =
+  | "head"
+  | "textbox"
+  | "checkbox"
+  | "select"
+  | "label"
+  | "newline"
+  | "table"
+  | "dynamic"
+  | "number"
+  | "password"
+  | "hotkey"
+  | "filepath"
+  | "color"
 
 
 export interface PluginSettingValueStyle {
@@ -24,6 +37,14 @@ export interface PluginSettingDefinitionItem {
   Value: PluginSettingDefinitionValue
   DisabledInPlatforms: Platform[]
   IsPlatformSpecific: boolean // if true, this setting may be different in different platforms
+  ShowWhen?: PluginSettingCondition // if set, this setting is only shown when the condition is satisfied
+  IsSecret?: boolean // if true, value is kept in secret store instead of plugin setting file, password setting is always secret
+}
+
+// satisfied when the value of setting Key is one of Values
+export interface PluginSettingCondition {
+  Key: string
+  Values: string[]
 }
 
 export interface MetadataCommand {
@@ -76,7 +97,57 @@ export interface PluginSettingValueSelectOption {
   Value: string
 }
 
-export type PluginSettingValidatorType = "is_number" | "not_empty"
+export interface PluginSettingValueNumber extends PluginSettingDefinitionValue {
+  Key: string
+  Label: string
+  Suffix: string
+  DefaultValue: string
+  Tooltip: string
+  Min?: number
+  Max?: number
+  Step: number
+  IsInteger: boolean
+  Validators: PluginSettingValidator[]
+  Style: PluginSettingValueStyle
+}
+
+export interface PluginSettingValuePassword extends PluginSettingDefinitionValue {
+  Key: string
+  Label: string
+  Suffix: string
+  Tooltip: string
+  Validators: PluginSettingValidator[]
+  Style: PluginSettingValueStyle
+}
+
+export interface PluginSettingValueHotkey extends PluginSettingDefinitionValue {
+  Key: string
+  Label: string
+  DefaultValue: string
+  Tooltip: string
+  Style: PluginSettingValueStyle
+}
+
+export interface PluginSettingValueFilePath extends PluginSettingDefinitionValue {
+  Key: string
+  Label: string
+  DefaultValue: string
+  Tooltip: string
+  IsDirectory: boolean
+  Extensions: string[]
+  Validators: PluginSettingValidator[]
+  Style: PluginSettingValueStyle
+}
+
+export interface PluginSettingValueColor extends PluginSettingDefinitionValue {
+  Key: string
+  Label: string
+  DefaultValue: string // #RRGGBB or #AARRGGBB
+  Tooltip: string
+  Style: PluginSettingValueStyle
+}
+
+export type PluginSettingValidatorType = "is_number" | "not_empty" | "regex" | "min" | "max" | "length" | "url" | "path_exists"
 
 export interface PluginSettingValidator {
   Type: PluginSettingValidatorType
@@ -95,3 +166,27 @@ export interface PluginSettingValidatorIsNumber extends PluginSettingValidatorVa
 export interface PluginSettingValidatorNotEmpty extends PluginSettingValidatorValue {
 
 }
+
+export interface PluginSettingValidatorRegex extends PluginSettingValidatorValue {
+  Pattern: string
+  Message: string
+}
+
+export interface PluginSettingValidatorMin extends PluginSettingValidatorValue {
+  Min: number
+}
+
+export interface PluginSettingValidatorMax extends PluginSettingValidatorValue {
+  Max: number
+}
+
+export interface PluginSettingValidatorLength extends PluginSettingValidatorValue {
+  Min: number
+  Max: number
+}
+
+export interface PluginSettingValidatorUrl extends PluginSettingValidatorValue {}
+
+export interface PluginSettingValidatorPathExists extends PluginSettingValidatorValue {
+  IsDirectory: boolean
+}
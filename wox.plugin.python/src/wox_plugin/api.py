@@ -1,9 +1,10 @@
-from typing import Protocol, Callable, Dict, List
+from typing import Protocol, Awaitable, Callable, Dict, List
 
 from .models.query import MetadataCommand
 from .models.context import Context
 from .models.query import ChangeQueryParam
 from .models.ai import AIModel, Conversation, ChatStreamCallback
+from .models.result import Result
 
 
 class PublicAPI(Protocol):
@@ -61,6 +62,22 @@ class PublicAPI(Protocol):
         """Register query commands"""
         ...
 
+    async def push_results(self, ctx: Context, query_id: str, results: List[Result]) -> None:
+        """
+        Push a batch of results to a running query before query returns,
+        so slow plugins can show their first results immediately.
+        It raises if the query is already finished.
+        """
+        ...
+
+    async def register_query_variable(self, ctx: Context, name: str, description: str, resolver: Callable[[str], Awaitable[str]]) -> None:
+        """
+        Register a query variable which can be used as {wox:<name>} or {wox:<name>:<arg>}
+        in query hotkeys, query shortcuts, web search urls and AI command prompts.
+        The resolver receives the arg (empty if not given) and returns the variable value.
+        """
+        ...
+
     async def ai_chat_stream(
         self,
         ctx: Context,
@@ -137,11 +137,14 @@ class Query:
     trigger_keyword: str = field(default="")
     command: str = field(default="")
     search: str = field(default="")
+    id: str = field(default="")
+    """Unique id of this query, use it to push results with PublicAPI.push_results"""
 
     def to_json(self) -> str:
         """Convert to JSON string with camelCase naming"""
         return json.dumps(
             {
+                "Id": self.id,
                 "Type": self.type,
                 "RawQuery": self.raw_query,
                 "Selection": json.loads(self.selection.to_json()),
@@ -168,6 +171,7 @@ class Query:
             trigger_keyword=data.get("TriggerKeyword", ""),
             command=data.get("Command", ""),
             search=data.get("Search", ""),
+            id=data.get("Id", ""),
         )
 
     def is_global_query(self) -> bool:
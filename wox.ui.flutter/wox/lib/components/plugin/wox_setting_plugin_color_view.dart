@@ -0,0 +1,66 @@
+import 'package:fluent_ui/fluent_ui.dart';
+import 'package:wox/components/wox_tooltip_view.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_color.dart';
+
+import 'wox_setting_plugin_item_view.dart';
+
+class WoxSettingPluginColor extends WoxSettingPluginItem {
+  final PluginSettingValueColor item;
+  final controller = TextEditingController();
+
+  WoxSettingPluginColor({super.key, required this.item, required super.value, required super.onUpdate}) {
+    controller.text = getSetting(item.key);
+  }
+
+  // parse #RRGGBB or #AARRGGBB, return null if invalid
+  Color? parseColor(String value) {
+    if (!RegExp(r'^#([0-9a-fA-F]{6}|[0-9a-fA-F]{8})$').hasMatch(value)) {
+      return null;
+    }
+
+    var hex = value.substring(1);
+    if (hex.length == 6) {
+      hex = "FF$hex";
+    }
+    return Color(int.parse(hex, radix: 16));
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return layout(
+      children: [
+        label(item.label, item.style),
+        if (item.tooltip != "") WoxTooltipView(tooltip: item.tooltip, paddingLeft: 0),
+        Container(
+          width: 20,
+          height: 20,
+          margin: const EdgeInsets.only(right: 4),
+          decoration: BoxDecoration(
+            color: parseColor(controller.text) ?? Colors.transparent,
+            border: Border.all(color: Colors.grey),
+          ),
+        ),
+        SizedBox(
+          width: item.style.width > 0 ? item.style.width.toDouble() : 100,
+          child: Focus(
+            onFocusChange: (hasFocus) {
+              if (!hasFocus) {
+                if (controller.text != "" && parseColor(controller.text) == null) {
+                  item.tooltip = "Color must be #RRGGBB or #AARRGGBB";
+                  return;
+                }
+
+                updateConfig(item.key, controller.text);
+              }
+            },
+            child: TextBox(
+              controller: controller,
+              placeholder: "#RRGGBB",
+            ),
+          ),
+        ),
+      ],
+      style: item.style,
+    );
+  }
+}
@@ -0,0 +1,65 @@
+import 'package:fluent_ui/fluent_ui.dart';
+import 'package:uuid/v4.dart';
+import 'package:wox/components/wox_tooltip_view.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_filepath.dart';
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+import 'package:wox/utils/picker.dart';
+
+import 'wox_setting_plugin_item_view.dart';
+
+class WoxSettingPluginFilePath extends WoxSettingPluginItem {
+  final PluginSettingValueFilePath item;
+  final controller = TextEditingController();
+
+  WoxSettingPluginFilePath({super.key, required this.item, required super.value, required super.onUpdate}) {
+    controller.text = getSetting(item.key);
+  }
+
+  void save(String path) {
+    var errMsg = PluginSettingValidatorItem.validateAll(item.validators, path);
+    item.tooltip = errMsg;
+    if (errMsg != "") {
+      return;
+    }
+
+    updateConfig(item.key, path);
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return layout(
+      children: [
+        label(item.label, item.style),
+        if (item.tooltip != "") WoxTooltipView(tooltip: item.tooltip, paddingLeft: 0),
+        SizedBox(
+          width: item.style.width > 0 ? item.style.width.toDouble() : 300,
+          child: Focus(
+            onFocusChange: (hasFocus) {
+              if (!hasFocus) {
+                save(controller.text);
+              }
+            },
+            child: TextBox(
+              controller: controller,
+              suffixMode: OverlayVisibilityMode.always,
+              suffix: Button(
+                onPressed: () async {
+                  final selected = await FileSelector.pick(
+                    const UuidV4().generate(),
+                    FileSelectorParams(isDirectory: item.isDirectory, extensions: item.extensions),
+                  );
+                  if (selected.isNotEmpty) {
+                    controller.text = selected[0];
+                    save(selected[0]);
+                  }
+                },
+                child: const Text('Browse'),
+              ),
+            ),
+          ),
+        ),
+      ],
+      style: item.style,
+    );
+  }
+}
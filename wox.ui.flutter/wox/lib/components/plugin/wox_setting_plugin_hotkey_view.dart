@@ -0,0 +1,30 @@
+import 'package:fluent_ui/fluent_ui.dart';
+import 'package:wox/components/wox_hotkey_recorder_view.dart';
+import 'package:wox/components/wox_tooltip_view.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_hotkey.dart';
+import 'package:wox/entity/wox_hotkey.dart';
+
+import 'wox_setting_plugin_item_view.dart';
+
+class WoxSettingPluginHotkey extends WoxSettingPluginItem {
+  final PluginSettingValueHotkey item;
+
+  const WoxSettingPluginHotkey({super.key, required this.item, required super.value, required super.onUpdate});
+
+  @override
+  Widget build(BuildContext context) {
+    return layout(
+      children: [
+        label(item.label, item.style),
+        if (item.tooltip != "") WoxTooltipView(tooltip: item.tooltip, paddingLeft: 0),
+        WoxHotkeyRecorder(
+          hotkey: WoxHotkey.parseHotkeyFromString(getSetting(item.key)),
+          onHotKeyRecorded: (hotkey) {
+            updateConfig(item.key, hotkey);
+          },
+        ),
+      ],
+      style: item.style,
+    );
+  }
+}
@@ -0,0 +1,83 @@
+import 'package:fluent_ui/fluent_ui.dart';
+import 'package:wox/components/wox_tooltip_view.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_number.dart';
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+import 'wox_setting_plugin_item_view.dart';
+
+class WoxSettingPluginNumber extends WoxSettingPluginItem {
+  final PluginSettingValueNumber item;
+  final controller = TextEditingController();
+
+  WoxSettingPluginNumber({super.key, required this.item, required super.value, required super.onUpdate}) {
+    controller.text = getSetting(item.key);
+  }
+
+  String validate(String value) {
+    if (value.trim().isNotEmpty) {
+      var number = double.tryParse(value.trim());
+      if (number == null) {
+        return "Value must be a number";
+      }
+      if (item.isInteger && number != number.truncateToDouble()) {
+        return "Value must be an integer";
+      }
+      if (item.min != null && number < item.min!) {
+        return "Value must be greater than or equal to ${item.min}";
+      }
+      if (item.max != null && number > item.max!) {
+        return "Value must be less than or equal to ${item.max}";
+      }
+    }
+
+    return PluginSettingValidatorItem.validateAll(item.validators, value);
+  }
+
+  void step(double direction) {
+    var number = double.tryParse(controller.text.trim()) ?? item.min ?? 0;
+    number += direction * item.step;
+    if (item.min != null && number < item.min!) number = item.min!;
+    if (item.max != null && number > item.max!) number = item.max!;
+    controller.text = item.isInteger ? number.toInt().toString() : number.toString();
+    updateConfig(item.key, controller.text);
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return layout(
+      children: [
+        label(item.label, item.style),
+        if (item.tooltip != "") WoxTooltipView(tooltip: item.tooltip, paddingLeft: 0),
+        SizedBox(
+          width: item.style.width > 0 ? item.style.width.toDouble() : 100,
+          child: Focus(
+            onFocusChange: (hasFocus) {
+              if (!hasFocus) {
+                var errMsg = validate(controller.text);
+                item.tooltip = errMsg;
+                if (errMsg != "") {
+                  return;
+                }
+
+                updateConfig(item.key, controller.text);
+              }
+            },
+            child: TextBox(
+              controller: controller,
+              suffixMode: OverlayVisibilityMode.always,
+              suffix: Row(
+                mainAxisSize: MainAxisSize.min,
+                children: [
+                  IconButton(icon: const Icon(FluentIcons.chevron_down_small), onPressed: () => step(-1)),
+                  IconButton(icon: const Icon(FluentIcons.chevron_up_small), onPressed: () => step(1)),
+                ],
+              ),
+            ),
+          ),
+        ),
+        suffix(item.suffix),
+      ],
+      style: item.style,
+    );
+  }
+}
@@ -0,0 +1,48 @@
+import 'package:fluent_ui/fluent_ui.dart';
+import 'package:wox/components/wox_tooltip_view.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_password.dart';
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+import 'wox_setting_plugin_item_view.dart';
+
+class WoxSettingPluginPassword extends WoxSettingPluginItem {
+  final PluginSettingValuePassword item;
+  final controller = TextEditingController();
+
+  WoxSettingPluginPassword({super.key, required this.item, required super.value, required super.onUpdate}) {
+    controller.text = getSetting(item.key);
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return layout(
+      children: [
+        label(item.label, item.style),
+        if (item.tooltip != "") WoxTooltipView(tooltip: item.tooltip, paddingLeft: 0),
+        SizedBox(
+          width: item.style.width > 0 ? item.style.width.toDouble() : 100,
+          child: Focus(
+            onFocusChange: (hasFocus) {
+              // mask means the saved password is not changed
+              if (!hasFocus && controller.text != PluginSettingValuePassword.mask) {
+                var errMsg = PluginSettingValidatorItem.validateAll(item.validators, controller.text);
+                item.tooltip = errMsg;
+                if (errMsg != "") {
+                  return;
+                }
+
+                updateConfig(item.key, controller.text);
+              }
+            },
+            child: TextBox(
+              controller: controller,
+              obscureText: true,
+            ),
+          ),
+        ),
+        suffix(item.suffix),
+      ],
+      style: item.style,
+    );
+  }
+}
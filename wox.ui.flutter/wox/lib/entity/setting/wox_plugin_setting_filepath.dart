@@ -0,0 +1,41 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+import '../wox_plugin_setting.dart';
+
+class PluginSettingValueFilePath {
+  late String key;
+  late String label;
+  late String defaultValue;
+  late String tooltip;
+  late bool isDirectory;
+  late List<String> extensions;
+  late List<PluginSettingValidatorItem> validators;
+
+  late PluginSettingValueStyle style;
+
+  PluginSettingValueFilePath.fromJson(Map<String, dynamic> json) {
+    key = json['Key'];
+    label = json['Label'] ?? "";
+    defaultValue = json['DefaultValue'] ?? "";
+    tooltip = json['Tooltip'] ?? "";
+    isDirectory = json['IsDirectory'] ?? false;
+
+    if (json['Extensions'] != null) {
+      extensions = (json['Extensions'] as List).map((e) => e.toString()).toList();
+    } else {
+      extensions = [];
+    }
+
+    if (json['Style'] != null) {
+      style = PluginSettingValueStyle.fromJson(json['Style']);
+    } else {
+      style = PluginSettingValueStyle.fromJson(<String, dynamic>{});
+    }
+
+    if (json['Validators'] != null) {
+      validators = (json['Validators'] as List).map((e) => PluginSettingValidatorItem.fromJson(e)).toList();
+    } else {
+      validators = [];
+    }
+  }
+}
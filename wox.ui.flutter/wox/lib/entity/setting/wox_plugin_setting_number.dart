@@ -0,0 +1,42 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+import '../wox_plugin_setting.dart';
+
+class PluginSettingValueNumber {
+  late String key;
+  late String label;
+  late String suffix;
+  late String defaultValue;
+  late String tooltip;
+  double? min;
+  double? max;
+  late double step;
+  late bool isInteger;
+  late List<PluginSettingValidatorItem> validators;
+
+  late PluginSettingValueStyle style;
+
+  PluginSettingValueNumber.fromJson(Map<String, dynamic> json) {
+    key = json['Key'];
+    label = json['Label'] ?? "";
+    suffix = json['Suffix'] ?? "";
+    defaultValue = json['DefaultValue'] ?? "";
+    tooltip = json['Tooltip'] ?? "";
+    min = json['Min'] == null ? null : (json['Min'] as num).toDouble();
+    max = json['Max'] == null ? null : (json['Max'] as num).toDouble();
+    step = json['Step'] == null || json['Step'] == 0 ? 1 : (json['Step'] as num).toDouble();
+    isInteger = json['IsInteger'] ?? false;
+
+    if (json['Style'] != null) {
+      style = PluginSettingValueStyle.fromJson(json['Style']);
+    } else {
+      style = PluginSettingValueStyle.fromJson(<String, dynamic>{});
+    }
+
+    if (json['Validators'] != null) {
+      validators = (json['Validators'] as List).map((e) => PluginSettingValidatorItem.fromJson(e)).toList();
+    } else {
+      validators = [];
+    }
+  }
+}
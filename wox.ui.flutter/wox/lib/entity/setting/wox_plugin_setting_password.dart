@@ -0,0 +1,35 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+import '../wox_plugin_setting.dart';
+
+class PluginSettingValuePassword {
+  // wox core sends this mask instead of the saved password
+  static const String mask = "********";
+
+  late String key;
+  late String label;
+  late String suffix;
+  late String tooltip;
+  late List<PluginSettingValidatorItem> validators;
+
+  late PluginSettingValueStyle style;
+
+  PluginSettingValuePassword.fromJson(Map<String, dynamic> json) {
+    key = json['Key'];
+    label = json['Label'] ?? "";
+    suffix = json['Suffix'] ?? "";
+    tooltip = json['Tooltip'] ?? "";
+
+    if (json['Style'] != null) {
+      style = PluginSettingValueStyle.fromJson(json['Style']);
+    } else {
+      style = PluginSettingValueStyle.fromJson(<String, dynamic>{});
+    }
+
+    if (json['Validators'] != null) {
+      validators = (json['Validators'] as List).map((e) => PluginSettingValidatorItem.fromJson(e)).toList();
+    } else {
+      validators = [];
+    }
+  }
+}
@@ -1,5 +1,9 @@
 import 'wox_setting_validator_is_number.dart';
+import 'wox_setting_validator_length.dart';
+import 'wox_setting_validator_min_max.dart';
 import 'wox_setting_validator_not_empty.dart';
+import 'wox_setting_validator_regex.dart';
+import 'wox_setting_validator_url.dart';
 
 interface class PluginSettingValidator {
   validate(dynamic value) => String; //if validate success return empty string, else return error message
@@ -15,6 +19,40 @@ class PluginSettingValidatorItem {
       validator = PluginSettingValidatorNotEmpty.fromJson(<String, dynamic>{});
     } else if (type == "is_number") {
       validator = PluginSettingValidatorIsNumber.fromJson(json["Value"]);
+    } else if (type == "regex") {
+      validator = PluginSettingValidatorRegex.fromJson(json["Value"]);
+    } else if (type == "min") {
+      validator = PluginSettingValidatorMin.fromJson(json["Value"]);
+    } else if (type == "max") {
+      validator = PluginSettingValidatorMax.fromJson(json["Value"]);
+    } else if (type == "length") {
+      validator = PluginSettingValidatorLength.fromJson(json["Value"]);
+    } else if (type == "url") {
+      validator = PluginSettingValidatorUrl.fromJson(<String, dynamic>{});
+    } else {
+      // e.g. path_exists, only validated by wox core
+      validator = PluginSettingValidatorServerSide();
     }
   }
+
+  // validate all validators, empty value is only checked by not_empty, same as wox core
+  static String validateAll(List<PluginSettingValidatorItem> validators, String value) {
+    for (var element in validators) {
+      if (value.trim().isEmpty && element.type != "not_empty") {
+        continue;
+      }
+      var errMsg = element.validator.validate(value);
+      if (errMsg != "") {
+        return errMsg;
+      }
+    }
+    return "";
+  }
+}
+
+class PluginSettingValidatorServerSide implements PluginSettingValidator {
+  @override
+  String validate(dynamic value) {
+    return "";
+  }
 }
@@ -0,0 +1,27 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+class PluginSettingValidatorLength implements PluginSettingValidator {
+  late int min;
+  late int max;
+
+  @override
+  String validate(dynamic value) {
+    if (value is! String) {
+      return "invalid value";
+    }
+
+    var length = value.runes.length;
+    if (length < min) {
+      return "Length must be at least $min";
+    }
+    if (max > 0 && length > max) {
+      return "Length must be at most $max";
+    }
+    return "";
+  }
+
+  PluginSettingValidatorLength.fromJson(Map<String, dynamic> json) {
+    min = json['Min'] ?? 0;
+    max = json['Max'] ?? 0;
+  }
+}
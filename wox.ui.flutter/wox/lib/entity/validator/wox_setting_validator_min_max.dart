@@ -0,0 +1,41 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+class PluginSettingValidatorMin implements PluginSettingValidator {
+  late double min;
+
+  @override
+  String validate(dynamic value) {
+    var number = double.tryParse(value.toString().trim());
+    if (number == null) {
+      return "Value must be a number";
+    }
+    if (number < min) {
+      return "Value must be greater than or equal to $min";
+    }
+    return "";
+  }
+
+  PluginSettingValidatorMin.fromJson(Map<String, dynamic> json) {
+    min = (json['Min'] as num).toDouble();
+  }
+}
+
+class PluginSettingValidatorMax implements PluginSettingValidator {
+  late double max;
+
+  @override
+  String validate(dynamic value) {
+    var number = double.tryParse(value.toString().trim());
+    if (number == null) {
+      return "Value must be a number";
+    }
+    if (number > max) {
+      return "Value must be less than or equal to $max";
+    }
+    return "";
+  }
+
+  PluginSettingValidatorMax.fromJson(Map<String, dynamic> json) {
+    max = (json['Max'] as num).toDouble();
+  }
+}
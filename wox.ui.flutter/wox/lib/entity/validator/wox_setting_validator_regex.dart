@@ -0,0 +1,23 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+class PluginSettingValidatorRegex implements PluginSettingValidator {
+  late String pattern;
+  late String message;
+
+  @override
+  String validate(dynamic value) {
+    if (value is! String) {
+      return "invalid value";
+    }
+
+    if (!RegExp(pattern).hasMatch(value)) {
+      return message != "" ? message : "Value doesn't match pattern $pattern";
+    }
+    return "";
+  }
+
+  PluginSettingValidatorRegex.fromJson(Map<String, dynamic> json) {
+    pattern = json['Pattern'] ?? "";
+    message = json['Message'] ?? "";
+  }
+}
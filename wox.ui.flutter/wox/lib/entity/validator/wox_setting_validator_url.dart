@@ -0,0 +1,14 @@
+import 'package:wox/entity/validator/wox_setting_validator.dart';
+
+class PluginSettingValidatorUrl implements PluginSettingValidator {
+  @override
+  String validate(dynamic value) {
+    var uri = Uri.tryParse(value.toString().trim());
+    if (uri == null || uri.scheme == "" || uri.host == "") {
+      return "Value must be a valid url";
+    }
+    return "";
+  }
+
+  PluginSettingValidatorUrl.fromJson(Map<String, dynamic> json);
+}
@@ -1,9 +1,14 @@
 import 'package:wox/entity/setting/wox_plugin_setting_table.dart';
 
 import 'setting/wox_plugin_setting_checkbox.dart';
+import 'setting/wox_plugin_setting_color.dart';
+import 'setting/wox_plugin_setting_filepath.dart';
 import 'setting/wox_plugin_setting_head.dart';
+import 'setting/wox_plugin_setting_hotkey.dart';
 import 'setting/wox_plugin_setting_label.dart';
 import 'setting/wox_plugin_setting_newline.dart';
+import 'setting/wox_plugin_setting_number.dart';
+import 'setting/wox_plugin_setting_password.dart';
 import 'setting/wox_plugin_setting_select.dart';
 import 'setting/wox_plugin_setting_select_ai_model.dart';
 import 'setting/wox_plugin_setting_textbox.dart';
@@ -13,6 +18,7 @@ class PluginSettingDefinitionItem {
   late dynamic value;
   late List<String> disabledInPlatforms;
   late bool isPlatformSpecific;
+  PluginSettingCondition? showWhen;
 
   PluginSettingDefinitionItem.fromJson(Map<String, dynamic> json) {
     if (json['DisabledInPlatforms'] == null) {
@@ -22,6 +28,9 @@ class PluginSettingDefinitionItem {
     }
     isPlatformSpecific = json['IsPlatformSpecific'];
     type = json['Type'];
+    if (json['ShowWhen'] != null) {
+      showWhen = PluginSettingCondition.fromJson(json['ShowWhen']);
+    }
 
     if (type == "checkbox") {
       value = PluginSettingValueCheckBox.fromJson(json['Value']);
@@ -39,10 +48,43 @@ class PluginSettingDefinitionItem {
       value = PluginSettingValueTable.fromJson(json['Value']);
     } else if (type == "textbox") {
       value = PluginSettingValueTextBox.fromJson(json['Value']);
+    } else if (type == "number") {
+      value = PluginSettingValueNumber.fromJson(json['Value']);
+    } else if (type == "password") {
+      value = PluginSettingValuePassword.fromJson(json['Value']);
+    } else if (type == "hotkey") {
+      value = PluginSettingValueHotkey.fromJson(json['Value']);
+    } else if (type == "filepath") {
+      value = PluginSettingValueFilePath.fromJson(json['Value']);
+    } else if (type == "color") {
+      value = PluginSettingValueColor.fromJson(json['Value']);
     } else {
       throw Exception("Unknown setting type: $type");
     }
   }
+
+  bool isVisible(Map<String, String> settings) {
+    return showWhen == null || showWhen!.isSatisfied(settings);
+  }
+}
+
+// satisfied when the value of setting key is one of values
+class PluginSettingCondition {
+  late String key;
+  late List<String> values;
+
+  PluginSettingCondition.fromJson(Map<String, dynamic> json) {
+    key = json['Key'];
+    if (json['Values'] != null) {
+      values = (json['Values'] as List).map((e) => e.toString()).toList();
+    } else {
+      values = [];
+    }
+  }
+
+  bool isSatisfied(Map<String, String> settings) {
+    return values.contains(settings[key] ?? "");
+  }
 }
 
 class PluginSettingValueStyle {
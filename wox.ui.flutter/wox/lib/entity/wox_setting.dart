@@ -105,12 +105,15 @@ class QueryHotkey {
 
   late bool isSilentExecution;
 
-  QueryHotkey({required this.hotkey, required this.query, required this.isSilentExecution});
+  late List<dynamic> workflow; // steps are edited in setting file, keep them as is
+
+  QueryHotkey({required this.hotkey, required this.query, required this.isSilentExecution, this.workflow = const []});
 
   QueryHotkey.fromJson(Map<String, dynamic> json) {
     hotkey = json['Hotkey'];
     query = json['Query'];
     isSilentExecution = json['IsSilentExecution'] ?? false;
+    workflow = json['Workflow'] ?? [];
   }
 
   Map<String, dynamic> toJson() {
@@ -118,6 +121,7 @@ class QueryHotkey {
     data['Hotkey'] = hotkey;
     data['Query'] = query;
     data['IsSilentExecution'] = isSilentExecution;
+    data['Workflow'] = workflow;
     return data;
   }
 }
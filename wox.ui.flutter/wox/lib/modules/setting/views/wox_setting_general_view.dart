@@ -209,7 +209,13 @@ class WoxSettingGeneralView extends GetView<WoxSettingController> {
                           "Label": "Query",
                           "Tooltip": "The query when the hotkey is triggered. Following variables are supported:\n\n"
                               "{wox:selected_text} represent the selected text.\n"
-                              "{wox:active_browser_url} represent the url of active browser tab.",
+                              "{wox:active_browser_url} represent the url of active browser tab.\n"
+                              "{wox:file_selection} represent the selected files.\n"
+                              "{wox:active_window_title} represent the title of active window.\n"
+                              "{wox:clipboard} represent the text in clipboard.\n"
+                              "{wox:date}, {wox:time} represent current date and time, E.g. {wox:date:2006-01-02}.\n"
+                              "{wox:env:NAME} represent the environment variable NAME.\n"
+                              "{wox:uuid} represent a random uuid.",
                           "Type": "text",
                           "TextMaxLines": 1,
                           "Validators": [
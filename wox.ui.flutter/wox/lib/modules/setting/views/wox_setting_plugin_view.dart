@@ -6,14 +6,24 @@ import 'package:flutter/material.dart' as material;
 import 'package:flutter/services.dart';
 import 'package:flutter_image_slideshow/flutter_image_slideshow.dart';
 import 'package:get/get.dart';
+import 'package:wox/components/plugin/wox_setting_plugin_color_view.dart';
+import 'package:wox/components/plugin/wox_setting_plugin_filepath_view.dart';
 import 'package:wox/components/plugin/wox_setting_plugin_head_view.dart';
+import 'package:wox/components/plugin/wox_setting_plugin_hotkey_view.dart';
 import 'package:wox/components/plugin/wox_setting_plugin_label_view.dart';
 import 'package:wox/components/plugin/wox_setting_plugin_newline_view.dart';
+import 'package:wox/components/plugin/wox_setting_plugin_number_view.dart';
+import 'package:wox/components/plugin/wox_setting_plugin_password_view.dart';
 import 'package:wox/components/plugin/wox_setting_plugin_select_ai_model_view.dart';
 import 'package:wox/components/plugin/wox_setting_plugin_select_view.dart';
 import 'package:wox/components/plugin/wox_setting_plugin_table_view.dart';
 import 'package:wox/components/wox_image_view.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_color.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_filepath.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_hotkey.dart';
 import 'package:wox/entity/setting/wox_plugin_setting_label.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_number.dart';
+import 'package:wox/entity/setting/wox_plugin_setting_password.dart';
 import 'package:wox/entity/setting/wox_plugin_setting_select_ai_model.dart';
 import 'package:wox/entity/setting/wox_plugin_setting_table.dart';
 import 'package:wox/entity/wox_plugin.dart';
@@ -378,7 +388,7 @@ class WoxSettingPluginView extends GetView<WoxSettingController> {
           child: Wrap(
             crossAxisAlignment: WrapCrossAlignment.center,
             children: [
-              ...plugin.settingDefinitions.map(
+              ...plugin.settingDefinitions.where((e) => e.isVisible(plugin.setting.settings)).map(
                 (e) {
                   if (e.type == "checkbox") {
                     return WoxSettingPluginCheckbox(
@@ -461,6 +471,57 @@ class WoxSettingPluginView extends GetView<WoxSettingController> {
                     );
                   }
 
+                  if (e.type == "number") {
+                    return WoxSettingPluginNumber(
+                      value: plugin.setting.settings[e.value.key] ?? "",
+                      item: e.value as PluginSettingValueNumber,
+                      onUpdate: (key, value) async {
+                        await controller.updatePluginSetting(plugin.id, key, value);
+                        controller.refreshPluginList();
+                      },
+                    );
+                  }
+                  if (e.type == "password") {
+                    return WoxSettingPluginPassword(
+                      value: plugin.setting.settings[e.value.key] ?? "",
+                      item: e.value as PluginSettingValuePassword,
+                      onUpdate: (key, value) async {
+                        await controller.updatePluginSetting(plugin.id, key, value);
+                        controller.refreshPluginList();
+                      },
+                    );
+                  }
+                  if (e.type == "hotkey") {
+                    return WoxSettingPluginHotkey(
+                      value: plugin.setting.settings[e.value.key] ?? "",
+                      item: e.value as PluginSettingValueHotkey,
+                      onUpdate: (key, value) async {
+                        await controller.updatePluginSetting(plugin.id, key, value);
+                        controller.refreshPluginList();
+                      },
+                    );
+                  }
+                  if (e.type == "filepath") {
+                    return WoxSettingPluginFilePath(
+                      value: plugin.setting.settings[e.value.key] ?? "",
+                      item: e.value as PluginSettingValueFilePath,
+                      onUpdate: (key, value) async {
+                        await controller.updatePluginSetting(plugin.id, key, value);
+                        controller.refreshPluginList();
+                      },
+                    );
+                  }
+                  if (e.type == "color") {
+                    return WoxSettingPluginColor(
+                      value: plugin.setting.settings[e.value.key] ?? "",
+                      item: e.value as PluginSettingValueColor,
+                      onUpdate: (key, value) async {
+                        await controller.updatePluginSetting(plugin.id, key, value);
+                        controller.refreshPluginList();
+                      },
+                    );
+                  }
+
                   return Text(e.type);
                 },
               )
@@ -2,11 +2,13 @@ import 'package:file_picker/file_picker.dart';
 
 class FileSelectorParams {
   late bool isDirectory;
+  late List<String> extensions; // allowed file extensions without dot, only used when isDirectory is false
 
-  FileSelectorParams({required this.isDirectory});
+  FileSelectorParams({required this.isDirectory, this.extensions = const []});
 
   FileSelectorParams.fromJson(Map<String, dynamic> json) {
     isDirectory = json['IsDirectory'];
+    extensions = [];
   }
 }
 
@@ -17,6 +19,14 @@ class FileSelector {
       if (selectedDirectory != null) {
         return [selectedDirectory];
       }
+    } else {
+      FilePickerResult? result = await FilePicker.platform.pickFiles(
+        type: params.extensions.isEmpty ? FileType.any : FileType.custom,
+        allowedExtensions: params.extensions.isEmpty ? null : params.extensions,
+      );
+      if (result != null) {
+        return result.paths.whereType<String>().toList();
+      }
     }
 
     return [];